@@ -0,0 +1,1230 @@
+// Code generated by `platform-backend openapi client`. DO NOT EDIT.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Client calls the platform backend API.
+type Client struct {
+	// BaseURL is the backend root, e.g. https://platform.internal (no /api/v1).
+	BaseURL string
+	// HTTP defaults to http.DefaultClient.
+	HTTP *http.Client
+}
+
+// New returns a Client for baseURL.
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// APIError is returned for any non-2xx response. Code, Details and
+// RequestID come from the server's error envelope.
+type APIError struct {
+	StatusCode int
+	ErrorResponse
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("platform API: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+	if e.RequestID != "" {
+		msg += " (request " + e.RequestID + ")"
+	}
+	return msg
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, contentType string, body, out interface{}) error {
+	var reqBody io.Reader
+	if contentType != "" {
+		data, err := encode(contentType, body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	u := strings.TrimRight(c.BaseURL, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := c.HTTP
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		if json.Unmarshal(data, &apiErr.ErrorResponse) != nil || apiErr.Code == "" {
+			// Not from a handler (proxy, unmatched route)
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		return apiErr
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+// Environment: Platform environment
+type Environment string
+
+const (
+	EnvironmentDev  Environment = "dev"
+	EnvironmentTest Environment = "test"
+	EnvironmentProd Environment = "prod"
+)
+
+type RepositorySpec struct {
+	// Organization to create the repository in; empty uses the default owner
+	Org string `json:"org,omitempty" yaml:"org,omitempty"`
+	// Defaults to private; internal requires org
+	Visibility string `json:"visibility,omitempty" yaml:"visibility,omitempty"`
+	// Defaults to dev
+	DefaultBranch string   `json:"defaultBranch,omitempty" yaml:"defaultBranch,omitempty"`
+	Description   string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Topics        []string `json:"topics,omitempty" yaml:"topics,omitempty"`
+	// ownerTeam access, defaults to push
+	TeamPermission string `json:"teamPermission,omitempty" yaml:"teamPermission,omitempty"`
+}
+
+// ServiceSecret: Secret written to the service's CI; values are never stored by the platform
+type ServiceSecret struct {
+	Name string `json:"name" yaml:"name"`
+	// Empty applies the secret to every environment
+	Environment string `json:"environment,omitempty" yaml:"environment,omitempty"`
+	// Literal value; exactly one of value and valueFrom
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+	// Platform secret reference, e.g. vault:teams/payments#registry
+	ValueFrom string `json:"valueFrom,omitempty" yaml:"valueFrom,omitempty"`
+}
+
+// CreateServiceRequest: Service definition, sent as YAML
+type CreateServiceRequest struct {
+	ServiceName string `json:"serviceName" yaml:"serviceName"`
+	RepoName    string `json:"repoName" yaml:"repoName"`
+	OwnerTeam   string `json:"ownerTeam" yaml:"ownerTeam"`
+	// Template language, e.g. go
+	Runtime  string `json:"runtime" yaml:"runtime"`
+	CicdType string `json:"cicdType" yaml:"cicdType"`
+	// e.g. v1
+	TemplateVersion string `json:"templateVersion" yaml:"templateVersion"`
+	// kubernetes services get a pipeline that only builds images; the platform deploys them by committing the image tag to the environment's GitOps repository
+	Deploytype    string        `json:"deploytype" yaml:"deploytype"`
+	Environments  []Environment `json:"environments" yaml:"environments"`
+	EnableWebhook bool          `json:"enableWebhook,omitempty" yaml:"enableWebhook,omitempty"`
+	RepoMode      string        `json:"repoMode,omitempty" yaml:"repoMode,omitempty"`
+	// Monorepo mode: owner/name or name of an existing repository
+	TargetRepo string `json:"targetRepo,omitempty" yaml:"targetRepo,omitempty"`
+	// Monorepo mode: service directory inside targetRepo
+	RepoPath   string          `json:"repoPath,omitempty" yaml:"repoPath,omitempty"`
+	Repository *RepositorySpec `json:"repository,omitempty" yaml:"repository,omitempty"`
+	Secrets    []ServiceSecret `json:"secrets,omitempty" yaml:"secrets,omitempty"`
+	Infra      *InfraSpec      `json:"infra,omitempty" yaml:"infra,omitempty"`
+}
+
+type CreateServiceResponse struct {
+	RepoURL string `json:"repoUrl" yaml:"repoUrl"`
+	// Monorepo mode: pull request adding the service
+	PullRequestURL string `json:"pullRequestUrl,omitempty" yaml:"pullRequestUrl,omitempty"`
+}
+
+type ImportServiceRequest struct {
+	ServiceName string `json:"serviceName" yaml:"serviceName"`
+	// owner/name or name
+	Repo         string        `json:"repo" yaml:"repo"`
+	OwnerTeam    string        `json:"ownerTeam" yaml:"ownerTeam"`
+	Environments []Environment `json:"environments,omitempty" yaml:"environments,omitempty"`
+	// Detected when empty
+	Runtime string `json:"runtime,omitempty" yaml:"runtime,omitempty"`
+	// Detected when empty
+	CicdType string `json:"cicdType,omitempty" yaml:"cicdType,omitempty"`
+	// Defaults to microservice
+	Deploytype      string `json:"deploytype,omitempty" yaml:"deploytype,omitempty"`
+	RegisterJenkins bool   `json:"registerJenkins,omitempty" yaml:"registerJenkins,omitempty"`
+	EnableWebhook   bool   `json:"enableWebhook,omitempty" yaml:"enableWebhook,omitempty"`
+	// Open a PR adding config.json and the artifact callback
+	AddPlatformConfig bool `json:"addPlatformConfig,omitempty" yaml:"addPlatformConfig,omitempty"`
+}
+
+type ImportServiceResponse struct {
+	ServiceName    string `json:"serviceName" yaml:"serviceName"`
+	RepoURL        string `json:"repoUrl" yaml:"repoUrl"`
+	Runtime        string `json:"runtime" yaml:"runtime"`
+	CicdType       string `json:"cicdType" yaml:"cicdType"`
+	PullRequestURL string `json:"pullRequestUrl,omitempty" yaml:"pullRequestUrl,omitempty"`
+	// Environment → seeded version
+	Environments map[string]string `json:"environments" yaml:"environments"`
+}
+
+type Service struct {
+	ServiceName     string `json:"serviceName" yaml:"serviceName"`
+	RepoName        string `json:"repoName" yaml:"repoName"`
+	OwnerTeam       string `json:"ownerTeam" yaml:"ownerTeam"`
+	Runtime         string `json:"runtime" yaml:"runtime"`
+	CicdType        string `json:"cicdType" yaml:"cicdType"`
+	TemplateVersion string `json:"templateVersion" yaml:"templateVersion"`
+	DeployType      string `json:"deployType" yaml:"deployType"`
+	RepoMode        string `json:"repoMode" yaml:"repoMode"`
+	RepoPath        string `json:"repoPath" yaml:"repoPath"`
+	// Environment → deployment status
+	Environments map[string]string `json:"environments" yaml:"environments"`
+}
+
+type EnvironmentDashboard struct {
+	CurrentVersion *string       `json:"currentVersion" yaml:"currentVersion"`
+	Status         string        `json:"status" yaml:"status"`
+	DeployedAt     *time.Time    `json:"deployedAt" yaml:"deployedAt"`
+	Argocd         *ArgoCDStatus `json:"argocd,omitempty" yaml:"argocd,omitempty"`
+}
+
+// ArgoCDStatus: Live status of the Argo CD Application deploying a microservice service to the environment. Absent when Argo CD is not configured or has no Application for it.
+type ArgoCDStatus struct {
+	Application string `json:"application" yaml:"application"`
+	// Synced, OutOfSync or Unknown
+	SyncStatus string `json:"syncStatus" yaml:"syncStatus"`
+	// Healthy, Progressing, Degraded, Suspended, Missing or Unknown
+	HealthStatus string `json:"healthStatus" yaml:"healthStatus"`
+	// Commit the Application is synced to
+	Revision string `json:"revision,omitempty" yaml:"revision,omitempty"`
+	// Phase of the last sync: Running, Succeeded, Failed or Error
+	Operation string `json:"operation,omitempty" yaml:"operation,omitempty"`
+	Message   string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+type ServiceDashboard struct {
+	ServiceName  string                          `json:"serviceName" yaml:"serviceName"`
+	Environments map[string]EnvironmentDashboard `json:"environments" yaml:"environments"`
+}
+
+type Artifact struct {
+	Version   string    `json:"version" yaml:"version"`
+	CreatedAt time.Time `json:"createdAt" yaml:"createdAt"`
+}
+
+// ArtifactEvent: Reported by pipelines after a successful deploy or rollback
+type ArtifactEvent struct {
+	ServiceName string `json:"serviceName" yaml:"serviceName"`
+	// dev, test, pre-prod, prod or a pull request preview pr-<number>
+	Environment  string `json:"environment" yaml:"environment"`
+	Version      string `json:"version" yaml:"version"`
+	ArtifactType string `json:"artifactType,omitempty" yaml:"artifactType,omitempty"`
+	CommitSHA    string `json:"commitSha,omitempty" yaml:"commitSha,omitempty"`
+	Pipeline     string `json:"pipeline" yaml:"pipeline"`
+	Action       string `json:"action" yaml:"action"`
+	Status       string `json:"status" yaml:"status"`
+}
+
+type DeployRequest struct {
+	Environment Environment `json:"environment" yaml:"environment"`
+	// Recorded on the deployment lock
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+	// Run at this time (within 30 days) instead of now. Prod still needs approval first; approvals and freezes are re-checked when it runs.
+	ScheduledAt *time.Time `json:"scheduledAt,omitempty" yaml:"scheduledAt,omitempty"`
+}
+
+type DeployAccepted struct {
+	// pending_approval when a prod deploy waits for approval; scheduled when queued for scheduledAt
+	Status                string     `json:"status,omitempty" yaml:"status,omitempty"`
+	Message               string     `json:"message,omitempty" yaml:"message,omitempty"`
+	ScheduledDeploymentID int64      `json:"scheduledDeploymentId,omitempty" yaml:"scheduledDeploymentId,omitempty"`
+	ScheduledAt           *time.Time `json:"scheduledAt,omitempty" yaml:"scheduledAt,omitempty"`
+	// Approval a scheduled prod deploy waits for
+	ApprovalID int64 `json:"approvalId,omitempty" yaml:"approvalId,omitempty"`
+	// Canary or blue-green rollout whose first step was triggered
+	RolloutID int64 `json:"rolloutId,omitempty" yaml:"rolloutId,omitempty"`
+}
+
+type ScheduledDeployment struct {
+	ID          int64       `json:"id" yaml:"id"`
+	ServiceName string      `json:"serviceName" yaml:"serviceName"`
+	Environment Environment `json:"environment" yaml:"environment"`
+	ScheduledAt time.Time   `json:"scheduledAt" yaml:"scheduledAt"`
+	ApprovalID  int64       `json:"approvalId,omitempty" yaml:"approvalId,omitempty"`
+	Status      string      `json:"status" yaml:"status"`
+	Reason      string      `json:"reason,omitempty" yaml:"reason,omitempty"`
+	RequestedBy string      `json:"requestedBy" yaml:"requestedBy"`
+	// Why it is waiting, or did not run
+	LastError  string     `json:"lastError,omitempty" yaml:"lastError,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt" yaml:"createdAt"`
+	ExecutedAt *time.Time `json:"executedAt,omitempty" yaml:"executedAt,omitempty"`
+}
+
+type HealthCheckRequest struct {
+	// Absolute http(s) URL probed with GET
+	URL string `json:"url" yaml:"url"`
+	// Defaults to 200
+	ExpectedStatus int64 `json:"expectedStatus,omitempty" yaml:"expectedStatus,omitempty"`
+	// How long the service must stay healthy; defaults to 300
+	WindowSeconds int64 `json:"windowSeconds,omitempty" yaml:"windowSeconds,omitempty"`
+	// Time between probes; defaults to 10, at most windowSeconds
+	IntervalSeconds int64 `json:"intervalSeconds,omitempty" yaml:"intervalSeconds,omitempty"`
+	// Per probe; defaults to 5, at most intervalSeconds
+	TimeoutSeconds int64 `json:"timeoutSeconds,omitempty" yaml:"timeoutSeconds,omitempty"`
+	// Consecutive failures that roll the deploy back; defaults to 3
+	FailureThreshold int64 `json:"failureThreshold,omitempty" yaml:"failureThreshold,omitempty"`
+}
+
+type HealthCheck struct {
+	ServiceName      string      `json:"serviceName" yaml:"serviceName"`
+	Environment      Environment `json:"environment" yaml:"environment"`
+	URL              string      `json:"url" yaml:"url"`
+	ExpectedStatus   int64       `json:"expectedStatus" yaml:"expectedStatus"`
+	WindowSeconds    int64       `json:"windowSeconds" yaml:"windowSeconds"`
+	IntervalSeconds  int64       `json:"intervalSeconds" yaml:"intervalSeconds"`
+	TimeoutSeconds   int64       `json:"timeoutSeconds" yaml:"timeoutSeconds"`
+	FailureThreshold int64       `json:"failureThreshold" yaml:"failureThreshold"`
+	UpdatedBy        string      `json:"updatedBy" yaml:"updatedBy"`
+	UpdatedAt        time.Time   `json:"updatedAt" yaml:"updatedAt"`
+}
+
+type HealthCheckRun struct {
+	ID          int64       `json:"id" yaml:"id"`
+	ServiceName string      `json:"serviceName" yaml:"serviceName"`
+	Environment Environment `json:"environment" yaml:"environment"`
+	Version     string      `json:"version" yaml:"version"`
+	// Version rolled back to
+	PreviousVersion string `json:"previousVersion,omitempty" yaml:"previousVersion,omitempty"`
+	// failed means the check failed but the deploy was not rolled back; see detail
+	Status     string     `json:"status" yaml:"status"`
+	Detail     string     `json:"detail,omitempty" yaml:"detail,omitempty"`
+	StartedAt  time.Time  `json:"startedAt" yaml:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty" yaml:"finishedAt,omitempty"`
+}
+
+type DeploymentStrategyRequest struct {
+	Strategy string `json:"strategy" yaml:"strategy"`
+	// Canary only: traffic percent after each step, increasing and ending at 100
+	CanarySteps []int64 `json:"canarySteps,omitempty" yaml:"canarySteps,omitempty"`
+	// Canary and blue-green: start the next step this long after the previous one reported back; 0 waits for promote
+	StepIntervalSeconds int64 `json:"stepIntervalSeconds,omitempty" yaml:"stepIntervalSeconds,omitempty"`
+}
+
+type DeploymentStrategy struct {
+	ServiceName         string      `json:"serviceName" yaml:"serviceName"`
+	Environment         Environment `json:"environment" yaml:"environment"`
+	Strategy            string      `json:"strategy" yaml:"strategy"`
+	CanarySteps         []int64     `json:"canarySteps,omitempty" yaml:"canarySteps,omitempty"`
+	StepIntervalSeconds int64       `json:"stepIntervalSeconds,omitempty" yaml:"stepIntervalSeconds,omitempty"`
+	UpdatedBy           string      `json:"updatedBy" yaml:"updatedBy"`
+	UpdatedAt           time.Time   `json:"updatedAt" yaml:"updatedAt"`
+}
+
+type Rollout struct {
+	ID          int64       `json:"id" yaml:"id"`
+	ServiceName string      `json:"serviceName" yaml:"serviceName"`
+	Environment Environment `json:"environment" yaml:"environment"`
+	Strategy    string      `json:"strategy" yaml:"strategy"`
+	// Traffic percent after each step
+	Steps []int64 `json:"steps" yaml:"steps"`
+	// 1-based; the step running or last finished
+	CurrentStep         int64 `json:"currentStep" yaml:"currentStep"`
+	StepIntervalSeconds int64 `json:"stepIntervalSeconds,omitempty" yaml:"stepIntervalSeconds,omitempty"`
+	// Version built by the first step
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// Version an abort rolls back to
+	PreviousVersion string `json:"previousVersion,omitempty" yaml:"previousVersion,omitempty"`
+	Status          string `json:"status" yaml:"status"`
+	// When a waiting rollout advances on its own
+	NextStepAt *time.Time    `json:"nextStepAt,omitempty" yaml:"nextStepAt,omitempty"`
+	StartedBy  string        `json:"startedBy" yaml:"startedBy"`
+	CreatedAt  time.Time     `json:"createdAt" yaml:"createdAt"`
+	FinishedAt *time.Time    `json:"finishedAt,omitempty" yaml:"finishedAt,omitempty"`
+	History    []RolloutStep `json:"history" yaml:"history"`
+}
+
+type RolloutStep struct {
+	Step           int64      `json:"step" yaml:"step"`
+	Phase          string     `json:"phase" yaml:"phase"`
+	TrafficPercent int64      `json:"trafficPercent" yaml:"trafficPercent"`
+	Status         string     `json:"status" yaml:"status"`
+	TriggeredBy    string     `json:"triggeredBy" yaml:"triggeredBy"`
+	TriggeredAt    time.Time  `json:"triggeredAt" yaml:"triggeredAt"`
+	FinishedAt     *time.Time `json:"finishedAt,omitempty" yaml:"finishedAt,omitempty"`
+	Error          string     `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+type RollbackRequest struct {
+	Environment Environment `json:"environment" yaml:"environment"`
+	Version     string      `json:"version" yaml:"version"`
+	// Recorded on the deployment lock
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+	// Roll back through an active deploy freeze; requires reason and is audited
+	Emergency bool `json:"emergency,omitempty" yaml:"emergency,omitempty"`
+}
+
+type Message struct {
+	Message string `json:"message" yaml:"message"`
+}
+
+type Approval struct {
+	ID          int64      `json:"id" yaml:"id"`
+	ServiceName string     `json:"serviceName" yaml:"serviceName"`
+	Environment string     `json:"environment" yaml:"environment"`
+	Status      string     `json:"status" yaml:"status"`
+	CreatedAt   time.Time  `json:"createdAt" yaml:"createdAt"`
+	ApprovedAt  *time.Time `json:"approvedAt,omitempty" yaml:"approvedAt,omitempty"`
+}
+
+type BranchCompliance struct {
+	Environment string   `json:"environment" yaml:"environment"`
+	Branch      string   `json:"branch" yaml:"branch"`
+	Exists      bool     `json:"exists" yaml:"exists"`
+	Protected   bool     `json:"protected" yaml:"protected"`
+	Compliant   bool     `json:"compliant" yaml:"compliant"`
+	Issues      []string `json:"issues,omitempty" yaml:"issues,omitempty"`
+}
+
+type ProtectionReport struct {
+	ServiceName string `json:"serviceName" yaml:"serviceName"`
+	// owner/name
+	Repo      string             `json:"repo" yaml:"repo"`
+	Compliant bool               `json:"compliant" yaml:"compliant"`
+	Branches  []BranchCompliance `json:"branches" yaml:"branches"`
+}
+
+type PutSecretsRequest struct {
+	Secrets []ServiceSecret `json:"secrets" yaml:"secrets"`
+}
+
+type SecretMetadata struct {
+	Name        string    `json:"name" yaml:"name"`
+	Environment string    `json:"environment" yaml:"environment"`
+	Store       string    `json:"store" yaml:"store"`
+	RotatedAt   time.Time `json:"rotatedAt" yaml:"rotatedAt"`
+}
+
+type Health struct {
+	Status string `json:"status" yaml:"status"`
+}
+
+type Readiness struct {
+	Status string `json:"status" yaml:"status"`
+	// Check name → ok or the failure
+	Checks map[string]string `json:"checks,omitempty" yaml:"checks,omitempty"`
+}
+
+// FreezeWindowRequest: Omit environment and ownerTeam for a global freeze
+type FreezeWindowRequest struct {
+	Name        string      `json:"name" yaml:"name"`
+	Environment Environment `json:"environment,omitempty" yaml:"environment,omitempty"`
+	// Only services owned by this team; omit for every team
+	OwnerTeam string `json:"ownerTeam,omitempty" yaml:"ownerTeam,omitempty"`
+	// Cron ("0 18 * * FRI") or RRULE ("FREQ=MONTHLY;BYMONTHDAY=-1"); omit for a one-off window
+	Schedule string `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+	// Length of each scheduled occurrence
+	DurationMinutes int64 `json:"durationMinutes,omitempty" yaml:"durationMinutes,omitempty"`
+	// IANA zone the schedule is evaluated in; defaults to UTC
+	Timezone string `json:"timezone,omitempty" yaml:"timezone,omitempty"`
+	// Start of a one-off window, or when a schedule starts applying
+	StartsAt *time.Time `json:"startsAt,omitempty" yaml:"startsAt,omitempty"`
+	// End of a one-off window, or when a schedule stops applying
+	EndsAt *time.Time `json:"endsAt,omitempty" yaml:"endsAt,omitempty"`
+	Reason string     `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+type FreezeWindow struct {
+	ID          int64       `json:"id" yaml:"id"`
+	Name        string      `json:"name" yaml:"name"`
+	Environment Environment `json:"environment,omitempty" yaml:"environment,omitempty"`
+	// Only services owned by this team; omit for every team
+	OwnerTeam string `json:"ownerTeam,omitempty" yaml:"ownerTeam,omitempty"`
+	// Cron ("0 18 * * FRI") or RRULE ("FREQ=MONTHLY;BYMONTHDAY=-1"); omit for a one-off window
+	Schedule string `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+	// Length of each scheduled occurrence
+	DurationMinutes int64 `json:"durationMinutes,omitempty" yaml:"durationMinutes,omitempty"`
+	// IANA zone the schedule is evaluated in; defaults to UTC
+	Timezone string `json:"timezone" yaml:"timezone"`
+	// Start of a one-off window, or when a schedule starts applying
+	StartsAt *time.Time `json:"startsAt,omitempty" yaml:"startsAt,omitempty"`
+	// End of a one-off window, or when a schedule stops applying
+	EndsAt    *time.Time `json:"endsAt,omitempty" yaml:"endsAt,omitempty"`
+	Reason    string     `json:"reason" yaml:"reason"`
+	CreatedBy string     `json:"createdBy" yaml:"createdBy"`
+	CreatedAt time.Time  `json:"createdAt" yaml:"createdAt"`
+}
+
+type FreezeExemptionRequest struct {
+	ServiceName string      `json:"serviceName" yaml:"serviceName"`
+	Environment Environment `json:"environment" yaml:"environment"`
+	Reason      string      `json:"reason" yaml:"reason"`
+	// Exemption stops applying here
+	ExpiresAt time.Time `json:"expiresAt" yaml:"expiresAt"`
+}
+
+type FreezeExemption struct {
+	ID          int64       `json:"id" yaml:"id"`
+	WindowID    int64       `json:"windowId" yaml:"windowId"`
+	ServiceName string      `json:"serviceName" yaml:"serviceName"`
+	Environment Environment `json:"environment" yaml:"environment"`
+	Reason      string      `json:"reason" yaml:"reason"`
+	RequestedBy string      `json:"requestedBy" yaml:"requestedBy"`
+	Status      string      `json:"status" yaml:"status"`
+	DecidedBy   string      `json:"decidedBy,omitempty" yaml:"decidedBy,omitempty"`
+	ExpiresAt   time.Time   `json:"expiresAt" yaml:"expiresAt"`
+	CreatedAt   time.Time   `json:"createdAt" yaml:"createdAt"`
+	DecidedAt   *time.Time  `json:"decidedAt,omitempty" yaml:"decidedAt,omitempty"`
+}
+
+type FreezeOccurrence struct {
+	WindowID    int64       `json:"windowId" yaml:"windowId"`
+	Name        string      `json:"name" yaml:"name"`
+	Environment Environment `json:"environment,omitempty" yaml:"environment,omitempty"`
+	OwnerTeam   string      `json:"ownerTeam,omitempty" yaml:"ownerTeam,omitempty"`
+	Reason      string      `json:"reason" yaml:"reason"`
+	Start       time.Time   `json:"start" yaml:"start"`
+	End         time.Time   `json:"end" yaml:"end"`
+}
+
+type DeploymentLock struct {
+	ServiceName string      `json:"serviceName" yaml:"serviceName"`
+	Environment Environment `json:"environment" yaml:"environment"`
+	Operation   string      `json:"operation" yaml:"operation"`
+	// X-Forwarded-User of the caller, or anonymous@<address>
+	Owner      string    `json:"owner" yaml:"owner"`
+	Reason     string    `json:"reason" yaml:"reason"`
+	AcquiredAt time.Time `json:"acquiredAt" yaml:"acquiredAt"`
+	// Lock lapses here if the pipeline never reports back
+	ExpiresAt time.Time `json:"expiresAt" yaml:"expiresAt"`
+}
+
+// ArtifactEnvironment: Environments pipelines report artifacts for
+type ArtifactEnvironment string
+
+const (
+	ArtifactEnvironmentDev     ArtifactEnvironment = "dev"
+	ArtifactEnvironmentTest    ArtifactEnvironment = "test"
+	ArtifactEnvironmentPreProd ArtifactEnvironment = "pre-prod"
+	ArtifactEnvironmentProd    ArtifactEnvironment = "prod"
+)
+
+type FieldError struct {
+	// Request field, e.g. repository.visibility
+	Field   string `json:"field" yaml:"field"`
+	Message string `json:"message" yaml:"message"`
+}
+
+type ErrorResponse struct {
+	Code    string `json:"code" yaml:"code"`
+	Message string `json:"message" yaml:"message"`
+	// Set for validation_failed
+	Details []FieldError `json:"details,omitempty" yaml:"details,omitempty"`
+	// Matches the X-Request-ID response header and server logs
+	RequestID string `json:"requestId" yaml:"requestId"`
+}
+
+// InfraSpec: Infrastructure of an ec2 service: a Terraform root module per environment, sized to an instance type from cpu and memory. Needs terraform.stateBucket configured.
+type InfraSpec struct {
+	Cloud   string `json:"cloud,omitempty" yaml:"cloud,omitempty"`
+	Compute string `json:"compute,omitempty" yaml:"compute,omitempty"`
+	// vCPUs, e.g. 2 or 500m
+	Cpu string `json:"cpu,omitempty" yaml:"cpu,omitempty"`
+	// e.g. 512Mi or 4Gi
+	Memory string `json:"memory,omitempty" yaml:"memory,omitempty"`
+	// Platform Terraform modules, e.g. v1; defaults to terraform.moduleVersion
+	ModuleVersion string `json:"moduleVersion,omitempty" yaml:"moduleVersion,omitempty"`
+}
+
+type InfraRun struct {
+	ID          int64       `json:"id" yaml:"id"`
+	ServiceName string      `json:"serviceName" yaml:"serviceName"`
+	Environment Environment `json:"environment" yaml:"environment"`
+	Action      string      `json:"action" yaml:"action"`
+	Status      string      `json:"status" yaml:"status"`
+	// s3://bucket/key of the environment's state
+	StateLocation string `json:"stateLocation" yaml:"stateLocation"`
+	// Plan / apply output; only on a single run
+	Output string `json:"output,omitempty" yaml:"output,omitempty"`
+	// CI run
+	RunURL      string     `json:"runUrl,omitempty" yaml:"runUrl,omitempty"`
+	RequestedBy string     `json:"requestedBy" yaml:"requestedBy"`
+	CreatedAt   time.Time  `json:"createdAt" yaml:"createdAt"`
+	FinishedAt  *time.Time `json:"finishedAt,omitempty" yaml:"finishedAt,omitempty"`
+}
+
+type InfraStack struct {
+	ServiceName string      `json:"serviceName" yaml:"serviceName"`
+	Environment Environment `json:"environment" yaml:"environment"`
+	Cloud       string      `json:"cloud" yaml:"cloud"`
+	Compute     string      `json:"compute" yaml:"compute"`
+	Cpu         string      `json:"cpu" yaml:"cpu"`
+	Memory      string      `json:"memory" yaml:"memory"`
+	// Sized from cpu and memory, e.g. t3.medium
+	InstanceType  string `json:"instanceType" yaml:"instanceType"`
+	ModuleVersion string `json:"moduleVersion" yaml:"moduleVersion"`
+	// Root module in the repository, e.g. infra/dev
+	RootModule string `json:"rootModule" yaml:"rootModule"`
+	// s3://bucket/key
+	StateLocation string    `json:"stateLocation" yaml:"stateLocation"`
+	CreatedAt     time.Time `json:"createdAt" yaml:"createdAt"`
+	LastPlan      *InfraRun `json:"lastPlan,omitempty" yaml:"lastPlan,omitempty"`
+	LastApply     *InfraRun `json:"lastApply,omitempty" yaml:"lastApply,omitempty"`
+}
+
+type InfraRunRequest struct {
+	Environment Environment `json:"environment" yaml:"environment"`
+	Action      string      `json:"action" yaml:"action"`
+}
+
+type InfraRunResult struct {
+	Status string `json:"status" yaml:"status"`
+	Output string `json:"output,omitempty" yaml:"output,omitempty"`
+	RunURL string `json:"runUrl,omitempty" yaml:"runUrl,omitempty"`
+}
+
+type ServiceConfig struct {
+	ServiceName string            `json:"serviceName" yaml:"serviceName"`
+	Environment Environment       `json:"environment" yaml:"environment"`
+	Version     int64             `json:"version" yaml:"version"`
+	Values      map[string]string `json:"values" yaml:"values"`
+	// Path of the rendered file, relative to the service
+	File           string `json:"file" yaml:"file"`
+	Delivery       string `json:"delivery" yaml:"delivery"`
+	Status         string `json:"status" yaml:"status"`
+	CommitSHA      string `json:"commitSha,omitempty" yaml:"commitSha,omitempty"`
+	PullRequestURL string `json:"pullRequestUrl,omitempty" yaml:"pullRequestUrl,omitempty"`
+	// Why a failed version did not reach the repository
+	Error     string    `json:"error,omitempty" yaml:"error,omitempty"`
+	Message   string    `json:"message,omitempty" yaml:"message,omitempty"`
+	UpdatedBy string    `json:"updatedBy" yaml:"updatedBy"`
+	UpdatedAt time.Time `json:"updatedAt" yaml:"updatedAt"`
+}
+
+type ConfigUpdate struct {
+	// Keys start with a letter or '_' and hold letters, digits, '_', '.' or '-'; serviceName and repoUrl are reserved
+	Values map[string]string `json:"values" yaml:"values"`
+	// config.json or config.<env>.json; defaults to the current file, else config.<env>.json
+	File string `json:"file,omitempty" yaml:"file,omitempty"`
+	// Defaults to pull_request in prod, commit elsewhere; a commit to a protected branch is proposed as a pull request instead
+	Delivery string `json:"delivery,omitempty" yaml:"delivery,omitempty"`
+	Message  string `json:"message,omitempty" yaml:"message,omitempty"`
+	// Version the change was made against
+	BaseVersion int64 `json:"baseVersion,omitempty" yaml:"baseVersion,omitempty"`
+}
+
+type ConfigChange struct {
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to" yaml:"to"`
+}
+
+type ConfigDiff struct {
+	ServiceName string      `json:"serviceName" yaml:"serviceName"`
+	Environment Environment `json:"environment" yaml:"environment"`
+	// 0 is the empty configuration
+	From    int64                   `json:"from" yaml:"from"`
+	To      int64                   `json:"to" yaml:"to"`
+	Added   map[string]string       `json:"added" yaml:"added"`
+	Removed map[string]string       `json:"removed" yaml:"removed"`
+	Changed map[string]ConfigChange `json:"changed" yaml:"changed"`
+}
+
+// Preview: Short-lived environment deploying the head of a pull request
+type Preview struct {
+	ServiceName string `json:"serviceName" yaml:"serviceName"`
+	// pr-<number>
+	Environment string `json:"environment" yaml:"environment"`
+	PullRequest int64  `json:"pullRequest" yaml:"pullRequest"`
+	// owner/name
+	Repository string `json:"repository" yaml:"repository"`
+	HeadRef    string `json:"headRef" yaml:"headRef"`
+	HeadSHA    string `json:"headSha" yaml:"headSha"`
+	BaseRef    string `json:"baseRef" yaml:"baseRef"`
+	Status     string `json:"status" yaml:"status"`
+	// Deployed version, once the pipeline reports it
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// Where the preview is reachable; also posted on the pull request
+	URL string `json:"url,omitempty" yaml:"url,omitempty"`
+	// Why the last deploy or teardown could not be started
+	Error     string    `json:"error,omitempty" yaml:"error,omitempty"`
+	CreatedAt time.Time `json:"createdAt" yaml:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt" yaml:"updatedAt"`
+	// Torn down at this time unless pushed to
+	ExpiresAt time.Time  `json:"expiresAt" yaml:"expiresAt"`
+	ClosedAt  *time.Time `json:"closedAt,omitempty" yaml:"closedAt,omitempty"`
+}
+
+// Healthz calls GET /healthz.
+//
+// Liveness probe.
+func (c *Client) Healthz(ctx context.Context) (Health, error) {
+	path := "/healthz"
+	var out Health
+	err := c.do(ctx, "GET", path, nil, "", nil, &out)
+	return out, err
+}
+
+// Readyz calls GET /readyz.
+//
+// Readiness probe.
+func (c *Client) Readyz(ctx context.Context) (Readiness, error) {
+	path := "/readyz"
+	var out Readiness
+	err := c.do(ctx, "GET", path, nil, "", nil, &out)
+	return out, err
+}
+
+// GetOpenAPI calls GET /openapi.json.
+//
+// This document.
+func (c *Client) GetOpenAPI(ctx context.Context) (map[string]interface{}, error) {
+	path := "/openapi.json"
+	var out map[string]interface{}
+	err := c.do(ctx, "GET", path, nil, "", nil, &out)
+	return out, err
+}
+
+// ListServices calls GET /api/v1/services.
+//
+// List services with deployment status per environment.
+func (c *Client) ListServices(ctx context.Context) ([]Service, error) {
+	path := "/api/v1/services"
+	var out []Service
+	err := c.do(ctx, "GET", path, nil, "", nil, &out)
+	return out, err
+}
+
+// CreateService calls POST /api/v1/services.
+//
+// Create a service from a golden template.
+func (c *Client) CreateService(ctx context.Context, body *CreateServiceRequest) (CreateServiceResponse, error) {
+	path := "/api/v1/services"
+	var out CreateServiceResponse
+	err := c.do(ctx, "POST", path, nil, "application/x-yaml", body, &out)
+	return out, err
+}
+
+// ImportService calls POST /api/v1/services:import.
+//
+// Register an existing repository as a service.
+func (c *Client) ImportService(ctx context.Context, body *ImportServiceRequest) (ImportServiceResponse, error) {
+	path := "/api/v1/services:import"
+	var out ImportServiceResponse
+	err := c.do(ctx, "POST", path, nil, "application/json", body, &out)
+	return out, err
+}
+
+// GetServiceDashboard calls GET /api/v1/services/{name}/dashboard.
+//
+// Current version and status per environment.
+func (c *Client) GetServiceDashboard(ctx context.Context, name string) (ServiceDashboard, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/dashboard", url.PathEscape(name))
+	var out ServiceDashboard
+	err := c.do(ctx, "GET", path, nil, "", nil, &out)
+	return out, err
+}
+
+// ListServiceEnvironments calls GET /api/v1/services/{name}/environments.
+//
+// Environments the service has state in.
+func (c *Client) ListServiceEnvironments(ctx context.Context, name string) ([]string, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/environments", url.PathEscape(name))
+	var out []string
+	err := c.do(ctx, "GET", path, nil, "", nil, &out)
+	return out, err
+}
+
+// ListServiceArtifacts calls GET /api/v1/services/{name}/artifacts.
+//
+// Artifacts built for an environment, newest first.
+func (c *Client) ListServiceArtifacts(ctx context.Context, name string, environment ArtifactEnvironment) ([]Artifact, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/artifacts", url.PathEscape(name))
+	q := url.Values{}
+	if environment != "" {
+		q.Set("environment", string(environment))
+	}
+	var out []Artifact
+	err := c.do(ctx, "GET", path, q, "", nil, &out)
+	return out, err
+}
+
+// GetBranchProtection calls GET /api/v1/services/{name}/branch-protection.
+//
+// Branch protection compliance of the service's environment branches.
+func (c *Client) GetBranchProtection(ctx context.Context, name string) (ProtectionReport, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/branch-protection", url.PathEscape(name))
+	var out ProtectionReport
+	err := c.do(ctx, "GET", path, nil, "", nil, &out)
+	return out, err
+}
+
+// DeployService calls POST /api/v1/services/{name}/deployments.
+//
+// Deploy to an environment (prod waits for approval).
+func (c *Client) DeployService(ctx context.Context, name string, body *DeployRequest) (DeployAccepted, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/deployments", url.PathEscape(name))
+	var out DeployAccepted
+	err := c.do(ctx, "POST", path, nil, "application/json", body, &out)
+	return out, err
+}
+
+// ListScheduledDeployments calls GET /api/v1/services/{name}/scheduled-deployments.
+//
+// Scheduled deployments of a service, latest first.
+func (c *Client) ListScheduledDeployments(ctx context.Context, name string) ([]ScheduledDeployment, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/scheduled-deployments", url.PathEscape(name))
+	var out []ScheduledDeployment
+	err := c.do(ctx, "GET", path, nil, "", nil, &out)
+	return out, err
+}
+
+// CancelScheduledDeployment calls DELETE /api/v1/services/{name}/scheduled-deployments/{id}.
+//
+// Cancel a scheduled deployment that has not started.
+func (c *Client) CancelScheduledDeployment(ctx context.Context, name string, id int64) (Message, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/scheduled-deployments/%s", url.PathEscape(name), url.PathEscape(fmt.Sprint(id)))
+	var out Message
+	err := c.do(ctx, "DELETE", path, nil, "", nil, &out)
+	return out, err
+}
+
+// RollbackService calls POST /api/v1/services/{name}/rollbacks.
+//
+// Roll an environment back to an earlier artifact.
+func (c *Client) RollbackService(ctx context.Context, name string, body *RollbackRequest) (Message, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/rollbacks", url.PathEscape(name))
+	var out Message
+	err := c.do(ctx, "POST", path, nil, "application/json", body, &out)
+	return out, err
+}
+
+// ListDeploymentStrategies calls GET /api/v1/services/{name}/strategies.
+//
+// List the deployment strategies set per environment.
+func (c *Client) ListDeploymentStrategies(ctx context.Context, name string) ([]DeploymentStrategy, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/strategies", url.PathEscape(name))
+	var out []DeploymentStrategy
+	err := c.do(ctx, "GET", path, nil, "", nil, &out)
+	return out, err
+}
+
+// PutDeploymentStrategy calls PUT /api/v1/services/{name}/strategies/{env}.
+//
+// Set how deploys to an environment roll out.
+func (c *Client) PutDeploymentStrategy(ctx context.Context, name string, env Environment, body *DeploymentStrategyRequest) (DeploymentStrategy, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/strategies/%s", url.PathEscape(name), url.PathEscape(string(env)))
+	var out DeploymentStrategy
+	err := c.do(ctx, "PUT", path, nil, "application/json", body, &out)
+	return out, err
+}
+
+// ListRollouts calls GET /api/v1/services/{name}/rollouts.
+//
+// List a service's latest canary and blue-green rollouts with their step history.
+func (c *Client) ListRollouts(ctx context.Context, name string) ([]Rollout, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/rollouts", url.PathEscape(name))
+	var out []Rollout
+	err := c.do(ctx, "GET", path, nil, "", nil, &out)
+	return out, err
+}
+
+// PromoteRollout calls POST /api/v1/services/{name}/rollouts/{id}:promote.
+//
+// Trigger the next step of a rollout.
+func (c *Client) PromoteRollout(ctx context.Context, name string, id int64) (Rollout, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/rollouts/%s:promote", url.PathEscape(name), url.PathEscape(fmt.Sprint(id)))
+	var out Rollout
+	err := c.do(ctx, "POST", path, nil, "", nil, &out)
+	return out, err
+}
+
+// PauseRollout calls POST /api/v1/services/{name}/rollouts/{id}:pause.
+//
+// Stop a rollout from advancing on its step interval.
+func (c *Client) PauseRollout(ctx context.Context, name string, id int64) (Rollout, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/rollouts/%s:pause", url.PathEscape(name), url.PathEscape(fmt.Sprint(id)))
+	var out Rollout
+	err := c.do(ctx, "POST", path, nil, "", nil, &out)
+	return out, err
+}
+
+// AbortRollout calls POST /api/v1/services/{name}/rollouts/{id}:abort.
+//
+// Roll the environment back to the version it ran before the rollout.
+func (c *Client) AbortRollout(ctx context.Context, name string, id int64) (Rollout, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/rollouts/%s:abort", url.PathEscape(name), url.PathEscape(fmt.Sprint(id)))
+	var out Rollout
+	err := c.do(ctx, "POST", path, nil, "", nil, &out)
+	return out, err
+}
+
+// PutServiceSecrets calls PUT /api/v1/services/{name}/secrets/{env}.
+//
+// Create or rotate CI secrets for one environment.
+func (c *Client) PutServiceSecrets(ctx context.Context, name string, env Environment, body *PutSecretsRequest) ([]SecretMetadata, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/secrets/%s", url.PathEscape(name), url.PathEscape(string(env)))
+	var out []SecretMetadata
+	err := c.do(ctx, "PUT", path, nil, "application/json", body, &out)
+	return out, err
+}
+
+// ListHealthChecks calls GET /api/v1/services/{name}/health-checks.
+//
+// Post-deploy health checks of a service.
+func (c *Client) ListHealthChecks(ctx context.Context, name string) ([]HealthCheck, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/health-checks", url.PathEscape(name))
+	var out []HealthCheck
+	err := c.do(ctx, "GET", path, nil, "", nil, &out)
+	return out, err
+}
+
+// PutHealthCheck calls PUT /api/v1/services/{name}/health-checks/{env}.
+//
+// Set the health check probed after each deploy to an environment.
+func (c *Client) PutHealthCheck(ctx context.Context, name string, env Environment, body *HealthCheckRequest) (HealthCheck, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/health-checks/%s", url.PathEscape(name), url.PathEscape(string(env)))
+	var out HealthCheck
+	err := c.do(ctx, "PUT", path, nil, "application/json", body, &out)
+	return out, err
+}
+
+// DeleteHealthCheck calls DELETE /api/v1/services/{name}/health-checks/{env}.
+//
+// Stop verifying deploys to an environment.
+func (c *Client) DeleteHealthCheck(ctx context.Context, name string, env Environment) (Message, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/health-checks/%s", url.PathEscape(name), url.PathEscape(string(env)))
+	var out Message
+	err := c.do(ctx, "DELETE", path, nil, "", nil, &out)
+	return out, err
+}
+
+// ListHealthCheckRuns calls GET /api/v1/services/{name}/health-check-runs.
+//
+// Latest deploy verifications of a service, newest first.
+func (c *Client) ListHealthCheckRuns(ctx context.Context, name string) ([]HealthCheckRun, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/health-check-runs", url.PathEscape(name))
+	var out []HealthCheckRun
+	err := c.do(ctx, "GET", path, nil, "", nil, &out)
+	return out, err
+}
+
+// ReleaseLock calls DELETE /api/v1/services/{name}/locks/{env}.
+//
+// Force-release a deployment lock.
+func (c *Client) ReleaseLock(ctx context.Context, name string, env Environment) (DeploymentLock, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/locks/%s", url.PathEscape(name), url.PathEscape(string(env)))
+	var out DeploymentLock
+	err := c.do(ctx, "DELETE", path, nil, "", nil, &out)
+	return out, err
+}
+
+// GetInfra calls GET /api/v1/services/{name}/infra.
+//
+// Terraform root modules of a service.
+func (c *Client) GetInfra(ctx context.Context, name string) ([]InfraStack, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/infra", url.PathEscape(name))
+	var out []InfraStack
+	err := c.do(ctx, "GET", path, nil, "", nil, &out)
+	return out, err
+}
+
+// ListInfraRuns calls GET /api/v1/services/{name}/infra-runs.
+//
+// Latest plans and applies of a service, newest first.
+func (c *Client) ListInfraRuns(ctx context.Context, name string) ([]InfraRun, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/infra-runs", url.PathEscape(name))
+	var out []InfraRun
+	err := c.do(ctx, "GET", path, nil, "", nil, &out)
+	return out, err
+}
+
+// StartInfraRun calls POST /api/v1/services/{name}/infra-runs.
+//
+// Plan or apply an environment's root module.
+func (c *Client) StartInfraRun(ctx context.Context, name string, body *InfraRunRequest) (InfraRun, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/infra-runs", url.PathEscape(name))
+	var out InfraRun
+	err := c.do(ctx, "POST", path, nil, "application/json", body, &out)
+	return out, err
+}
+
+// GetInfraRun calls GET /api/v1/services/{name}/infra-runs/{id}.
+//
+// A plan or apply with its output.
+func (c *Client) GetInfraRun(ctx context.Context, name string, id int64) (InfraRun, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/infra-runs/%s", url.PathEscape(name), url.PathEscape(fmt.Sprint(id)))
+	var out InfraRun
+	err := c.do(ctx, "GET", path, nil, "", nil, &out)
+	return out, err
+}
+
+// RegisterArtifact calls POST /api/v1/artifacts.
+//
+// Record a successful pipeline run.
+func (c *Client) RegisterArtifact(ctx context.Context, body *ArtifactEvent) (Message, error) {
+	path := "/api/v1/artifacts"
+	var out Message
+	err := c.do(ctx, "POST", path, nil, "application/json", body, &out)
+	return out, err
+}
+
+// ReportInfraRun calls POST /api/v1/infra-runs/{id}/result.
+//
+// Record the result of a plan or apply.
+func (c *Client) ReportInfraRun(ctx context.Context, id int64, body *InfraRunResult) (Message, error) {
+	path := fmt.Sprintf("/api/v1/infra-runs/%s/result", url.PathEscape(fmt.Sprint(id)))
+	var out Message
+	err := c.do(ctx, "POST", path, nil, "application/json", body, &out)
+	return out, err
+}
+
+// GithubWebhook calls POST /api/v1/webhooks/github.
+//
+// Receive a GitHub webhook delivery.
+func (c *Client) GithubWebhook(ctx context.Context, body map[string]interface{}) ([]Preview, error) {
+	path := "/api/v1/webhooks/github"
+	var out []Preview
+	err := c.do(ctx, "POST", path, nil, "application/json", body, &out)
+	return out, err
+}
+
+// ListApprovals calls GET /api/v1/approvals.
+//
+// Pending approvals and decision history.
+func (c *Client) ListApprovals(ctx context.Context, environment Environment) ([]Approval, error) {
+	path := "/api/v1/approvals"
+	q := url.Values{}
+	if environment != "" {
+		q.Set("environment", string(environment))
+	}
+	var out []Approval
+	err := c.do(ctx, "GET", path, q, "", nil, &out)
+	return out, err
+}
+
+// ApproveDeployment calls POST /api/v1/approvals/{id}:approve.
+//
+// Approve a pending deployment and trigger it.
+func (c *Client) ApproveDeployment(ctx context.Context, id int64) (Message, error) {
+	path := fmt.Sprintf("/api/v1/approvals/%s:approve", url.PathEscape(fmt.Sprint(id)))
+	var out Message
+	err := c.do(ctx, "POST", path, nil, "", nil, &out)
+	return out, err
+}
+
+// RejectDeployment calls POST /api/v1/approvals/{id}:reject.
+//
+// Reject a pending deployment.
+func (c *Client) RejectDeployment(ctx context.Context, id int64) (Message, error) {
+	path := fmt.Sprintf("/api/v1/approvals/%s:reject", url.PathEscape(fmt.Sprint(id)))
+	var out Message
+	err := c.do(ctx, "POST", path, nil, "", nil, &out)
+	return out, err
+}
+
+// ListLocks calls GET /api/v1/locks.
+//
+// Active deployment locks.
+func (c *Client) ListLocks(ctx context.Context, service string) ([]DeploymentLock, error) {
+	path := "/api/v1/locks"
+	q := url.Values{}
+	if service != "" {
+		q.Set("service", service)
+	}
+	var out []DeploymentLock
+	err := c.do(ctx, "GET", path, q, "", nil, &out)
+	return out, err
+}
+
+// ListFreezeWindows calls GET /api/v1/freeze-windows.
+//
+// Deploy freeze windows.
+func (c *Client) ListFreezeWindows(ctx context.Context) ([]FreezeWindow, error) {
+	path := "/api/v1/freeze-windows"
+	var out []FreezeWindow
+	err := c.do(ctx, "GET", path, nil, "", nil, &out)
+	return out, err
+}
+
+// CreateFreezeWindow calls POST /api/v1/freeze-windows.
+//
+// Create a one-off or recurring freeze window.
+func (c *Client) CreateFreezeWindow(ctx context.Context, body *FreezeWindowRequest) (FreezeWindow, error) {
+	path := "/api/v1/freeze-windows"
+	var out FreezeWindow
+	err := c.do(ctx, "POST", path, nil, "application/json", body, &out)
+	return out, err
+}
+
+// DeleteFreezeWindow calls DELETE /api/v1/freeze-windows/{id}.
+//
+// Delete a freeze window and its exemptions.
+func (c *Client) DeleteFreezeWindow(ctx context.Context, id int64) (Message, error) {
+	path := fmt.Sprintf("/api/v1/freeze-windows/%s", url.PathEscape(fmt.Sprint(id)))
+	var out Message
+	err := c.do(ctx, "DELETE", path, nil, "", nil, &out)
+	return out, err
+}
+
+// RequestFreezeExemption calls POST /api/v1/freeze-windows/{id}/exemptions.
+//
+// Ask to let one service/environment through a freeze.
+func (c *Client) RequestFreezeExemption(ctx context.Context, id int64, body *FreezeExemptionRequest) (FreezeExemption, error) {
+	path := fmt.Sprintf("/api/v1/freeze-windows/%s/exemptions", url.PathEscape(fmt.Sprint(id)))
+	var out FreezeExemption
+	err := c.do(ctx, "POST", path, nil, "application/json", body, &out)
+	return out, err
+}
+
+// ListFreezeExemptions calls GET /api/v1/freeze-exemptions.
+//
+// Freeze exemptions, newest first.
+func (c *Client) ListFreezeExemptions(ctx context.Context, status string) ([]FreezeExemption, error) {
+	path := "/api/v1/freeze-exemptions"
+	q := url.Values{}
+	if status != "" {
+		q.Set("status", status)
+	}
+	var out []FreezeExemption
+	err := c.do(ctx, "GET", path, q, "", nil, &out)
+	return out, err
+}
+
+// ApproveFreezeExemption calls POST /api/v1/freeze-exemptions/{id}:approve.
+//
+// Approve a pending exemption.
+func (c *Client) ApproveFreezeExemption(ctx context.Context, id int64) (Message, error) {
+	path := fmt.Sprintf("/api/v1/freeze-exemptions/%s:approve", url.PathEscape(fmt.Sprint(id)))
+	var out Message
+	err := c.do(ctx, "POST", path, nil, "", nil, &out)
+	return out, err
+}
+
+// RejectFreezeExemption calls POST /api/v1/freeze-exemptions/{id}:reject.
+//
+// Reject or withdraw a pending exemption.
+func (c *Client) RejectFreezeExemption(ctx context.Context, id int64) (Message, error) {
+	path := fmt.Sprintf("/api/v1/freeze-exemptions/%s:reject", url.PathEscape(fmt.Sprint(id)))
+	var out Message
+	err := c.do(ctx, "POST", path, nil, "", nil, &out)
+	return out, err
+}
+
+// GetFreezeCalendar calls GET /api/v1/freeze-calendar.
+//
+// Change calendar: freeze occurrences in a time range.
+func (c *Client) GetFreezeCalendar(ctx context.Context, environment Environment, from time.Time, to time.Time) ([]FreezeOccurrence, error) {
+	path := "/api/v1/freeze-calendar"
+	q := url.Values{}
+	if environment != "" {
+		q.Set("environment", string(environment))
+	}
+	if !from.IsZero() {
+		q.Set("from", from.Format(time.RFC3339))
+	}
+	if !to.IsZero() {
+		q.Set("to", to.Format(time.RFC3339))
+	}
+	var out []FreezeOccurrence
+	err := c.do(ctx, "GET", path, q, "", nil, &out)
+	return out, err
+}
+
+// GetServiceConfig calls GET /api/v1/services/{name}/config/{env}.
+//
+// Configuration of a service in an environment.
+func (c *Client) GetServiceConfig(ctx context.Context, name string, env Environment, version int64) (ServiceConfig, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/config/%s", url.PathEscape(name), url.PathEscape(string(env)))
+	q := url.Values{}
+	if version != 0 {
+		q.Set("version", fmt.Sprint(version))
+	}
+	var out ServiceConfig
+	err := c.do(ctx, "GET", path, q, "", nil, &out)
+	return out, err
+}
+
+// PutServiceConfig calls PUT /api/v1/services/{name}/config/{env}.
+//
+// Replace the configuration of a service in an environment.
+func (c *Client) PutServiceConfig(ctx context.Context, name string, env Environment, body *ConfigUpdate) (ServiceConfig, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/config/%s", url.PathEscape(name), url.PathEscape(string(env)))
+	var out ServiceConfig
+	err := c.do(ctx, "PUT", path, nil, "application/json", body, &out)
+	return out, err
+}
+
+// ListServiceConfigHistory calls GET /api/v1/services/{name}/config/{env}/history.
+//
+// Configuration versions of an environment, newest first.
+func (c *Client) ListServiceConfigHistory(ctx context.Context, name string, env Environment) ([]ServiceConfig, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/config/%s/history", url.PathEscape(name), url.PathEscape(string(env)))
+	var out []ServiceConfig
+	err := c.do(ctx, "GET", path, nil, "", nil, &out)
+	return out, err
+}
+
+// DiffServiceConfig calls GET /api/v1/services/{name}/config/{env}/diff.
+//
+// Compare two configuration versions.
+func (c *Client) DiffServiceConfig(ctx context.Context, name string, env Environment, from int64, to int64) (ConfigDiff, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/config/%s/diff", url.PathEscape(name), url.PathEscape(string(env)))
+	q := url.Values{}
+	if from != 0 {
+		q.Set("from", fmt.Sprint(from))
+	}
+	if to != 0 {
+		q.Set("to", fmt.Sprint(to))
+	}
+	var out ConfigDiff
+	err := c.do(ctx, "GET", path, q, "", nil, &out)
+	return out, err
+}
+
+// ListPreviews calls GET /api/v1/services/{name}/previews.
+//
+// Preview environments of a service, newest pull request first.
+func (c *Client) ListPreviews(ctx context.Context, name string) ([]Preview, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/previews", url.PathEscape(name))
+	var out []Preview
+	err := c.do(ctx, "GET", path, nil, "", nil, &out)
+	return out, err
+}
+
+// DeletePreview calls DELETE /api/v1/services/{name}/previews/{env}.
+//
+// Tear a preview environment down.
+func (c *Client) DeletePreview(ctx context.Context, name string, env string) (Preview, error) {
+	path := fmt.Sprintf("/api/v1/services/%s/previews/%s", url.PathEscape(name), url.PathEscape(env))
+	var out Preview
+	err := c.do(ctx, "DELETE", path, nil, "", nil, &out)
+	return out, err
+}
+
+func encode(contentType string, body interface{}) ([]byte, error) {
+	if contentType == "application/x-yaml" {
+		return yaml.Marshal(body)
+	}
+	return json.Marshal(body)
+}
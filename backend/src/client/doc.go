@@ -0,0 +1,13 @@
+// Package client is a typed Go client for the platform backend API,
+// generated from src/internal/openapi/openapi.json. Regenerate after
+// changing the spec:
+//
+//	go generate ./src/client
+//
+// Usage:
+//
+//	c := client.New("https://platform.internal")
+//	services, err := c.ListServices(ctx)
+package client
+
+//go:generate go run .. openapi client -o client.gen.go
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"src/src/internal/openapi"
+	"src/src/internal/templates"
+)
+
+// runCommand handles CLI subcommands, e.g. `go run ./src templates verify`.
+func runCommand(args []string) int {
+	if len(args) == 2 && args[0] == "templates" && args[1] == "verify" {
+		return verifyTemplates()
+	}
+	if len(args) >= 2 && args[0] == "openapi" && args[1] == "client" {
+		return generateClient(args[2:])
+	}
+
+	fmt.Fprintln(os.Stderr, "usage: platform-backend [templates verify | openapi client [-o file] [-package name]]")
+	return 2
+}
+
+func verifyTemplates() int {
+	results, err := templates.VerifyAll()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "❌ template verification failed:", err)
+		return 1
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.OK() {
+			fmt.Println("✅", r.Name())
+			continue
+		}
+
+		failed++
+		fmt.Println("❌", r.Name())
+		for _, e := range r.Errors {
+			fmt.Println("   -", e)
+		}
+	}
+
+	fmt.Printf("\n%d combinations checked, %d failed\n", len(results), failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// generateClient writes the typed API client, e.g.
+// `go run ./src openapi client -o src/client/client.gen.go`.
+func generateClient(args []string) int {
+	fs := flag.NewFlagSet("openapi client", flag.ContinueOnError)
+	out := fs.String("o", "", "output file (default stdout)")
+	pkg := fs.String("package", "client", "package name of the generated code")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	src, err := openapi.GenerateClient(openapi.Spec, *pkg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "❌ client generation failed:", err)
+		return 1
+	}
+
+	if *out == "" {
+		os.Stdout.Write(src)
+		return 0
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "❌ write client:", err)
+		return 1
+	}
+
+	fmt.Println("✅ wrote", *out)
+	return 0
+}
@@ -0,0 +1,265 @@
+// Package argocd connects microservice services to Argo CD. Each
+// service/environment gets an Application, <service>-<env>, that deploys the
+// manifests in the service repository's environment branch to the
+// <service>-<env> namespace. The platform creates the Applications when the
+// service is provisioned, syncs one after every deploy or rollback its
+// pipeline reports, and reads their sync and health status for the
+// dashboard. Everything here is a no-op until argocd.url is configured.
+package argocd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"path"
+
+	"src/src/internal/cicd"
+	"src/src/internal/config"
+	"src/src/internal/db"
+	"src/src/internal/model"
+)
+
+var ErrNotConfigured = errors.New("argocd.url is not configured")
+
+var settings = config.Default().ArgoCD
+
+// Configure sets the Argo CD server, token secret and where Applications
+// are created.
+func Configure(cfg config.ArgoCD) {
+	settings = cfg
+}
+
+// Enabled reports whether an Argo CD server is configured.
+func Enabled() bool {
+	return settings.URL != ""
+}
+
+// Manages reports whether Argo CD deploys services of deployType.
+func Manages(deployType string) bool {
+	return Enabled() && deployType == model.DeployTypeMicroservice
+}
+
+// AppName is the Application (and namespace) of a service in env.
+func AppName(serviceName, env string) string {
+	return serviceName + "-" + env
+}
+
+// Service is what a service's Applications are created from.
+type Service struct {
+	Name     string
+	RepoURL  string
+	RepoPath string // service directory in a monorepo, "" otherwise
+}
+
+// application is the Application deploying revision of s to env.
+func application(s Service, env, revision string) *Application {
+	app := &Application{}
+	app.Metadata.Name = AppName(s.Name, env)
+	app.Metadata.Labels = map[string]string{
+		"app.kubernetes.io/managed-by": "platform",
+		"platform/service":             s.Name,
+		"platform/environment":         env,
+	}
+
+	app.Spec.Project = settings.Project
+	app.Spec.Source.RepoURL = s.RepoURL
+	app.Spec.Source.Path = path.Join(s.RepoPath, settings.Path)
+	app.Spec.Source.TargetRevision = revision
+	app.Spec.Destination.Server = settings.DestinationServer
+	app.Spec.Destination.Namespace = AppName(s.Name, env)
+	app.Spec.SyncPolicy = &struct {
+		SyncOptions []string `json:"syncOptions,omitempty"`
+	}{SyncOptions: []string{"CreateNamespace=true"}}
+	return app
+}
+
+/* ===================== PROVISIONING ===================== */
+
+// Provision creates the Applications of s for envs. If one fails, those
+// already created are deleted again. The returned cleanup deletes them all,
+// for when provisioning fails later on.
+func Provision(ctx context.Context, s Service, envs []string) (cleanup func(), err error) {
+	c, err := NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var created []string
+	cleanup = func() {
+		for _, name := range created {
+			log.Printf("🗑️ Deleting Argo CD application %s", name)
+			if err := c.DeleteApplication(context.Background(), name); err != nil && !IsNotFound(err) {
+				log.Printf("⚠️ delete Argo CD application %s: %v", name, err)
+			}
+		}
+	}
+
+	for _, env := range envs {
+		app := application(s, env, cicd.EnvironmentBranch(env))
+		if err := c.CreateApplication(ctx, app); err != nil {
+			cleanup()
+			return nil, err
+		}
+		created = append(created, app.Metadata.Name)
+		log.Printf("🐙 Argo CD application %s created (%s@%s)", app.Metadata.Name, app.Spec.Source.Path, app.Spec.Source.TargetRevision)
+	}
+	return cleanup, nil
+}
+
+/* ===================== SYNC ===================== */
+
+// OnArtifact syncs the Application of a microservice service after its
+// pipeline reported a deploy or rollback, so the cluster picks up what the
+// environment branch now holds. Services provisioned before Argo CD was
+// configured get their Application on the first sync.
+func OnArtifact(ctx context.Context, a model.ArtifactEvent) error {
+	// Previews follow their pull request's branch; see SyncPreview
+	if !Enabled() || model.IsPreviewEnvironment(a.Environment) {
+		return nil
+	}
+
+	s, deployType, err := lookup(ctx, a.ServiceName)
+	if err != nil || !Manages(deployType) {
+		return err
+	}
+
+	c, err := NewClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	name := AppName(a.ServiceName, a.Environment)
+	err = c.Sync(ctx, name)
+	if IsNotFound(err) {
+		log.Printf("🐙 Argo CD application %s missing; creating it", name)
+		if err := c.CreateApplication(ctx, application(s, a.Environment, cicd.EnvironmentBranch(a.Environment))); err != nil {
+			return err
+		}
+		err = c.Sync(ctx, name)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("🐙 Argo CD sync of %s started after %s %s", name, a.Action, a.Version)
+	return nil
+}
+
+/* ===================== PREVIEWS ===================== */
+
+// SyncPreview syncs the Application of a preview environment after its
+// pipeline reported a deploy, creating it on branch on the first one.
+func SyncPreview(ctx context.Context, serviceName, env, branch string) error {
+	if !Enabled() {
+		return nil
+	}
+
+	s, deployType, err := lookup(ctx, serviceName)
+	if err != nil || !Manages(deployType) {
+		return err
+	}
+
+	c, err := NewClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	name := AppName(serviceName, env)
+	err = c.Sync(ctx, name)
+	if IsNotFound(err) {
+		if err := c.CreateApplication(ctx, application(s, env, branch)); err != nil {
+			return err
+		}
+		log.Printf("🐙 Argo CD application %s created for preview (%s)", name, branch)
+		err = c.Sync(ctx, name)
+	}
+	return err
+}
+
+// DeletePreview deletes the Application of a preview environment, if any.
+func DeletePreview(ctx context.Context, serviceName, env string) error {
+	if !Enabled() {
+		return nil
+	}
+
+	c, err := NewClient(ctx)
+	if err != nil {
+		return err
+	}
+	err = c.DeleteApplication(ctx, AppName(serviceName, env))
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+/* ===================== STATUS ===================== */
+
+// Status is an Application's state as shown on the dashboard.
+type Status struct {
+	Application  string `json:"application"`
+	SyncStatus   string `json:"syncStatus"`   // Synced | OutOfSync | Unknown
+	HealthStatus string `json:"healthStatus"` // Healthy | Progressing | Degraded | Suspended | Missing | Unknown
+	Revision     string `json:"revision,omitempty"`
+	Operation    string `json:"operation,omitempty"` // phase of the last sync: Running | Succeeded | Failed | Error
+	Message      string `json:"message,omitempty"`
+}
+
+// Statuses returns the Application status of serviceName in each of envs
+// that has one. It is empty when Argo CD does not manage the service.
+func Statuses(ctx context.Context, serviceName string, envs []string) (map[string]*Status, error) {
+	if !Enabled() {
+		return nil, nil
+	}
+
+	_, deployType, err := lookup(ctx, serviceName)
+	if err != nil || !Manages(deployType) {
+		return nil, err
+	}
+
+	c, err := NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := map[string]*Status{}
+	for _, env := range envs {
+		app, err := c.GetApplication(ctx, AppName(serviceName, env))
+		if IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		st := &Status{
+			Application:  app.Metadata.Name,
+			SyncStatus:   app.Status.Sync.Status,
+			HealthStatus: app.Status.Health.Status,
+			Revision:     app.Status.Sync.Revision,
+		}
+		if op := app.Status.OperationState; op != nil {
+			st.Operation, st.Message = op.Phase, op.Message
+		}
+		statuses[env] = st
+	}
+	return statuses, nil
+}
+
+// lookup reads what Applications are created from. Unknown services are not
+// an error; they have no deploy type.
+func lookup(ctx context.Context, serviceName string) (Service, string, error) {
+	s := Service{Name: serviceName}
+	var deployType string
+	err := db.DB.QueryRowContext(ctx, `
+		SELECT COALESCE(deploy_type,''), COALESCE(repo_url,''), COALESCE(repo_path,'')
+		FROM services
+		WHERE service_name = ?`,
+		serviceName,
+	).Scan(&deployType, &s.RepoURL, &s.RepoPath)
+	if errors.Is(err, sql.ErrNoRows) {
+		return s, "", nil
+	}
+	return s, deployType, err
+}
@@ -0,0 +1,243 @@
+package argocd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"src/src/internal/config"
+	"src/src/internal/db"
+	"src/src/internal/model"
+	"src/src/internal/secrets"
+)
+
+const testToken = "argocd-test-token"
+
+// fakeArgoCD serves the part of the Argo CD API the platform uses, keeping
+// Applications in memory.
+type fakeArgoCD struct {
+	mu      sync.Mutex
+	apps    map[string]*Application
+	synced  []string
+	failFor string // Application names whose creation fails
+}
+
+func newFakeArgoCD(t *testing.T) *fakeArgoCD {
+	t.Helper()
+
+	f := &fakeArgoCD{apps: map[string]*Application{}}
+	srv := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(srv.Close)
+
+	if err := secrets.Init(secrets.Config{Backend: "env"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ARGOCD_TOKEN", testToken)
+
+	prev := settings
+	cfg := config.Default().ArgoCD
+	cfg.URL = srv.URL + "/"
+	Configure(cfg)
+	t.Cleanup(func() { Configure(prev) })
+	return f
+}
+
+func (f *fakeArgoCD) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer "+testToken {
+		http.Error(w, `{"message":"invalid session"}`, http.StatusUnauthorized)
+		return
+	}
+
+	name, isSync := strings.CutSuffix(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/applications"), "/"), "/sync")
+
+	switch {
+	case r.Method == http.MethodPost && name == "" && r.URL.Query().Get("upsert") == "true":
+		var app Application
+		if err := json.NewDecoder(r.Body).Decode(&app); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if app.Metadata.Name == f.failFor {
+			http.Error(w, `{"message":"repository not accessible"}`, http.StatusBadRequest)
+			return
+		}
+		app.Status.Sync.Status, app.Status.Health.Status = "OutOfSync", "Missing"
+		f.apps[app.Metadata.Name] = &app
+		json.NewEncoder(w).Encode(app)
+
+	case f.apps[name] == nil:
+		http.Error(w, `{"message":"application not found"}`, http.StatusNotFound)
+
+	case r.Method == http.MethodGet && !isSync:
+		json.NewEncoder(w).Encode(f.apps[name])
+
+	case r.Method == http.MethodPost && isSync:
+		app := f.apps[name]
+		app.Status.Sync.Status, app.Status.Sync.Revision = "Synced", "3f2a9c1e"
+		app.Status.Health.Status = "Progressing"
+		app.Status.OperationState = &struct {
+			Phase   string `json:"phase"`
+			Message string `json:"message"`
+		}{Phase: "Running", Message: "waiting for healthy state"}
+		f.synced = append(f.synced, name)
+		json.NewEncoder(w).Encode(app)
+
+	case r.Method == http.MethodDelete:
+		delete(f.apps, name)
+		w.Write([]byte("{}"))
+
+	default:
+		http.Error(w, "unexpected "+r.Method+" "+r.URL.String(), http.StatusMethodNotAllowed)
+	}
+}
+
+func expectService(t *testing.T, deployType string) sqlmock.Sqlmock {
+	t.Helper()
+
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	db.DB = conn
+
+	mock.ExpectQuery("FROM services").WithArgs("orders").
+		WillReturnRows(sqlmock.NewRows([]string{"deploy_type", "repo_url", "repo_path"}).
+			AddRow(deployType, "https://github.com/acme/platform", "services/orders"))
+	return mock
+}
+
+func TestProvision(t *testing.T) {
+	f := newFakeArgoCD(t)
+
+	s := Service{Name: "orders", RepoURL: "https://github.com/acme/orders"}
+	cleanup, err := Provision(context.Background(), s, []string{"dev", "test", "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(f.apps) != 3 {
+		t.Fatalf("applications = %d, want 3", len(f.apps))
+	}
+	prod := f.apps["orders-prod"]
+	if prod == nil {
+		t.Fatal("orders-prod not created")
+	}
+	if src := prod.Spec.Source; src.RepoURL != s.RepoURL || src.Path != "deploy" || src.TargetRevision != "master" {
+		t.Errorf("source = %+v", src)
+	}
+	if dst := prod.Spec.Destination; dst.Namespace != "orders-prod" || dst.Server != "https://kubernetes.default.svc" {
+		t.Errorf("destination = %+v", dst)
+	}
+	if prod.Spec.Project != "default" || prod.Metadata.Labels["platform/environment"] != "prod" {
+		t.Errorf("project %q labels %v", prod.Spec.Project, prod.Metadata.Labels)
+	}
+
+	// Provisioning failed later on
+	cleanup()
+	if len(f.apps) != 0 {
+		t.Errorf("applications left after cleanup: %d", len(f.apps))
+	}
+}
+
+func TestProvisionFailureDeletesCreated(t *testing.T) {
+	f := newFakeArgoCD(t)
+	f.failFor = "orders-test"
+
+	_, err := Provision(context.Background(), Service{Name: "orders", RepoURL: "https://github.com/acme/orders"}, []string{"dev", "test", "prod"})
+	if err == nil || !strings.Contains(err.Error(), "repository not accessible") {
+		t.Fatalf("err = %v", err)
+	}
+	if len(f.apps) != 0 {
+		t.Errorf("applications left: %d", len(f.apps))
+	}
+}
+
+func TestOnArtifactSyncs(t *testing.T) {
+	f := newFakeArgoCD(t)
+	mock := expectService(t, model.DeployTypeMicroservice)
+
+	// Provisioned before Argo CD was configured: no application yet
+	a := model.ArtifactEvent{ServiceName: "orders", Environment: "dev", Version: "1.4.0", Action: "deploy"}
+	if err := OnArtifact(context.Background(), a); err != nil {
+		t.Fatal(err)
+	}
+
+	app := f.apps["orders-dev"]
+	if app == nil {
+		t.Fatal("orders-dev not created")
+	}
+	if app.Spec.Source.Path != "services/orders/deploy" || app.Spec.Source.TargetRevision != "dev" {
+		t.Errorf("source = %+v", app.Spec.Source)
+	}
+	if len(f.synced) != 1 || f.synced[0] != "orders-dev" {
+		t.Errorf("synced = %v", f.synced)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestOnArtifactSkipsOtherDeployTypes(t *testing.T) {
+	f := newFakeArgoCD(t)
+	mock := expectService(t, model.DeployTypeEC2)
+
+	a := model.ArtifactEvent{ServiceName: "orders", Environment: "dev", Version: "1.4.0", Action: "deploy"}
+	if err := OnArtifact(context.Background(), a); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.apps) != 0 || len(f.synced) != 0 {
+		t.Errorf("apps %d synced %v", len(f.apps), f.synced)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStatuses(t *testing.T) {
+	newFakeArgoCD(t)
+	if _, err := Provision(context.Background(), Service{Name: "orders", RepoURL: "https://github.com/acme/orders"}, []string{"dev", "prod"}); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewClient(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Sync(context.Background(), "orders-dev"); err != nil {
+		t.Fatal(err)
+	}
+	mock := expectService(t, model.DeployTypeMicroservice)
+
+	got, err := Statuses(context.Background(), "orders", []string{"dev", "prod", "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]Status{
+		"dev": {
+			Application: "orders-dev", SyncStatus: "Synced", HealthStatus: "Progressing",
+			Revision: "3f2a9c1e", Operation: "Running", Message: "waiting for healthy state",
+		},
+		"prod": {Application: "orders-prod", SyncStatus: "OutOfSync", HealthStatus: "Missing"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("statuses = %v", got)
+	}
+	for env, w := range want {
+		if got[env] == nil || *got[env] != w {
+			t.Errorf("%s = %+v, want %+v", env, got[env], w)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,169 @@
+package argocd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"src/src/internal/secrets"
+)
+
+var defaultHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// Client talks to the Argo CD REST API with a bearer token.
+type Client struct {
+	BaseURL string
+	Token   string
+	HTTP    *http.Client
+}
+
+// NewClient returns a client for the configured server, reading the API
+// token from the secrets backend.
+func NewClient(ctx context.Context) (*Client, error) {
+	if !Enabled() {
+		return nil, ErrNotConfigured
+	}
+	token, err := secrets.Get(ctx, settings.TokenSecret)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		BaseURL: strings.TrimRight(settings.URL, "/"),
+		Token:   token,
+		HTTP:    defaultHTTPClient,
+	}, nil
+}
+
+// Error is returned for any non-2xx response.
+type Error struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("argocd %s %s failed: status=%d body=%s", e.Method, e.Path, e.StatusCode, strings.TrimSpace(e.Body))
+}
+
+// IsNotFound reports whether err is Argo CD answering 404, e.g. for an
+// Application that does not exist.
+func IsNotFound(err error) bool {
+	var ae *Error
+	return errors.As(err, &ae) && ae.StatusCode == http.StatusNotFound
+}
+
+/* ===================== APPLICATIONS ===================== */
+
+// Application is the part of an Argo CD Application the platform sets and
+// reads.
+type Application struct {
+	Metadata struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels,omitempty"`
+	} `json:"metadata"`
+	Spec struct {
+		Project string `json:"project"`
+		Source  struct {
+			RepoURL        string `json:"repoURL"`
+			Path           string `json:"path"`
+			TargetRevision string `json:"targetRevision"`
+		} `json:"source"`
+		Destination struct {
+			Server    string `json:"server"`
+			Namespace string `json:"namespace"`
+		} `json:"destination"`
+		SyncPolicy *struct {
+			SyncOptions []string `json:"syncOptions,omitempty"`
+		} `json:"syncPolicy,omitempty"`
+	} `json:"spec"`
+	Status struct {
+		Sync struct {
+			Status   string `json:"status"`
+			Revision string `json:"revision"`
+		} `json:"sync"`
+		Health struct {
+			Status string `json:"status"`
+		} `json:"health"`
+		OperationState *struct {
+			Phase   string `json:"phase"`
+			Message string `json:"message"`
+		} `json:"operationState,omitempty"`
+	} `json:"status"`
+}
+
+// CreateApplication creates app, or updates its spec if it already exists.
+func (c *Client) CreateApplication(ctx context.Context, app *Application) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/applications?upsert=true", app, nil)
+}
+
+// GetApplication returns the Application called name with its status.
+func (c *Client) GetApplication(ctx context.Context, name string) (*Application, error) {
+	var app Application
+	if err := c.do(ctx, http.MethodGet, "/api/v1/applications/"+url.PathEscape(name), nil, &app); err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
+
+// Sync starts a sync of the Application called name to the head of its
+// target revision.
+func (c *Client) Sync(ctx context.Context, name string) error {
+	body := map[string]interface{}{"prune": false}
+	return c.do(ctx, http.MethodPost, "/api/v1/applications/"+url.PathEscape(name)+"/sync", body, nil)
+}
+
+// DeleteApplication deletes the Application called name together with the
+// resources it deployed.
+func (c *Client) DeleteApplication(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/applications/"+url.PathEscape(name)+"?cascade=true", nil, nil)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var payload io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		return &Error{Method: method, Path: path, StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("decode argocd %s %s: %w", method, path, err)
+		}
+	}
+	return nil
+}
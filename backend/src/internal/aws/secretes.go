@@ -2,39 +2,91 @@ package aws
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"sync"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/aws/smithy-go"
 )
 
-func GetGitToken(secretName string) (string, error) {
+var (
+	smOnce   sync.Once
+	smClient *secretsmanager.Client
+	smErr    error
+)
+
+// secretsClient loads the AWS config once and reuses the client.
+func secretsClient(ctx context.Context) (*secretsmanager.Client, error) {
+	smOnce.Do(func() {
+		cfg, err := config.LoadDefaultConfig(ctx)
+		if err != nil {
+			log.Println("❌ Failed to load AWS config:", err)
+			smErr = err
+			return
+		}
+		smClient = secretsmanager.NewFromConfig(cfg)
+	})
+	return smClient, smErr
+}
+
+// ErrSecretNotFound is returned when the secret does not exist.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// GetSecret returns the string (or binary) value of a Secrets Manager secret.
+func GetSecret(ctx context.Context, secretName string) (string, error) {
 	log.Println("🔐 Fetching secret from AWS Secrets Manager:", secretName)
 
-	cfg, err := config.LoadDefaultConfig(context.TODO())
+	client, err := secretsClient(ctx)
 	if err != nil {
-		log.Println("❌ Failed to load AWS config:", err)
 		return "", err
 	}
 
-	client := secretsmanager.NewFromConfig(cfg)
-
-	secret, err := client.GetSecretValue(context.TODO(), &secretsmanager.GetSecretValueInput{
+	secret, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
 		SecretId: aws.String(secretName),
 	})
 	if err != nil {
+		var nf *types.ResourceNotFoundException
+		if errors.As(err, &nf) {
+			return "", fmt.Errorf("%w: %s", ErrSecretNotFound, secretName)
+		}
 		log.Println("❌ Failed to fetch secret:", err)
 		return "", err
 	}
 
-	if secret.SecretString == nil {
-		log.Println("❌ SecretString is nil for secret:", secretName)
-		return "", err
+	// ❗ NEVER log the secret value
+	switch {
+	case secret.SecretString != nil:
+		log.Println("✅ Secret fetched successfully:", secretName)
+		return *secret.SecretString, nil
+	case secret.SecretBinary != nil:
+		log.Println("✅ Secret fetched successfully:", secretName)
+		return string(secret.SecretBinary), nil
+	default:
+		return "", fmt.Errorf("secret %s has no value", secretName)
+	}
+}
+
+// Ping checks Secrets Manager answers. Any API response (including access
+// denied for ListSecrets) counts as reachable; only transport and
+// credential-loading failures are reported.
+func Ping(ctx context.Context) error {
+	client, err := secretsClient(ctx)
+	if err != nil {
+		return err
 	}
 
-	log.Println("✅ Secret fetched successfully:", secretName)
+	_, err = client.ListSecrets(ctx, &secretsmanager.ListSecretsInput{
+		MaxResults: aws.Int32(1),
+	})
 
-	// ❗ NEVER log secret.SecretString
-	return *secret.SecretString, nil
-}
\ No newline at end of file
+	var apiErr smithy.APIError
+	if err != nil && !errors.As(err, &apiErr) {
+		return err
+	}
+	return nil
+}
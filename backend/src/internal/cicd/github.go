@@ -1,41 +1,34 @@
 package cicd
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
-	"strings"
 	"time"
-	"src/src/internal/aws"
+
 	"src/src/internal/git"
+	"src/src/internal/github"
+	"src/src/internal/templates"
 )
 
 type GitHubClient struct {
-	Token string
+	api *github.Client
 }
 
 // ------------------------------------------------------------
-// Create GitHub client (token from AWS)
+// Create GitHub client (token for the repo owner)
 // ------------------------------------------------------------
-func NewGitHubClient() (*GitHubClient, error) {
-	log.Println("[GITHUB][STEP 1] Fetching GitHub token from AWS Secrets Manager")
+func NewGitHubClient(ts git.TokenSource, owner string) (*GitHubClient, error) {
+	log.Println("[GITHUB][STEP 1] Fetching GitHub token for owner:", owner)
 
-	token, err := aws.GetGitToken("git-token")
+	client, err := git.Client(ts, owner)
 	if err != nil {
 		log.Println("[GITHUB][ERROR] Failed to fetch GitHub token:", err)
 		return nil, err
 	}
 
-	if token == "" {
-		log.Println("[GITHUB][ERROR] GitHub token is EMPTY")
-		return nil, fmt.Errorf("github token is empty")
-	}
-
 	log.Println("[GITHUB][STEP 1] GitHub token fetched successfully")
-	return &GitHubClient{Token: token}, nil
+	return &GitHubClient{api: client}, nil
 }
 
 // ------------------------------------------------------------
@@ -61,77 +54,37 @@ func (g *GitHubClient) CreateWebhook(owner, repo, webhookURL string) error {
 		},
 	}
 
-	body, err := json.Marshal(payload)
-	if err != nil {
-		log.Println("[GITHUB][ERROR] Failed to marshal webhook payload:", err)
-		return err
-	}
-
-	log.Println("[GITHUB][STEP 3] Webhook payload created (size:", len(body), "bytes)")
-
-	// 2️⃣ Create HTTP request
-	url := fmt.Sprintf(
-		"https://api.github.com/repos/%s/%s/hooks",
-		owner,
-		repo,
-	)
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
-	if err != nil {
-		log.Println("[GITHUB][ERROR] Failed to create HTTP request:", err)
-		return err
-	}
-
-	req.Header.Set("Authorization", "token "+g.Token)
-	req.Header.Set("Accept", "application/vnd.github+json")
-	req.Header.Set("Content-Type", "application/json")
-
-	log.Println("[GITHUB][STEP 4] HTTP request created")
-	log.Println("[GITHUB] POST", url)
-
-	// 3️⃣ Execute request
-	client := &http.Client{Timeout: 15 * time.Second}
+	// 2️⃣ Execute request
+	path := fmt.Sprintf("/repos/%s/%s/hooks", owner, repo)
+	log.Println("[GITHUB][STEP 3] POST", path)
 
 	startHTTP := time.Now()
-	resp, err := client.Do(req)
+	err := g.api.Post(context.Background(), path, payload, nil)
 	duration := time.Since(startHTTP)
 
-	if err != nil {
-		log.Println("[GITHUB][ERROR] HTTP request failed:", err)
-		log.Println("[GITHUB] HTTP duration:", duration)
-		return err
-	}
-	defer resp.Body.Close()
-
-	respBody, _ := io.ReadAll(resp.Body)
-
-	log.Println("[GITHUB][STEP 5] HTTP response received")
-	log.Println("[GITHUB] Status   :", resp.Status)
+	log.Println("[GITHUB][STEP 4] HTTP response received")
 	log.Println("[GITHUB] Duration :", duration)
-	log.Println("[GITHUB] Body     :", strings.TrimSpace(string(respBody)))
 
-	// 4️⃣ Handle errors explicitly
-	if resp.StatusCode == 401 {
+	// 3️⃣ Handle errors explicitly
+	if github.HasStatus(err, 401) {
 		log.Println("[GITHUB][ERROR] Unauthorized – token is invalid or expired")
 	}
 
-	if resp.StatusCode == 403 {
+	if github.HasStatus(err, 403) {
 		log.Println("[GITHUB][ERROR] Forbidden – missing permissions (admin:repo_hook)")
 	}
 
-	if resp.StatusCode == 404 {
+	if github.HasStatus(err, 404) {
 		log.Println("[GITHUB][ERROR] Repo not found – check owner/repo name")
 	}
 
-	if resp.StatusCode == 422 {
+	if github.HasStatus(err, 422) {
 		log.Println("[GITHUB][WARN] Webhook already exists OR validation failed")
 	}
 
-	if resp.StatusCode >= 300 {
-		return fmt.Errorf(
-			"github webhook creation failed: %s",
-			resp.Status,
-		)
+	if err != nil {
+		log.Println("[GITHUB][ERROR] Webhook request failed:", err)
+		return fmt.Errorf("github webhook creation failed: %w", err)
 	}
 
 	log.Println("[GITHUB][SUCCESS] Webhook created successfully")
@@ -144,154 +97,104 @@ func (g *GitHubClient) CreateWebhook(owner, repo, webhookURL string) error {
 
 
 
-func TriggerGitHubDeploy(repo, branch string) error {
-	token, err := aws.GetGitToken("git-token")
+// owner is the repo owner (org or user); "" falls back to the default owner.
+// servicePath is the service's directory inside a monorepo ("" for standalone repos)
+// rollout is zero for an all-at-once deploy
+func TriggerGitHubDeploy(ts git.TokenSource, owner, repo, branch, servicePath string, rollout RolloutParams) error {
+	if owner == "" {
+		var err error
+		if owner, _, err = ts.DefaultOwner(); err != nil {
+			return err
+		}
+	}
+
+	client, err := git.Client(ts, owner)
 	if err != nil {
 		log.Println("[GITHUB][ERROR] Failed to fetch GitHub token:", err)
 		return err
 	}
 
-	workflow := "cicd.yaml"
+	workflow := templates.WorkflowFileName(servicePath)
 
 	payload := map[string]interface{}{
 		"ref": branch,
 	}
-
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return err
+	inputs := map[string]string{}
+	if servicePath != "" {
+		inputs["service_path"] = servicePath
 	}
-
-	owner, err := git.GetAuthenticatedUser(token)
-	if err != nil {
-		return err
+	rollout.githubInputs(inputs)
+	if len(inputs) > 0 {
+		payload["inputs"] = inputs
 	}
 
-	fmt.Println("Authenticated GitHub User:", owner)
-
-	req, err := http.NewRequest(
-		"POST",
-		fmt.Sprintf(
-			"https://api.github.com/repos/%s/%s/actions/workflows/%s/dispatches",
-			owner, repo, workflow,
-		),
-		bytes.NewBuffer(body),
+	err = client.Post(context.Background(),
+		fmt.Sprintf("/repos/%s/%s/actions/workflows/%s/dispatches", owner, repo, workflow),
+		payload,
+		nil,
 	)
 	if err != nil {
-		return err
-	}
-
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Accept", "application/vnd.github+json")
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "platform-backend")
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusNoContent {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("status: %s, body: %s", resp.Status, string(bodyBytes))
+		return fmt.Errorf("github deploy trigger failed: %w", err)
 	}
 
 	return nil
 }
 
 
-func TriggerGitHubRollback(repo, environment, version string) error {
+func TriggerGitHubRollback(ts git.TokenSource, owner, repo, environment, version, servicePath string, rollout RolloutParams) error {
 	log.Println("[GITHUB][ROLLBACK] Starting GitHub rollback trigger")
 
+	if owner == "" {
+		var err error
+		if owner, _, err = ts.DefaultOwner(); err != nil {
+			log.Printf("[GITHUB][ROLLBACK][ERROR] Failed to resolve GitHub owner: %v\n", err)
+			return err
+		}
+	}
+
 	// 🔐 Fetch GitHub token
-	token, err := aws.GetGitToken("git-token")
+	client, err := git.Client(ts, owner)
 	if err != nil {
 		log.Printf("[GITHUB][ROLLBACK][ERROR] Failed to fetch GitHub token: %v\n", err)
 		return err
 	}
 	log.Println("[GITHUB][ROLLBACK] GitHub token fetched successfully")
 
-	workflow := "cicd.yaml" // same workflow, handles rollback via inputs
+	workflow := templates.WorkflowFileName(servicePath) // same workflow, handles rollback via inputs
 
-	ref := environmentBranch(environment)
+	ref := EnvironmentBranch(environment)
 	log.Printf(
 		"[GITHUB][ROLLBACK] Using ref=%s for environment=%s\n",
 		ref,
 		environment,
 	)
 
-	payload := map[string]interface{}{
-		"ref": ref,
-		"inputs": map[string]string{
-			"rollback": "true",
-			"rollback_version": version,
-		},
+	inputs := map[string]string{
+		"rollback": "true",
+		"rollback_version": version,
 	}
-
-	body, err := json.Marshal(payload)
-	if err != nil {
-		log.Printf("[GITHUB][ROLLBACK][ERROR] Failed to marshal payload: %v\n", err)
-		return err
+	if servicePath != "" {
+		inputs["service_path"] = servicePath
 	}
+	rollout.githubInputs(inputs)
 
-	log.Printf(
-		"[GITHUB][ROLLBACK] Dispatch payload: %s\n",
-		string(body),
-	)
-
-	owner, err := git.GetAuthenticatedUser(token)
-	if err != nil {
-		log.Printf("[GITHUB][ROLLBACK][ERROR] Failed to get authenticated GitHub user: %v\n", err)
-		return err
+	payload := map[string]interface{}{
+		"ref":    ref,
+		"inputs": inputs,
 	}
 
-	log.Printf("[GITHUB][ROLLBACK] Authenticated GitHub user: %s\n", owner)
-
-	url := fmt.Sprintf(
-		"https://api.github.com/repos/%s/%s/actions/workflows/%s/dispatches",
-		owner,
-		repo,
-		workflow,
-	)
-
-	log.Printf("[GITHUB][ROLLBACK] Dispatch URL: %s\n", url)
-
-	req, err := http.NewRequest(
-		"POST",
-		url,
-		bytes.NewBuffer(body),
-	)
-	if err != nil {
-		log.Printf("[GITHUB][ROLLBACK][ERROR] Failed to create HTTP request: %v\n", err)
-		return err
-	}
+	log.Printf("[GITHUB][ROLLBACK] Dispatch inputs: %v\n", inputs)
+	log.Printf("[GITHUB][ROLLBACK] GitHub owner: %s\n", owner)
 
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Accept", "application/vnd.github+json")
-	req.Header.Set("Content-Type", "application/json")
+	path := fmt.Sprintf("/repos/%s/%s/actions/workflows/%s/dispatches", owner, repo, workflow)
+	log.Printf("[GITHUB][ROLLBACK] Dispatch path: %s\n", path)
 
 	log.Println("[GITHUB][ROLLBACK] Sending request to GitHub")
 
-	resp, err := http.DefaultClient.Do(req)
+	err = client.Post(context.Background(), path, payload, nil)
 	if err != nil {
-		log.Printf("[GITHUB][ROLLBACK][ERROR] HTTP request failed: %v\n", err)
-		return err
-	}
-	defer resp.Body.Close()
-
-	log.Printf(
-		"[GITHUB][ROLLBACK] GitHub response status: %s (%d)\n",
-		resp.Status,
-		resp.StatusCode,
-	)
-
-	if resp.StatusCode != http.StatusNoContent {
-		log.Printf(
-			"[GITHUB][ROLLBACK][ERROR] GitHub rejected rollback trigger: status=%s\n",
-			resp.Status,
-		)
-		return fmt.Errorf("github rollback trigger failed: %s", resp.Status)
+		log.Printf("[GITHUB][ROLLBACK][ERROR] GitHub rejected rollback trigger: %v\n", err)
+		return fmt.Errorf("github rollback trigger failed: %w", err)
 	}
 
 	log.Println("[GITHUB][ROLLBACK][SUCCESS] GitHub rollback workflow dispatched successfully")
@@ -299,7 +202,7 @@ func TriggerGitHubRollback(repo, environment, version string) error {
 }
 
 
-func environmentBranch(env string) string {
+func EnvironmentBranch(env string) string {
 	switch env {
 	case "dev":
 		return "dev"
@@ -2,15 +2,19 @@ package cicd
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
 	"time"
 	"io"
+
+	"src/src/internal/config"
+	"src/src/internal/github"
+	"src/src/internal/secrets"
 )
 
 type JenkinsClient struct {
@@ -19,20 +23,40 @@ type JenkinsClient struct {
 	Token   string
 }
 
+var jenkinsCfg = config.Default().Jenkins
+
+// Configure sets the Jenkins server and credential secrets used by
+// NewJenkinsClient and RegisterJenkins.
+func Configure(cfg config.Jenkins) {
+	jenkinsCfg = cfg
+}
+
 // 🔐 Create Jenkins client with normalized base URL
-func NewJenkinsClient() *JenkinsClient {
-	baseURL := os.Getenv("JENKINS_URL")
-	user := os.Getenv("JENKINS_USER")
-	token := os.Getenv("JENKINS_API_TOKEN")
+// (URL from the config, credentials from the secrets backend)
+func NewJenkinsClient() (*JenkinsClient, error) {
+	baseURL := jenkinsCfg.URL
 
 	log.Println("[JENKINS] Initializing Jenkins client")
-	log.Println("[JENKINS] Raw JENKINS_URL:", baseURL)
-	log.Println("[JENKINS] Jenkins user:", user)
+	log.Println("[JENKINS] Raw Jenkins URL:", baseURL)
 
-	if baseURL == "" || user == "" || token == "" {
-		log.Fatal("[JENKINS] Missing required Jenkins environment variables")
+	if baseURL == "" {
+		return nil, fmt.Errorf("jenkins.url is not configured")
 	}
 
+	ctx := context.Background()
+
+	user, err := secrets.Get(ctx, jenkinsCfg.UserSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := secrets.Get(ctx, jenkinsCfg.TokenSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("[JENKINS] Jenkins user:", user)
+
 	client := &JenkinsClient{
 		BaseURL: strings.TrimRight(baseURL, "/"),
 		User:    user,
@@ -40,7 +64,7 @@ func NewJenkinsClient() *JenkinsClient {
 	}
 
 	log.Println("[JENKINS] Normalized BaseURL:", client.BaseURL)
-	return client
+	return client, nil
 }
 
 //
@@ -91,6 +115,31 @@ func (j *JenkinsClient) getCrumb() (string, string, error) {
 	return data.CrumbRequestField, data.Crumb, nil
 }
 
+// PingJenkins checks Jenkins answers with the configured credentials.
+func PingJenkins(ctx context.Context) error {
+	j, err := NewJenkinsClient()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", j.BaseURL+"/api/json?tree=mode", nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(j.User, j.Token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("jenkins: %s", resp.Status)
+	}
+	return nil
+}
+
 //
 // ─────────────────────────────────────────────
 // 🚀 CREATE MULTIBRANCH JOB
@@ -98,12 +147,27 @@ func (j *JenkinsClient) getCrumb() (string, string, error) {
 //
 
 
+// scriptPath is the Jenkinsfile location relative to the repository root;
+// conn is the GitHub host the repository lives on
 func (j *JenkinsClient) CreateMultibranchJob(
-	jobName, repoURL, credentialsID, webhookToken string,
+	conn github.Connection,
+	jobName, repoURL, credentialsID, webhookToken, scriptPath string,
 ) error {
 
 	log.Println("[JENKINS] Creating multibranch job:", jobName)
 
+	owner, repo, err := github.ParseRepoURL(repoURL)
+	if err != nil {
+		return err
+	}
+
+	// GHES needs the API endpoint; it must also be registered as a GitHub
+	// server in Jenkins' global configuration
+	apiURI := ""
+	if !conn.IsDotCom() {
+		apiURI = "\n          <apiUri>" + conn.APIURL + "</apiUri>"
+	}
+
 	configXML := fmt.Sprintf(`
 <org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject plugin="workflow-multibranch">
   <description>Auto-created by Platform</description>
@@ -127,22 +191,24 @@ func (j *JenkinsClient) CreateMultibranchJob(
           <id>%s</id>
           <repoOwner>%s</repoOwner>
           <repository>%s</repository>
-          <credentialsId>%s</credentialsId>
+          <credentialsId>%s</credentialsId>%s
         </source>
       </jenkins.branch.BranchSource>
     </data>
   </sources>
 
   <factory class="org.jenkinsci.plugins.workflow.multibranch.WorkflowBranchProjectFactory">
-    <scriptPath>Jenkinsfile</scriptPath>
+    <scriptPath>%s</scriptPath>
   </factory>
 </org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject>
 `,
 		webhookToken,
 		jobName,
-		extractOwner(repoURL),
-		extractRepo(repoURL),
+		owner,
+		repo,
 		credentialsID,
+		apiURI,
+		scriptPath,
 	)
 
 	endpoint := fmt.Sprintf(
@@ -172,14 +238,12 @@ func (j *JenkinsClient) CreateMultibranchJob(
 
 
 
-func TriggerJenkinsDeploy(jobName, branch string) error {
-	jenkinsURL := strings.TrimRight(os.Getenv("JENKINS_URL"), "/")
-	user := os.Getenv("JENKINS_USER")
-	apiToken := os.Getenv("JENKINS_API_TOKEN")
-
-	if jenkinsURL == "" || user == "" || apiToken == "" {
-		return fmt.Errorf("jenkins environment variables not set")
+func TriggerJenkinsDeploy(jobName, branch, servicePath string, rollout RolloutParams) error {
+	jenkins, err := NewJenkinsClient()
+	if err != nil {
+		return err
 	}
+	jenkinsURL, user, apiToken := jenkins.BaseURL, jenkins.User, jenkins.Token
 
 	client := &http.Client{}
 
@@ -222,6 +286,12 @@ func TriggerJenkinsDeploy(jobName, branch string) error {
 	formData := url.Values{}
 	formData.Set("ROLLBACK", "false")
 	formData.Set("ROLLBACK_VERSION", "")
+	if servicePath != "" {
+		formData.Set("SERVICE_PATH", servicePath)
+	}
+	for k, v := range rollout.values() {
+		formData.Set(k, v)
+	}
 
 	/* =========================
 	   3️⃣  BUILD MULTIBRANCH URL
@@ -267,10 +337,12 @@ func TriggerJenkinsDeploy(jobName, branch string) error {
 
 
 
-func TriggerJenkinsRollback(serviceName, branch, version string) error {
-	jenkinsURL := strings.TrimRight(os.Getenv("JENKINS_URL"), "/")
-	user := os.Getenv("JENKINS_USER")
-	apiToken := os.Getenv("JENKINS_API_TOKEN")
+func TriggerJenkinsRollback(serviceName, branch, version, servicePath string, rollout RolloutParams) error {
+	jenkins, err := NewJenkinsClient()
+	if err != nil {
+		return err
+	}
+	jenkinsURL, user, apiToken := jenkins.BaseURL, jenkins.User, jenkins.Token
 
 	client := &http.Client{}
 
@@ -299,6 +371,12 @@ func TriggerJenkinsRollback(serviceName, branch, version string) error {
 	formData := url.Values{}
 	formData.Set("ROLLBACK", "true")
 	formData.Set("ROLLBACK_VERSION", version)
+	if servicePath != "" {
+		formData.Set("SERVICE_PATH", servicePath)
+	}
+	for k, v := range rollout.values() {
+		formData.Set(k, v)
+	}
 
 	buildURL := fmt.Sprintf(
 		"%s/job/%s/job/%s/buildWithParameters",
@@ -0,0 +1,85 @@
+package cicd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+)
+
+// JenkinsCredentialID is the folder credential id a pipeline reads for an
+// environment secret, e.g. credentials('prod-REGISTRY_PASSWORD').
+func JenkinsCredentialID(env, name string) string {
+	return env + "-" + name
+}
+
+// UpsertFolderSecret stores a secret-text credential in the multibranch
+// job's folder store, so only that service's pipelines can read it.
+func (j *JenkinsClient) UpsertFolderSecret(folder, id, description, secret string) error {
+	log.Printf("[JENKINS] Writing credential %s to folder %s", id, folder)
+
+	var body bytes.Buffer
+	body.WriteString("<org.jenkinsci.plugins.plaincredentials.impl.StringCredentialsImpl>\n")
+	body.WriteString("  <scope>GLOBAL</scope>\n")
+	writeXMLElement(&body, "id", id)
+	writeXMLElement(&body, "description", description)
+	writeXMLElement(&body, "secret", secret)
+	body.WriteString("</org.jenkinsci.plugins.plaincredentials.impl.StringCredentialsImpl>\n")
+
+	store := fmt.Sprintf(
+		"%s/job/%s/credentials/store/folder/domain/_",
+		j.BaseURL,
+		url.PathEscape(folder),
+	)
+
+	// 1️⃣ Update in place (rotation)
+	status, err := j.postXML(store+"/credential/"+url.PathEscape(id)+"/config.xml", body.Bytes())
+	if err != nil {
+		return err
+	}
+	if status < 300 {
+		return nil
+	}
+
+	// 2️⃣ Not there yet → create
+	if status != http.StatusNotFound {
+		return fmt.Errorf("jenkins credential update failed: status=%d", status)
+	}
+
+	status, err = j.postXML(store+"/createCredentials", body.Bytes())
+	if err != nil {
+		return err
+	}
+	if status >= 300 {
+		return fmt.Errorf("jenkins credential creation failed: status=%d", status)
+	}
+
+	return nil
+}
+
+func (j *JenkinsClient) postXML(endpoint string, body []byte) (int, error) {
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.SetBasicAuth(j.User, j.Token)
+	req.Header.Set("Content-Type", "application/xml")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	return resp.StatusCode, nil
+}
+
+func writeXMLElement(buf *bytes.Buffer, name, value string) {
+	buf.WriteString("  <" + name + ">")
+	xml.EscapeText(buf, []byte(value))
+	buf.WriteString("</" + name + ">\n")
+}
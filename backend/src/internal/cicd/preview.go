@@ -0,0 +1,122 @@
+package cicd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"src/src/internal/git"
+	"src/src/internal/templates"
+)
+
+// PreviewParams point a deploy pipeline at a preview environment instead of
+// the one its branch maps to.
+//
+//	PREVIEW_ENV       pr-<number>
+//	PREVIEW_TEARDOWN  true removes the preview instead of deploying it
+//
+// GitHub workflows receive the same names in lower case as inputs.
+type PreviewParams struct {
+	Environment string
+	Teardown    bool
+}
+
+func (p PreviewParams) values() map[string]string {
+	return map[string]string{
+		"PREVIEW_ENV":      p.Environment,
+		"PREVIEW_TEARDOWN": strconv.FormatBool(p.Teardown),
+	}
+}
+
+// TriggerGitHubPreview dispatches the deploy workflow of a service on ref
+// for a preview. owner "" falls back to the default owner.
+func TriggerGitHubPreview(ts git.TokenSource, owner, repo, ref, servicePath string, p PreviewParams) error {
+	if owner == "" {
+		var err error
+		if owner, _, err = ts.DefaultOwner(); err != nil {
+			return err
+		}
+	}
+
+	client, err := git.Client(ts, owner)
+	if err != nil {
+		log.Println("[GITHUB][ERROR] Failed to fetch GitHub token:", err)
+		return err
+	}
+
+	inputs := map[string]string{}
+	for k, v := range p.values() {
+		inputs[strings.ToLower(k)] = v
+	}
+	if servicePath != "" {
+		inputs["service_path"] = servicePath
+	}
+
+	workflow := templates.WorkflowFileName(servicePath)
+	err = client.Post(context.Background(),
+		fmt.Sprintf("/repos/%s/%s/actions/workflows/%s/dispatches", owner, repo, workflow),
+		map[string]interface{}{"ref": ref, "inputs": inputs},
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("github preview trigger failed: %w", err)
+	}
+	return nil
+}
+
+// TriggerJenkinsPreview builds branch of a service's multibranch job for a
+// preview.
+func TriggerJenkinsPreview(jobName, branch, servicePath string, p PreviewParams) error {
+	jenkins, err := NewJenkinsClient()
+	if err != nil {
+		return err
+	}
+
+	// 1️⃣ CSRF crumb
+	crumbField, crumb, err := jenkins.getCrumb()
+	if err != nil {
+		return err
+	}
+
+	// 2️⃣ Parameters
+	formData := url.Values{}
+	formData.Set("ROLLBACK", "false")
+	if servicePath != "" {
+		formData.Set("SERVICE_PATH", servicePath)
+	}
+	for k, v := range p.values() {
+		formData.Set(k, v)
+	}
+
+	// Multibranch items are named after the branch with '/' encoded, and
+	// that name is escaped again in the URL
+	buildURL := fmt.Sprintf("%s/job/%s/job/%s/buildWithParameters",
+		jenkins.BaseURL, jobName, url.PathEscape(url.PathEscape(branch)))
+	req, err := http.NewRequest("POST", buildURL, strings.NewReader(formData.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(jenkins.User, jenkins.Token)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set(crumbField, crumb)
+
+	// 3️⃣ Trigger
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 201 && resp.StatusCode != 302 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("jenkins preview trigger failed: %s - %s", resp.Status, string(body))
+	}
+
+	log.Printf("✅ Jenkins preview %s of %s triggered (%s)", p.Environment, jobName, branch)
+	return nil
+}
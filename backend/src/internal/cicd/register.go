@@ -3,24 +3,34 @@ package cicd
 import (
 	"fmt"
 	"log"
-	"os"
-	"strings"
+
+	"src/src/internal/git"
+	"src/src/internal/github"
 )
 
 func RegisterJenkins(
-	repoURL, serviceName string,
+	ts git.TokenSource,
+	repoURL, serviceName, scriptPath string,
 	enableWebhook bool,
 ) (string, error) {
 
 	log.Println("[CICD] Registering Jenkins for service:", serviceName)
 
-	jenkins := NewJenkinsClient()
+	jenkins, err := NewJenkinsClient()
+	if err != nil {
+		log.Println("[CICD][ERROR] Failed to create Jenkins client:", err)
+		return "", err
+	}
+
+	owner, repo, err := github.ParseRepoURL(repoURL)
+	if err != nil {
+		return "", err
+	}
 
 	// GitHub client only needed if webhook enabled
-	var github *GitHubClient
-	var err error
+	var gh *GitHubClient
 	if enableWebhook {
-		github, err = NewGitHubClient()
+		gh, err = NewGitHubClient(ts, owner)
 		if err != nil {
 			log.Println("[CICD][ERROR] Failed to create GitHub client:", err)
 			return "", err
@@ -39,10 +49,12 @@ func RegisterJenkins(
 	// 1️⃣ Create Jenkins job
 	log.Println("[CICD] Creating Jenkins multibranch job")
 	if err := jenkins.CreateMultibranchJob(
+		ts.Connection(),
 		serviceName,
 		repoURL,
-		os.Getenv("JENKINS_GITHUB_CREDENTIALS_ID"),
+		jenkinsCfg.GitHubCredentialsID,
 		webhookToken,
+		scriptPath,
 	); err != nil {
 		log.Println("[CICD][ERROR] Jenkins job creation failed:", err)
 		return "", err
@@ -54,15 +66,15 @@ func RegisterJenkins(
 	if enableWebhook {
 		webhookURL := fmt.Sprintf(
 			"%s/multibranch-webhook-trigger/invoke?token=%s",
-			strings.TrimRight(os.Getenv("JENKINS_URL"), "/"),
+			jenkins.BaseURL,
 			webhookToken,
 		)
 
 		log.Println("[CICD] Creating GitHub webhook:", webhookURL)
 
-		if err := github.CreateWebhook(
-			extractOwner(repoURL),
-			extractRepo(repoURL),
+		if err := gh.CreateWebhook(
+			owner,
+			repo,
 			webhookURL,
 		); err != nil {
 			log.Println("[CICD][ERROR] GitHub webhook creation failed:", err)
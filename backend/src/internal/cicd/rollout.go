@@ -0,0 +1,48 @@
+package cicd
+
+import (
+	"strconv"
+	"strings"
+)
+
+// RolloutParams tell a pipeline which step of a canary or blue-green
+// rollout to run. The zero value is a plain all-at-once deploy and sends no
+// parameters, so pipelines that do not declare them keep working.
+//
+//	DEPLOY_STRATEGY  canary | blue-green
+//	ROLLOUT_ID       platform rollout id
+//	ROLLOUT_STEP     1-based step number
+//	ROLLOUT_PHASE    step (shift traffic) | abort (back to the old version)
+//	TRAFFIC_PERCENT  share of traffic on the new version after this step
+//	ROLLOUT_VERSION  build to ship; empty on the first step, which builds it
+//
+// GitHub workflows receive the same names in lower case as inputs.
+type RolloutParams struct {
+	Strategy       string
+	ID             int64
+	Step           int
+	Phase          string
+	TrafficPercent int
+	Version        string
+}
+
+func (p RolloutParams) values() map[string]string {
+	if p.Strategy == "" {
+		return nil
+	}
+	return map[string]string{
+		"DEPLOY_STRATEGY": p.Strategy,
+		"ROLLOUT_ID":      strconv.FormatInt(p.ID, 10),
+		"ROLLOUT_STEP":    strconv.Itoa(p.Step),
+		"ROLLOUT_PHASE":   p.Phase,
+		"TRAFFIC_PERCENT": strconv.Itoa(p.TrafficPercent),
+		"ROLLOUT_VERSION": p.Version,
+	}
+}
+
+// githubInputs adds p to workflow_dispatch inputs.
+func (p RolloutParams) githubInputs(inputs map[string]string) {
+	for k, v := range p.values() {
+		inputs[strings.ToLower(k)] = v
+	}
+}
@@ -0,0 +1,121 @@
+package cicd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"src/src/internal/git"
+	"src/src/internal/templates"
+)
+
+// TerraformParams tell the plan/apply pipeline what to run and where to
+// report the result.
+//
+//	TF_ACTION   plan | apply
+//	TF_DIR      root module, relative to the repository
+//	RUN_ID      platform infrastructure run
+//	RESULT_URL  where the pipeline posts model.InfraRunResult
+//
+// GitHub workflows get action, environment, run_id and result_url inputs
+// and find the root module themselves.
+type TerraformParams struct {
+	Action      string
+	Environment string
+	Dir         string
+	RunID       int64
+	ResultURL   string
+}
+
+// InfraJobName is the Jenkins job running a service's infra/Jenkinsfile.
+func InfraJobName(serviceName string) string {
+	return serviceName + "-infra"
+}
+
+// TriggerGitHubTerraform dispatches the plan/apply workflow of a service on
+// branch. owner "" falls back to the default owner.
+func TriggerGitHubTerraform(ts git.TokenSource, owner, repo, branch, servicePath string, p TerraformParams) error {
+	if owner == "" {
+		var err error
+		if owner, _, err = ts.DefaultOwner(); err != nil {
+			return err
+		}
+	}
+
+	client, err := git.Client(ts, owner)
+	if err != nil {
+		log.Println("[GITHUB][ERROR] Failed to fetch GitHub token:", err)
+		return err
+	}
+
+	inputs := map[string]string{
+		"action":      p.Action,
+		"environment": p.Environment,
+		"run_id":      strconv.FormatInt(p.RunID, 10),
+		"result_url":  p.ResultURL,
+	}
+	if servicePath != "" {
+		inputs["service_path"] = servicePath
+	}
+
+	workflow := templates.InfraWorkflowFileName(servicePath)
+	err = client.Post(context.Background(),
+		fmt.Sprintf("/repos/%s/%s/actions/workflows/%s/dispatches", owner, repo, workflow),
+		map[string]interface{}{"ref": branch, "inputs": inputs},
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("github terraform trigger failed: %w", err)
+	}
+	return nil
+}
+
+// TriggerJenkinsTerraform builds branch of a service's infra job.
+func TriggerJenkinsTerraform(jobName, branch string, p TerraformParams) error {
+	jenkins, err := NewJenkinsClient()
+	if err != nil {
+		return err
+	}
+
+	// 1️⃣ CSRF crumb
+	crumbField, crumb, err := jenkins.getCrumb()
+	if err != nil {
+		return err
+	}
+
+	// 2️⃣ Parameters
+	formData := url.Values{}
+	formData.Set("TF_ACTION", p.Action)
+	formData.Set("TF_DIR", p.Dir)
+	formData.Set("RUN_ID", strconv.FormatInt(p.RunID, 10))
+	formData.Set("RESULT_URL", p.ResultURL)
+
+	buildURL := fmt.Sprintf("%s/job/%s/job/%s/buildWithParameters", jenkins.BaseURL, jobName, branch)
+	req, err := http.NewRequest("POST", buildURL, strings.NewReader(formData.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(jenkins.User, jenkins.Token)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set(crumbField, crumb)
+
+	// 3️⃣ Trigger
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 201 && resp.StatusCode != 302 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("jenkins terraform trigger failed: %s - %s", resp.Status, string(body))
+	}
+
+	log.Printf("✅ Jenkins %s of %s triggered (%s)", p.Action, jobName, p.Dir)
+	return nil
+}
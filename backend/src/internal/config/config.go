@@ -0,0 +1,277 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"src/src/internal/secrets"
+)
+
+// Config is everything the backend reads at startup. It is loaded once by
+// main and handed to each package; nothing below main reads the
+// environment directly.
+//
+//	server:    {addr: ":8080"}
+//	database:  {host: mysql, port: "3306", user: platform, name: platform}
+//	github:    {host: ghe.example.com, app: {id: "12345", org: acme}}
+//	jenkins:   {url: https://jenkins.example.com, githubCredentialsId: github}
+//	workspace: {dir: /var/lib/platform/work}
+//	platform:  {url: https://platform.example.com}
+//	deployments: {lockTtl: 30m, schedulerInterval: 30s, scheduleGrace: 1h}
+//	notifications: {webhookUrl: https://chat.example.com/hooks/platform}
+//	kubernetes: {registry: ghcr.io/acme, gitopsRepos: {dev: acme/gitops-dev}}
+//	argocd:    {url: https://argocd.example.com, project: payments}
+//	terraform: {stateBucket: acme-terraform-state, lockTable: terraform-locks}
+//	previews:  {enabled: true, url: "https://{service}-{env}.preview.example.com", ttl: 72h}
+//	secrets:   {backend: vault, vault: {addr: https://vault:8200}}
+type Config struct {
+	Server      Server         `yaml:"server"`
+	Database    Database       `yaml:"database"`
+	GitHub      GitHub         `yaml:"github"`
+	Jenkins     Jenkins        `yaml:"jenkins"`
+	Workspace   Workspace      `yaml:"workspace"`
+	Platform    Platform       `yaml:"platform"`
+	Deployments Deployments    `yaml:"deployments"`
+	Notify      Notifications  `yaml:"notifications"`
+	Kubernetes  Kubernetes     `yaml:"kubernetes"`
+	ArgoCD      ArgoCD         `yaml:"argocd"`
+	Terraform   Terraform      `yaml:"terraform"`
+	Previews    Previews       `yaml:"previews"`
+	Secrets     secrets.Config `yaml:"secrets"`
+}
+
+type Server struct {
+	Addr              string        `yaml:"addr"`
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout"`
+	ReadTimeout       time.Duration `yaml:"readTimeout"`
+	WriteTimeout      time.Duration `yaml:"writeTimeout"` // covers synchronous provisioning
+	IdleTimeout       time.Duration `yaml:"idleTimeout"`
+	ShutdownTimeout   time.Duration `yaml:"shutdownTimeout"` // drain budget after SIGTERM
+	Readiness         Readiness     `yaml:"readiness"`
+	TrustedProxies    []string      `yaml:"trustedProxies"` // CIDRs of the authenticating proxy that sets X-Forwarded-User
+}
+
+// Readiness selects the optional /readyz checks; MySQL and the secrets
+// backend are always checked.
+type Readiness struct {
+	Timeout time.Duration `yaml:"timeout"`
+	GitHub  bool          `yaml:"github"`
+	Jenkins bool          `yaml:"jenkins"`
+}
+
+type Database struct {
+	Host           string        `yaml:"host"`
+	Port           string        `yaml:"port"`
+	User           string        `yaml:"user"`
+	Name           string        `yaml:"name"`
+	PasswordSecret string        `yaml:"passwordSecret"`
+	ConnectTimeout time.Duration `yaml:"connectTimeout"` // retry budget at startup
+}
+
+type GitHub struct {
+	Host      string `yaml:"host"`      // GHES hostname ("" → github.com)
+	APIURL    string `yaml:"apiUrl"`    // overrides derived from Host
+	UploadURL string `yaml:"uploadUrl"` //
+	WebURL    string `yaml:"webUrl"`    //
+
+	TokenSecret string    `yaml:"tokenSecret"` // PAT secret when no app is configured
+	App         GitHubApp `yaml:"app"`
+
+	// Workflow the platform dispatches for deploys and rollbacks
+	DispatchWorkflow string `yaml:"dispatchWorkflow"`
+}
+
+type GitHubApp struct {
+	ID               string `yaml:"id"`
+	PrivateKeySecret string `yaml:"privateKeySecret"` // "file:/path.pem" works too
+	Org              string `yaml:"org"`              // default org for new repositories
+}
+
+type Jenkins struct {
+	URL                 string `yaml:"url"`
+	UserSecret          string `yaml:"userSecret"`
+	TokenSecret         string `yaml:"tokenSecret"`
+	GitHubCredentialsID string `yaml:"githubCredentialsId"`
+}
+
+type Workspace struct {
+	Dir string `yaml:"dir"` // scratch space for clones and rendered templates
+}
+
+type Platform struct {
+	URL         string `yaml:"url"`         // base URL pipelines call back to; required
+	ArtifactURL string `yaml:"artifactUrl"` // where pipelines report artifacts ("" → URL/api/artifacts)
+}
+
+type Deployments struct {
+	// How long a deploy/rollback holds its service/environment lock when the
+	// pipeline never reports back (failed runs do not call the artifact API)
+	LockTTL time.Duration `yaml:"lockTtl"`
+
+	// Scheduled deployments: how often the elected replica looks for due
+	// runs, and how long after scheduledAt a run may still start (e.g.
+	// while waiting for a lock) before it is skipped
+	SchedulerInterval time.Duration `yaml:"schedulerInterval"`
+	ScheduleGrace     time.Duration `yaml:"scheduleGrace"`
+}
+
+// Notifications go to owner teams, e.g. after an automatic rollback. The
+// webhook receives one JSON message per notification; without it they are
+// only logged.
+type Notifications struct {
+	WebhookURL string `yaml:"webhookUrl"`
+}
+
+// Kubernetes services are deployed through GitOps: the platform commits
+// their manifests and image tag to a config repository per environment,
+// which Argo CD / Flux applies to the cluster.
+type Kubernetes struct {
+	Registry      string            `yaml:"registry"`      // images are <registry>/<service>:<short commit>
+	GitOpsRepos   map[string]string `yaml:"gitopsRepos"`   // environment → "owner/name"
+	GitOpsBranch  string            `yaml:"gitopsBranch"`  // branch the controller watches
+	IngressDomain string            `yaml:"ingressDomain"` // hosts are <service>.<env>.<domain>; no Ingress when empty
+}
+
+// ArgoCD manages microservice services: the platform creates an Argo CD
+// Application per service/environment, syncs it after each deploy and shows
+// its sync and health status on the dashboard.
+type ArgoCD struct {
+	URL               string `yaml:"url"`               // Argo CD server; the integration is off when empty
+	TokenSecret       string `yaml:"tokenSecret"`       // API token (account or project role)
+	Project           string `yaml:"project"`           // Argo CD project Applications are created in
+	DestinationServer string `yaml:"destinationServer"` // cluster API server Applications deploy to
+	Path              string `yaml:"path"`              // manifests directory in the service repository
+}
+
+// Terraform provisions ec2 services' infrastructure: each environment gets a
+// root module in the service repository, built on the platform modules in
+// template_data, whose state is kept in S3.
+type Terraform struct {
+	ModuleVersion string `yaml:"moduleVersion"` // platform modules used unless a service asks for another
+	Region        string `yaml:"region"`        // AWS region of the state bucket and the resources
+	StateBucket   string `yaml:"stateBucket"`   // the integration is off when empty
+	LockTable     string `yaml:"lockTable"`     // DynamoDB table for state locking
+}
+
+// Previews are short-lived pr-<number> environments deploying the head of a
+// pull request. GitHub delivers pull_request events to
+// /api/v1/webhooks/github, signed with the webhook secret.
+type Previews struct {
+	Enabled       bool          `yaml:"enabled"`
+	WebhookSecret string        `yaml:"webhookSecret"` // secret the webhook deliveries are signed with
+	URL           string        `yaml:"url"`           // {service} and {env} are replaced; posted on the pull request
+	TTL           time.Duration `yaml:"ttl"`           // torn down after this long without a push
+}
+
+// Default returns the configuration used for anything not set by the file
+// or the environment.
+func Default() Config {
+	return Config{
+		Server: Server{
+			Addr:              ":8080",
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      5 * time.Minute,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   5 * time.Minute,
+			Readiness:         Readiness{Timeout: 3 * time.Second},
+		},
+		Database: Database{
+			Port:           "3306",
+			PasswordSecret: "db-password",
+			ConnectTimeout: 2 * time.Minute,
+		},
+		GitHub: GitHub{
+			TokenSecret:      "git-token",
+			App:              GitHubApp{PrivateKeySecret: "github-app-private-key"},
+			DispatchWorkflow: "cicd.yaml",
+		},
+		Jenkins: Jenkins{
+			UserSecret:  "jenkins-user",
+			TokenSecret: "jenkins-api-token",
+		},
+		Workspace: Workspace{Dir: os.TempDir()},
+		Deployments: Deployments{
+			LockTTL:           30 * time.Minute,
+			SchedulerInterval: 30 * time.Second,
+			ScheduleGrace:     time.Hour,
+		},
+		Kubernetes: Kubernetes{GitOpsBranch: "main"},
+		ArgoCD: ArgoCD{
+			TokenSecret:       "argocd-token",
+			Project:           "default",
+			DestinationServer: "https://kubernetes.default.svc",
+			Path:              "deploy",
+		},
+		Terraform: Terraform{ModuleVersion: "v1", Region: "us-east-1"},
+		Previews: Previews{
+			WebhookSecret: "github-webhook-secret",
+			TTL:           72 * time.Hour,
+		},
+	}
+}
+
+// Load builds the configuration: defaults, then the YAML file named by
+// CONFIG_FILE (if any), then environment overrides. Every problem found is
+// reported at once.
+func Load() (*Config, error) {
+	cfg := Default()
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := readFile(path, &cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	// Kept for deployments that still ship a separate secrets file
+	if path := os.Getenv("SECRETS_CONFIG"); path != "" {
+		if err := readFile(path, &cfg.Secrets); err != nil {
+			return nil, err
+		}
+	}
+
+	problems := applyEnv(&cfg)
+
+	// Pipelines report to the platform's own artifact endpoint unless told
+	// otherwise
+	if cfg.Platform.ArtifactURL == "" && cfg.Platform.URL != "" {
+		cfg.Platform.ArtifactURL = strings.TrimRight(cfg.Platform.URL, "/") + "/api/artifacts"
+	}
+
+	problems = append(problems, cfg.validate()...)
+
+	if len(problems) > 0 {
+		return nil, &Error{Problems: problems}
+	}
+	return &cfg, nil
+}
+
+func readFile(path string, out interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return nil
+}
+
+// Error lists every invalid or missing setting.
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
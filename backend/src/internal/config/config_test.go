@@ -0,0 +1,200 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// isolate blanks every variable Load reads so the host environment cannot
+// leak into a test; applyEnv ignores empty values.
+func isolate(t *testing.T) {
+	t.Helper()
+
+	var c Config
+	for key := range stringOverrides(&c) {
+		t.Setenv(key, "")
+	}
+	for key := range durationOverrides(&c) {
+		t.Setenv(key, "")
+	}
+	for key := range boolOverrides(&c) {
+		t.Setenv(key, "")
+	}
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("SECRETS_CONFIG", "")
+}
+
+func writeConfig(t *testing.T, yaml string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+}
+
+const minimalYAML = `
+database: {host: mysql, user: platform, name: platform}
+platform: {url: "https://platform.example.com/"}
+`
+
+func TestLoadYAML(t *testing.T) {
+	isolate(t)
+	writeConfig(t, minimalYAML+`
+server: {addr: ":9090", shutdownTimeout: 30s}
+github: {host: ghe.example.com}
+deployments: {lockTtl: 10m}
+`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Server.Addr != ":9090" || cfg.Server.ShutdownTimeout != 30*time.Second {
+		t.Errorf("server = %+v", cfg.Server)
+	}
+	if cfg.Deployments.LockTTL != 10*time.Minute || cfg.Deployments.ScheduleGrace != time.Hour {
+		t.Errorf("deployments = %+v; file values and defaults should merge", cfg.Deployments)
+	}
+	if cfg.GitHub.Host != "ghe.example.com" || cfg.GitHub.TokenSecret != "git-token" {
+		t.Errorf("github = %+v", cfg.GitHub)
+	}
+	if cfg.Platform.ArtifactURL != "https://platform.example.com/api/artifacts" {
+		t.Errorf("artifact url = %q, want one derived from platform.url", cfg.Platform.ArtifactURL)
+	}
+}
+
+func TestLoadEnvOverridesFile(t *testing.T) {
+	isolate(t)
+	writeConfig(t, `
+database: {host: mysql, user: platform, name: platform}
+platform: {url: "https://platform.example.com", artifactUrl: "https://file.example.com/artifacts"}
+server: {addr: ":9090", readiness: {github: false}}
+`)
+	t.Setenv("SERVER_ADDR", ":7070")
+	t.Setenv("DEPLOY_LOCK_TTL", "45m")
+	t.Setenv("READYZ_GITHUB", "true")
+	t.Setenv("PLATFORM_ARTIFACT_URL", "https://env.example.com/artifacts")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Server.Addr != ":7070" {
+		t.Errorf("addr = %q, want the env value", cfg.Server.Addr)
+	}
+	if cfg.Deployments.LockTTL != 45*time.Minute {
+		t.Errorf("lock ttl = %s", cfg.Deployments.LockTTL)
+	}
+	if !cfg.Server.Readiness.GitHub {
+		t.Error("READYZ_GITHUB ignored")
+	}
+	if cfg.Platform.ArtifactURL != "https://env.example.com/artifacts" {
+		t.Errorf("artifact url = %q", cfg.Platform.ArtifactURL)
+	}
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	isolate(t)
+	writeConfig(t, `
+database: {host: mysql, port: "mysql"}
+github: {apiUrl: "ftp://ghe.example.com"}
+`)
+	t.Setenv("SCHEDULER_INTERVAL", "often")
+	t.Setenv("PREVIEWS_ENABLED", "sometimes")
+
+	_, err := Load()
+	var cfgErr *Error
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("got %v, want *Error", err)
+	}
+
+	for _, want := range []string{
+		"database.user is required",
+		"database.name is required",
+		`database.port: "mysql" is not a port number`,
+		"platform.url is required",
+		`github.apiUrl: "ftp://ghe.example.com" is not an http(s) URL`,
+		`SCHEDULER_INTERVAL: "often" is not a duration`,
+		`PREVIEWS_ENABLED: "sometimes" is not a boolean`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("missing problem %q in:\n%v", want, err)
+		}
+	}
+}
+
+func TestLoadRejectsUnknownFields(t *testing.T) {
+	isolate(t)
+	writeConfig(t, minimalYAML+"databse: {host: typo}\n")
+
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "databse") {
+		t.Errorf("got %v, want an unknown field error", err)
+	}
+}
+
+func TestValidateOptionalIntegrations(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   string // "" → valid
+	}{
+		{name: "defaults plus required", modify: func(*Config) {}},
+		{
+			name:   "jenkins readiness without jenkins",
+			modify: func(c *Config) { c.Server.Readiness.Jenkins = true },
+			want:   "server.readiness.jenkins needs jenkins.url",
+		},
+		{
+			name:   "trusted proxy",
+			modify: func(c *Config) { c.Server.TrustedProxies = []string{"10.0.0.0/8", "10.0.0.7"} },
+			want:   `server.trustedProxies: "10.0.0.7" is not a CIDR`,
+		},
+		{
+			name:   "github app id",
+			modify: func(c *Config) { c.GitHub.App.ID = "my-app" },
+			want:   `github.app.id: "my-app" is not numeric`,
+		},
+		{
+			name: "gitops repo",
+			modify: func(c *Config) {
+				c.Kubernetes.GitOpsRepos = map[string]string{"dev": "gitops"}
+				c.Kubernetes.Registry = "ghcr.io/acme"
+			},
+			want: `kubernetes.gitopsRepos.dev: "gitops" must be owner/name`,
+		},
+		{
+			name:   "previews without ttl",
+			modify: func(c *Config) { c.Previews.Enabled = true; c.Previews.TTL = 0 },
+			want:   "previews.ttl must be a positive duration",
+		},
+		{
+			name:   "vault ref without vault",
+			modify: func(c *Config) { c.Secrets.Refs = map[string]string{"git-token": "vault:github#token"} },
+			want:   "secrets.refs.git-token uses vault but secrets.vault.addr is not set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Default()
+			c.Database.Host, c.Database.User, c.Database.Name = "mysql", "platform", "platform"
+			c.Platform.URL = "https://platform.example.com"
+			tt.modify(&c)
+
+			problems := c.validate()
+			switch {
+			case tt.want == "" && len(problems) > 0:
+				t.Errorf("unexpected problems %v", problems)
+			case tt.want != "" && (len(problems) != 1 || problems[0] != tt.want):
+				t.Errorf("problems = %v, want [%s]", problems, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Environment variables that override the file. Names match what the
+// deployments have always set.
+func stringOverrides(c *Config) map[string]*string {
+	return map[string]*string{
+		"SERVER_ADDR": &c.Server.Addr,
+
+		"DB_HOST":            &c.Database.Host,
+		"DB_PORT":            &c.Database.Port,
+		"DB_USER":            &c.Database.User,
+		"DB_NAME":            &c.Database.Name,
+		"DB_PASSWORD_SECRET": &c.Database.PasswordSecret,
+
+		"GITHUB_HOST":                   &c.GitHub.Host,
+		"GITHUB_API_URL":                &c.GitHub.APIURL,
+		"GITHUB_UPLOAD_URL":             &c.GitHub.UploadURL,
+		"GITHUB_WEB_URL":                &c.GitHub.WebURL,
+		"GITHUB_TOKEN_SECRET":           &c.GitHub.TokenSecret,
+		"GITHUB_APP_ID":                 &c.GitHub.App.ID,
+		"GITHUB_APP_PRIVATE_KEY_SECRET": &c.GitHub.App.PrivateKeySecret,
+		"GITHUB_APP_ORG":                &c.GitHub.App.Org,
+		"GITHUB_DISPATCH_WORKFLOW":      &c.GitHub.DispatchWorkflow,
+
+		"JENKINS_URL":                   &c.Jenkins.URL,
+		"JENKINS_USER_SECRET":           &c.Jenkins.UserSecret,
+		"JENKINS_TOKEN_SECRET":          &c.Jenkins.TokenSecret,
+		"JENKINS_GITHUB_CREDENTIALS_ID": &c.Jenkins.GitHubCredentialsID,
+
+		"WORKSPACE_DIR":         &c.Workspace.Dir,
+		"PLATFORM_URL":          &c.Platform.URL,
+		"PLATFORM_ARTIFACT_URL": &c.Platform.ArtifactURL,
+		"NOTIFY_WEBHOOK_URL":    &c.Notify.WebhookURL,
+
+		"KUBERNETES_REGISTRY":       &c.Kubernetes.Registry,
+		"KUBERNETES_INGRESS_DOMAIN": &c.Kubernetes.IngressDomain,
+		"GITOPS_BRANCH":             &c.Kubernetes.GitOpsBranch,
+
+		"ARGOCD_URL":                &c.ArgoCD.URL,
+		"ARGOCD_TOKEN_SECRET":       &c.ArgoCD.TokenSecret,
+		"ARGOCD_PROJECT":            &c.ArgoCD.Project,
+		"ARGOCD_DESTINATION_SERVER": &c.ArgoCD.DestinationServer,
+		"ARGOCD_PATH":               &c.ArgoCD.Path,
+
+		"TERRAFORM_MODULE_VERSION": &c.Terraform.ModuleVersion,
+		"TERRAFORM_REGION":         &c.Terraform.Region,
+		"TERRAFORM_STATE_BUCKET":   &c.Terraform.StateBucket,
+		"TERRAFORM_LOCK_TABLE":     &c.Terraform.LockTable,
+
+		"PREVIEW_WEBHOOK_SECRET": &c.Previews.WebhookSecret,
+		"PREVIEW_URL":            &c.Previews.URL,
+
+		"SECRETS_BACKEND":    &c.Secrets.Backend,
+		"SECRETS_DIR":        &c.Secrets.File.Dir,
+		"SECRETS_ENV_PREFIX": &c.Secrets.Env.Prefix,
+		"VAULT_ADDR":         &c.Secrets.Vault.Addr,
+		"VAULT_TOKEN":        &c.Secrets.Vault.Token,
+		"VAULT_MOUNT":        &c.Secrets.Vault.Mount,
+	}
+}
+
+func durationOverrides(c *Config) map[string]*time.Duration {
+	return map[string]*time.Duration{
+		"SECRETS_CACHE_TTL": &c.Secrets.CacheTTL,
+
+		"SERVER_READ_HEADER_TIMEOUT": &c.Server.ReadHeaderTimeout,
+		"SERVER_READ_TIMEOUT":        &c.Server.ReadTimeout,
+		"SERVER_WRITE_TIMEOUT":       &c.Server.WriteTimeout,
+		"SERVER_IDLE_TIMEOUT":        &c.Server.IdleTimeout,
+		"SERVER_SHUTDOWN_TIMEOUT":    &c.Server.ShutdownTimeout,
+		"READYZ_TIMEOUT":             &c.Server.Readiness.Timeout,
+		"DB_CONNECT_TIMEOUT":         &c.Database.ConnectTimeout,
+		"DEPLOY_LOCK_TTL":            &c.Deployments.LockTTL,
+		"SCHEDULER_INTERVAL":         &c.Deployments.SchedulerInterval,
+		"SCHEDULE_GRACE":             &c.Deployments.ScheduleGrace,
+		"PREVIEW_TTL":                &c.Previews.TTL,
+	}
+}
+
+func boolOverrides(c *Config) map[string]*bool {
+	return map[string]*bool{
+		"READYZ_GITHUB":    &c.Server.Readiness.GitHub,
+		"READYZ_JENKINS":   &c.Server.Readiness.Jenkins,
+		"PREVIEWS_ENABLED": &c.Previews.Enabled,
+	}
+}
+
+func applyEnv(c *Config) []string {
+	var problems []string
+
+	for key, field := range stringOverrides(c) {
+		if v, ok := os.LookupEnv(key); ok && v != "" {
+			*field = v
+		}
+	}
+
+	for key, field := range durationOverrides(c) {
+		v, ok := os.LookupEnv(key)
+		if !ok || v == "" {
+			continue
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %q is not a duration", key, v))
+			continue
+		}
+		*field = d
+	}
+
+	for key, field := range boolOverrides(c) {
+		v, ok := os.LookupEnv(key)
+		if !ok || v == "" {
+			continue
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %q is not a boolean", key, v))
+			continue
+		}
+		*field = b
+	}
+
+	return problems
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (c *Config) validate() []string {
+	var p problems
+
+	p.required("server.addr", c.Server.Addr)
+	p.positive("server.readHeaderTimeout", c.Server.ReadHeaderTimeout)
+	p.positive("server.readTimeout", c.Server.ReadTimeout)
+	p.positive("server.writeTimeout", c.Server.WriteTimeout)
+	p.positive("server.idleTimeout", c.Server.IdleTimeout)
+	p.positive("server.shutdownTimeout", c.Server.ShutdownTimeout)
+	p.positive("server.readiness.timeout", c.Server.Readiness.Timeout)
+	if c.Server.Readiness.Jenkins && c.Jenkins.URL == "" {
+		p.add("server.readiness.jenkins needs jenkins.url")
+	}
+	for _, cidr := range c.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			p.add("server.trustedProxies: %q is not a CIDR", cidr)
+		}
+	}
+
+	p.required("database.host", c.Database.Host)
+	p.required("database.user", c.Database.User)
+	p.required("database.name", c.Database.Name)
+	if _, err := strconv.Atoi(c.Database.Port); err != nil {
+		p.add("database.port: %q is not a port number", c.Database.Port)
+	}
+	p.positive("database.connectTimeout", c.Database.ConnectTimeout)
+
+	p.positive("deployments.lockTtl", c.Deployments.LockTTL)
+	p.positive("deployments.schedulerInterval", c.Deployments.SchedulerInterval)
+	p.positive("deployments.scheduleGrace", c.Deployments.ScheduleGrace)
+
+	p.url("github.apiUrl", c.GitHub.APIURL)
+	p.url("github.uploadUrl", c.GitHub.UploadURL)
+	p.url("github.webUrl", c.GitHub.WebURL)
+	if strings.Contains(c.GitHub.Host, "/") {
+		p.add("github.host: %q must be a hostname, not a URL", c.GitHub.Host)
+	}
+	if c.GitHub.App.ID != "" {
+		if _, err := strconv.ParseInt(c.GitHub.App.ID, 10, 64); err != nil {
+			p.add("github.app.id: %q is not numeric", c.GitHub.App.ID)
+		}
+		p.required("github.app.privateKeySecret", c.GitHub.App.PrivateKeySecret)
+	} else {
+		p.required("github.tokenSecret", c.GitHub.TokenSecret)
+	}
+	if !strings.HasSuffix(c.GitHub.DispatchWorkflow, ".yaml") && !strings.HasSuffix(c.GitHub.DispatchWorkflow, ".yml") {
+		p.add("github.dispatchWorkflow: %q must be a .yaml or .yml file name", c.GitHub.DispatchWorkflow)
+	}
+
+	// Jenkins is optional; only services with cicdType jenkins need it
+	p.url("jenkins.url", c.Jenkins.URL)
+	if c.Jenkins.URL != "" {
+		p.required("jenkins.userSecret", c.Jenkins.UserSecret)
+		p.required("jenkins.tokenSecret", c.Jenkins.TokenSecret)
+	}
+
+	if info, err := os.Stat(c.Workspace.Dir); err != nil || !info.IsDir() {
+		p.add("workspace.dir: %q is not a directory", c.Workspace.Dir)
+	}
+
+	// Pipelines call back here; there is no sensible default host
+	p.required("platform.url", c.Platform.URL)
+	p.url("platform.url", c.Platform.URL)
+	p.url("platform.artifactUrl", c.Platform.ArtifactURL)
+	p.url("notifications.webhookUrl", c.Notify.WebhookURL)
+
+	// Kubernetes is optional; only services with deploytype kubernetes need it
+	if len(c.Kubernetes.GitOpsRepos) > 0 {
+		p.required("kubernetes.registry", c.Kubernetes.Registry)
+		p.required("kubernetes.gitopsBranch", c.Kubernetes.GitOpsBranch)
+	}
+	for env, repo := range c.Kubernetes.GitOpsRepos {
+		if owner, name, ok := strings.Cut(repo, "/"); !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			p.add("kubernetes.gitopsRepos.%s: %q must be owner/name", env, repo)
+		}
+	}
+	if strings.Contains(c.Kubernetes.IngressDomain, "/") {
+		p.add("kubernetes.ingressDomain: %q must be a domain, not a URL", c.Kubernetes.IngressDomain)
+	}
+
+	// Argo CD is optional; only services with deploytype microservice use it
+	p.url("argocd.url", c.ArgoCD.URL)
+	if c.ArgoCD.URL != "" {
+		p.required("argocd.tokenSecret", c.ArgoCD.TokenSecret)
+		p.required("argocd.project", c.ArgoCD.Project)
+		p.url("argocd.destinationServer", c.ArgoCD.DestinationServer)
+		p.required("argocd.destinationServer", c.ArgoCD.DestinationServer)
+	}
+	if strings.HasPrefix(c.ArgoCD.Path, "/") {
+		p.add("argocd.path: %q must be relative to the service", c.ArgoCD.Path)
+	}
+
+	// Terraform is optional; only ec2 services with infra need it
+	if c.Terraform.StateBucket != "" {
+		p.required("terraform.region", c.Terraform.Region)
+		p.required("terraform.moduleVersion", c.Terraform.ModuleVersion)
+	}
+
+	// Previews are optional; they need a signed webhook and a way to expire
+	if c.Previews.Enabled {
+		p.required("previews.webhookSecret", c.Previews.WebhookSecret)
+		p.positive("previews.ttl", c.Previews.TTL)
+	}
+	p.url("previews.url", strings.NewReplacer("{service}", "service", "{env}", "env").Replace(c.Previews.URL))
+
+	switch c.Secrets.Backend {
+	case "", "aws", "env", "file":
+	case "vault":
+		p.required("secrets.vault.addr", c.Secrets.Vault.Addr)
+	default:
+		p.add("secrets.backend: %q must be aws, vault, env or file", c.Secrets.Backend)
+	}
+	if c.Secrets.Vault.Addr == "" {
+		for name, ref := range c.Secrets.Refs {
+			if strings.HasPrefix(ref, "vault:") {
+				p.add("secrets.refs.%s uses vault but secrets.vault.addr is not set", name)
+			}
+		}
+	}
+	if c.Secrets.CacheTTL < 0 {
+		p.add("secrets.cacheTTL must not be negative")
+	}
+
+	sort.Strings(p)
+	return p
+}
+
+type problems []string
+
+func (p *problems) add(format string, args ...interface{}) {
+	*p = append(*p, fmt.Sprintf(format, args...))
+}
+
+func (p *problems) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		p.add("%s is required", field)
+	}
+}
+
+func (p *problems) positive(field string, d time.Duration) {
+	if d <= 0 {
+		p.add("%s must be a positive duration", field)
+	}
+}
+
+// url accepts an empty value; anything else must be an absolute http(s) URL.
+func (p *problems) url(field, value string) {
+	if value == "" {
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		p.add("%s: %q is not an http(s) URL", field, value)
+	}
+}
@@ -1,52 +1,91 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
-	"os"
+	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
+
+	"src/src/internal/config"
+	"src/src/internal/secrets"
 )
 
 var DB *sql.DB
 
-func InitMySQL() {
+// InitMySQL opens the pool and pings MySQL until it answers, backing off
+// between attempts, for at most cfg.ConnectTimeout.
+func InitMySQL(ctx context.Context, cfg config.Database) error {
 	log.Println("🗄️ Initializing MySQL connection")
 
-	// Read env vars (DO NOT log password)
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbUser := os.Getenv("DB_USER")
-	dbName := os.Getenv("DB_NAME")
-
+	// DO NOT log password
 	log.Printf(
 		"📡 MySQL config → host=%s port=%s user=%s db=%s",
-		dbHost, dbPort, dbUser, dbName,
+		cfg.Host, cfg.Port, cfg.User, cfg.Name,
 	)
 
+	dbPassword, err := secrets.Get(ctx, cfg.PasswordSecret)
+	if err != nil && !errors.Is(err, secrets.ErrNotFound) {
+		return fmt.Errorf("read DB password: %w", err)
+	}
+
 	dsn := fmt.Sprintf(
 		"%s:%s@tcp(%s:%s)/%s?parseTime=true",
-		dbUser,
-		os.Getenv("DB_PASSWORD"), // never log this
-		dbHost,
-		dbPort,
-		dbName,
+		cfg.User,
+		dbPassword, // never log this
+		cfg.Host,
+		cfg.Port,
+		cfg.Name,
 	)
 
-	var err error
 	DB, err = sql.Open("mysql", dsn)
 	if err != nil {
-		log.Println("❌ Failed to open MySQL connection:", err)
-		panic(err)
+		return fmt.Errorf("open MySQL connection: %w", err)
 	}
 
 	log.Println("🔌 MySQL connection opened, pinging database...")
 
-	if err = DB.Ping(); err != nil {
-		log.Println("❌ MySQL ping failed:", err)
-		panic(err)
+	ctx, cancel := context.WithTimeout(ctx, cfg.ConnectTimeout)
+	defer cancel()
+
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		err = Ping(ctx)
+		if err == nil {
+			break
+		}
+
+		log.Printf("⏳ MySQL ping failed (attempt %d), retrying in %s: %v", attempt, backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("MySQL not reachable after %s: %w", cfg.ConnectTimeout, err)
+		case <-time.After(backoff):
+		}
+
+		if backoff *= 2; backoff > 30*time.Second {
+			backoff = 30 * time.Second
+		}
 	}
 
 	log.Println("✅ MySQL connection established successfully")
-}
\ No newline at end of file
+	return nil
+}
+
+// Ping checks the database answers (used by /readyz).
+func Ping(ctx context.Context) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.PingContext(ctx)
+}
+
+// IsDuplicateKey reports whether err is MySQL's ER_DUP_ENTRY (1062), i.e. a
+// UNIQUE or PRIMARY KEY was violated.
+func IsDuplicateKey(err error) bool {
+	var myErr *mysql.MySQLError
+	return errors.As(err, &myErr) && myErr.Number == 1062
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"src/src/internal/config"
+)
+
+func TestInitMySQLRetriesUntilConnectTimeout(t *testing.T) {
+	// A port nothing listens on
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	cfg := config.Default().Database
+	cfg.Host, cfg.User, cfg.Name = "127.0.0.1", "platform", "platform"
+	cfg.Port = strconv.Itoa(port)
+	cfg.ConnectTimeout = 1500 * time.Millisecond
+	t.Setenv("DB_PASSWORD", "")
+	t.Cleanup(func() { DB = nil })
+
+	start := time.Now()
+	err = InitMySQL(context.Background(), cfg)
+	elapsed := time.Since(start)
+
+	if err == nil || !strings.Contains(err.Error(), "not reachable after 1.5s") {
+		t.Fatalf("got %v", err)
+	}
+	// First ping, a 1s backoff, a second ping, then the budget runs out
+	if elapsed < time.Second || elapsed > 10*time.Second {
+		t.Errorf("gave up after %s", elapsed)
+	}
+}
+
+func TestInitMySQLStopsOnCancel(t *testing.T) {
+	cfg := config.Default().Database
+	cfg.Host, cfg.Port, cfg.User, cfg.Name = "127.0.0.1", "1", "platform", "platform"
+	t.Cleanup(func() { DB = nil })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // SIGTERM during startup
+
+	start := time.Now()
+	if err := InitMySQL(ctx, cfg); err == nil {
+		t.Fatal("want error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("kept retrying for %s after cancel; connect timeout is %s", elapsed, cfg.ConnectTimeout)
+	}
+}
@@ -106,6 +106,292 @@ func EnsureSchema() error {
 		INDEX idx_approvals_service (service_name)
 	);`
 
+	/* ===================== SERVICE SECRETS ===================== */
+
+	// Names + rotation times only; values live in GitHub / Jenkins
+	serviceSecretsTable := `
+	CREATE TABLE IF NOT EXISTS service_secrets (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		service_name VARCHAR(150) NOT NULL,
+		environment VARCHAR(50) NOT NULL,
+		name VARCHAR(100) NOT NULL,
+		store VARCHAR(20) NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		rotated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE KEY uniq_service_env_secret (service_name, environment, name)
+	);`
+
+	/* ===================== DEPLOYMENT LOCKS ===================== */
+
+	// One row per service/environment while a deploy or rollback is in
+	// flight; released when the pipeline reports back or at expires_at
+	deploymentLocksTable := `
+	CREATE TABLE IF NOT EXISTS deployment_locks (
+		service_name VARCHAR(150) NOT NULL,
+		environment VARCHAR(20) NOT NULL,
+		token CHAR(32) NOT NULL,
+		operation VARCHAR(20) NOT NULL,
+		owner VARCHAR(100) NOT NULL,
+		reason VARCHAR(255) NOT NULL DEFAULT '',
+		acquired_at TIMESTAMP NOT NULL,
+		expires_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (service_name, environment),
+		INDEX idx_locks_expires (expires_at)
+	);`
+
+	/* ===================== FREEZE WINDOWS ===================== */
+
+	// A one-off window uses starts_at/ends_at. A recurring one has a cron or
+	// RRULE schedule whose occurrences last duration_minutes, optionally
+	// bounded by starts_at/ends_at. Empty environment/owner_team match all.
+	freezeWindowsTable := `
+	CREATE TABLE IF NOT EXISTS freeze_windows (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		name VARCHAR(150) NOT NULL,
+		environment VARCHAR(20) NOT NULL DEFAULT '',
+		owner_team VARCHAR(100) NOT NULL DEFAULT '',
+		schedule VARCHAR(255) NOT NULL DEFAULT '',
+		duration_minutes INT NOT NULL DEFAULT 0,
+		timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+		starts_at TIMESTAMP NULL,
+		ends_at TIMESTAMP NULL,
+		reason VARCHAR(255) NOT NULL DEFAULT '',
+		created_by VARCHAR(100) NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_freeze_scope (environment, owner_team)
+	);`
+
+	freezeExemptionsTable := `
+	CREATE TABLE IF NOT EXISTS freeze_exemptions (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		window_id BIGINT NOT NULL,
+		service_name VARCHAR(150) NOT NULL,
+		environment VARCHAR(20) NOT NULL,
+		reason VARCHAR(255) NOT NULL,
+		requested_by VARCHAR(100) NOT NULL,
+		status ENUM('pending','approved','rejected') NOT NULL DEFAULT 'pending',
+		decided_by VARCHAR(100) NOT NULL DEFAULT '',
+		expires_at TIMESTAMP NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		decided_at TIMESTAMP NULL,
+		INDEX idx_exemptions_lookup (window_id, service_name, environment, status),
+		FOREIGN KEY (window_id)
+			REFERENCES freeze_windows(id)
+			ON DELETE CASCADE
+	);`
+
+	// Audit trail of emergency rollbacks run during a freeze
+	freezeOverridesTable := `
+	CREATE TABLE IF NOT EXISTS freeze_overrides (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		window_id BIGINT NOT NULL,
+		service_name VARCHAR(150) NOT NULL,
+		environment VARCHAR(20) NOT NULL,
+		operation VARCHAR(20) NOT NULL,
+		actor VARCHAR(100) NOT NULL,
+		reason VARCHAR(255) NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_overrides_service (service_name, environment)
+	);`
+
+	/* ===================== SCHEDULED DEPLOYMENTS ===================== */
+
+	// Picked up by whichever replica holds the scheduler's GET_LOCK
+	scheduledDeploymentsTable := `
+	CREATE TABLE IF NOT EXISTS scheduled_deployments (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		service_name VARCHAR(150) NOT NULL,
+		environment VARCHAR(20) NOT NULL,
+		scheduled_at TIMESTAMP NOT NULL,
+		approval_id BIGINT NULL,
+		status VARCHAR(20) NOT NULL DEFAULT 'scheduled',
+		reason VARCHAR(255) NOT NULL DEFAULT '',
+		requested_by VARCHAR(100) NOT NULL,
+		last_error TEXT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		executed_at TIMESTAMP NULL,
+		INDEX idx_scheduled_due (status, scheduled_at),
+		INDEX idx_scheduled_service (service_name),
+		INDEX idx_scheduled_approval (approval_id)
+	);`
+
+	/* ===================== HEALTH CHECKS ===================== */
+
+	// Probed after each successful deploy; a failing check rolls back
+	healthChecksTable := `
+	CREATE TABLE IF NOT EXISTS health_checks (
+		service_name VARCHAR(150) NOT NULL,
+		environment VARCHAR(20) NOT NULL,
+		url VARCHAR(2048) NOT NULL,
+		expected_status INT NOT NULL DEFAULT 200,
+		window_seconds INT NOT NULL,
+		interval_seconds INT NOT NULL,
+		timeout_seconds INT NOT NULL,
+		failure_threshold INT NOT NULL,
+		updated_by VARCHAR(100) NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (service_name, environment)
+	);`
+
+	// One row per verified deploy, including why it was rolled back
+	healthCheckRunsTable := `
+	CREATE TABLE IF NOT EXISTS health_check_runs (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		service_name VARCHAR(150) NOT NULL,
+		environment VARCHAR(20) NOT NULL,
+		version VARCHAR(255) NOT NULL,
+		previous_version VARCHAR(255) NOT NULL DEFAULT '',
+		status VARCHAR(20) NOT NULL DEFAULT 'running',
+		detail TEXT NULL,
+		started_at TIMESTAMP NOT NULL,
+		finished_at TIMESTAMP NULL,
+		INDEX idx_health_runs_service (service_name, environment),
+		INDEX idx_health_runs_status (status)
+	);`
+
+	/* ===================== ROLLOUTS ===================== */
+
+	// Absent rows mean all-at-once; canary_steps is e.g. "10,50,100"
+	deploymentStrategiesTable := `
+	CREATE TABLE IF NOT EXISTS deployment_strategies (
+		service_name VARCHAR(150) NOT NULL,
+		environment VARCHAR(20) NOT NULL,
+		strategy VARCHAR(20) NOT NULL,
+		canary_steps VARCHAR(100) NOT NULL DEFAULT '',
+		step_interval_seconds INT NOT NULL DEFAULT 0,
+		updated_by VARCHAR(100) NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (service_name, environment)
+	);`
+
+	// One row per canary / blue-green deploy; at most one unfinished per
+	// service/environment
+	rolloutsTable := `
+	CREATE TABLE IF NOT EXISTS rollouts (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		service_name VARCHAR(150) NOT NULL,
+		environment VARCHAR(20) NOT NULL,
+		strategy VARCHAR(20) NOT NULL,
+		steps VARCHAR(100) NOT NULL,
+		current_step INT NOT NULL DEFAULT 1,
+		step_interval_seconds INT NOT NULL DEFAULT 0,
+		version VARCHAR(255) NOT NULL DEFAULT '',
+		previous_version VARCHAR(255) NOT NULL DEFAULT '',
+		status VARCHAR(20) NOT NULL,
+		next_step_at TIMESTAMP NULL,
+		started_by VARCHAR(100) NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		finished_at TIMESTAMP NULL,
+		INDEX idx_rollouts_service (service_name, environment, status),
+		INDEX idx_rollouts_due (status, next_step_at)
+	);`
+
+	// Deployment history of a rollout: every step and abort it ran
+	rolloutStepsTable := `
+	CREATE TABLE IF NOT EXISTS rollout_steps (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		rollout_id BIGINT NOT NULL,
+		step INT NOT NULL,
+		phase VARCHAR(10) NOT NULL,
+		traffic_percent INT NOT NULL,
+		status VARCHAR(20) NOT NULL,
+		triggered_by VARCHAR(100) NOT NULL,
+		triggered_at TIMESTAMP NOT NULL,
+		finished_at TIMESTAMP NULL,
+		error TEXT NULL,
+		INDEX idx_rollout_steps_rollout (rollout_id),
+		FOREIGN KEY (rollout_id)
+			REFERENCES rollouts(id)
+			ON DELETE CASCADE
+	);`
+
+	/* ===================== INFRASTRUCTURE ===================== */
+
+	// One Terraform root module per service/environment
+	infraStacksTable := `
+	CREATE TABLE IF NOT EXISTS infra_stacks (
+		service_name VARCHAR(150) NOT NULL,
+		environment VARCHAR(20) NOT NULL,
+		cloud VARCHAR(20) NOT NULL,
+		compute VARCHAR(20) NOT NULL,
+		cpu VARCHAR(20) NOT NULL,
+		memory VARCHAR(20) NOT NULL,
+		instance_type VARCHAR(50) NOT NULL,
+		module_version VARCHAR(20) NOT NULL,
+		root_module VARCHAR(255) NOT NULL,
+		state_location VARCHAR(1024) NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (service_name, environment)
+	);`
+
+	// Every plan / apply with its output; at most one running per stack
+	infraRunsTable := `
+	CREATE TABLE IF NOT EXISTS infra_runs (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		service_name VARCHAR(150) NOT NULL,
+		environment VARCHAR(20) NOT NULL,
+		action VARCHAR(10) NOT NULL,
+		status VARCHAR(20) NOT NULL,
+		state_location VARCHAR(1024) NOT NULL,
+		output MEDIUMTEXT NULL,
+		run_url VARCHAR(2048) NOT NULL DEFAULT '',
+		requested_by VARCHAR(100) NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		finished_at TIMESTAMP NULL,
+		INDEX idx_infra_runs_service (service_name, environment)
+	);`
+
+	/* ===================== SERVICE CONFIGURATION ===================== */
+
+	// Versioned key-value configuration per service/environment; values
+	// is a JSON object
+	serviceConfigsTable := `
+	CREATE TABLE IF NOT EXISTS service_configs (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		service_name VARCHAR(150) NOT NULL,
+		environment VARCHAR(20) NOT NULL,
+		version INT NOT NULL,
+		config_values MEDIUMTEXT NOT NULL,
+		file VARCHAR(512) NOT NULL,
+		delivery VARCHAR(20) NOT NULL,
+		status VARCHAR(20) NOT NULL,
+		commit_sha VARCHAR(40) NOT NULL DEFAULT '',
+		pull_request_url VARCHAR(2048) NOT NULL DEFAULT '',
+		error TEXT NULL,
+		message VARCHAR(255) NOT NULL DEFAULT '',
+		updated_by VARCHAR(100) NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		UNIQUE KEY uq_service_configs_version (service_name, environment, version)
+	);`
+
+	/* ===================== PREVIEW ENVIRONMENTS ===================== */
+
+	// One row per service and pull request; a reopened pull request
+	// reuses its row
+	previewsTable := `
+	CREATE TABLE IF NOT EXISTS previews (
+		service_name VARCHAR(150) NOT NULL,
+		environment VARCHAR(20) NOT NULL,
+		pull_request INT NOT NULL,
+		repo_owner VARCHAR(100) NOT NULL,
+		repo_name VARCHAR(100) NOT NULL,
+		head_ref VARCHAR(255) NOT NULL,
+		head_sha VARCHAR(40) NOT NULL,
+		base_ref VARCHAR(255) NOT NULL,
+		status VARCHAR(20) NOT NULL,
+		version VARCHAR(255) NOT NULL DEFAULT '',
+		url VARCHAR(2048) NOT NULL DEFAULT '',
+		error TEXT NULL,
+		comment_id BIGINT NULL,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		expires_at TIMESTAMP NOT NULL,
+		closed_at TIMESTAMP NULL,
+		PRIMARY KEY (service_name, environment),
+		INDEX idx_previews_pull_request (repo_owner, repo_name, pull_request),
+		INDEX idx_previews_expiry (status, expires_at)
+	);`
+
 	/* ===================== EXECUTION ===================== */
 
 	tables := []struct {
@@ -117,6 +403,21 @@ func EnsureSchema() error {
 		{"artifacts", artifactsTable},
 		{"environment_state", environmentStateTable},
 		{"deployment_approvals", approvalsTable},
+		{"service_secrets", serviceSecretsTable},
+		{"deployment_locks", deploymentLocksTable},
+		{"freeze_windows", freezeWindowsTable},
+		{"freeze_exemptions", freezeExemptionsTable},
+		{"freeze_overrides", freezeOverridesTable},
+		{"scheduled_deployments", scheduledDeploymentsTable},
+		{"health_checks", healthChecksTable},
+		{"health_check_runs", healthCheckRunsTable},
+		{"deployment_strategies", deploymentStrategiesTable},
+		{"rollouts", rolloutsTable},
+		{"rollout_steps", rolloutStepsTable},
+		{"infra_stacks", infraStacksTable},
+		{"infra_runs", infraRunsTable},
+		{"service_configs", serviceConfigsTable},
+		{"previews", previewsTable},
 	}
 
 	for _, t := range tables {
@@ -126,6 +427,24 @@ func EnsureSchema() error {
 		}
 	}
 
+	/* ===================== COLUMNS (MYSQL SAFE) ===================== */
+
+	columns := []string{
+		`ALTER TABLE services ADD COLUMN repo_mode VARCHAR(20) NOT NULL DEFAULT 'standalone';`,
+		`ALTER TABLE services ADD COLUMN repo_path VARCHAR(255) NULL;`,
+		`ALTER TABLE services ADD COLUMN pull_request_url VARCHAR(255) NULL;`,
+		`ALTER TABLE services ADD COLUMN origin VARCHAR(20) NOT NULL DEFAULT 'created';`,
+		`ALTER TABLE services ADD COLUMN repo_owner VARCHAR(100) NULL;`,
+		`ALTER TABLE services ADD COLUMN visibility VARCHAR(20) NULL;`,
+	}
+
+	for _, col := range columns {
+		if _, err := DB.Exec(col); err != nil {
+			// MySQL will throw "Duplicate column name" if column exists — safe to ignore
+			log.Println("ℹ️ Column already exists or skipped:", err)
+		}
+	}
+
 	/* ===================== INDEXES (MYSQL SAFE) ===================== */
 
 	indexes := []string{
@@ -0,0 +1,200 @@
+// Package deploy starts a service's deploy or rollback pipeline for one
+// environment. The deploy handler, prod approvals, the scheduler and
+// automatic rollbacks all go through Trigger / Rollback so they resolve the
+// service and pick the CI system the same way; pull request previews go
+// through Preview.
+//
+// Kubernetes services have no deploy pipeline: Trigger pins the image
+// built from the environment branch's head commit in the environment's
+// GitOps repository and reports the deploy itself, as a pipeline would
+// through the artifact API.
+package deploy
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"src/src/internal/cicd"
+	"src/src/internal/db"
+	"src/src/internal/git"
+	"src/src/internal/gitops"
+	"src/src/internal/model"
+	"src/src/internal/service"
+)
+
+// reporter records a GitOps deploy or rollback like a pipeline's artifact
+// report; main wires it to the artifact handler.
+var reporter = func(ctx context.Context, a model.ArtifactEvent) error {
+	log.Printf("📦 %s %s of %s/%s not recorded: no reporter", a.Action, a.Version, a.ServiceName, a.Environment)
+	return nil
+}
+
+// SetReporter sets what records GitOps deploys and rollbacks.
+func SetReporter(fn func(context.Context, model.ArtifactEvent) error) {
+	reporter = fn
+}
+
+// tokens are the GitHub credentials pipelines are started with.
+var tokens = git.DefaultTokenSource()
+
+// SetTokenSource sets the GitHub credentials deploys act with.
+func SetTokenSource(ts git.TokenSource) {
+	tokens = ts
+}
+
+// Target is the CI configuration of a service.
+type Target struct {
+	ServiceName string
+	CICDType    string
+	DeployType  string
+	OwnerTeam   string
+	RepoOwner   string
+	Repo        string
+	RepoPath    string
+}
+
+// Lookup loads the target of serviceName, or service.ErrServiceNotFound.
+func Lookup(ctx context.Context, serviceName string) (*Target, error) {
+	t := &Target{ServiceName: serviceName}
+	err := db.DB.QueryRowContext(ctx, `
+		SELECT cicd_type, COALESCE(deploy_type, ''), COALESCE(owner_team, ''),
+		       COALESCE(repo_owner, ''), repo_name, COALESCE(repo_path, '')
+		FROM services
+		WHERE service_name = ?`,
+		serviceName,
+	).Scan(&t.CICDType, &t.DeployType, &t.OwnerTeam, &t.RepoOwner, &t.Repo, &t.RepoPath)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrServiceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Trigger starts the deploy pipeline of t for env; rollout is zero for an
+// all-at-once deploy. It does not check approvals, freezes or locks;
+// callers do.
+func Trigger(t *Target, env string, rollout cicd.RolloutParams) error {
+	branch := cicd.EnvironmentBranch(env)
+	if t.DeployType == model.DeployTypeKubernetes {
+		return deployGitOps(t, env, branch, rollout)
+	}
+	log.Printf("🚀 Triggering %s deploy of %s (%s) via %s", env, t.ServiceName, branch, t.CICDType)
+
+	switch t.CICDType {
+	case "jenkins":
+		return cicd.TriggerJenkinsDeploy(t.ServiceName, branch, t.RepoPath, rollout)
+	case "github":
+		return cicd.TriggerGitHubDeploy(tokens, t.RepoOwner, t.Repo, branch, t.RepoPath, rollout)
+	default:
+		return fmt.Errorf("unsupported cicd type %q", t.CICDType)
+	}
+}
+
+// ErrNoPreviews is returned for services whose deploys are not pipelines.
+var ErrNoPreviews = errors.New("kubernetes services have no preview environments")
+
+// Preview starts the deploy pipeline of t on ref for a preview environment,
+// or tears the preview down. Kubernetes services deploy through the GitOps
+// repositories of the fixed environments and have no previews.
+func Preview(t *Target, ref string, p cicd.PreviewParams) error {
+	if t.DeployType == model.DeployTypeKubernetes {
+		return ErrNoPreviews
+	}
+	action := "deploy"
+	if p.Teardown {
+		action = "teardown"
+	}
+	log.Printf("🔍 Triggering preview %s of %s in %s (%s) via %s", action, t.ServiceName, p.Environment, ref, t.CICDType)
+
+	switch t.CICDType {
+	case "jenkins":
+		return cicd.TriggerJenkinsPreview(t.ServiceName, ref, t.RepoPath, p)
+	case "github":
+		return cicd.TriggerGitHubPreview(tokens, t.RepoOwner, t.Repo, ref, t.RepoPath, p)
+	default:
+		return fmt.Errorf("unsupported cicd type %q", t.CICDType)
+	}
+}
+
+// Rollback starts the rollback pipeline of t, redeploying version to env;
+// rollout is set when it aborts a canary or blue-green rollout. Like
+// Trigger it leaves every check to the caller.
+func Rollback(t *Target, env, version string, rollout cicd.RolloutParams) error {
+	if t.DeployType == model.DeployTypeKubernetes {
+		return rollbackGitOps(t, env, version)
+	}
+	log.Printf("⏪ Triggering %s rollback of %s to %s via %s", env, t.ServiceName, version, t.CICDType)
+
+	switch t.CICDType {
+	case "jenkins":
+		return cicd.TriggerJenkinsRollback(t.ServiceName, env, version, t.RepoPath, rollout)
+	case "github":
+		return cicd.TriggerGitHubRollback(tokens, t.RepoOwner, t.Repo, env, version, t.RepoPath, rollout)
+	default:
+		return fmt.Errorf("unsupported cicd type %q", t.CICDType)
+	}
+}
+
+/* ===================== GITOPS ===================== */
+
+// deployGitOps pins the image built from branch's head commit, tagged with
+// its short SHA by the service's build pipeline.
+func deployGitOps(t *Target, env, branch string, rollout cicd.RolloutParams) error {
+	if rollout.Strategy != "" {
+		return fmt.Errorf("%s rollouts are not supported for kubernetes services", rollout.Strategy)
+	}
+
+	sha, err := git.BranchSHA(tokens, t.RepoOwner, t.Repo, branch)
+	if err != nil {
+		return err
+	}
+	version := git.ShortSHA(sha)
+
+	log.Printf("☸️ Deploying %s of %s (%s) to %s via GitOps", version, t.ServiceName, branch, env)
+	err = gitops.Apply(gitops.Service{Name: t.ServiceName, OwnerTeam: t.OwnerTeam}, env, version,
+		fmt.Sprintf("Deploy %s %s to %s", t.ServiceName, version, env))
+	if err != nil {
+		return err
+	}
+
+	report(t, env, version, "deploy")
+	return nil
+}
+
+// rollbackGitOps pins a version deployed before; versions are the commits
+// images were built from.
+func rollbackGitOps(t *Target, env, version string) error {
+	log.Printf("⏪ Rolling back %s/%s to %s via GitOps", t.ServiceName, env, version)
+	err := gitops.Apply(gitops.Service{Name: t.ServiceName, OwnerTeam: t.OwnerTeam}, env, version,
+		fmt.Sprintf("Roll back %s in %s to %s", t.ServiceName, env, version))
+	if err != nil {
+		return err
+	}
+
+	report(t, env, version, "rollback")
+	return nil
+}
+
+// report records the commit once pushed; the controller applies it from
+// there. A failed report is logged rather than returned since the deploy
+// itself went through.
+func report(t *Target, env, version, action string) {
+	err := reporter(context.Background(), model.ArtifactEvent{
+		ServiceName:  t.ServiceName,
+		Environment:  env,
+		Version:      version,
+		ArtifactType: "docker",
+		CommitSHA:    version,
+		Pipeline:     "gitops",
+		Action:       action,
+		Status:       "success",
+	})
+	if err != nil {
+		log.Printf("⚠️ Record %s of %s/%s %s: %v", action, t.ServiceName, env, version, err)
+	}
+}
@@ -0,0 +1,392 @@
+// Package freeze keeps release freezes (quarter-end, holidays) out of the
+// deploy path. Deploys, rollbacks and approvals call Check; a service can be
+// let through one window by an approved exemption, and an emergency
+// rollback may override a freeze as long as it is recorded.
+package freeze
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+	_ "time/tzdata" // window time zones must resolve in minimal images
+
+	"src/src/internal/db"
+	"src/src/internal/model"
+)
+
+var (
+	ErrWindowNotFound    = errors.New("freeze window not found")
+	ErrExemptionNotFound = errors.New("exemption not found or already decided")
+	ErrSelfApproval      = errors.New("an exemption must be approved by someone other than its requester")
+)
+
+// MaxDuration caps one occurrence of a recurring window.
+const MaxDuration = 31 * 24 * time.Hour
+
+// FrozenError is returned by Check while a window blocks the change.
+type FrozenError struct {
+	Window model.FreezeWindow
+	Until  time.Time
+}
+
+func (e *FrozenError) Error() string {
+	msg := fmt.Sprintf("changes are frozen by %q until %s", e.Window.Name, e.Until.UTC().Format(time.RFC3339))
+	if e.Window.Reason != "" {
+		msg += ": " + e.Window.Reason
+	}
+	return msg
+}
+
+// Occurrence is one period during which a window is active.
+type Occurrence struct {
+	WindowID    int64     `json:"windowId"`
+	Name        string    `json:"name"`
+	Environment string    `json:"environment,omitempty"`
+	OwnerTeam   string    `json:"ownerTeam,omitempty"`
+	Reason      string    `json:"reason"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+}
+
+// now is replaced in tests.
+var now = func() time.Time { return time.Now().UTC().Truncate(time.Second) }
+
+/* ===================== EVALUATION ===================== */
+
+// Occurrences returns the periods of w that overlap [from, to).
+func Occurrences(w model.FreezeWindow, from, to time.Time) ([]Occurrence, error) {
+	if w.Schedule == "" {
+		if w.StartsAt == nil || w.EndsAt == nil || !w.StartsAt.Before(to) || !w.EndsAt.After(from) {
+			return nil, nil
+		}
+		return []Occurrence{occurrence(w, *w.StartsAt, *w.EndsAt)}, nil
+	}
+
+	sched, err := ParseSchedule(w.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	d := time.Duration(w.DurationMinutes) * time.Minute
+
+	// An occurrence overlaps when it starts after from-d and before to
+	var out []Occurrence
+	for _, start := range sched.Starts(from.Add(-d+1), to, loc) {
+		if w.StartsAt != nil && start.Before(*w.StartsAt) {
+			continue
+		}
+		if w.EndsAt != nil && !start.Before(*w.EndsAt) {
+			continue
+		}
+		out = append(out, occurrence(w, start, start.Add(d)))
+	}
+	return out, nil
+}
+
+// Active reports whether w covers at, and until when.
+func Active(w model.FreezeWindow, at time.Time) (until time.Time, ok bool, err error) {
+	occs, err := Occurrences(w, at, at.Add(1))
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	for _, o := range occs {
+		if o.End.After(until) {
+			until = o.End
+		}
+	}
+	return until, len(occs) > 0, nil
+}
+
+func occurrence(w model.FreezeWindow, start, end time.Time) Occurrence {
+	return Occurrence{
+		WindowID:    w.ID,
+		Name:        w.Name,
+		Environment: w.Environment,
+		OwnerTeam:   w.OwnerTeam,
+		Reason:      w.Reason,
+		Start:       start.UTC(),
+		End:         end.UTC(),
+	}
+}
+
+/* ===================== CHECK ===================== */
+
+// Check returns *FrozenError if a window covering the service's team or
+// env is active now and the service holds no approved exemption for it.
+func Check(ctx context.Context, serviceName, env string) error {
+	var team sql.NullString
+	err := db.DB.QueryRowContext(ctx, `
+		SELECT owner_team FROM services WHERE service_name = ?`,
+		serviceName,
+	).Scan(&team)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	windows, err := listWindows(ctx, `
+		WHERE environment IN ('', ?) AND owner_team IN ('', ?)`,
+		env, team.String,
+	)
+	if err != nil {
+		return err
+	}
+
+	at := now()
+	for _, w := range windows {
+		until, active, err := Active(w, at)
+		if err != nil {
+			return fmt.Errorf("freeze window %d: %w", w.ID, err)
+		}
+		if !active {
+			continue
+		}
+
+		var exempt bool
+		if err := db.DB.QueryRowContext(ctx, `
+			SELECT EXISTS (
+			  SELECT 1 FROM freeze_exemptions
+			  WHERE window_id = ? AND service_name = ? AND environment = ?
+			    AND status = 'approved' AND expires_at > ?
+			)`,
+			w.ID, serviceName, env, at,
+		).Scan(&exempt); err != nil {
+			return err
+		}
+		if exempt {
+			log.Printf("🧊 %s/%s is exempt from freeze %q", serviceName, env, w.Name)
+			continue
+		}
+
+		return &FrozenError{Window: w, Until: until}
+	}
+	return nil
+}
+
+// RecordOverride audits a change let through an active freeze, e.g. an
+// emergency rollback.
+func RecordOverride(ctx context.Context, frozen *FrozenError, serviceName, env, operation, actor, reason string) error {
+	_, err := db.DB.ExecContext(ctx, `
+		INSERT INTO freeze_overrides
+		(window_id, service_name, environment, operation, actor, reason)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		frozen.Window.ID, serviceName, env, operation, actor, reason,
+	)
+	if err == nil {
+		log.Printf("🚨 Freeze %q overridden for %s of %s/%s by %s: %s",
+			frozen.Window.Name, operation, serviceName, env, actor, reason)
+	}
+	return err
+}
+
+/* ===================== WINDOWS ===================== */
+
+func CreateWindow(ctx context.Context, w *model.FreezeWindow) error {
+	res, err := db.DB.ExecContext(ctx, `
+		INSERT INTO freeze_windows
+		(name, environment, owner_team, schedule, duration_minutes, timezone, starts_at, ends_at, reason, created_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		w.Name, w.Environment, w.OwnerTeam, w.Schedule, w.DurationMinutes, w.Timezone,
+		w.StartsAt, w.EndsAt, w.Reason, w.CreatedBy,
+	)
+	if err != nil {
+		return err
+	}
+
+	if w.ID, err = res.LastInsertId(); err != nil {
+		return err
+	}
+	w.CreatedAt = now()
+
+	log.Printf("🧊 Freeze window %d %q created by %s", w.ID, w.Name, w.CreatedBy)
+	return nil
+}
+
+func ListWindows(ctx context.Context) ([]model.FreezeWindow, error) {
+	return listWindows(ctx, "")
+}
+
+func GetWindow(ctx context.Context, id int64) (*model.FreezeWindow, error) {
+	windows, err := listWindows(ctx, `WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(windows) == 0 {
+		return nil, ErrWindowNotFound
+	}
+	return &windows[0], nil
+}
+
+func DeleteWindow(ctx context.Context, id int64, by string) error {
+	res, err := db.DB.ExecContext(ctx, `DELETE FROM freeze_windows WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrWindowNotFound
+	}
+
+	log.Printf("🧊 Freeze window %d deleted by %s", id, by)
+	return nil
+}
+
+// Calendar lists occurrences in [from, to), for one environment when env is
+// set, ordered by start.
+func Calendar(ctx context.Context, env string, from, to time.Time) ([]Occurrence, error) {
+	where, args := "", []interface{}{}
+	if env != "" {
+		where, args = `WHERE environment IN ('', ?)`, append(args, env)
+	}
+	windows, err := listWindows(ctx, where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []Occurrence{}
+	for _, w := range windows {
+		occs, err := Occurrences(w, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("freeze window %d: %w", w.ID, err)
+		}
+		out = append(out, occs...)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out, nil
+}
+
+func listWindows(ctx context.Context, where string, args ...interface{}) ([]model.FreezeWindow, error) {
+	rows, err := db.DB.QueryContext(ctx, `
+		SELECT id, name, environment, owner_team, schedule, duration_minutes, timezone,
+		       starts_at, ends_at, reason, created_by, created_at
+		FROM freeze_windows `+where+`
+		ORDER BY id`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.FreezeWindow{}
+	for rows.Next() {
+		var w model.FreezeWindow
+		if err := rows.Scan(
+			&w.ID, &w.Name, &w.Environment, &w.OwnerTeam, &w.Schedule, &w.DurationMinutes, &w.Timezone,
+			&w.StartsAt, &w.EndsAt, &w.Reason, &w.CreatedBy, &w.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+/* ===================== EXEMPTIONS ===================== */
+
+func RequestExemption(ctx context.Context, e *model.FreezeExemption) error {
+	res, err := db.DB.ExecContext(ctx, `
+		INSERT INTO freeze_exemptions
+		(window_id, service_name, environment, reason, requested_by, status, expires_at)
+		VALUES (?, ?, ?, ?, ?, 'pending', ?)`,
+		e.WindowID, e.ServiceName, e.Environment, e.Reason, e.RequestedBy, e.ExpiresAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if e.ID, err = res.LastInsertId(); err != nil {
+		return err
+	}
+	e.Status = "pending"
+	e.CreatedAt = now()
+
+	log.Printf("🧊 Exemption %d from freeze %d requested for %s/%s by %s",
+		e.ID, e.WindowID, e.ServiceName, e.Environment, e.RequestedBy)
+	return nil
+}
+
+// ListExemptions returns exemptions, newest first, filtered by status when
+// set.
+func ListExemptions(ctx context.Context, status string) ([]model.FreezeExemption, error) {
+	query := `
+		SELECT id, window_id, service_name, environment, reason, requested_by,
+		       status, decided_by, expires_at, created_at, decided_at
+		FROM freeze_exemptions`
+	args := []interface{}{}
+	if status != "" {
+		query += ` WHERE status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY created_at DESC`
+
+	rows, err := db.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.FreezeExemption{}
+	for rows.Next() {
+		var e model.FreezeExemption
+		if err := rows.Scan(
+			&e.ID, &e.WindowID, &e.ServiceName, &e.Environment, &e.Reason, &e.RequestedBy,
+			&e.Status, &e.DecidedBy, &e.ExpiresAt, &e.CreatedAt, &e.DecidedAt,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// DecideExemption approves or rejects a pending exemption. Requesters may
+// withdraw (reject) their own but not approve it.
+func DecideExemption(ctx context.Context, id int64, approve bool, by string) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var requestedBy string
+	err = tx.QueryRowContext(ctx, `
+		SELECT requested_by FROM freeze_exemptions
+		WHERE id = ? AND status = 'pending'
+		FOR UPDATE`,
+		id,
+	).Scan(&requestedBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrExemptionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if approve && requestedBy == by {
+		return ErrSelfApproval
+	}
+
+	status := "rejected"
+	if approve {
+		status = "approved"
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE freeze_exemptions
+		SET status = ?, decided_by = ?, decided_at = NOW()
+		WHERE id = ?`,
+		status, by, id,
+	); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	log.Printf("🧊 Exemption %d %s by %s", id, status, by)
+	return nil
+}
@@ -0,0 +1,78 @@
+package freeze
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"src/src/internal/db"
+)
+
+var windowColumns = []string{
+	"id", "name", "environment", "owner_team", "schedule", "duration_minutes", "timezone",
+	"starts_at", "ends_at", "reason", "created_by", "created_at",
+}
+
+// expectFridayFreeze is a two-hour prod freeze every Friday at 18:00 UTC.
+func expectFridayFreeze(t *testing.T, clock string) sqlmock.Sqlmock {
+	t.Helper()
+
+	conn, m, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	db.DB = conn
+
+	prev := now
+	now = func() time.Time { return at(clock) }
+	t.Cleanup(func() { now = prev })
+
+	m.ExpectQuery("SELECT owner_team FROM services").WithArgs("orders").
+		WillReturnRows(sqlmock.NewRows([]string{"owner_team"}).AddRow("payments"))
+	m.ExpectQuery("FROM freeze_windows").WithArgs("prod", "payments").WillReturnRows(sqlmock.NewRows(windowColumns).
+		AddRow(3, "friday", "prod", "", "0 18 * * FRI", 120, "UTC", nil, nil, "weekend", "alice", at("2024-04-01T00:00:00Z")))
+	return m
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		clock  string
+		active bool // the window is open, so exemptions are looked up
+		exempt bool
+		until  string // empty when not frozen
+	}{
+		{name: "inside the window", clock: "2024-05-03T19:00:00Z", active: true, until: "2024-05-03T20:00:00Z"},
+		{name: "exempt", clock: "2024-05-03T19:00:00Z", active: true, exempt: true},
+		{name: "after the window", clock: "2024-05-03T20:00:00Z"},
+		{name: "other weekday", clock: "2024-05-02T19:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := expectFridayFreeze(t, tt.clock)
+			if tt.active {
+				m.ExpectQuery("FROM freeze_exemptions").WithArgs(int64(3), "orders", "prod", at(tt.clock)).
+					WillReturnRows(sqlmock.NewRows([]string{"exempt"}).AddRow(tt.exempt))
+			}
+
+			err := Check(context.Background(), "orders", "prod")
+
+			var frozen *FrozenError
+			switch {
+			case tt.until == "" && err != nil:
+				t.Errorf("got %v, want no freeze", err)
+			case tt.until != "" && !errors.As(err, &frozen):
+				t.Errorf("got %v, want *FrozenError", err)
+			case tt.until != "" && !frozen.Until.Equal(at(tt.until)):
+				t.Errorf("frozen until %s, want %s", frozen.Until, tt.until)
+			}
+			if err := m.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
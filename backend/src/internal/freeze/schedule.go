@@ -0,0 +1,343 @@
+package freeze
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed recurrence: the minutes at which a recurring freeze
+// starts. It understands 5-field cron and the RRULE subset below.
+//
+// RRULE: FREQ=DAILY|WEEKLY|MONTHLY|YEARLY with BYMONTH, BYMONTHDAY
+// (negative counts from the month end), BYDAY (MO..SU; MONTHLY and YEARLY
+// accept an ordinal such as 1MO or -1FR), BYHOUR and BYMINUTE. BYHOUR and
+// BYMINUTE default to 0. There is no DTSTART, so INTERVAL, COUNT and UNTIL
+// are not supported; bound the window with startsAt/endsAt instead.
+type Schedule struct {
+	minutes [60]bool
+	hours   [24]bool
+	day     func(time.Time) bool
+}
+
+// ParseSchedule accepts a cron expression or an RRULE, with or without the
+// "RRULE:" prefix.
+func ParseSchedule(expr string) (*Schedule, error) {
+	expr = strings.ToUpper(strings.TrimSpace(expr))
+	// Cron fields never contain '='
+	if strings.Contains(expr, "=") {
+		return parseRRule(strings.TrimPrefix(expr, "RRULE:"))
+	}
+	return parseCron(expr)
+}
+
+// Starts returns the occurrence start times in [from, to), in loc.
+func (s *Schedule) Starts(from, to time.Time, loc *time.Location) []time.Time {
+	var out []time.Time
+
+	f := from.In(loc)
+	day := time.Date(f.Year(), f.Month(), f.Day(), 0, 0, 0, 0, loc)
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		if !s.day(day) {
+			continue
+		}
+		for h := 0; h < 24; h++ {
+			if !s.hours[h] {
+				continue
+			}
+			for m := 0; m < 60; m++ {
+				if !s.minutes[m] {
+					continue
+				}
+				t := time.Date(day.Year(), day.Month(), day.Day(), h, m, 0, 0, loc)
+				if !t.Before(from) && t.Before(to) {
+					out = append(out, t)
+				}
+			}
+		}
+	}
+	return out
+}
+
+/* ===================== CRON ===================== */
+
+var (
+	monthNames = map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}
+	cronDayNames = map[string]int{"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6}
+)
+
+// parseCron reads "minute hour day-of-month month day-of-week". As in
+// Vixie cron, when both day fields are restricted a day matching either
+// one qualifies.
+func parseCron(expr string) (*Schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron schedule needs 5 fields, got %d", len(fields))
+	}
+
+	s := &Schedule{}
+	minutes, _, err := cronField(fields[0], 0, 59, nil)
+	if err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	hours, _, err := cronField(fields[1], 0, 23, nil)
+	if err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	doms, domAny, err := cronField(fields[2], 1, 31, nil)
+	if err != nil {
+		return nil, fmt.Errorf("day of month: %w", err)
+	}
+	months, _, err := cronField(fields[3], 1, 12, monthNames)
+	if err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	dows, dowAny, err := cronField(fields[4], 0, 7, cronDayNames)
+	if err != nil {
+		return nil, fmt.Errorf("day of week: %w", err)
+	}
+	dows[0] = dows[0] || dows[7] // 7 is also Sunday
+
+	copy(s.minutes[:], minutes)
+	copy(s.hours[:], hours)
+	s.day = func(d time.Time) bool {
+		if !months[int(d.Month())] {
+			return false
+		}
+		domOK, dowOK := doms[d.Day()], dows[int(d.Weekday())]
+		switch {
+		case domAny && dowAny:
+			return true
+		case domAny:
+			return dowOK
+		case dowAny:
+			return domOK
+		default:
+			return domOK || dowOK
+		}
+	}
+	return s, nil
+}
+
+// cronField expands one field into a set indexed by value. star reports a
+// bare "*", which matters for the day-of-month/day-of-week rule.
+func cronField(field string, lo, hi int, names map[string]int) (set []bool, star bool, err error) {
+	set = make([]bool, hi+1)
+	star = field == "*"
+
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			if step, err = strconv.Atoi(stepStr); err != nil || step < 1 {
+				return nil, false, fmt.Errorf("invalid step %q", stepStr)
+			}
+		}
+
+		start, end := lo, hi
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			if start, err = cronValue(a, lo, hi, names); err != nil {
+				return nil, false, err
+			}
+			end = start
+			if isRange {
+				if end, err = cronValue(b, lo, hi, names); err != nil {
+					return nil, false, err
+				}
+			} else if hasStep {
+				end = hi
+			}
+			if end < start {
+				return nil, false, fmt.Errorf("range %q runs backwards", rng)
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			set[v] = true
+		}
+	}
+	return set, star, nil
+}
+
+func cronValue(s string, lo, hi int, names map[string]int) (int, error) {
+	if v, ok := names[s]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < lo || v > hi {
+		return 0, fmt.Errorf("%q is not between %d and %d", s, lo, hi)
+	}
+	return v, nil
+}
+
+/* ===================== RRULE ===================== */
+
+var rruleDays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+type byDay struct {
+	weekday time.Weekday
+	nth     int // 0 = every; 1 = first; -1 = last
+}
+
+func parseRRule(expr string) (*Schedule, error) {
+	parts := map[string]string{}
+	for _, kv := range strings.Split(expr, ";") {
+		if kv == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, fmt.Errorf("RRULE part %q is not KEY=VALUE", kv)
+		}
+		parts[k] = v
+	}
+
+	var (
+		s         = &Schedule{}
+		months    []bool
+		monthDays []int
+		days      []byDay
+		err       error
+	)
+
+	for k, v := range parts {
+		switch k {
+		case "FREQ":
+		case "BYMONTH":
+			if months, err = rruleSet(v, 1, 12); err != nil {
+				return nil, fmt.Errorf("BYMONTH: %w", err)
+			}
+		case "BYMONTHDAY":
+			for _, d := range strings.Split(v, ",") {
+				n, err := strconv.Atoi(d)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("BYMONTHDAY: %q is not between -31 and 31", d)
+				}
+				monthDays = append(monthDays, n)
+			}
+		case "BYDAY":
+			for _, d := range strings.Split(v, ",") {
+				if len(d) < 2 {
+					return nil, fmt.Errorf("BYDAY: invalid day %q", d)
+				}
+				wd, ok := rruleDays[d[len(d)-2:]]
+				if !ok {
+					return nil, fmt.Errorf("BYDAY: invalid day %q", d)
+				}
+				bd := byDay{weekday: wd}
+				if ord := d[:len(d)-2]; ord != "" {
+					if bd.nth, err = strconv.Atoi(ord); err != nil || bd.nth == 0 || bd.nth < -5 || bd.nth > 5 {
+						return nil, fmt.Errorf("BYDAY: invalid ordinal in %q", d)
+					}
+				}
+				days = append(days, bd)
+			}
+		case "BYHOUR":
+			hours, err := rruleSet(v, 0, 23)
+			if err != nil {
+				return nil, fmt.Errorf("BYHOUR: %w", err)
+			}
+			copy(s.hours[:], hours)
+		case "BYMINUTE":
+			minutes, err := rruleSet(v, 0, 59)
+			if err != nil {
+				return nil, fmt.Errorf("BYMINUTE: %w", err)
+			}
+			copy(s.minutes[:], minutes)
+		default:
+			return nil, fmt.Errorf("RRULE %s is not supported", k)
+		}
+	}
+	if _, ok := parts["BYHOUR"]; !ok {
+		s.hours[0] = true
+	}
+	if _, ok := parts["BYMINUTE"]; !ok {
+		s.minutes[0] = true
+	}
+
+	// Without DTSTART there is nothing to inherit the day from
+	switch freq := parts["FREQ"]; freq {
+	case "DAILY":
+	case "WEEKLY":
+		if len(days) == 0 {
+			return nil, fmt.Errorf("FREQ=WEEKLY needs BYDAY")
+		}
+	case "MONTHLY", "YEARLY":
+		if len(days) == 0 && len(monthDays) == 0 {
+			return nil, fmt.Errorf("FREQ=%s needs BYMONTHDAY or BYDAY", freq)
+		}
+		if freq == "YEARLY" && months == nil {
+			return nil, fmt.Errorf("FREQ=YEARLY needs BYMONTH")
+		}
+	case "":
+		return nil, fmt.Errorf("RRULE needs FREQ")
+	default:
+		return nil, fmt.Errorf("FREQ=%s is not supported", freq)
+	}
+	if parts["FREQ"] != "MONTHLY" && parts["FREQ"] != "YEARLY" {
+		for _, d := range days {
+			if d.nth != 0 {
+				return nil, fmt.Errorf("BYDAY ordinals need FREQ=MONTHLY or YEARLY")
+			}
+		}
+	}
+
+	// The BY* filters intersect, so BYDAY=FR;BYMONTHDAY=13 is Friday the 13th
+	s.day = func(d time.Time) bool {
+		if months != nil && !months[int(d.Month())] {
+			return false
+		}
+		last := time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, d.Location()).Day()
+		if len(monthDays) > 0 && !matchesMonthDay(monthDays, d.Day(), last) {
+			return false
+		}
+		return len(days) == 0 || matchesByDay(days, d, last)
+	}
+	return s, nil
+}
+
+func rruleSet(v string, lo, hi int) ([]bool, error) {
+	set := make([]bool, hi+1)
+	for _, p := range strings.Split(v, ",") {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < lo || n > hi {
+			return nil, fmt.Errorf("%q is not between %d and %d", p, lo, hi)
+		}
+		set[n] = true
+	}
+	return set, nil
+}
+
+func matchesMonthDay(monthDays []int, day, last int) bool {
+	for _, n := range monthDays {
+		if n == day || (n < 0 && last+n+1 == day) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesByDay(days []byDay, d time.Time, last int) bool {
+	for _, bd := range days {
+		if bd.weekday != d.Weekday() {
+			continue
+		}
+		switch {
+		case bd.nth == 0:
+			return true
+		case bd.nth > 0 && (d.Day()-1)/7+1 == bd.nth:
+			return true
+		case bd.nth < 0 && (last-d.Day())/7+1 == -bd.nth:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package freeze
+
+import (
+	"testing"
+	"time"
+
+	"src/src/internal/model"
+)
+
+func at(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParseSchedule(t *testing.T) {
+	valid := []string{
+		"0 18 * * FRI",
+		"*/15 9-17 * * 1-5",
+		"0 0 1 JAN,JUL *",
+		"RRULE:FREQ=MONTHLY;BYMONTHDAY=-1;BYHOUR=18",
+		"FREQ=YEARLY;BYMONTH=12;BYMONTHDAY=20",
+		"freq=monthly;byday=-1fr",
+	}
+	for _, expr := range valid {
+		if _, err := ParseSchedule(expr); err != nil {
+			t.Errorf("ParseSchedule(%q) = %v", expr, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"0 18 * *",
+		"60 * * * *",
+		"0 18 * * 5-1",
+		"FREQ=HOURLY",
+		"FREQ=WEEKLY",
+		"FREQ=YEARLY;BYMONTHDAY=1",
+		"FREQ=DAILY;INTERVAL=2",
+		"FREQ=WEEKLY;BYDAY=1MO",
+	}
+	for _, expr := range invalid {
+		if _, err := ParseSchedule(expr); err == nil {
+			t.Errorf("ParseSchedule(%q) accepted", expr)
+		}
+	}
+}
+
+func TestActive(t *testing.T) {
+	weekend := model.FreezeWindow{Schedule: "0 18 * * FRI", DurationMinutes: 60 * 62, Timezone: "UTC"}
+	monthEnd := model.FreezeWindow{Schedule: "FREQ=MONTHLY;BYMONTHDAY=-1;BYHOUR=18", DurationMinutes: 24 * 60, Timezone: "America/New_York"}
+	lastFriday := model.FreezeWindow{Schedule: "FREQ=MONTHLY;BYDAY=-1FR", DurationMinutes: 24 * 60, Timezone: "UTC"}
+	bounded := weekend
+	bounded.StartsAt = ptr(at("2024-06-01T00:00:00Z"))
+
+	tests := []struct {
+		name  string
+		w     model.FreezeWindow
+		at    string
+		want  bool
+		until string
+	}{
+		{"before friday evening", weekend, "2024-05-03T17:59:00Z", false, ""},
+		{"friday evening", weekend, "2024-05-03T18:00:00Z", true, "2024-05-06T08:00:00Z"},
+		{"sunday", weekend, "2024-05-05T12:00:00Z", true, "2024-05-06T08:00:00Z"},
+		{"monday after", weekend, "2024-05-06T08:00:00Z", false, ""},
+		{"before bounds", bounded, "2024-05-04T12:00:00Z", false, ""},
+		{"within bounds", bounded, "2024-06-08T12:00:00Z", true, "2024-06-10T08:00:00Z"},
+		// 30 April 18:00 EDT is 22:00 UTC
+		{"month end local", monthEnd, "2024-04-30T22:30:00Z", true, "2024-05-01T22:00:00Z"},
+		{"month end before local start", monthEnd, "2024-04-30T21:00:00Z", false, ""},
+		{"february leap day", monthEnd, "2024-02-29T23:30:00Z", true, "2024-03-01T23:00:00Z"},
+		{"last friday", lastFriday, "2024-05-31T10:00:00Z", true, "2024-06-01T00:00:00Z"},
+		{"not last friday", lastFriday, "2024-05-24T10:00:00Z", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, ok, err := Active(tt.w, at(tt.at))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.want {
+				t.Fatalf("active = %v, want %v", ok, tt.want)
+			}
+			if ok && !until.Equal(at(tt.until)) {
+				t.Errorf("until = %s, want %s", until.Format(time.RFC3339), tt.until)
+			}
+		})
+	}
+}
+
+func TestOccurrencesOneOff(t *testing.T) {
+	w := model.FreezeWindow{
+		Name:     "holidays",
+		StartsAt: ptr(at("2024-12-20T00:00:00Z")),
+		EndsAt:   ptr(at("2025-01-02T00:00:00Z")),
+	}
+
+	occs, err := Occurrences(w, at("2024-12-01T00:00:00Z"), at("2024-12-21T00:00:00Z"))
+	if err != nil || len(occs) != 1 || occs[0].Name != "holidays" {
+		t.Fatalf("Occurrences = %+v, %v", occs, err)
+	}
+
+	occs, _ = Occurrences(w, at("2025-01-02T00:00:00Z"), at("2025-02-01T00:00:00Z"))
+	if len(occs) != 0 {
+		t.Errorf("window ended, got %+v", occs)
+	}
+}
+
+func ptr(t time.Time) *time.Time { return &t }
@@ -0,0 +1,82 @@
+package git
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"log"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+// EnsureEnvironment creates the GitHub deployment environment if missing.
+func EnsureEnvironment(ts TokenSource, owner, repo, env string) error {
+	c, err := Client(ts, owner)
+	if err != nil {
+		return err
+	}
+
+	return c.Put(context.Background(),
+		fmt.Sprintf("/repos/%s/%s/environments/%s", owner, repo, env),
+		map[string]interface{}{},
+		nil,
+	)
+}
+
+// SetEnvironmentSecret writes a GitHub Actions environment secret. The value
+// is encrypted client-side with a libsodium sealed box for the environment's
+// public key, so it never leaves the platform in plain text.
+func SetEnvironmentSecret(ts TokenSource, owner, repo, env, name, value string) error {
+	c, err := Client(ts, owner)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("🔑 Writing Actions secret %s to %s/%s (%s)", name, owner, repo, env)
+
+	base := fmt.Sprintf("/repos/%s/%s/environments/%s/secrets", owner, repo, env)
+
+	var key struct {
+		KeyID string `json:"key_id"`
+		Key   string `json:"key"`
+	}
+	if err := c.Get(context.Background(), base+"/public-key", &key); err != nil {
+		return err
+	}
+
+	encrypted, err := sealSecret(key.Key, value)
+	if err != nil {
+		return err
+	}
+
+	return c.Put(context.Background(), base+"/"+name,
+		map[string]string{
+			"encrypted_value": encrypted,
+			"key_id":          key.KeyID,
+		},
+		nil,
+	)
+}
+
+// sealSecret encrypts value for a base64 Curve25519 public key and returns
+// the base64 sealed box GitHub expects.
+func sealSecret(publicKey, value string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("decode repository public key: %w", err)
+	}
+	if len(raw) != 32 {
+		return "", fmt.Errorf("repository public key has %d bytes, want 32", len(raw))
+	}
+
+	var pk [32]byte
+	copy(pk[:], raw)
+
+	sealed, err := box.SealAnonymous(nil, []byte(value), &pk, rand.Reader)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
@@ -1,71 +1,52 @@
 package git
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/http"
+
+	"src/src/internal/github"
 )
 
-func CreateBranch(token, owner, repo, newBranch, sourceBranch string) error {
-	// 1️⃣ Get source branch SHA
-	sha, err := getBranchSHA(token, owner, repo, sourceBranch)
+func CreateBranch(ts TokenSource, owner, repo, newBranch, sourceBranch string) error {
+	c, err := Client(ts, owner)
 	if err != nil {
 		return err
 	}
 
-	// 2️⃣ Create new branch ref
-	payload := map[string]string{
-		"ref": fmt.Sprintf("refs/heads/%s", newBranch),
-		"sha": sha,
-	}
-
-	body, _ := json.Marshal(payload)
-
-	req, _ := http.NewRequest(
-		"POST",
-		fmt.Sprintf("https://api.github.com/repos/%s/%s/git/refs", owner, repo),
-		bytes.NewBuffer(body),
-	)
-	req.Header.Set("Authorization", "token "+token)
-	req.Header.Set("Accept", "application/vnd.github+json")
-
-	resp, err := http.DefaultClient.Do(req)
+	// 1️⃣ Get source branch SHA
+	sha, err := BranchSHA(ts, owner, repo, sourceBranch)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode == 422 {
+	// 2️⃣ Create new branch ref
+	err = c.Post(context.Background(),
+		fmt.Sprintf("/repos/%s/%s/git/refs", owner, repo),
+		map[string]string{
+			"ref": fmt.Sprintf("refs/heads/%s", newBranch),
+			"sha": sha,
+		},
+		nil,
+	)
+
+	if github.HasStatus(err, 422) {
 		// branch already exists → safe
 		return nil
 	}
 
-	if resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to create branch %s", newBranch)
+	if err != nil {
+		return fmt.Errorf("failed to create branch %s: %w", newBranch, err)
 	}
 
 	return nil
 }
 
-
-func getBranchSHA(token, owner, repo, branch string) (string, error) {
-	req, _ := http.NewRequest(
-		"GET",
-		fmt.Sprintf("https://api.github.com/repos/%s/%s/git/ref/heads/%s", owner, repo, branch),
-		nil,
-	)
-	req.Header.Set("Authorization", "token "+token)
-
-	resp, err := http.DefaultClient.Do(req)
+// BranchSHA returns the commit at the head of branch.
+func BranchSHA(ts TokenSource, owner, repo, branch string) (string, error) {
+	c, err := Client(ts, owner)
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 300 {
-		return "", fmt.Errorf("branch %s not found", branch)
-	}
 
 	var res struct {
 		Object struct {
@@ -73,8 +54,43 @@ func getBranchSHA(token, owner, repo, branch string) (string, error) {
 		} `json:"object"`
 	}
 
-	json.NewDecoder(resp.Body).Decode(&res)
+	err = c.Get(context.Background(),
+		fmt.Sprintf("/repos/%s/%s/git/ref/heads/%s", owner, repo, branch),
+		&res,
+	)
+	if err != nil {
+		return "", fmt.Errorf("branch %s not found: %w", branch, err)
+	}
+	if res.Object.SHA == "" {
+		return "", fmt.Errorf("branch %s: github returned no commit", branch)
+	}
+
 	return res.Object.SHA, nil
 }
 
+// ShortSHA abbreviates a commit to the 7 characters image tags and the
+// dashboard use; shorter input is returned unchanged.
+func ShortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+func DeleteBranch(ts TokenSource, owner, repo, branch string) error {
+	c, err := Client(ts, owner)
+	if err != nil {
+		return err
+	}
+
+	err = c.Delete(context.Background(),
+		fmt.Sprintf("/repos/%s/%s/git/refs/heads/%s", owner, repo, branch),
+	)
 
+	// 422 → branch already gone
+	if err != nil && !github.HasStatus(err, 422) {
+		return fmt.Errorf("failed to delete branch %s: %w", branch, err)
+	}
+
+	return nil
+}
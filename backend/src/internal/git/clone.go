@@ -0,0 +1,150 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// CloneRepo clones a single branch of an existing repository into localPath.
+func CloneRepo(ts TokenSource, owner, repoName, branch, localPath string) (*git.Repository, error) {
+	auth, err := basicAuth(ts, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("📥 Cloning %s/%s@%s", owner, repoName, branch)
+
+	repo, err := git.PlainClone(localPath, false, &git.CloneOptions{
+		URL:           ts.Connection().CloneURL(owner, repoName),
+		ReferenceName: plumbing.NewBranchReferenceName(branch),
+		SingleBranch:  true,
+		Depth:         1,
+		Auth:          auth,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("git clone failed: %w", err)
+	}
+
+	return repo, nil
+}
+
+// PushNewBranch commits every change in the worktree onto a new branch and
+// pushes it to origin, a repository of owner.
+func PushNewBranch(ts TokenSource, owner string, repo *git.Repository, branch, message string) error {
+	auth, err := basicAuth(ts, owner)
+	if err != nil {
+		return err
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	// 1️⃣ Create & checkout branch from the cloned HEAD
+	err = worktree.Checkout(&git.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName(branch),
+		Create: true,
+		Keep:   true,
+	})
+	if err != nil {
+		return fmt.Errorf("create/checkout branch %s failed: %w", branch, err)
+	}
+
+	// 2️⃣ Stage & commit
+	if err := worktree.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		return err
+	}
+
+	_, err = worktree.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  "Platform Bot",
+			Email: "platform@company.com",
+			When:  time.Now(),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// 3️⃣ Push branch
+	err = repo.Push(&git.PushOptions{
+		RemoteName: "origin",
+		RefSpecs: []config.RefSpec{
+			config.RefSpec(
+				fmt.Sprintf("refs/heads/%s:refs/heads/%s", branch, branch),
+			),
+		},
+		Auth: auth,
+	})
+	if err != nil {
+		return fmt.Errorf("git push failed: %w", err)
+	}
+
+	return nil
+}
+
+// ErrNothingToCommit is returned by PushBranch when the worktree is clean.
+var ErrNothingToCommit = errors.New("nothing to commit")
+
+// PushBranch commits every change in the worktree onto the cloned branch
+// and pushes it to origin. A push rejected because the branch moved on
+// returns an error wrapping git.ErrForceNeeded; clone again and
+// retry.
+func PushBranch(ts TokenSource, owner string, repo *git.Repository, branch, message string) error {
+	auth, err := basicAuth(ts, owner)
+	if err != nil {
+		return err
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	// 1️⃣ Stage & commit
+	if err := worktree.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		return err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return err
+	}
+	if status.IsClean() {
+		return ErrNothingToCommit
+	}
+
+	_, err = worktree.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  "Platform Bot",
+			Email: "platform@company.com",
+			When:  time.Now(),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// 2️⃣ Push
+	err = repo.Push(&git.PushOptions{
+		RemoteName: "origin",
+		RefSpecs: []config.RefSpec{
+			config.RefSpec(
+				fmt.Sprintf("refs/heads/%s:refs/heads/%s", branch, branch),
+			),
+		},
+		Auth: auth,
+	})
+	if err != nil {
+		return fmt.Errorf("git push failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"src/src/internal/github"
+)
+
+type Deployment struct {
+	ID          int64     `json:"id"`
+	SHA         string    `json:"sha"`
+	Ref         string    `json:"ref"`
+	Environment string    `json:"environment"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ListRepoFiles returns every file path in the repository tree at ref.
+func ListRepoFiles(ts TokenSource, owner, repo, ref string) ([]string, error) {
+	c, err := Client(ts, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	var tree struct {
+		Tree []struct {
+			Path string `json:"path"`
+			Type string `json:"type"`
+		} `json:"tree"`
+		Truncated bool `json:"truncated"`
+	}
+
+	err = c.Get(context.Background(),
+		fmt.Sprintf("/repos/%s/%s/git/trees/%s?recursive=1", owner, repo, ref),
+		&tree,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, e := range tree.Tree {
+		if e.Type == "blob" {
+			files = append(files, e.Path)
+		}
+	}
+
+	return files, nil
+}
+
+// ListDeployments returns up to limit GitHub deployments, newest first.
+func ListDeployments(ts TokenSource, owner, repo string, limit int) ([]Deployment, error) {
+	c, err := Client(ts, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return github.List[Deployment](context.Background(), c,
+		fmt.Sprintf("/repos/%s/%s/deployments?per_page=100", owner, repo),
+		limit,
+	)
+}
+
+// GetDeploymentState returns the latest status of a deployment
+// (success, failure, in_progress, ...) or "" when it has none.
+func GetDeploymentState(ts TokenSource, owner, repo string, id int64) (string, error) {
+	c, err := Client(ts, owner)
+	if err != nil {
+		return "", err
+	}
+
+	type status struct {
+		State string `json:"state"`
+	}
+
+	statuses, err := github.List[status](context.Background(), c,
+		fmt.Sprintf("/repos/%s/%s/deployments/%d/statuses?per_page=1", owner, repo, id),
+		1,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if len(statuses) == 0 {
+		return "", nil
+	}
+	return statuses[0].State, nil
+}
@@ -1,117 +1,89 @@
 package git
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"log"
-	"net/http"
-	"time"
-	"io"
+
+	"src/src/internal/github"
 )
 
 type githubUser struct {
 	Login string `json:"login"`
 }
 
-var githubClient = &http.Client{
-	Timeout: 15 * time.Second,
+// RepoOptions describes the repository to create. An empty Org creates the
+// repository under the token owner's personal account.
+type RepoOptions struct {
+	Name        string
+	Org         string
+	Visibility  string // private | internal | public
+	Description string
+	Topics      []string
 }
 
+// CreateRepo creates the repository and returns its owner and URL.
+func CreateRepo(ts TokenSource, opts RepoOptions) (string, string, error) {
+	log.Println("📦 Creating GitHub repository:", opts.Name)
 
-func CreateRepo(token, repoName string) (string, error) {
-	log.Println("📦 Creating GitHub repository:", repoName)
+	owner := opts.Org
+	endpoint := "/orgs/" + opts.Org + "/repos"
 
-	owner, err := GetAuthenticatedUser(token)
-	if err != nil {
-		return "", err
+	if owner == "" {
+		user, _, err := ts.DefaultOwner()
+		if err != nil {
+			return "", "", err
+		}
+		owner = user
+		endpoint = "/user/repos"
 	}
 
-	exists, err := RepoExists(token, owner, repoName)
+	exists, err := RepoExists(ts, owner, opts.Name)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
-	repoURL := fmt.Sprintf("https://github.com/%s/%s", owner, repoName)
+	repoURL := ts.Connection().RepoURL(owner, opts.Name)
 
 	if exists {
 		log.Println("⚠️ Repo already exists:", repoURL)
-		return repoURL, nil
+		return owner, repoURL, nil
 	}
 
-	body, _ := json.Marshal(map[string]interface{}{
-		"name":    repoName,
-		"private": false,
-	})
-
-	req, err := http.NewRequest(
-		"POST",
-		"https://api.github.com/user/repos",
-		bytes.NewBuffer(body),
-	)
-	if err != nil {
-		return "", err
+	visibility := opts.Visibility
+	if visibility == "" {
+		visibility = "private"
 	}
 
-	req.Header.Set("Authorization", "token "+token)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/vnd.github+json")
-	req.Header.Set("User-Agent", "platform-backend")
-
-	client := &http.Client{Timeout: 20 * time.Second}
-
-	resp, err := client.Do(req)
+	c, err := Client(ts, owner)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf(
-			"repo creation failed: status=%d body=%s",
-			resp.StatusCode,
-			string(body),
-		)
+	err = c.Post(context.Background(), endpoint, map[string]interface{}{
+		"name":        opts.Name,
+		"visibility":  visibility,
+		"private":     visibility != "public",
+		"description": opts.Description,
+	}, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("repo creation failed: %w", err)
 	}
 
 	log.Println("✅ Repo created:", repoURL)
-	return repoURL, nil
-}
-
-
 
-func GetAuthenticatedUser(token string) (string, error) {
-	req, err := http.NewRequest(
-		"GET",
-		"https://api.github.com/user",
-		nil,
-	)
-	if err != nil {
-		return "", err
+	if len(opts.Topics) > 0 {
+		if err := SetTopics(ts, owner, opts.Name, opts.Topics); err != nil {
+			return "", "", err
+		}
 	}
 
-	req.Header.Set("Authorization", "token "+token)
-	req.Header.Set("Accept", "application/vnd.github+json")
-	req.Header.Set("User-Agent", "platform-backend")
-
-	resp, err := githubClient.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf(
-			"github api /user failed: status=%d body=%s",
-			resp.StatusCode,
-			string(body),
-		)
-	}
+	return owner, repoURL, nil
+}
 
+// authenticatedUser returns the login of the account c acts as.
+func authenticatedUser(c *github.Client) (string, error) {
 	var user githubUser
-	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+	if err := c.Get(context.Background(), "/user", &user); err != nil {
 		return "", err
 	}
 
@@ -122,38 +94,46 @@ func GetAuthenticatedUser(token string) (string, error) {
 	return user.Login, nil
 }
 
-
-func RepoExists(token, owner, repoName string) (bool, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, repoName)
-
-	req, err := http.NewRequest("GET", url, nil)
+func RepoExists(ts TokenSource, owner, repoName string) (bool, error) {
+	c, err := Client(ts, owner)
 	if err != nil {
 		return false, err
 	}
 
-	req.Header.Set("Authorization", "token "+token)
-	req.Header.Set("Accept", "application/vnd.github+json")
-	req.Header.Set("User-Agent", "platform-backend")
-
-	resp, err := githubClient.Do(req)
-	if err != nil {
-		return false, err
-	}
-	defer resp.Body.Close()
+	err = c.Get(context.Background(),
+		fmt.Sprintf("/repos/%s/%s", owner, repoName),
+		nil,
+	)
 
-	switch resp.StatusCode {
-	case http.StatusOK:
+	switch {
+	case err == nil:
 		return true, nil
-	case http.StatusNotFound:
+	case github.IsNotFound(err):
 		return false, nil
-	case http.StatusUnauthorized, http.StatusForbidden:
-		return false, fmt.Errorf("github auth failed (status %d)", resp.StatusCode)
+	case github.HasStatus(err, 401), github.HasStatus(err, 403):
+		return false, fmt.Errorf("github auth failed: %w", err)
 	default:
-		body, _ := io.ReadAll(resp.Body)
-		return false, fmt.Errorf(
-			"repo check failed: status=%d body=%s",
-			resp.StatusCode,
-			string(body),
-		)
+		return false, fmt.Errorf("repo check failed: %w", err)
 	}
-}
\ No newline at end of file
+}
+
+func GetDefaultBranch(ts TokenSource, owner, repoName string) (string, error) {
+	c, err := Client(ts, owner)
+	if err != nil {
+		return "", err
+	}
+
+	var repo struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+
+	err = c.Get(context.Background(),
+		fmt.Sprintf("/repos/%s/%s", owner, repoName),
+		&repo,
+	)
+	if err != nil {
+		return "", fmt.Errorf("repo lookup failed: %w", err)
+	}
+
+	return repo.DefaultBranch, nil
+}
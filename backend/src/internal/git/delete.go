@@ -1,61 +1,37 @@
-
 package git
 
 import (
+	"context"
 	"fmt"
 	"log"
-	"net/http"
-	"time"
-)
-
-func DeleteRepo(token, repoName string) error {
-	log.Println("🗑️ Deleting GitHub repository:", repoName)
-
-	owner, err := GetAuthenticatedUser(token)
-	log.Println("the owner of the Github reposiroty is",owner)
-	if err != nil {
-		log.Println("❌ Failed to determine GitHub owner:", err)
-		return err
-	}
 
-	url := fmt.Sprintf(
-		"https://api.github.com/repos/%s/%s",
-		owner,
-		repoName,
-	)
+	"src/src/internal/github"
+)
 
-	req, err := http.NewRequest("DELETE", url, nil)
+func DeleteRepo(ts TokenSource, owner, repoName string) error {
+	c, err := Client(ts, owner)
 	if err != nil {
-		log.Println("❌ Failed to create delete request:", err)
 		return err
 	}
 
-	req.Header.Set("Authorization", "token "+token)
-	req.Header.Set("Accept", "application/vnd.github+json")
-
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
+	log.Println("🗑️ Deleting GitHub repository:", owner+"/"+repoName)
 
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Println("❌ GitHub delete request failed:", err)
-		return err
-	}
-	defer resp.Body.Close()
+	err = c.Delete(context.Background(),
+		fmt.Sprintf("/repos/%s/%s", owner, repoName),
+	)
 
-	switch resp.StatusCode {
-	case http.StatusNoContent:
+	switch {
+	case err == nil:
 		log.Println("✅ GitHub repository deleted:", repoName)
 		return nil
 
-	case http.StatusNotFound:
+	case github.IsNotFound(err):
 		log.Println("⚠️ GitHub repository not found (already deleted):", repoName)
 		return nil
 
 	default:
-		err := fmt.Errorf("GitHub repo deletion failed with status %d", resp.StatusCode)
+		err := fmt.Errorf("GitHub repo deletion failed: %w", err)
 		log.Println("❌", err)
 		return err
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,242 @@
+package git
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"src/src/internal/github"
+)
+
+// Installation tokens live for an hour; refresh a little early
+const tokenRefreshMargin = 5 * time.Minute
+
+var errNoInstallation = errors.New("github app is not installed")
+
+type installationToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type appInstallation struct {
+	ID      int64 `json:"id"`
+	Account struct {
+		Login string `json:"login"`
+		Type  string `json:"type"` // Organization | User
+	} `json:"account"`
+}
+
+// AppTokenSource authenticates as a GitHub App: it signs a JWT with the
+// app's private key, finds the installation for a repo owner and exchanges
+// the JWT for an installation token, cached until shortly before expiry.
+type AppTokenSource struct {
+	AppID string
+	Org   string // default owner for new repositories
+	Conn  github.Connection
+
+	key *rsa.PrivateKey
+
+	mu            sync.Mutex
+	installations map[string]appInstallation // owner → installation
+	tokens        map[int64]installationToken
+}
+
+func NewAppTokenSource(conn github.Connection, appID string, privateKeyPEM []byte, org string) (*AppTokenSource, error) {
+	key, err := parseRSAPrivateKey(privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AppTokenSource{
+		AppID:         appID,
+		Org:           org,
+		Conn:          conn,
+		key:           key,
+		installations: map[string]appInstallation{},
+		tokens:        map[int64]installationToken{},
+	}, nil
+}
+
+func (a *AppTokenSource) Token(owner string) (string, error) {
+	if owner == "" {
+		var err error
+		if owner, _, err = a.DefaultOwner(); err != nil {
+			return "", err
+		}
+	}
+
+	inst, err := a.installation(owner)
+	if err != nil {
+		return "", err
+	}
+
+	a.mu.Lock()
+	cached, ok := a.tokens[inst.ID]
+	a.mu.Unlock()
+
+	if ok && time.Until(cached.ExpiresAt) > tokenRefreshMargin {
+		return cached.Token, nil
+	}
+
+	log.Printf("🔐 Minting installation token for %s (installation %d)", owner, inst.ID)
+
+	client, err := a.client()
+	if err != nil {
+		return "", err
+	}
+
+	var tok installationToken
+	err = client.Post(context.Background(),
+		fmt.Sprintf("/app/installations/%d/access_tokens", inst.ID),
+		nil,
+		&tok,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	a.mu.Lock()
+	a.tokens[inst.ID] = tok
+	a.mu.Unlock()
+
+	return tok.Token, nil
+}
+
+func (a *AppTokenSource) Connection() github.Connection {
+	return a.Conn
+}
+
+// DefaultOwner is the configured org, or the app's only installation.
+func (a *AppTokenSource) DefaultOwner() (string, bool, error) {
+	if a.Org != "" {
+		return a.Org, true, nil
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return "", false, err
+	}
+
+	installs, err := github.List[appInstallation](context.Background(), client, "/app/installations?per_page=100", 0)
+	if err != nil {
+		return "", false, err
+	}
+
+	if len(installs) != 1 {
+		return "", false, fmt.Errorf(
+			"github app has %d installations; set GITHUB_APP_ORG to choose the default owner",
+			len(installs),
+		)
+	}
+
+	inst := installs[0]
+	a.mu.Lock()
+	a.installations[strings.ToLower(inst.Account.Login)] = inst
+	a.mu.Unlock()
+
+	return inst.Account.Login, inst.Account.Type == "Organization", nil
+}
+
+// installation looks up (and caches) the app installation for an org or user.
+func (a *AppTokenSource) installation(owner string) (appInstallation, error) {
+	key := strings.ToLower(owner)
+
+	a.mu.Lock()
+	inst, ok := a.installations[key]
+	a.mu.Unlock()
+	if ok {
+		return inst, nil
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return inst, err
+	}
+
+	for _, kind := range []string{"orgs", "users"} {
+		err := client.Get(context.Background(),
+			fmt.Sprintf("/%s/%s/installation", kind, owner),
+			&inst,
+		)
+		if github.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return inst, err
+		}
+
+		a.mu.Lock()
+		a.installations[key] = inst
+		a.mu.Unlock()
+		return inst, nil
+	}
+
+	return inst, fmt.Errorf("%w on %s", errNoInstallation, owner)
+}
+
+// JWT returns a short-lived RS256 token identifying the app itself.
+func (a *AppTokenSource) JWT() (string, error) {
+	now := time.Now()
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-60 * time.Second).Unix(), // tolerate clock drift
+		"exp": now.Add(9 * time.Minute).Unix(),   // GitHub allows at most 10
+		"iss": a.AppID,
+	})
+
+	enc := base64.RawURLEncoding
+	signingInput := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + enc.EncodeToString(sig), nil
+}
+
+// client talks to the API as the app itself (JWT auth).
+func (a *AppTokenSource) client() (*github.Client, error) {
+	jwt, err := a.JWT()
+	if err != nil {
+		return nil, err
+	}
+
+	return a.Conn.NewClient(jwt), nil
+}
+
+func parseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("github app private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse github app private key: %w", err)
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("github app private key is not an RSA key")
+	}
+	return key, nil
+}
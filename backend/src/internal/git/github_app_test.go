@@ -0,0 +1,196 @@
+package git
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"src/src/internal/github"
+)
+
+func testKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+// verifyJWT checks token is an RS256 JWT signed by key and returns its
+// claims.
+func verifyJWT(t *testing.T, key *rsa.PublicKey, token string) map[string]interface{} {
+	t.Helper()
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("jwt has %d parts", len(parts))
+	}
+	enc := base64.RawURLEncoding
+
+	var header map[string]string
+	raw, _ := enc.DecodeString(parts[0])
+	if err := json.Unmarshal(raw, &header); err != nil || header["alg"] != "RS256" {
+		t.Fatalf("jwt header = %s", raw)
+	}
+
+	sig, err := enc.DecodeString(parts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig); err != nil {
+		t.Fatalf("jwt signature: %v", err)
+	}
+
+	var claims map[string]interface{}
+	raw, _ = enc.DecodeString(parts[1])
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		t.Fatal(err)
+	}
+	return claims
+}
+
+// fakeApp serves the installation endpoints of the GitHub API for one app
+// installed on acme.
+type fakeApp struct {
+	t   *testing.T
+	key *rsa.PublicKey
+
+	mu      sync.Mutex
+	ttl     time.Duration // lifetime of minted tokens
+	lookups int
+	minted  int
+}
+
+func newFakeApp(t *testing.T, key *rsa.PublicKey, ttl time.Duration) (*fakeApp, *httptest.Server) {
+	f := &fakeApp{t: t, key: key, ttl: ttl}
+	srv := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeApp) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	jwt, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		http.Error(w, `{"message":"missing jwt"}`, http.StatusUnauthorized)
+		return
+	}
+	verifyJWT(f.t, f.key, jwt)
+
+	switch {
+	case r.Method == "GET" && strings.ToLower(r.URL.Path) == "/orgs/acme/installation": // logins ignore case
+		f.lookups++
+		fmt.Fprint(w, `{"id": 7, "account": {"login": "acme", "type": "Organization"}}`)
+	case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/installation"):
+		http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+	case r.Method == "POST" && r.URL.Path == "/app/installations/7/access_tokens":
+		f.minted++
+		json.NewEncoder(w).Encode(installationToken{
+			Token:     fmt.Sprintf("ghs_%d", f.minted),
+			ExpiresAt: time.Now().Add(f.ttl),
+		})
+	default:
+		http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+	}
+}
+
+func newTestApp(t *testing.T, ttl time.Duration) (*AppTokenSource, *fakeApp) {
+	t.Helper()
+
+	key, pemKey := testKey(t)
+	f, srv := newFakeApp(t, &key.PublicKey, ttl)
+
+	app, err := NewAppTokenSource(github.Connection{APIURL: srv.URL}, "12345", pemKey, "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return app, f
+}
+
+func TestAppJWT(t *testing.T) {
+	key, pemKey := testKey(t)
+	app, err := NewAppTokenSource(github.DotCom, "12345", pemKey, "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := app.JWT()
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := verifyJWT(t, &key.PublicKey, token)
+
+	if claims["iss"] != "12345" {
+		t.Errorf("iss = %v", claims["iss"])
+	}
+	iat, exp := int64(claims["iat"].(float64)), int64(claims["exp"].(float64))
+	if iat > time.Now().Unix() {
+		t.Errorf("iat %d is in the future", iat)
+	}
+	if exp-iat > int64((10 * time.Minute).Seconds()) {
+		t.Errorf("jwt lives %ds; GitHub allows at most 10 minutes", exp-iat)
+	}
+}
+
+func TestAppTokenCached(t *testing.T) {
+	app, f := newTestApp(t, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		token, err := app.Token("ACME")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "ghs_1" {
+			t.Fatalf("call %d: token = %q", i, token)
+		}
+	}
+	if f.lookups != 1 || f.minted != 1 {
+		t.Errorf("lookups = %d, minted = %d; want 1 and 1", f.lookups, f.minted)
+	}
+}
+
+func TestAppTokenRefreshedBeforeExpiry(t *testing.T) {
+	// Inside the refresh margin: every call mints a fresh token
+	app, f := newTestApp(t, tokenRefreshMargin-time.Minute)
+
+	first, err := app.Token("acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := app.Token("acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second || f.minted != 2 {
+		t.Errorf("tokens %q, %q after %d mints; want a refresh", first, second, f.minted)
+	}
+	if f.lookups != 1 {
+		t.Errorf("installation looked up %d times, want 1", f.lookups)
+	}
+}
+
+func TestAppTokenNotInstalled(t *testing.T) {
+	app, _ := newTestApp(t, time.Hour)
+
+	if _, err := app.Token("globex"); !errors.Is(err, errNoInstallation) {
+		t.Errorf("got %v, want %v", err, errNoInstallation)
+	}
+}
@@ -0,0 +1,126 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"src/src/internal/github"
+)
+
+// BranchProtection is the subset of GitHub branch protection the platform
+// manages for environment branches.
+type BranchProtection struct {
+	RequiredReviews  int
+	StatusChecks     []string
+	EnforceAdmins    bool
+	AllowForcePushes bool
+	AllowDeletions   bool
+}
+
+func ProtectBranch(ts TokenSource, owner, repo, branch string, p BranchProtection) error {
+	c, err := Client(ts, owner)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("🛡️ Protecting %s/%s@%s (reviews=%d checks=%v)",
+		owner, repo, branch, p.RequiredReviews, p.StatusChecks)
+
+	payload := map[string]interface{}{
+		"required_status_checks":        nil,
+		"enforce_admins":                p.EnforceAdmins,
+		"required_pull_request_reviews": nil,
+		"restrictions":                  nil,
+		"allow_force_pushes":            p.AllowForcePushes,
+		"allow_deletions":               p.AllowDeletions,
+	}
+
+	if len(p.StatusChecks) > 0 {
+		payload["required_status_checks"] = map[string]interface{}{
+			"strict":   true,
+			"contexts": p.StatusChecks,
+		}
+	}
+
+	if p.RequiredReviews > 0 {
+		payload["required_pull_request_reviews"] = map[string]interface{}{
+			"required_approving_review_count": p.RequiredReviews,
+			"dismiss_stale_reviews":           true,
+		}
+	}
+
+	return c.Put(context.Background(),
+		fmt.Sprintf("/repos/%s/%s/branches/%s/protection", owner, repo, branch),
+		payload,
+		nil,
+	)
+}
+
+// GetBranchProtection returns the branch's current protection, or nil when
+// the branch is not protected.
+func GetBranchProtection(ts TokenSource, owner, repo, branch string) (*BranchProtection, error) {
+	c, err := Client(ts, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		RequiredStatusChecks *struct {
+			Contexts []string `json:"contexts"`
+		} `json:"required_status_checks"`
+		RequiredPullRequestReviews *struct {
+			RequiredApprovingReviewCount int `json:"required_approving_review_count"`
+		} `json:"required_pull_request_reviews"`
+		EnforceAdmins struct {
+			Enabled bool `json:"enabled"`
+		} `json:"enforce_admins"`
+		AllowForcePushes struct {
+			Enabled bool `json:"enabled"`
+		} `json:"allow_force_pushes"`
+		AllowDeletions struct {
+			Enabled bool `json:"enabled"`
+		} `json:"allow_deletions"`
+	}
+
+	err = c.Get(context.Background(),
+		fmt.Sprintf("/repos/%s/%s/branches/%s/protection", owner, repo, branch),
+		&res,
+	)
+	if github.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p := &BranchProtection{
+		EnforceAdmins:    res.EnforceAdmins.Enabled,
+		AllowForcePushes: res.AllowForcePushes.Enabled,
+		AllowDeletions:   res.AllowDeletions.Enabled,
+	}
+	if res.RequiredStatusChecks != nil {
+		p.StatusChecks = res.RequiredStatusChecks.Contexts
+	}
+	if res.RequiredPullRequestReviews != nil {
+		p.RequiredReviews = res.RequiredPullRequestReviews.RequiredApprovingReviewCount
+	}
+
+	return p, nil
+}
+
+func BranchExists(ts TokenSource, owner, repo, branch string) (bool, error) {
+	c, err := Client(ts, owner)
+	if err != nil {
+		return false, err
+	}
+
+	err = c.Get(context.Background(),
+		fmt.Sprintf("/repos/%s/%s/branches/%s", owner, repo, branch),
+		nil,
+	)
+	if github.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
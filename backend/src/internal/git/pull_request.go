@@ -0,0 +1,37 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+func CreatePullRequest(ts TokenSource, owner, repo, head, base, title, body string) (string, error) {
+	c, err := Client(ts, owner)
+	if err != nil {
+		return "", err
+	}
+
+	log.Printf("🔀 Opening pull request %s → %s on %s/%s", head, base, owner, repo)
+
+	var pr struct {
+		HTMLURL string `json:"html_url"`
+	}
+
+	err = c.Post(context.Background(),
+		fmt.Sprintf("/repos/%s/%s/pulls", owner, repo),
+		map[string]string{
+			"title": title,
+			"head":  head,
+			"base":  base,
+			"body":  body,
+		},
+		&pr,
+	)
+	if err != nil {
+		return "", fmt.Errorf("pull request creation failed: %w", err)
+	}
+
+	log.Println("✅ Pull request opened:", pr.HTMLURL)
+	return pr.HTMLURL, nil
+}
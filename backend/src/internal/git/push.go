@@ -8,19 +8,20 @@ import (
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
 )
 
-func PushRepo(token, repoName, localPath, branch string) error {
-	// 1️⃣ Init repo
-	repo, err := git.PlainInit(localPath, false)
+// PushRepo commits localPath and pushes it to branch. extraBranches are
+// created on the remote from the same commit (e.g. a non-dev default branch).
+func PushRepo(ts TokenSource, owner, repoName, localPath, branch string, extraBranches ...string) error {
+	auth, err := basicAuth(ts, owner)
 	if err != nil {
-		return fmt.Errorf("git init failed: %w", err)
+		return err
 	}
 
-	owner, err := GetAuthenticatedUser(token)
+	// 1️⃣ Init repo
+	repo, err := git.PlainInit(localPath, false)
 	if err != nil {
-		return err
+		return fmt.Errorf("git init failed: %w", err)
 	}
 
 	// 2️⃣ Worktree
@@ -58,7 +59,7 @@ func PushRepo(token, repoName, localPath, branch string) error {
 	}
 
 	// 6️⃣ Add remote
-	remoteURL := fmt.Sprintf("https://github.com/%s/%s.git", owner, repoName)
+	remoteURL := ts.Connection().CloneURL(owner, repoName)
 
 	_, err = repo.CreateRemote(&config.RemoteConfig{
 		Name: "origin",
@@ -68,22 +69,29 @@ func PushRepo(token, repoName, localPath, branch string) error {
 		return err
 	}
 
-	// 7️⃣ Push dev branch
+	// 7️⃣ Push dev branch (+ extra branches from the same commit)
+	refSpecs := []config.RefSpec{
+		config.RefSpec(
+			fmt.Sprintf("refs/heads/%s:refs/heads/%s", branch, branch),
+		),
+	}
+	for _, extra := range extraBranches {
+		if extra == "" || extra == branch {
+			continue
+		}
+		refSpecs = append(refSpecs, config.RefSpec(
+			fmt.Sprintf("refs/heads/%s:refs/heads/%s", branch, extra),
+		))
+	}
+
 	err = repo.Push(&git.PushOptions{
 		RemoteName: "origin",
-		RefSpecs: []config.RefSpec{
-			config.RefSpec(
-				fmt.Sprintf("refs/heads/%s:refs/heads/%s", branch, branch),
-			),
-		},
-		Auth: &http.BasicAuth{
-			Username: "x-access-token",
-			Password: token,
-		},
+		RefSpecs:   refSpecs,
+		Auth:       auth,
 	})
 	if err != nil {
 		return fmt.Errorf("git push failed: %w", err)
 	}
 
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,182 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"src/src/internal/github"
+)
+
+// staticTokens is a TokenSource with one token for every owner.
+type staticTokens struct {
+	conn  github.Connection
+	token string
+	owner string
+}
+
+func (s staticTokens) Token(string) (string, error)        { return s.token, nil }
+func (s staticTokens) DefaultOwner() (string, bool, error) { return s.owner, false, nil }
+func (s staticTokens) Connection() github.Connection       { return s.conn }
+
+// fakeGitHub serves the repository and ref endpoints of a GHES API mounted
+// under /api/v3.
+type fakeGitHub struct {
+	t *testing.T
+
+	mu    sync.Mutex
+	repos map[string]bool   // owner/repo
+	refs  map[string]string // owner/repo:branch → sha
+	calls []string
+}
+
+func newFakeGitHub(t *testing.T) (*fakeGitHub, staticTokens) {
+	f := &fakeGitHub{t: t, repos: map[string]bool{}, refs: map[string]string{}}
+	srv := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(srv.Close)
+
+	return f, staticTokens{
+		conn: github.Connection{
+			APIURL:    srv.URL + "/api/v3",
+			UploadURL: srv.URL + "/api/uploads",
+			WebURL:    "https://ghe.example.com",
+		},
+		token: "ghp_test",
+		owner: "octo",
+	}
+}
+
+func (f *fakeGitHub) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer ghp_test" {
+		http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+		return
+	}
+
+	path, ok := strings.CutPrefix(r.URL.Path, "/api/v3")
+	if !ok {
+		f.t.Errorf("request outside the API root: %s", r.URL.Path)
+		http.NotFound(w, r)
+		return
+	}
+	f.calls = append(f.calls, r.Method+" "+path)
+
+	var body map[string]interface{}
+	if r.Body != nil {
+		json.NewDecoder(r.Body).Decode(&body)
+	}
+
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case r.Method == "POST" && path == "/user/repos":
+		f.repos["octo/"+body["name"].(string)] = true
+		w.WriteHeader(http.StatusCreated)
+
+	case r.Method == "GET" && len(parts) == 3 && parts[0] == "repos":
+		if !f.repos[parts[1]+"/"+parts[2]] {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+		}
+
+	case r.Method == "GET" && len(parts) == 7 && parts[3] == "git" && parts[4] == "ref":
+		sha, ok := f.refs[parts[1]+"/"+parts[2]+":"+parts[6]]
+		if !ok {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"object":{"sha":%q}}`, sha)
+
+	case r.Method == "POST" && len(parts) == 5 && parts[4] == "refs":
+		key := parts[1] + "/" + parts[2] + ":" + strings.TrimPrefix(body["ref"].(string), "refs/heads/")
+		if _, ok := f.refs[key]; ok {
+			http.Error(w, `{"message":"Reference already exists"}`, http.StatusUnprocessableEntity)
+			return
+		}
+		f.refs[key] = body["sha"].(string)
+		w.WriteHeader(http.StatusCreated)
+
+	default:
+		http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+	}
+}
+
+func TestCreateRepoOnEnterprise(t *testing.T) {
+	f, ts := newFakeGitHub(t)
+
+	owner, url, err := CreateRepo(ts, RepoOptions{Name: "shop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owner != "octo" || url != "https://ghe.example.com/octo/shop" {
+		t.Errorf("got %s, %s", owner, url)
+	}
+	if !f.repos["octo/shop"] {
+		t.Error("repository was not created")
+	}
+
+	// Second call finds the repository and creates nothing
+	f.calls = nil
+	if _, _, err := CreateRepo(ts, RepoOptions{Name: "shop"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.calls) != 1 || f.calls[0] != "GET /repos/octo/shop" {
+		t.Errorf("calls = %v", f.calls)
+	}
+}
+
+func TestCreateBranch(t *testing.T) {
+	f, ts := newFakeGitHub(t)
+	f.refs["octo/shop:main"] = "abc1234"
+
+	if err := CreateBranch(ts, "octo", "shop", "dev", "main"); err != nil {
+		t.Fatal(err)
+	}
+	if f.refs["octo/shop:dev"] != "abc1234" {
+		t.Errorf("dev = %q", f.refs["octo/shop:dev"])
+	}
+
+	// Existing branch is left alone
+	if err := CreateBranch(ts, "octo", "shop", "dev", "main"); err != nil {
+		t.Errorf("existing branch: %v", err)
+	}
+
+	if err := CreateBranch(ts, "octo", "shop", "test", "missing"); err == nil {
+		t.Error("missing source branch: want error")
+	}
+}
+
+func TestRepoExistsAuthFailure(t *testing.T) {
+	_, ts := newFakeGitHub(t)
+	ts.token = "ghp_revoked"
+
+	if _, err := RepoExists(ts, "octo", "shop"); !github.HasStatus(err, http.StatusUnauthorized) {
+		t.Errorf("got %v, want 401", err)
+	}
+}
+
+func TestBranchSHAEmpty(t *testing.T) {
+	f, ts := newFakeGitHub(t)
+	f.refs["octo/shop:main"] = ""
+
+	if sha, err := BranchSHA(ts, "octo", "shop", "main"); err == nil {
+		t.Errorf("got %q, want an error for a ref without a commit", sha)
+	}
+}
+
+func TestShortSHA(t *testing.T) {
+	for sha, want := range map[string]string{
+		"0123456789abcdef0123456789abcdef01234567": "0123456",
+		"0123456": "0123456",
+		"abc":     "abc",
+		"":        "",
+	} {
+		if got := ShortSHA(sha); got != want {
+			t.Errorf("ShortSHA(%q) = %q", sha, got)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+)
+
+var slugInvalid = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// TeamSlug converts a team name ("Payments Core") into its GitHub slug.
+func TeamSlug(team string) string {
+	slug := slugInvalid.ReplaceAllString(strings.ToLower(strings.TrimSpace(team)), "-")
+	return strings.Trim(slug, "-")
+}
+
+func SetTopics(ts TokenSource, owner, repo string, topics []string) error {
+	c, err := Client(ts, owner)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("🏷️ Setting topics on %s/%s: %v", owner, repo, topics)
+
+	return c.Put(context.Background(),
+		fmt.Sprintf("/repos/%s/%s/topics", owner, repo),
+		map[string]interface{}{"names": topics},
+		nil,
+	)
+}
+
+func SetDefaultBranch(ts TokenSource, owner, repo, branch string) error {
+	c, err := Client(ts, owner)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("🌿 Setting default branch of %s/%s to %s", owner, repo, branch)
+
+	return c.Patch(context.Background(),
+		fmt.Sprintf("/repos/%s/%s", owner, repo),
+		map[string]interface{}{"default_branch": branch},
+		nil,
+	)
+}
+
+// GrantTeamAccess gives an org team a permission (pull, triage, push,
+// maintain, admin) on a repository.
+func GrantTeamAccess(ts TokenSource, org, teamSlug, owner, repo, permission string) error {
+	c, err := Client(ts, owner)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("👥 Granting team %s/%s %s access to %s/%s", org, teamSlug, permission, owner, repo)
+
+	return c.Put(context.Background(),
+		fmt.Sprintf("/orgs/%s/teams/%s/repos/%s/%s", org, teamSlug, owner, repo),
+		map[string]interface{}{"permission": permission},
+		nil,
+	)
+}
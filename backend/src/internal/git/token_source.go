@@ -0,0 +1,147 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+
+	"src/src/internal/config"
+	"src/src/internal/github"
+	"src/src/internal/secrets"
+)
+
+// TokenSource hands out GitHub credentials. Tokens are requested per repo
+// owner because GitHub App installations are scoped to one account. Every
+// git and cicd function that reaches GitHub takes the source to act with.
+type TokenSource interface {
+	// Token returns a token that can act on repositories owned by owner
+	// ("" means the default owner).
+	Token(owner string) (string, error)
+
+	// DefaultOwner is the account repositories land in when the request does
+	// not name one; org reports whether it is an organization.
+	DefaultOwner() (owner string, org bool, err error)
+
+	// Connection is the github.com or GHES host the tokens are valid on.
+	Connection() github.Connection
+}
+
+// DefaultTokenSource is the personal access token named in the default
+// configuration; packages hold it until main configures the real source.
+func DefaultTokenSource() TokenSource {
+	return NewPATTokenSource(github.DotCom, config.Default().GitHub.TokenSecret)
+}
+
+// Client returns an API client acting on owner's repositories.
+func Client(ts TokenSource, owner string) (*github.Client, error) {
+	token, err := ts.Token(owner)
+	if err != nil {
+		return nil, err
+	}
+	return ts.Connection().NewClient(token), nil
+}
+
+// basicAuth returns git credentials for owner's repositories.
+func basicAuth(ts TokenSource, owner string) (*http.BasicAuth, error) {
+	token, err := ts.Token(owner)
+	if err != nil {
+		return nil, err
+	}
+	return &http.BasicAuth{Username: "x-access-token", Password: token}, nil
+}
+
+// NewTokenSource picks GitHub App auth when cfg.App.ID is set and falls back
+// to the personal access token in cfg.TokenSecret. Either acts on the host
+// cfg describes.
+func NewTokenSource(cfg config.GitHub) (TokenSource, error) {
+	conn := github.ConnectionFromConfig(cfg)
+	log.Println("🐙 GitHub API:", conn.APIURL)
+
+	if cfg.App.ID == "" {
+		log.Println("🔐 GitHub auth: personal access token")
+		return NewPATTokenSource(conn, cfg.TokenSecret), nil
+	}
+
+	pem, err := secrets.Get(context.Background(), cfg.App.PrivateKeySecret)
+	if err != nil {
+		return nil, fmt.Errorf("load github app private key: %w", err)
+	}
+
+	log.Println("🔐 GitHub auth: app", cfg.App.ID)
+	return NewAppTokenSource(conn, cfg.App.ID, []byte(pem), cfg.App.Org)
+}
+
+/* ===================== PERSONAL ACCESS TOKEN ===================== */
+
+// PATTokenSource serves one personal access token from the secrets backend
+// for every owner. The token's user is looked up once and cached.
+type PATTokenSource struct {
+	conn       github.Connection
+	secretName string
+
+	mu    sync.Mutex
+	login string
+}
+
+func NewPATTokenSource(conn github.Connection, secretName string) *PATTokenSource {
+	return &PATTokenSource{conn: conn, secretName: secretName}
+}
+
+func (p *PATTokenSource) Connection() github.Connection {
+	return p.conn
+}
+
+func (p *PATTokenSource) Token(owner string) (string, error) {
+	token, err := secrets.Get(context.Background(), p.secretName)
+	if err != nil {
+		return "", err
+	}
+	if token == "" {
+		return "", fmt.Errorf("github token is empty")
+	}
+	return token, nil
+}
+
+func (p *PATTokenSource) DefaultOwner() (string, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.login != "" {
+		return p.login, false, nil
+	}
+
+	token, err := p.Token("")
+	if err != nil {
+		return "", false, err
+	}
+
+	login, err := authenticatedUser(p.conn.NewClient(token))
+	if github.HasStatus(err, 401) {
+		// Token was probably rotated; re-read it next time
+		secrets.Invalidate(p.secretName)
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	p.login = login
+	return login, false, nil
+}
+
+// Ping checks GitHub answers with the default owner's credentials.
+// /rate_limit does not count against the rate limit.
+func Ping(ctx context.Context, ts TokenSource) error {
+	owner, _, err := ts.DefaultOwner()
+	if err != nil {
+		return err
+	}
+
+	c, err := Client(ts, owner)
+	if err != nil {
+		return err
+	}
+	return c.Get(ctx, "/rate_limit", nil)
+}
@@ -0,0 +1,256 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var defaultHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// Client is the single way the platform talks to the GitHub REST API.
+// It sets auth and API headers, retries 429 and idempotent 5xx with
+// exponential backoff and waits out primary and secondary rate limits.
+type Client struct {
+	BaseURL    string
+	Token      string // PAT, installation token or app JWT
+	HTTP       *http.Client
+	MaxRetries int
+	MaxWait    time.Duration // longest rate-limit wait before giving up
+}
+
+func newClient(token string) *Client {
+	return &Client{
+		Token:      token,
+		HTTP:       defaultHTTPClient,
+		MaxRetries: 4,
+		MaxWait:    90 * time.Second,
+	}
+}
+
+// Error is returned for any non-2xx response.
+type Error struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf(
+		"github %s %s failed: status=%d body=%s",
+		e.Method,
+		e.URL,
+		e.StatusCode,
+		strings.TrimSpace(e.Body),
+	)
+}
+
+// HasStatus reports whether err is a GitHub response with the given status.
+func HasStatus(err error, status int) bool {
+	var ge *Error
+	return errors.As(err, &ge) && ge.StatusCode == status
+}
+
+func IsNotFound(err error) bool {
+	return HasStatus(err, http.StatusNotFound)
+}
+
+func (c *Client) Get(ctx context.Context, path string, out interface{}) error {
+	_, err := c.Do(ctx, "GET", path, nil, out)
+	return err
+}
+
+func (c *Client) Post(ctx context.Context, path string, body, out interface{}) error {
+	_, err := c.Do(ctx, "POST", path, body, out)
+	return err
+}
+
+func (c *Client) Put(ctx context.Context, path string, body, out interface{}) error {
+	_, err := c.Do(ctx, "PUT", path, body, out)
+	return err
+}
+
+func (c *Client) Patch(ctx context.Context, path string, body, out interface{}) error {
+	_, err := c.Do(ctx, "PATCH", path, body, out)
+	return err
+}
+
+func (c *Client) Delete(ctx context.Context, path string) error {
+	_, err := c.Do(ctx, "DELETE", path, nil, nil)
+	return err
+}
+
+// Do sends a request to path (relative to BaseURL, or an absolute URL),
+// JSON-encoding body and decoding a 2xx response into out. The response is
+// returned with its body already consumed so callers can read headers.
+func (c *Client) Do(ctx context.Context, method, path string, body, out interface{}) (*http.Response, error) {
+	url := c.url(path)
+
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		c.setHeaders(req, payload != nil)
+
+		resp, err := c.httpClient().Do(req)
+		if err != nil {
+			// Transport errors are only safe to repeat for idempotent calls
+			if ctx.Err() == nil && idempotent(method) && attempt < c.MaxRetries {
+				wait := backoff(attempt)
+				log.Printf("[GITHUB][RETRY] %s %s: %v (retry in %s)", method, url, err, wait)
+				if err := sleep(ctx, wait); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			return nil, err
+		}
+
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode < 300 {
+			if out != nil && len(respBody) > 0 {
+				if err := json.Unmarshal(respBody, out); err != nil {
+					return resp, fmt.Errorf("decode github %s %s: %w", method, url, err)
+				}
+			}
+			return resp, nil
+		}
+
+		apiErr := &Error{Method: method, URL: url, StatusCode: resp.StatusCode, Body: string(respBody)}
+
+		wait, retry := c.retryDelay(method, resp, respBody, attempt)
+		if !retry || attempt >= c.MaxRetries {
+			return resp, apiErr
+		}
+
+		log.Printf("[GITHUB][RETRY] %s %s: status=%d (retry in %s)", method, url, resp.StatusCode, wait)
+		if err := sleep(ctx, wait); err != nil {
+			return resp, err
+		}
+	}
+}
+
+// retryDelay decides whether a failed response is worth repeating and how
+// long to wait first. Rate-limited requests were never processed, so any
+// method may repeat them; a 5xx may have been, so only idempotent ones do.
+func (c *Client) retryDelay(method string, resp *http.Response, body []byte, attempt int) (time.Duration, bool) {
+	rateLimited := resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusForbidden &&
+			(resp.Header.Get("X-RateLimit-Remaining") == "0" ||
+				resp.Header.Get("Retry-After") != "" ||
+				strings.Contains(strings.ToLower(string(body)), "rate limit")))
+
+	switch {
+	case rateLimited:
+		wait := backoff(attempt)
+
+		if s := resp.Header.Get("Retry-After"); s != "" {
+			if secs, err := strconv.Atoi(s); err == nil {
+				wait = time.Duration(secs) * time.Second
+			}
+		} else if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+				wait = time.Until(time.Unix(reset, 0)) + time.Second
+			}
+		}
+
+		if wait > c.MaxWait {
+			log.Printf("[GITHUB][RATE LIMIT] reset in %s exceeds max wait %s, giving up", wait, c.MaxWait)
+			return 0, false
+		}
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+
+	case resp.StatusCode >= 500 && idempotent(method):
+		return backoff(attempt), true
+	}
+
+	return 0, false
+}
+
+func (c *Client) url(path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+
+	base := c.BaseURL
+	if base == "" {
+		base = DotCom.APIURL
+	}
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTP != nil {
+		return c.HTTP
+	}
+	return defaultHTTPClient
+}
+
+func (c *Client) setHeaders(req *http.Request, hasBody bool) {
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("User-Agent", "platform-backend")
+	if hasBody {
+		req.Header.Set("Content-Type", "application/json")
+	}
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "PUT", "DELETE", "OPTIONS":
+		return true
+	}
+	return false
+}
+
+// backoff is 500ms doubling per attempt (capped at 30s) plus jitter.
+func backoff(attempt int) time.Duration {
+	d := 500 * time.Millisecond << attempt
+	if d > 30*time.Second || d <= 0 {
+		d = 30 * time.Second
+	}
+	return d + time.Duration(rand.Int63n(int64(250*time.Millisecond)))
+}
+
+// sleep waits d or until ctx ends; tests replace it to record waits.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,207 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// recordSleeps replaces sleep with one that returns at once and records the
+// requested waits.
+func recordSleeps(t *testing.T) *[]time.Duration {
+	t.Helper()
+
+	var waits []time.Duration
+	prev := sleep
+	sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	t.Cleanup(func() { sleep = prev })
+	return &waits
+}
+
+// serveSequence answers the n-th request with responses[n] (the last one
+// repeats) and counts requests.
+func serveSequence(t *testing.T, responses ...func(http.ResponseWriter)) (*Client, *int) {
+	t.Helper()
+
+	var n int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		respond := responses[min(n, len(responses)-1)]
+		n++
+		respond(w)
+	}))
+	t.Cleanup(srv.Close)
+
+	return Connection{APIURL: srv.URL}.NewClient("t"), &n
+}
+
+func status(code int, headers ...string) func(http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		for i := 0; i+1 < len(headers); i += 2 {
+			w.Header().Set(headers[i], headers[i+1])
+		}
+		w.WriteHeader(code)
+		fmt.Fprintf(w, `{"message":%q}`, http.StatusText(code))
+	}
+}
+
+func ok(body string) func(http.ResponseWriter) {
+	return func(w http.ResponseWriter) { fmt.Fprint(w, body) }
+}
+
+func TestRetryServerErrorWithBackoff(t *testing.T) {
+	waits := recordSleeps(t)
+	c, n := serveSequence(t, status(502), status(503), ok(`{"login":"octo"}`))
+
+	var user struct{ Login string }
+	if err := c.Get(context.Background(), "/user", &user); err != nil {
+		t.Fatal(err)
+	}
+	if user.Login != "octo" || *n != 3 {
+		t.Errorf("login %q after %d requests", user.Login, *n)
+	}
+
+	if len(*waits) != 2 {
+		t.Fatalf("waits = %v", *waits)
+	}
+	for attempt, d := range *waits {
+		base := 500 * time.Millisecond << attempt
+		if d < base || d >= base+250*time.Millisecond {
+			t.Errorf("attempt %d waited %s, want %s plus jitter", attempt, d, base)
+		}
+	}
+}
+
+func TestRetryGivesUpAfterMaxRetries(t *testing.T) {
+	waits := recordSleeps(t)
+	c, n := serveSequence(t, status(500))
+
+	err := c.Get(context.Background(), "/user", nil)
+	if !HasStatus(err, 500) {
+		t.Fatalf("got %v", err)
+	}
+	if *n != c.MaxRetries+1 || len(*waits) != c.MaxRetries {
+		t.Errorf("%d requests, %d waits", *n, len(*waits))
+	}
+}
+
+func TestServerErrorNotRetriedForPost(t *testing.T) {
+	waits := recordSleeps(t)
+	c, n := serveSequence(t, status(502), ok(`{}`))
+
+	if err := c.Post(context.Background(), "/repos/acme/shop/hooks", map[string]string{}, nil); !HasStatus(err, 502) {
+		t.Fatalf("got %v", err)
+	}
+	if *n != 1 || len(*waits) != 0 {
+		t.Errorf("POST repeated: %d requests", *n)
+	}
+}
+
+func TestRateLimitRetryAfter(t *testing.T) {
+	waits := recordSleeps(t)
+	c, n := serveSequence(t, status(403, "Retry-After", "7"), ok(`{}`))
+
+	// Secondary limits apply to writes too; the request was not processed
+	if err := c.Post(context.Background(), "/repos/acme/shop/hooks", map[string]string{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if *n != 2 || len(*waits) != 1 || (*waits)[0] != 7*time.Second {
+		t.Errorf("%d requests, waits %v", *n, *waits)
+	}
+}
+
+func TestRateLimitReset(t *testing.T) {
+	waits := recordSleeps(t)
+	reset := time.Now().Add(30 * time.Second).Unix()
+	c, _ := serveSequence(t,
+		status(403, "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", strconv.FormatInt(reset, 10)),
+		ok(`{}`),
+	)
+
+	if err := c.Get(context.Background(), "/user", nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(*waits) != 1 {
+		t.Fatalf("waits = %v", *waits)
+	}
+	if d := (*waits)[0]; d < 29*time.Second || d > 31*time.Second {
+		t.Errorf("waited %s for a reset 30s away", d)
+	}
+}
+
+func TestRateLimitBeyondMaxWait(t *testing.T) {
+	waits := recordSleeps(t)
+	reset := time.Now().Add(time.Hour).Unix()
+	c, n := serveSequence(t,
+		status(403, "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", strconv.FormatInt(reset, 10)),
+		ok(`{}`),
+	)
+
+	if err := c.Get(context.Background(), "/user", nil); !HasStatus(err, 403) {
+		t.Fatalf("got %v", err)
+	}
+	if *n != 1 || len(*waits) != 0 {
+		t.Errorf("%d requests, waits %v; want an immediate give-up", *n, *waits)
+	}
+}
+
+func TestListFollowsLinks(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		if page < 3 {
+			w.Header().Set("Link", fmt.Sprintf(
+				`<%s/items?page=%d>; rel="next", <%s/items?page=3>; rel="last"`,
+				srv.URL, page+1, srv.URL,
+			))
+		}
+		fmt.Fprintf(w, `[%d, %d]`, page*10, page*10+1)
+	}))
+	t.Cleanup(srv.Close)
+	c := Connection{APIURL: srv.URL}.NewClient("t")
+
+	all, err := List[int](context.Background(), c, "/items", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(all) != "[10 11 20 21 30 31]" {
+		t.Errorf("all pages = %v", all)
+	}
+
+	limited, err := List[int](context.Background(), c, "/items", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(limited) != "[10 11 20]" {
+		t.Errorf("limit 3 = %v", limited)
+	}
+}
+
+func TestNextPage(t *testing.T) {
+	h := http.Header{}
+	if NextPage(h) != "" {
+		t.Error("no Link header")
+	}
+
+	h.Set("Link", `<https://api.github.com/x?page=3>; rel="last"`)
+	if NextPage(h) != "" {
+		t.Error("last page has no next")
+	}
+
+	h.Set("Link", `<https://api.github.com/x?page=1>; rel="prev", <https://api.github.com/x?page=3>; rel="next"`)
+	if got := NextPage(h); got != "https://api.github.com/x?page=3" {
+		t.Errorf("next = %q", got)
+	}
+}
@@ -0,0 +1,119 @@
+package github
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"src/src/internal/config"
+)
+
+// Connection describes one GitHub installation: github.com or a GitHub
+// Enterprise Server (GHES) host.
+type Connection struct {
+	APIURL    string // REST API root, e.g. https://ghe.example.com/api/v3
+	UploadURL string // upload API root, e.g. https://ghe.example.com/api/uploads
+	WebURL    string // web + clone host, e.g. https://ghe.example.com
+}
+
+// DotCom is the public github.com connection.
+var DotCom = Connection{
+	APIURL:    "https://api.github.com",
+	UploadURL: "https://uploads.github.com",
+	WebURL:    "https://github.com",
+}
+
+// Enterprise returns the conventional URLs of a GHES host.
+func Enterprise(host string) Connection {
+	base := "https://" + strings.TrimSuffix(strings.TrimPrefix(host, "https://"), "/")
+	return Connection{
+		APIURL:    base + "/api/v3",
+		UploadURL: base + "/api/uploads",
+		WebURL:    base,
+	}
+}
+
+// ConnectionFromConfig builds the connection for cfg.Host (empty →
+// github.com), with any explicit API, upload or web URL taking precedence.
+func ConnectionFromConfig(cfg config.GitHub) Connection {
+	conn := DotCom
+	if cfg.Host != "" {
+		conn = Enterprise(cfg.Host)
+	}
+
+	if cfg.APIURL != "" {
+		conn.APIURL = cfg.APIURL
+	}
+	if cfg.UploadURL != "" {
+		conn.UploadURL = cfg.UploadURL
+	}
+	if cfg.WebURL != "" {
+		conn.WebURL = cfg.WebURL
+	}
+
+	conn.APIURL = strings.TrimRight(conn.APIURL, "/")
+	conn.UploadURL = strings.TrimRight(conn.UploadURL, "/")
+	conn.WebURL = strings.TrimRight(conn.WebURL, "/")
+	return conn
+}
+
+// IsDotCom reports whether the connection points at github.com.
+func (c Connection) IsDotCom() bool {
+	return c.APIURL == DotCom.APIURL
+}
+
+// NewClient returns an API client for this connection.
+func (c Connection) NewClient(token string) *Client {
+	client := newClient(token)
+	client.BaseURL = c.APIURL
+	return client
+}
+
+// RepoURL is the repository's web URL.
+func (c Connection) RepoURL(owner, repo string) string {
+	return fmt.Sprintf("%s/%s/%s", c.WebURL, owner, repo)
+}
+
+// CloneURL is the repository's HTTPS clone URL.
+func (c Connection) CloneURL(owner, repo string) string {
+	return c.RepoURL(owner, repo) + ".git"
+}
+
+// ParseRepoURL extracts owner and repository name from a repository
+// reference: web or clone URLs (with or without .git, trailing slashes or
+// extra path such as /tree/main), SSH remotes (git@host:owner/repo.git,
+// ssh://git@host/owner/repo) and bare "owner/repo".
+func ParseRepoURL(raw string) (owner, repo string, err error) {
+	s := strings.TrimSpace(raw)
+	path := s
+
+	switch {
+	case strings.Contains(s, "://"):
+		u, perr := url.Parse(s)
+		if perr != nil {
+			return "", "", fmt.Errorf("invalid repository url %q: %w", raw, perr)
+		}
+		path = u.Path
+
+	case strings.HasPrefix(s, "git@"):
+		// scp-like syntax: git@host:owner/repo.git
+		i := strings.Index(s, ":")
+		if i < 0 {
+			return "", "", fmt.Errorf("invalid repository url %q", raw)
+		}
+		path = s[i+1:]
+	}
+
+	parts := strings.FieldsFunc(path, func(r rune) bool { return r == '/' })
+	if len(parts) < 2 {
+		return "", "", fmt.Errorf("repository url %q has no owner/name", raw)
+	}
+
+	owner = parts[0]
+	repo = strings.TrimSuffix(parts[1], ".git")
+	if owner == "" || repo == "" {
+		return "", "", fmt.Errorf("repository url %q has no owner/name", raw)
+	}
+
+	return owner, repo, nil
+}
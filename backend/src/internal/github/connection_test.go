@@ -0,0 +1,83 @@
+package github
+
+import (
+	"testing"
+
+	"src/src/internal/config"
+)
+
+func TestConnectionFromConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.GitHub
+		want Connection
+	}{
+		{name: "github.com", want: DotCom},
+		{
+			name: "enterprise host",
+			cfg:  config.GitHub{Host: "ghe.example.com"},
+			want: Connection{
+				APIURL:    "https://ghe.example.com/api/v3",
+				UploadURL: "https://ghe.example.com/api/uploads",
+				WebURL:    "https://ghe.example.com",
+			},
+		},
+		{
+			name: "explicit urls win",
+			cfg: config.GitHub{
+				Host:   "https://ghe.example.com/",
+				APIURL: "http://127.0.0.1:8080/api/",
+				WebURL: "http://127.0.0.1:8080/",
+			},
+			want: Connection{
+				APIURL:    "http://127.0.0.1:8080/api",
+				UploadURL: "https://ghe.example.com/api/uploads",
+				WebURL:    "http://127.0.0.1:8080",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConnectionFromConfig(tt.cfg); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConnectionURLs(t *testing.T) {
+	conn := Enterprise("ghe.example.com")
+
+	if conn.IsDotCom() || !DotCom.IsDotCom() {
+		t.Error("IsDotCom")
+	}
+	if got := conn.CloneURL("acme", "shop"); got != "https://ghe.example.com/acme/shop.git" {
+		t.Errorf("CloneURL = %s", got)
+	}
+	if got := conn.NewClient("t").BaseURL; got != conn.APIURL {
+		t.Errorf("client BaseURL = %s", got)
+	}
+}
+
+func TestParseRepoURL(t *testing.T) {
+	for _, raw := range []string{
+		"https://github.com/acme/shop",
+		"https://ghe.example.com/acme/shop.git",
+		"https://github.com/acme/shop/tree/main",
+		"https://github.com/acme/shop/",
+		"git@ghe.example.com:acme/shop.git",
+		"ssh://git@github.com/acme/shop",
+		"acme/shop",
+	} {
+		owner, repo, err := ParseRepoURL(raw)
+		if err != nil || owner != "acme" || repo != "shop" {
+			t.Errorf("ParseRepoURL(%q) = %q, %q, %v", raw, owner, repo, err)
+		}
+	}
+
+	for _, raw := range []string{"", "shop", "https://github.com/acme", "git@github.com"} {
+		if _, _, err := ParseRepoURL(raw); err == nil {
+			t.Errorf("ParseRepoURL(%q): want error", raw)
+		}
+	}
+}
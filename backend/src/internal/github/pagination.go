@@ -0,0 +1,43 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+)
+
+var linkNext = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// NextPage returns the rel="next" URL from a Link header, or "".
+func NextPage(h http.Header) string {
+	for _, link := range h.Values("Link") {
+		if m := linkNext.FindStringSubmatch(link); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// List follows Link pagination from path and collects up to limit items
+// (limit <= 0 collects every page).
+func List[T any](ctx context.Context, c *Client, path string, limit int) ([]T, error) {
+	var all []T
+
+	for next := path; next != ""; {
+		var page []T
+
+		resp, err := c.Do(ctx, "GET", next, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, page...)
+		if limit > 0 && len(all) >= limit {
+			return all[:limit], nil
+		}
+
+		next = NextPage(resp.Header)
+	}
+
+	return all, nil
+}
@@ -0,0 +1,106 @@
+// Package gitops deploys kubernetes services by committing to the GitOps
+// repository of each environment, which a controller such as Argo CD or
+// Flux applies to the cluster. A service lives in <service>/ of the
+// repository: Deployment, Service, HPA and Ingress manifests rendered from
+// its metadata on the first deploy, and a kustomization pinning the image
+// tag. A deploy or rollback only rewrites that tag, so manifest changes
+// made in the repository afterwards are kept.
+package gitops
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+
+	"src/src/internal/config"
+	"src/src/internal/git"
+)
+
+// pushAttempts bounds the retries when another deploy pushed to the
+// repository between our clone and push.
+const pushAttempts = 3
+
+var ErrNotConfigured = errors.New("no GitOps repository configured")
+
+var (
+	settings = config.Default().Kubernetes
+	workDir  = os.TempDir()
+	tokens   = git.DefaultTokenSource()
+)
+
+// Configure sets the registry, repositories and ingress domain, where
+// repositories are cloned and the credentials they are pushed with.
+func Configure(cfg config.Kubernetes, workspace config.Workspace, ts git.TokenSource) {
+	settings = cfg
+	workDir = workspace.Dir
+	tokens = ts
+}
+
+// Image returns the image repository of a service, without tag.
+func Image(serviceName string) string {
+	return strings.TrimSuffix(settings.Registry, "/") + "/" + serviceName
+}
+
+// Apply pins the image of s in env's GitOps repository to version (the
+// image tag), rendering the service's manifests first if it has none yet.
+// Applying the version already pinned is not an error.
+func Apply(s Service, env, version, message string) error {
+	repo, ok := settings.GitOpsRepos[env]
+	if !ok {
+		return fmt.Errorf("%w for %s", ErrNotConfigured, env)
+	}
+	owner, name, _ := strings.Cut(repo, "/")
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = apply(owner, name, s, env, version, message)
+		if !errors.Is(err, gogit.ErrForceNeeded) || attempt == pushAttempts {
+			break
+		}
+		log.Printf("🔁 %s moved on while deploying %s; retrying (%d/%d)", repo, s.Name, attempt, pushAttempts)
+	}
+	if errors.Is(err, git.ErrNothingToCommit) {
+		log.Printf("☸️ %s/%s already pins %s in %s", s.Name, env, version, repo)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("☸️ %s/%s pinned to %s in %s", s.Name, env, version, repo)
+	return nil
+}
+
+func apply(owner, repoName string, s Service, env, version, message string) error {
+	workspace, err := os.MkdirTemp(workDir, "gitops-"+repoName+"-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workspace)
+
+	// 1️⃣ Clone the branch the controller watches
+	repo, err := git.CloneRepo(tokens, owner, repoName, settings.GitOpsBranch, workspace)
+	if err != nil {
+		return err
+	}
+
+	// 2️⃣ First deploy: render the manifests
+	dir := filepath.Join(workspace, s.Name)
+	if _, err := os.Stat(filepath.Join(dir, kustomizationFile)); errors.Is(err, os.ErrNotExist) {
+		log.Printf("📝 Rendering manifests of %s for %s", s.Name, env)
+		if err := render(dir, s, env, Image(s.Name), settings.IngressDomain); err != nil {
+			return err
+		}
+	}
+
+	// 3️⃣ Pin the tag, commit & push
+	if err := setImage(dir, Image(s.Name), version); err != nil {
+		return err
+	}
+	return git.PushBranch(tokens, owner, repo, settings.GitOpsBranch, message)
+}
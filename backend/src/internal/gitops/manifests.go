@@ -0,0 +1,267 @@
+package gitops
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// kustomizationFile lists a service's manifests and pins its image tag; a
+// deploy only ever rewrites the tag.
+const kustomizationFile = "kustomization.yaml"
+
+// containerPort is what every golden template's Dockerfile exposes.
+const containerPort = 8080
+
+// Service is what a service's manifests are rendered from.
+type Service struct {
+	Name      string
+	OwnerTeam string
+}
+
+type manifestData struct {
+	Service
+	Environment string
+	Image       string
+	Port        int
+	Host        string
+	Labels      map[string]string
+}
+
+var manifests = template.Must(template.New("manifests").Parse(`
+{{- define "deployment.yaml" -}}
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Name }}
+  labels:
+{{- range $k, $v := .Labels }}
+    {{ $k }}: {{ $v }}
+{{- end }}
+spec:
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: {{ .Name }}
+  template:
+    metadata:
+      labels:
+{{- range $k, $v := .Labels }}
+        {{ $k }}: {{ $v }}
+{{- end }}
+    spec:
+      containers:
+        - name: {{ .Name }}
+          image: {{ .Image }}
+          ports:
+            - name: http
+              containerPort: {{ .Port }}
+          env:
+            - name: PORT
+              value: "{{ .Port }}"
+          readinessProbe:
+            tcpSocket:
+              port: http
+          resources:
+            requests:
+              cpu: 100m
+              memory: 128Mi
+            limits:
+              cpu: 500m
+              memory: 512Mi
+{{ end }}
+
+{{- define "service.yaml" -}}
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Name }}
+  labels:
+{{- range $k, $v := .Labels }}
+    {{ $k }}: {{ $v }}
+{{- end }}
+spec:
+  selector:
+    app.kubernetes.io/name: {{ .Name }}
+  ports:
+    - name: http
+      port: 80
+      targetPort: http
+{{ end }}
+
+{{- define "hpa.yaml" -}}
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: {{ .Name }}
+  labels:
+{{- range $k, $v := .Labels }}
+    {{ $k }}: {{ $v }}
+{{- end }}
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: {{ .Name }}
+  minReplicas: 2
+  maxReplicas: 5
+  metrics:
+    - type: Resource
+      resource:
+        name: cpu
+        target:
+          type: Utilization
+          averageUtilization: 70
+{{ end }}
+
+{{- define "ingress.yaml" -}}
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: {{ .Name }}
+  labels:
+{{- range $k, $v := .Labels }}
+    {{ $k }}: {{ $v }}
+{{- end }}
+spec:
+  rules:
+    - host: {{ .Host }}
+      http:
+        paths:
+          - path: /
+            pathType: Prefix
+            backend:
+              service:
+                name: {{ .Name }}
+                port:
+                  name: http
+{{ end }}
+`))
+
+// render writes the manifests of s for env into dir. The image tag is left
+// to the kustomization.
+func render(dir string, s Service, env, image, domain string) error {
+	data := manifestData{
+		Service:     s,
+		Environment: env,
+		Image:       image,
+		Port:        containerPort,
+		Labels: map[string]string{
+			"app.kubernetes.io/name":       s.Name,
+			"app.kubernetes.io/managed-by": "platform",
+			"platform/environment":         env,
+		},
+	}
+	if s.OwnerTeam != "" {
+		data.Labels["platform/owner-team"] = s.OwnerTeam
+	}
+
+	files := []string{"deployment.yaml", "service.yaml", "hpa.yaml"}
+	if domain != "" {
+		data.Host = fmt.Sprintf("%s.%s.%s", s.Name, env, domain)
+		files = append(files, "ingress.yaml")
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, name := range files {
+		var buf bytes.Buffer
+		if err := manifests.ExecuteTemplate(&buf, name, data); err != nil {
+			return fmt.Errorf("render %s: %w", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+
+	k := kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+		Resources:  files,
+	}
+	out, err := yaml.Marshal(k)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, kustomizationFile), out, 0644)
+}
+
+type kustomization struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Kind       string   `yaml:"kind"`
+	Resources  []string `yaml:"resources"`
+}
+
+// setImage pins image to tag in dir's kustomization, keeping everything
+// else in the file as it is.
+func setImage(dir, image, tag string) error {
+	path := filepath.Join(dir, kustomizationFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: not a mapping", path)
+	}
+	root := doc.Content[0]
+
+	images := mappingValue(root, "images")
+	if images == nil {
+		images = &yaml.Node{Kind: yaml.SequenceNode}
+		root.Content = append(root.Content, scalar("images"), images)
+	}
+
+	var entry *yaml.Node
+	for _, n := range images.Content {
+		if name := mappingValue(n, "name"); name != nil && name.Value == image {
+			entry = n
+			break
+		}
+	}
+	if entry == nil {
+		entry = &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{scalar("name"), scalar(image)}}
+		images.Content = append(images.Content, entry)
+	}
+
+	if tagNode := mappingValue(entry, "newTag"); tagNode != nil {
+		tagNode.Value, tagNode.Tag, tagNode.Style = tag, "!!str", yaml.DoubleQuotedStyle
+	} else {
+		t := scalar(tag)
+		t.Style = yaml.DoubleQuotedStyle
+		entry.Content = append(entry.Content, scalar("newTag"), t)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func scalar(v string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+}
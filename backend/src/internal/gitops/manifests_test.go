@@ -0,0 +1,106 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestRenderAndPin(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "orders")
+	image := "ghcr.io/acme/orders"
+
+	if err := render(dir, Service{Name: "orders", OwnerTeam: "payments"}, "dev", image, "apps.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := setImage(dir, image, "3f2a9c1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every manifest is valid YAML naming the service
+	for _, name := range []string{"deployment.yaml", "service.yaml", "hpa.yaml", "ingress.yaml"} {
+		var m struct {
+			Kind     string `yaml:"kind"`
+			Metadata struct {
+				Name   string            `yaml:"name"`
+				Labels map[string]string `yaml:"labels"`
+			} `yaml:"metadata"`
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := yaml.Unmarshal(data, &m); err != nil {
+			t.Fatalf("%s: %v\n%s", name, err, data)
+		}
+		if m.Metadata.Name != "orders" || m.Metadata.Labels["platform/owner-team"] != "payments" {
+			t.Errorf("%s: metadata %+v", name, m.Metadata)
+		}
+	}
+
+	ingress, _ := os.ReadFile(filepath.Join(dir, "ingress.yaml"))
+	if !strings.Contains(string(ingress), "host: orders.dev.apps.example.com") {
+		t.Errorf("ingress host not set:\n%s", ingress)
+	}
+
+	if got := pinnedTag(t, dir, image); got != "3f2a9c1" {
+		t.Errorf("tag = %q", got)
+	}
+}
+
+func TestSetImageKeepsEdits(t *testing.T) {
+	dir := t.TempDir()
+	image := "ghcr.io/acme/orders"
+	edited := `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+  - deployment.yaml
+  - pdb.yaml
+images:
+  - name: ghcr.io/acme/sidecar
+    newTag: "1.0"
+  - name: ghcr.io/acme/orders
+    newTag: "3f2a9c1"
+`
+	if err := os.WriteFile(filepath.Join(dir, kustomizationFile), []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := setImage(dir, image, "0123456"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := os.ReadFile(filepath.Join(dir, kustomizationFile))
+	want := strings.Replace(edited, `newTag: "3f2a9c1"`, `newTag: "0123456"`, 1)
+	if string(got) != want {
+		t.Errorf("kustomization =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func pinnedTag(t *testing.T, dir, image string) string {
+	t.Helper()
+
+	var k struct {
+		Resources []string `yaml:"resources"`
+		Images    []struct {
+			Name   string `yaml:"name"`
+			NewTag string `yaml:"newTag"`
+		} `yaml:"images"`
+	}
+	data, err := os.ReadFile(filepath.Join(dir, kustomizationFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal(data, &k); err != nil {
+		t.Fatal(err)
+	}
+	for _, img := range k.Images {
+		if img.Name == image {
+			return img.NewTag
+		}
+	}
+	return ""
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// trustedProxies are the addresses of the authenticating proxy; only its
+// X-Forwarded-User names a signed-in user.
+var trustedProxies []*net.IPNet
+
+// SetTrustedProxies sets the CIDRs of the authenticating proxy in front of
+// the API.
+func SetTrustedProxies(cidrs []string) error {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("trusted proxy %q: %w", cidr, err)
+		}
+		nets = append(nets, n)
+	}
+	trustedProxies = nets
+	return nil
+}
+
+// actor names who made the request, for lock ownership and audit logs. An
+// authenticating proxy in front of the API sets X-Forwarded-User; without
+// one the client address is the best available.
+func actor(r *http.Request) string {
+	if u := r.Header.Get("X-Forwarded-User"); u != "" {
+		return truncate(u, 100)
+	}
+	return "anonymous@" + remoteHost(r)
+}
+
+// principal is the user the authenticating proxy signed in; false when the
+// request did not come through a trusted proxy. Unlike actor, a client
+// cannot choose it, so checks that depend on who is asking use it.
+func principal(r *http.Request) (string, bool) {
+	u := r.Header.Get("X-Forwarded-User")
+	if u == "" {
+		return "", false
+	}
+	ip := net.ParseIP(remoteHost(r))
+	for _, n := range trustedProxies {
+		if ip != nil && n.Contains(ip) {
+			return truncate(u, 100), true
+		}
+	}
+	return "", false
+}
+
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
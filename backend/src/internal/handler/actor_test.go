@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPrincipal(t *testing.T) {
+	if err := SetTrustedProxies([]string{"10.0.0.0/8"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetTrustedProxies(nil) })
+
+	tests := []struct {
+		name   string
+		remote string
+		user   string
+		want   string // "" → no principal
+	}{
+		{name: "through the proxy", remote: "10.1.2.3:4431", user: "alice", want: "alice"},
+		{name: "proxy without a user", remote: "10.1.2.3:4431"},
+		{name: "header from a client", remote: "192.0.2.1:1234", user: "alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/api/v1/freeze-exemptions/5:approve", nil)
+			r.RemoteAddr = tt.remote
+			if tt.user != "" {
+				r.Header.Set("X-Forwarded-User", tt.user)
+			}
+
+			got, ok := principal(r)
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("principal = %q, %v; want %q", got, ok, tt.want)
+			}
+		})
+	}
+}
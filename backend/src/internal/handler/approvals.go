@@ -2,15 +2,20 @@ package handler
 
 import (
 	"database/sql"
-	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
-	"src/src/internal/cicd"
 	"src/src/internal/db"
+	"src/src/internal/deploy"
+	"src/src/internal/freeze"
+	"src/src/internal/locks"
+	"src/src/internal/rollout"
+	"src/src/internal/scheduler"
+	"src/src/internal/validate"
 )
 
 /* ===================== MODELS ===================== */
@@ -36,8 +41,10 @@ func GetApprovals(w http.ResponseWriter, r *http.Request) {
 	log.Println("[APPROVAL] Fetching approvals (pending + history)")
 
 	env := r.URL.Query().Get("environment")
-	if env == "" {
-		http.Error(w, "environment is required", http.StatusBadRequest)
+	var errs validate.Errors
+	errs.Environment("environment", env, validate.Environments)
+	if err := errs.Err(); err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
 
@@ -56,12 +63,12 @@ func GetApprovals(w http.ResponseWriter, r *http.Request) {
 
 	if err != nil {
 		log.Println("[APPROVAL][ERROR]", err)
-		http.Error(w, "failed to fetch approvals", http.StatusInternalServerError)
+		writeInternalError(w, r, err)
 		return
 	}
 	defer rows.Close()
 
-	var approvals []Approval
+	approvals := []Approval{}
 
 	for rows.Next() {
 		var a Approval
@@ -79,24 +86,26 @@ func GetApprovals(w http.ResponseWriter, r *http.Request) {
 		approvals = append(approvals, a)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(approvals)
+	writeJSON(w, http.StatusOK, approvals)
 }
 
 /* ===================== APPROVE ===================== */
 
 func ApproveDeployment(w http.ResponseWriter, r *http.Request) {
-	log.Println("[APPROVAL] Approve request received")
-
-	id, err := extractApprovalID(r.URL.Path)
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
-		http.Error(w, "invalid approval id", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid approval id")
 		return
 	}
+	approveDeployment(w, r, id)
+}
+
+func approveDeployment(w http.ResponseWriter, r *http.Request, id int64) {
+	log.Println("[APPROVAL] Approve request received")
 
 	tx, err := db.DB.Begin()
 	if err != nil {
-		http.Error(w, "db error", http.StatusInternalServerError)
+		writeInternalError(w, r, err)
 		return
 	}
 	defer tx.Rollback()
@@ -110,79 +119,124 @@ func ApproveDeployment(w http.ResponseWriter, r *http.Request) {
 	`, id).Scan(&serviceName, &environment)
 
 	if err == sql.ErrNoRows {
-		http.Error(w, "approval not found or already processed", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, CodeNotFound, "approval not found or already processed")
 		return
 	}
 	if err != nil {
-		http.Error(w, "db error", http.StatusInternalServerError)
+		writeInternalError(w, r, err)
 		return
 	}
 
 	log.Printf("[APPROVAL] Approving service=%s env=%s",
 		serviceName, environment)
 
-	_, err = tx.Exec(`
-		UPDATE deployment_approvals
-		SET status='approved', approved_at=NOW()
-		WHERE id=?
-	`, id)
+	// A scheduled deploy is only approved here; the scheduler re-checks
+	// the approval and freezes when it runs
+	sched, err := scheduler.ForApproval(r.Context(), tx, id)
 	if err != nil {
-		http.Error(w, "failed to update approval", http.StatusInternalServerError)
+		writeInternalError(w, r, err)
 		return
 	}
-
-	if err := tx.Commit(); err != nil {
-		http.Error(w, "failed to commit approval", http.StatusInternalServerError)
+	if sched != nil {
+		if err := markApproved(tx, id); err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{
+			"message": "production deployment approved; scheduled for " + sched.ScheduledAt.UTC().Format(time.RFC3339),
+		})
 		return
 	}
 
-	/* ===== Trigger CICD after approval ===== */
-
-	var cicdType, repo string
-	err = db.DB.QueryRow(`
-		SELECT cicd_type, repo_name
-		FROM services
-		WHERE service_name = ?
-	`, serviceName).Scan(&cicdType, &repo)
+	// A freeze that started after the request keeps the approval pending
+	if err := freeze.Check(r.Context(), serviceName, environment); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
 
+	// Take the lock before approving so a busy environment keeps the
+	// approval pending instead of consuming it
+	lock, err := locks.Acquire(r.Context(), serviceName, environment, "deploy", actor(r), fmt.Sprintf("approval %d", id))
 	if err != nil {
-		http.Error(w, "service not found", http.StatusNotFound)
+		writeServiceError(w, r, err)
 		return
 	}
+	triggered := false
+	defer func() {
+		if !triggered {
+			locks.Release(r.Context(), lock)
+		}
+	}()
 
-	branch := "master" // prod
+	// An unfinished rollout keeps the approval pending too
+	if err := rollout.Idle(r.Context(), serviceName, environment); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
 
-	log.Printf("[APPROVAL] Triggering prod deployment via %s", cicdType)
+	// The approval is committed only once the pipeline has started; a
+	// failed trigger rolls it back so it can be approved again
+	if err := markApproved(tx, id); err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
 
-	switch cicdType {
-	case "jenkins":
-		err = cicd.TriggerJenkinsDeploy(serviceName, branch)
-	case "github":
-		err = cicd.TriggerGitHubDeploy(repo, branch)
-	default:
-		http.Error(w, "unsupported cicd type", http.StatusBadRequest)
+	/* ===== Trigger CICD after approval ===== */
+
+	target, err := deploy.Lookup(r.Context(), serviceName)
+	if err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
 
+	log.Printf("[APPROVAL] Triggering prod deployment via %s", target.CICDType)
+
+	ro, err := rollout.Start(r.Context(), target, environment, actor(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"message":"production deployment approved and triggered"}`))
+	triggered = true
+	if err := tx.Commit(); err != nil {
+		// The pipeline is already running; only the bookkeeping failed
+		log.Printf("[APPROVAL] ⚠️ Approval %d triggered but not recorded: %v", id, err)
+		writeInternalError(w, r, err)
+		return
+	}
+	message := "production deployment approved and triggered"
+	if ro != nil {
+		message = fmt.Sprintf("production deployment approved; %s rollout %d started", ro.Strategy, ro.ID)
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": message})
+}
+
+func markApproved(tx *sql.Tx, id int64) error {
+	_, err := tx.Exec(`
+		UPDATE deployment_approvals
+		SET status='approved', approved_at=NOW()
+		WHERE id=?
+	`, id)
+	return err
 }
 
 /* ===================== REJECT ===================== */
 
 func RejectDeployment(w http.ResponseWriter, r *http.Request) {
-	log.Println("[APPROVAL] Reject request received")
-
-	id, err := extractApprovalID(r.URL.Path)
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
-		http.Error(w, "invalid approval id", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid approval id")
 		return
 	}
+	rejectDeployment(w, r, id)
+}
+
+func rejectDeployment(w http.ResponseWriter, r *http.Request, id int64) {
+	log.Println("[APPROVAL] Reject request received")
 
 	res, err := db.DB.Exec(`
 		UPDATE deployment_approvals
@@ -191,24 +245,43 @@ func RejectDeployment(w http.ResponseWriter, r *http.Request) {
 	`, id)
 
 	if err != nil {
-		http.Error(w, "failed to reject approval", http.StatusInternalServerError)
+		writeInternalError(w, r, err)
 		return
 	}
 
 	rows, _ := res.RowsAffected()
 	if rows == 0 {
-		http.Error(w, "approval not found or already processed", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, CodeNotFound, "approval not found or already processed")
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"message":"production deployment rejected"}`))
+	writeJSON(w, http.StatusOK, map[string]string{"message": "production deployment rejected"})
 }
 
-/* ===================== HELPERS ===================== */
+/* ===================== DECIDE (v1) ===================== */
 
-func extractApprovalID(path string) (int64, error) {
-	parts := strings.Split(strings.Trim(path, "/"), "/")
-	idStr := parts[len(parts)-2]
-	return strconv.ParseInt(idStr, 10, 64)
-}
\ No newline at end of file
+// DecideApproval serves POST /api/v1/approvals/{id}:approve and
+// /api/v1/approvals/{id}:reject. ServeMux wildcards span whole segments, so
+// the segment is split here.
+func DecideApproval(w http.ResponseWriter, r *http.Request) {
+	idStr, verb, ok := strings.Cut(r.PathValue("action"), ":")
+	if !ok {
+		writeError(w, r, http.StatusNotFound, CodeNotFound, "expected /approvals/{id}:approve or /approvals/{id}:reject")
+		return
+	}
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid approval id")
+		return
+	}
+
+	switch verb {
+	case "approve":
+		approveDeployment(w, r, id)
+	case "reject":
+		rejectDeployment(w, r, id)
+	default:
+		writeError(w, r, http.StatusNotFound, CodeNotFound, "unknown approval action: "+verb)
+	}
+}
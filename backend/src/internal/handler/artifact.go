@@ -1,22 +1,25 @@
 package handler
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
-	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"time"
 
+	"src/src/internal/argocd"
 	"src/src/internal/db"
+	"src/src/internal/healthcheck"
+	"src/src/internal/locks"
 	"src/src/internal/model"
+	"src/src/internal/preview"
+	"src/src/internal/rollout"
+	"src/src/internal/validate"
 )
 
 func RegisterArtifact(w http.ResponseWriter, r *http.Request) {
-	// 🔒 Allow POST only
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	// 🔒 Limit request body (1MB max)
 	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
 	defer r.Body.Close()
@@ -24,67 +27,90 @@ func RegisterArtifact(w http.ResponseWriter, r *http.Request) {
 	var req model.ArtifactEvent
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json body", http.StatusBadRequest)
-		return
-	}
-
-	// 🔒 Hard guardrails
-	if req.Status != "success" {
-		http.Error(w, "only successful pipelines are accepted", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid json body")
 		return
 	}
 
-	if err := validateArtifactRequest(req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	// 🔒 Hard guardrails (only successful pipelines are accepted)
+	if err := validate.ArtifactEvent(&req); err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
 
-	if err := saveArtifact(req); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := RecordArtifact(r.Context(), req); err != nil {
+		writeInternalError(w, r, err)
 		return
 	}
 
 	// ✅ Success response
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]string{
+	writeJSON(w, http.StatusCreated, map[string]string{
 		"message": "artifact registered successfully",
 	})
 }
 
-
-func validateArtifactRequest(req model.ArtifactEvent) error {
-	if req.ServiceName == "" {
-		return errors.New("serviceName is required")
+// RecordArtifact stores a successful deploy or rollback, frees the
+// environment, advances its rollout, syncs its Argo CD application, updates
+// its preview and starts the health check. The environment's current
+// version only moves once the deploy is complete, so a canary or blue-green
+// rollout keeps reporting the previous version until its last step. The
+// report, the rollout's progress and the current version are written in one
+// transaction; the follow-ups only run once it is committed.
+// Pipelines reach it through RegisterArtifact; GitOps deploys call it
+// directly.
+func RecordArtifact(ctx context.Context, a model.ArtifactEvent) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
 	}
-	if !isValidEnv(req.Environment) {
-		return errors.New("invalid environment")
+	defer tx.Rollback()
+
+	if err := saveArtifact(ctx, tx, a); err != nil {
+		return err
 	}
-	if req.Version == "" {
-		return errors.New("version is required")
+
+	// 🚦 Advance the environment's canary / blue-green rollout, if any
+	complete, err := rollout.OnArtifact(ctx, tx, a)
+	if err != nil {
+		return fmt.Errorf("rollout %s/%s: %w", a.ServiceName, a.Environment, err)
 	}
-	if req.Action != "deploy" && req.Action != "rollback" {
-		return errors.New("action must be deploy or rollback")
+	if complete {
+		if err := saveState(ctx, tx, a); err != nil {
+			return err
+		}
 	}
 
-	if req.Pipeline != "jenkins" && req.Pipeline != "github" {
-		return errors.New("pipeline must be jenkins or github")
+	if err := tx.Commit(); err != nil {
+		return err
 	}
 
-	return nil
-}
+	// 🔓 The run is done; let the next deploy/rollback in
+	if err := locks.Finish(ctx, a.ServiceName, a.Environment); err != nil {
+		log.Printf("⚠️ [%s] release lock %s/%s: %v", RequestID(ctx), a.ServiceName, a.Environment, err)
+	}
 
+	// 🐙 Let Argo CD apply what the pipeline published
+	if err := argocd.OnArtifact(ctx, a); err != nil {
+		log.Printf("⚠️ [%s] argocd sync %s/%s: %v", RequestID(ctx), a.ServiceName, a.Environment, err)
+	}
 
+	// 🔍 Mark a pull request's preview ready and post its URL
+	if err := preview.OnArtifact(ctx, a); err != nil {
+		log.Printf("⚠️ [%s] preview %s/%s: %v", RequestID(ctx), a.ServiceName, a.Environment, err)
+	}
 
-func saveArtifact(a model.ArtifactEvent) error {
-	tx, err := db.DB.Begin()
-	if err != nil {
-		return err
+	// 🩺 Verify the new version once fully rolled out; a failing health
+	// check rolls it back
+	if a.Action == "deploy" && complete {
+		if _, err := healthcheck.Start(ctx, a.ServiceName, a.Environment, a.Version); err != nil {
+			log.Printf("⚠️ [%s] start health check %s/%s: %v", RequestID(ctx), a.ServiceName, a.Environment, err)
+		}
 	}
-	defer tx.Rollback()
+	return nil
+}
 
-	// 1️⃣ Insert into artifacts (HISTORY)
-	_, err = tx.Exec(`
+// saveArtifact records a pipeline report in the artifact history.
+func saveArtifact(ctx context.Context, tx *sql.Tx, a model.ArtifactEvent) error {
+	_, err := tx.ExecContext(ctx, `
 		INSERT INTO artifacts
 		(service_name, environment, version, artifact_type,
 		 commit_sha, pipeline, action)
@@ -97,12 +123,12 @@ func saveArtifact(a model.ArtifactEvent) error {
 		a.Pipeline,
 		a.Action,
 	)
-	if err != nil {
-		return err
-	}
+	return err
+}
 
-	// 2️⃣ Update current environment state (UPSERT)
-	_, err = tx.Exec(`
+// saveState makes the reported version the environment's current one.
+func saveState(ctx context.Context, tx *sql.Tx, a model.ArtifactEvent) error {
+	_, err := tx.ExecContext(ctx, `
 		REPLACE INTO environment_state
 		(service_name, environment, version,
 		 status, deployed_at)
@@ -113,22 +139,5 @@ func saveArtifact(a model.ArtifactEvent) error {
 		"success",
 		time.Now(),
 	)
-	if err != nil {
-		return err
-	}
-
-	return tx.Commit()
-}
-
-
-func isValidEnv(env string) bool {
-	switch env {
-	case "dev", "test","pre-prod","prod":
-		return true
-	default:
-		return false
-	}
+	return err
 }
-
-
-
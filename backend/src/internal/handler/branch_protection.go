@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"src/src/internal/service"
+)
+
+func GetBranchProtection(w http.ResponseWriter, r *http.Request) {
+	// GET /api/v1/services/{name}/branch-protection
+	report, err := service.ProtectionReport(r.PathValue("name"))
+	if errors.Is(err, service.ErrServiceNotFound) {
+		writeServiceError(w, r, err)
+		return
+	}
+	if err != nil {
+		writeError(w, r, http.StatusBadGateway, CodeUpstreamFailed, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
@@ -8,53 +8,53 @@ import (
 
 	"gopkg.in/yaml.v3"
 
+	"src/src/internal/infra"
 	"src/src/internal/model"
 	"src/src/internal/service"
+	"src/src/internal/validate"
 )
 
 func CreateService(w http.ResponseWriter, r *http.Request) {
-	log.Println("📥 /create-service (YAML) request received")
-
-	// Allow only POST
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
+	log.Println("📥 create service (YAML) request received")
 
 	// Content-Type check (allow charset)
 	ct := r.Header.Get("Content-Type")
 	if !strings.Contains(ct, "yaml") {
-		http.Error(w, "Content-Type must be application/x-yaml", http.StatusUnsupportedMediaType)
+		writeError(w, r, http.StatusUnsupportedMediaType, CodeUnsupportedMediaType, "Content-Type must be application/x-yaml")
 		return
 	}
 
 	// Read body
-	body, err := io.ReadAll(r.Body)
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
 	if err != nil {
-		http.Error(w, "failed to read body", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "failed to read body")
 		return
 	}
 
 	// Parse YAML
 	var req model.CreateServiceRequest
 	if err := yaml.Unmarshal(body, &req); err != nil {
-		http.Error(w, "invalid YAML format", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid YAML format")
+		return
+	}
+
+	// Validate fields, formats and enums (cleans repoPath)
+	if err := validate.CreateService(&req); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	// 🏗️ Infra needs a Terraform state backend
+	if req.Infra != nil && !infra.Enabled() {
+		var errs validate.Errors
+		errs.Add("infra", "terraform.stateBucket is not configured on this platform")
+		writeServiceError(w, r, errs.Err())
 		return
 	}
 
-	// Validate required fields
-	if req.ServiceName == "" ||
-		req.RepoName == "" ||
-		req.OwnerTeam == "" ||
-		req.Runtime == "" ||
-		req.TemplateVersion == "" ||
-		req.CICDType == "" ||
-		len(req.Environments) == 0 {
-		http.Error(
-			w,
-			"ServiceName, repoName, ownerTeam, runtime, templateVersion, cicdType,Environments are required",
-			http.StatusBadRequest,
-		)
+	// Secrets (values never reach the platform DB)
+	if err := service.ValidateSecrets(req.Secrets, req.Environments); err != nil {
+		writeError(w, r, http.StatusBadRequest, CodeValidationFailed, err.Error())
 		return
 	}
 
@@ -72,14 +72,12 @@ func CreateService(w http.ResponseWriter, r *http.Request) {
 	)
 
 	// Call service layer
-	repoURL, err := service.CreateService(req)
+	resp, err := service.CreateService(req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 		return
 	}
 
 	// Response
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"repoUrl":"` + repoURL + `"}`))
+	writeJSON(w, http.StatusOK, resp)
 }
\ No newline at end of file
@@ -1,119 +1,195 @@
 package handler
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
-	"strings"
 	"log"
-	"src/src/internal/cicd"
+	"time"
+
 	"src/src/internal/db"
+	"src/src/internal/deploy"
+	"src/src/internal/freeze"
+	"src/src/internal/locks"
+	"src/src/internal/model"
+	"src/src/internal/rollout"
+	"src/src/internal/scheduler"
+	"src/src/internal/validate"
 )
 
 type DeployRequest struct {
-	Environment string `json:"environment"`
+	Environment string     `json:"environment"`
+	Reason      string     `json:"reason,omitempty"`      // recorded on the deployment lock
+	ScheduledAt *time.Time `json:"scheduledAt,omitempty"` // run later instead of now
 }
 
 
 func DeployServices(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	// /services/{serviceName}/deploy
-	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
-	if len(parts) != 3 || parts[2] != "deploy" {
-		http.Error(w, "invalid path", http.StatusBadRequest)
-		return
-	}
-	serviceName := parts[1]
+	// POST /api/v1/services/{name}/deployments
+	serviceName := r.PathValue("name")
 	log.Printf("[[deploying the serving]] %s ",serviceName)
 
 	var req DeployRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid body", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid body")
 		return
 	}
 
-	if req.Environment == "" {
-		http.Error(w, "environment required", http.StatusBadRequest)
+	if err := validate.Deploy(req.Environment, req.Reason, req.ScheduledAt); err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
 
-	// env → branch mapping
-	var branch string
-	switch req.Environment {
-	case "dev":
-		branch = "dev"
-	case "test":
-		branch = "test"
-	case "prod":
-		branch = "master"
-	default:
-		http.Error(w, "invalid environment", http.StatusBadRequest)
+	// ⏰ Scheduled runs are checked against freezes when they execute
+	var sched *model.ScheduledDeployment
+	if req.ScheduledAt != nil {
+		sched = &model.ScheduledDeployment{
+			ServiceName: serviceName,
+			Environment: req.Environment,
+			ScheduledAt: req.ScheduledAt.UTC(),
+			Reason:      req.Reason,
+			RequestedBy: actor(r),
+		}
+	} else if err := freeze.Check(r.Context(), serviceName, req.Environment); err != nil {
+		// 🧊 No deploys (or approval requests) during a freeze; ask for an exemption
+		writeServiceError(w, r, err)
 		return
 	}
 
-
 	if req.Environment == "prod" {
-		// Create approval request
-		_, err := db.DB.Exec(`
-			INSERT INTO deployment_approvals
-			(service_name, environment,status,created_at)
-			VALUES (?, ?, 'pending',NOW())
-		`,
-			serviceName,
-			req.Environment,
-		)
-
+		// Create approval request (one pending request per service)
+		pendingID, err := requestApproval(r.Context(), serviceName, req.Environment, sched)
 		if err != nil {
-			http.Error(w, "failed to create approval", 500)
+			writeInternalError(w, r, err)
+			return
+		}
+		if pendingID != 0 {
+			writeError(w, r, http.StatusConflict, CodeConflict,
+				fmt.Sprintf("a prod deployment of %s is already awaiting approval (approval %d)", serviceName, pendingID))
 			return
 		}
 
-		w.WriteHeader(http.StatusAccepted)
-		w.Write([]byte(`{"status":"pending_approval"}`))
+		if sched != nil {
+			writeJSON(w, http.StatusAccepted, scheduledResponse(sched))
+			return
+		}
+		writeJSON(w, http.StatusAccepted, map[string]string{"status": "pending_approval"})
 		return
 	}
 
-	log.Printf("[[deploying to the environment is %s]]",req.Environment)
+	// 🔍 Get CICD type & repo info
+	target, err := deploy.Lookup(r.Context(), serviceName)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
 
-	log.Printf("getting the DB details")
+	if sched != nil {
+		if err := scheduler.Create(r.Context(), nil, sched); err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusAccepted, scheduledResponse(sched))
+		return
+	}
 
+	log.Printf("[[deploying to the environment is %s]]",req.Environment)
 
-	// 🔍 Get CICD type & repo info
-	var cicdType, repo string
-	err := db.DB.QueryRow(`
-		SELECT cicd_type, repo_name
-		FROM services
-		WHERE service_name = ?`,
-		serviceName,
-	).Scan(&cicdType, &repo)
+	// 🔒 One deploy/rollback per service/environment at a time
+	lock, err := locks.Acquire(r.Context(), serviceName, req.Environment, "deploy", actor(r), req.Reason)
 	if err != nil {
-		http.Error(w, "service not found", http.StatusNotFound)
+		writeServiceError(w, r, err)
 		return
 	}
-	log.Printf("Selecting the pipeline type and triggering the deployment")
-	// 🚀 Trigger correct CICD
-	switch cicdType {
-	case "jenkins":
-		err = cicd.TriggerJenkinsDeploy(serviceName, branch)
-
-	case "github":
-		err = cicd.TriggerGitHubDeploy(repo, branch)
 
-	default:
-		http.Error(w, "unsupported cicd type", http.StatusBadRequest)
+	// 🚦 No deploy while a canary / blue-green rollout is unfinished
+	if err := rollout.Idle(r.Context(), serviceName, req.Environment); err != nil {
+		locks.Release(r.Context(), lock)
+		writeServiceError(w, r, err)
 		return
 	}
 
+	// 🚀 Trigger correct CICD with the environment's strategy
+	ro, err := rollout.Start(r.Context(), target, req.Environment, actor(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		// Nothing is running; free the environment again
+		locks.Release(r.Context(), lock)
+		writeServiceError(w, r, err)
+		return
+	}
+
+	if ro != nil {
+		writeJSON(w, http.StatusAccepted, map[string]interface{}{
+			"message":   fmt.Sprintf("%s rollout started (step 1 of %d)", ro.Strategy, len(ro.Steps)),
+			"rolloutId": ro.ID,
+		})
 		return
 	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"message": "deployment triggered"})
+}
+
+func scheduledResponse(d *model.ScheduledDeployment) map[string]interface{} {
+	resp := map[string]interface{}{
+		"status":                "scheduled",
+		"scheduledDeploymentId": d.ID,
+		"scheduledAt":           d.ScheduledAt,
+	}
+	if d.ApprovalID != nil {
+		resp["approvalId"] = *d.ApprovalID
+	}
+	return resp
+}
+
+// requestApproval records a pending prod deployment, and sched with it
+// when the deploy is scheduled. If one is already pending its ID is
+// returned and nothing is inserted.
+func requestApproval(ctx context.Context, serviceName, env string, sched *model.ScheduledDeployment) (pendingID int64, err error) {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// FOR UPDATE holds the gap so two requests cannot both insert
+	err = tx.QueryRowContext(ctx, `
+		SELECT id FROM deployment_approvals
+		WHERE service_name = ? AND environment = ? AND status = 'pending'
+		LIMIT 1
+		FOR UPDATE`,
+		serviceName, env,
+	).Scan(&pendingID)
+	if err == nil {
+		return pendingID, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO deployment_approvals
+		(service_name, environment,status,created_at)
+		VALUES (?, ?, 'pending',NOW())`,
+		serviceName, env,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	if sched != nil {
+		approvalID, err := res.LastInsertId()
+		if err != nil {
+			return 0, err
+		}
+		sched.ApprovalID = &approvalID
+		if err := scheduler.Create(ctx, tx, sched); err != nil {
+			return 0, err
+		}
+	}
 
-	w.WriteHeader(http.StatusAccepted)
-	w.Write([]byte(`{"message":"deployment triggered"}`))
+	return 0, tx.Commit()
 }
 
 
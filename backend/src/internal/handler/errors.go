@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"src/src/internal/freeze"
+	"src/src/internal/healthcheck"
+	"src/src/internal/infra"
+	"src/src/internal/locks"
+	"src/src/internal/preview"
+	"src/src/internal/rollout"
+	"src/src/internal/scheduler"
+	"src/src/internal/service"
+	"src/src/internal/svcconfig"
+	"src/src/internal/validate"
+)
+
+/* ===================== ERROR ENVELOPE ===================== */
+
+// Error codes returned in ErrorResponse.Code. Clients branch on these, not
+// on the message text.
+const (
+	CodeBadRequest           = "bad_request"
+	CodeValidationFailed     = "validation_failed"
+	CodeNotFound             = "not_found"
+	CodeConflict             = "conflict"
+	CodeLocked               = "locked"
+	CodeFrozen               = "frozen"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeUpstreamFailed       = "upstream_failed"
+	CodeInternal             = "internal"
+)
+
+// ErrorResponse is the body of every 4xx/5xx response from the API.
+type ErrorResponse struct {
+	Code      string                `json:"code"`
+	Message   string                `json:"message"`
+	Details   []validate.FieldError `json:"details,omitempty"`
+	RequestID string                `json:"requestId"`
+}
+
+// writeError sends the error envelope with an explicit status and code.
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	writeJSON(w, status, ErrorResponse{
+		Code:      code,
+		Message:   message,
+		RequestID: RequestID(r.Context()),
+	})
+}
+
+// writeServiceError maps typed errors from validation and the service layer
+// to a status. Anything unrecognised is a 500 whose cause is logged under
+// the request ID but never sent to the client.
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	var (
+		invalid validate.Errors
+		locked  *locks.LockedError
+		frozen  *freeze.FrozenError
+		state   *rollout.StateError
+	)
+
+	switch {
+	case errors.As(err, &invalid):
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Code:      CodeValidationFailed,
+			Message:   "request has invalid fields",
+			Details:   invalid,
+			RequestID: RequestID(r.Context()),
+		})
+	case errors.As(err, &locked):
+		setRetryAfter(w, locked.Held.ExpiresAt)
+		writeError(w, r, http.StatusConflict, CodeLocked, err.Error())
+	case errors.As(err, &frozen):
+		setRetryAfter(w, frozen.Until)
+		writeError(w, r, http.StatusConflict, CodeFrozen, err.Error())
+	case errors.Is(err, freeze.ErrSelfApproval), errors.Is(err, preview.ErrSignature):
+		writeError(w, r, http.StatusForbidden, CodeForbidden, err.Error())
+	case errors.Is(err, freeze.ErrWindowNotFound), errors.Is(err, freeze.ErrExemptionNotFound),
+		errors.Is(err, scheduler.ErrNotFound), errors.Is(err, healthcheck.ErrNotFound),
+		errors.Is(err, rollout.ErrNotFound), errors.Is(err, infra.ErrNotFound),
+		errors.Is(err, svcconfig.ErrNotFound), errors.Is(err, preview.ErrNotFound),
+		errors.Is(err, preview.ErrNotConfigured):
+		writeError(w, r, http.StatusNotFound, CodeNotFound, err.Error())
+	case errors.Is(err, rollout.ErrInProgress), errors.Is(err, rollout.ErrNothingToAbort), errors.As(err, &state),
+		errors.Is(err, infra.ErrInProgress), errors.Is(err, infra.ErrPlanRequired), errors.Is(err, infra.ErrFinished),
+		errors.Is(err, svcconfig.ErrInProgress), errors.Is(err, svcconfig.ErrConflict):
+		writeError(w, r, http.StatusConflict, CodeConflict, err.Error())
+	case errors.Is(err, preview.ErrBadPayload):
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, err.Error())
+	case errors.Is(err, infra.ErrNotConfigured):
+		writeError(w, r, http.StatusBadRequest, CodeValidationFailed, err.Error())
+	case errors.Is(err, rollout.ErrTrigger), errors.Is(err, infra.ErrTrigger), errors.Is(err, svcconfig.ErrPublish),
+		errors.Is(err, preview.ErrTeardown):
+		writeError(w, r, http.StatusBadGateway, CodeUpstreamFailed, err.Error())
+	case errors.Is(err, locks.ErrNotLocked):
+		writeError(w, r, http.StatusNotFound, CodeNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidSecrets):
+		writeError(w, r, http.StatusBadRequest, CodeValidationFailed, err.Error())
+	case errors.Is(err, service.ErrServiceNotFound), errors.Is(err, service.ErrRepoNotFound):
+		writeError(w, r, http.StatusNotFound, CodeNotFound, err.Error())
+	case errors.Is(err, service.ErrServiceAlreadyExists), errors.Is(err, service.ErrRepoAlreadyExists):
+		writeError(w, r, http.StatusConflict, CodeConflict, err.Error())
+	default:
+		writeInternalError(w, r, err)
+	}
+}
+
+func setRetryAfter(w http.ResponseWriter, until time.Time) {
+	retry := time.Until(until).Round(time.Second)
+	w.Header().Set("Retry-After", strconv.Itoa(max(int(retry.Seconds()), 1)))
+}
+
+// writeInternalError hides err (SQL, stack details) from the client.
+func writeInternalError(w http.ResponseWriter, r *http.Request, err error) {
+	id := RequestID(r.Context())
+	log.Printf("❌ [%s] %s %s: %v", id, r.Method, r.URL.Path, err)
+	writeError(w, r, http.StatusInternalServerError, CodeInternal, "internal server error")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
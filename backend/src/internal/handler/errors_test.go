@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"src/src/internal/db"
+)
+
+func decodeError(t *testing.T, rec *httptest.ResponseRecorder) ErrorResponse {
+	t.Helper()
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("Content-Type = %q, want application/json", ct)
+	}
+	var body ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode error body %q: %v", rec.Body.String(), err)
+	}
+	return body
+}
+
+func TestValidationErrorListsEveryField(t *testing.T) {
+	body := "serviceName: Orders_Service\nrepoName: orders\nownerTeam: payments\nruntime: go\n" +
+		"cicdType: gitlab\ntemplateVersion: v1\ndeploytype: microservice\nenvironments: [dev, qa]\n"
+	req := httptest.NewRequest("POST", "/api/v1/services", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-yaml")
+
+	rec := httptest.NewRecorder()
+	NewRouter().ServeHTTP(rec, req)
+
+	if rec.Code != 400 {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+	got := decodeError(t, rec)
+	if got.Code != CodeValidationFailed {
+		t.Errorf("code = %q, want %q", got.Code, CodeValidationFailed)
+	}
+
+	fields := map[string]bool{}
+	for _, d := range got.Details {
+		fields[d.Field] = true
+	}
+	for _, want := range []string{"serviceName", "cicdType", "environments"} {
+		if !fields[want] {
+			t.Errorf("details missing %s: %+v", want, got.Details)
+		}
+	}
+}
+
+func TestRequestIDIsEchoed(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/v1/services/orders/artifacts", nil)
+	req.Header.Set("X-Request-ID", "edge-42")
+
+	rec := httptest.NewRecorder()
+	NewRouter().ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("X-Request-ID"); got != "edge-42" {
+		t.Errorf("X-Request-ID header = %q, want edge-42", got)
+	}
+	if got := decodeError(t, rec).RequestID; got != "edge-42" {
+		t.Errorf("requestId = %q, want edge-42", got)
+	}
+}
+
+func TestInternalErrorHidesCause(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mockDB.Close()
+	db.DB = mockDB
+
+	mock.ExpectQuery("FROM environment_state").
+		WillReturnError(errors.New("Error 1146: Table 'platform.environment_state' doesn't exist"))
+
+	rec := httptest.NewRecorder()
+	NewRouter().ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/services/orders/dashboard", nil))
+
+	if rec.Code != 500 {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+	got := decodeError(t, rec)
+	if strings.Contains(got.Message, "1146") || got.Code != CodeInternal {
+		t.Errorf("internal error leaked or miscoded: %+v", got)
+	}
+	if got.RequestID == "" || got.RequestID != rec.Header().Get("X-Request-ID") {
+		t.Errorf("requestId %q does not match header %q", got.RequestID, rec.Header().Get("X-Request-ID"))
+	}
+}
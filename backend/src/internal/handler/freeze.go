@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"src/src/internal/freeze"
+	"src/src/internal/model"
+	"src/src/internal/validate"
+)
+
+// The change calendar defaults to the next 30 days and spans at most ~a quarter.
+const (
+	defaultCalendarSpan = 30 * 24 * time.Hour
+	maxCalendarSpan     = 92 * 24 * time.Hour
+)
+
+/* ===================== FREEZE WINDOWS ===================== */
+
+func GetFreezeWindows(w http.ResponseWriter, r *http.Request) {
+	windows, err := freeze.ListWindows(r.Context())
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, windows)
+}
+
+func CreateFreezeWindow(w http.ResponseWriter, r *http.Request) {
+	var win model.FreezeWindow
+	if err := json.NewDecoder(r.Body).Decode(&win); err != nil {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid request body")
+		return
+	}
+
+	if err := validate.FreezeWindow(&win); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	win.CreatedBy = actor(r)
+	if err := freeze.CreateWindow(r.Context(), &win); err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, win)
+}
+
+func DeleteFreezeWindow(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid freeze window id")
+		return
+	}
+
+	if err := freeze.DeleteWindow(r.Context(), id, actor(r)); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "freeze window deleted"})
+}
+
+/* ===================== CHANGE CALENDAR ===================== */
+
+// GetFreezeCalendar lists freeze occurrences between ?from and ?to
+// (RFC 3339), optionally for one ?environment.
+func GetFreezeCalendar(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	env := q.Get("environment")
+
+	var errs validate.Errors
+	errs.OneOf("environment", env, validate.Environments...)
+
+	from, to := time.Now().UTC(), time.Time{}
+	if v := q.Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			errs.Add("from", "must be an RFC 3339 time")
+		}
+		from = t
+	}
+	if v := q.Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			errs.Add("to", "must be an RFC 3339 time")
+		}
+		to = t
+	} else {
+		to = from.Add(defaultCalendarSpan)
+	}
+	if len(errs) == 0 && (!to.After(from) || to.Sub(from) > maxCalendarSpan) {
+		errs.Add("to", "must be after from and at most %d days later", int(maxCalendarSpan.Hours()/24))
+	}
+	if err := errs.Err(); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	occs, err := freeze.Calendar(r.Context(), env, from, to)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, occs)
+}
+
+/* ===================== EXEMPTIONS ===================== */
+
+func RequestFreezeExemption(w http.ResponseWriter, r *http.Request) {
+	windowID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid freeze window id")
+		return
+	}
+
+	// 🔐 The requester is who DecideFreezeExemption keeps from approving
+	by, ok := principal(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, CodeUnauthorized, "requesting an exemption needs a signed-in user")
+		return
+	}
+
+	var ex model.FreezeExemption
+	if err := json.NewDecoder(r.Body).Decode(&ex); err != nil {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.FreezeExemption(&ex); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	win, err := freeze.GetWindow(r.Context(), windowID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if win.Environment != "" && win.Environment != ex.Environment {
+		var errs validate.Errors
+		errs.Add("environment", "window %d only covers %s", win.ID, win.Environment)
+		writeServiceError(w, r, errs)
+		return
+	}
+
+	ex.WindowID = win.ID
+	ex.RequestedBy = by
+	if err := freeze.RequestExemption(r.Context(), &ex); err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, ex)
+}
+
+func GetFreezeExemptions(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+
+	var errs validate.Errors
+	errs.OneOf("status", status, validate.ExemptionStatuses...)
+	if err := errs.Err(); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	exemptions, err := freeze.ListExemptions(r.Context(), status)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, exemptions)
+}
+
+// DecideFreezeExemption serves POST /api/v1/freeze-exemptions/{id}:approve
+// and {id}:reject, like DecideApproval.
+func DecideFreezeExemption(w http.ResponseWriter, r *http.Request) {
+	idStr, verb, ok := strings.Cut(r.PathValue("action"), ":")
+	if !ok || (verb != "approve" && verb != "reject") {
+		writeError(w, r, http.StatusNotFound, CodeNotFound, "expected /freeze-exemptions/{id}:approve or /freeze-exemptions/{id}:reject")
+		return
+	}
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid exemption id")
+		return
+	}
+
+	// 🔐 Four eyes: the decider must be a signed-in user, never a header the
+	// client chose
+	by, ok := principal(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, CodeUnauthorized, "deciding an exemption needs a signed-in user")
+		return
+	}
+
+	if err := freeze.DecideExemption(r.Context(), id, verb == "approve", by); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "exemption " + verb + "d"})
+}
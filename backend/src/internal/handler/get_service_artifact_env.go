@@ -1,12 +1,11 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
-	"strings"
 	"time"
 
 	"src/src/internal/db"
+	"src/src/internal/validate"
 )
 
 type ArtifactResponse struct {
@@ -15,24 +14,15 @@ type ArtifactResponse struct {
 }
 
 func GetServiceArtifacts(w http.ResponseWriter, r *http.Request) {
-	// 🔒 Allow GET only
-	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	// Expected path: /services/{serviceName}/artifacts
-	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
-	if len(parts) != 3 || parts[0] != "artifact-by-env" || parts[2] != "artifacts" {
-		http.Error(w, "invalid path", http.StatusBadRequest)
-		return
-	}
-	serviceName := parts[1]
+	// GET /api/v1/services/{name}/artifacts?environment=
+	serviceName := r.PathValue("name")
 
 	// Query param: environment
 	environment := r.URL.Query().Get("environment")
-	if environment == "" {
-		http.Error(w, "environment query param is required", http.StatusBadRequest)
+	var errs validate.Errors
+	errs.Environment("environment", environment, validate.ArtifactEnvironments)
+	if err := errs.Err(); err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
 
@@ -44,7 +34,7 @@ func GetServiceArtifacts(w http.ResponseWriter, r *http.Request) {
 		serviceName, environment,
 	)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeInternalError(w, r, err)
 		return
 	}
 	defer rows.Close()
@@ -54,17 +44,16 @@ func GetServiceArtifacts(w http.ResponseWriter, r *http.Request) {
 	for rows.Next() {
 		var a ArtifactResponse
 		if err := rows.Scan(&a.Version, &a.CreatedAt); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeInternalError(w, r, err)
 			return
 		}
 		artifacts = append(artifacts, a)
 	}
 
 	if len(artifacts) == 0 {
-		http.Error(w, "no artifacts found for service/environment", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, CodeNotFound, "no artifacts found for service/environment")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(artifacts)
+	writeJSON(w, http.StatusOK, artifacts)
 }
\ No newline at end of file
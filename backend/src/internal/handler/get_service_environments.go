@@ -1,27 +1,13 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
-	"strings"
 	"src/src/internal/db"
 )
 
 func GetServiceEnvironments(w http.ResponseWriter, r *http.Request) {
-	// 🔒 Allow GET only
-	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	// Expected path: /services/{serviceName}/environments
-	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
-	if len(parts) != 3 {
-		http.Error(w, "invalid path", http.StatusBadRequest)
-		return
-	}
-
-	serviceName := parts[1]
+	// GET /api/v1/services/{name}/environments
+	serviceName := r.PathValue("name")
 
 	rows, err := db.DB.Query(
 		`SELECT DISTINCT environment
@@ -30,7 +16,7 @@ func GetServiceEnvironments(w http.ResponseWriter, r *http.Request) {
 		serviceName,
 	)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeInternalError(w, r, err)
 		return
 	}
 	defer rows.Close()
@@ -40,18 +26,17 @@ func GetServiceEnvironments(w http.ResponseWriter, r *http.Request) {
 	for rows.Next() {
 		var env string
 		if err := rows.Scan(&env); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeInternalError(w, r, err)
 			return
 		}
 		environments = append(environments, env)
 	}
 
 	if len(environments) == 0 {
-		http.Error(w, "service not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, CodeNotFound, "service not found")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(environments)
+	writeJSON(w, http.StatusOK, environments)
 }
 
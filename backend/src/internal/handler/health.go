@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ReadinessCheck is one dependency probed by /readyz.
+type ReadinessCheck struct {
+	Name  string
+	Probe func(ctx context.Context) error
+}
+
+var (
+	readinessChecks  []ReadinessCheck
+	readinessTimeout = 3 * time.Second
+	draining         atomic.Bool
+)
+
+// SetReadinessChecks installs the checks /readyz runs, each bounded by timeout.
+func SetReadinessChecks(timeout time.Duration, checks ...ReadinessCheck) {
+	readinessTimeout = timeout
+	readinessChecks = checks
+}
+
+// StartDraining makes /readyz fail so load balancers stop sending new
+// requests while in-flight ones finish.
+func StartDraining() {
+	draining.Store(true)
+}
+
+// Healthz is the liveness probe: the process is up and serving HTTP.
+func Healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// Readyz is the readiness probe: every dependency check must pass.
+func Readyz(w http.ResponseWriter, r *http.Request) {
+	status := http.StatusOK
+	body := struct {
+		Status string            `json:"status"`
+		Checks map[string]string `json:"checks,omitempty"`
+	}{Status: "ok"}
+
+	if draining.Load() {
+		status, body.Status = http.StatusServiceUnavailable, "shutting down"
+	} else {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		defer cancel()
+
+		body.Checks = runReadinessChecks(ctx)
+		for _, result := range body.Checks {
+			if result != "ok" {
+				status, body.Status = http.StatusServiceUnavailable, "unavailable"
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// runReadinessChecks probes all dependencies concurrently.
+func runReadinessChecks(ctx context.Context) map[string]string {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]string, len(readinessChecks))
+	)
+
+	for _, c := range readinessChecks {
+		wg.Add(1)
+		go func(c ReadinessCheck) {
+			defer wg.Done()
+
+			result := "ok"
+			if err := c.Probe(ctx); err != nil {
+				result = err.Error()
+			}
+
+			mu.Lock()
+			results[c.Name] = result
+			mu.Unlock()
+		}(c)
+	}
+
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"src/src/internal/healthcheck"
+	"src/src/internal/model"
+	"src/src/internal/validate"
+)
+
+/* ===================== HEALTH CHECKS ===================== */
+
+// GetHealthChecks lists the post-deploy health checks of a service.
+func GetHealthChecks(w http.ResponseWriter, r *http.Request) {
+	checks, err := healthcheck.List(r.Context(), r.PathValue("name"))
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, checks)
+}
+
+// PutHealthCheck sets the check run after every deploy of a service to
+// one environment. Omitted numbers take the defaults.
+func PutHealthCheck(w http.ResponseWriter, r *http.Request) {
+	var c model.HealthCheck
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid request body")
+		return
+	}
+	c.ServiceName = r.PathValue("name")
+	c.Environment = r.PathValue("env")
+
+	if err := validate.HealthCheck(&c); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	c.UpdatedBy = actor(r)
+	if err := healthcheck.Put(r.Context(), &c); err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, c)
+}
+
+func DeleteHealthCheck(w http.ResponseWriter, r *http.Request) {
+	if err := healthcheck.Delete(r.Context(), r.PathValue("name"), r.PathValue("env"), actor(r)); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "health check deleted"})
+}
+
+// GetHealthCheckRuns lists a service's latest deploy verifications,
+// including why a deploy was rolled back.
+func GetHealthCheckRuns(w http.ResponseWriter, r *http.Request) {
+	runs, err := healthcheck.Runs(r.Context(), r.PathValue("name"))
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, runs)
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func readyz(t *testing.T) (int, map[string]interface{}) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	Readyz(rec, httptest.NewRequest("GET", "/readyz", nil))
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	return rec.Code, body
+}
+
+func setChecks(t *testing.T, timeout time.Duration, checks ...ReadinessCheck) {
+	t.Helper()
+
+	prevChecks, prevTimeout := readinessChecks, readinessTimeout
+	SetReadinessChecks(timeout, checks...)
+	t.Cleanup(func() { SetReadinessChecks(prevTimeout, prevChecks...) })
+}
+
+func pass(context.Context) error { return nil }
+
+func TestReadyz(t *testing.T) {
+	setChecks(t, time.Second,
+		ReadinessCheck{Name: "mysql", Probe: pass},
+		ReadinessCheck{Name: "secrets", Probe: pass},
+	)
+
+	code, body := readyz(t)
+	if code != http.StatusOK || body["status"] != "ok" {
+		t.Fatalf("%d %v", code, body)
+	}
+	checks := body["checks"].(map[string]interface{})
+	if checks["mysql"] != "ok" || checks["secrets"] != "ok" {
+		t.Errorf("checks = %v", checks)
+	}
+}
+
+func TestReadyzFailingCheck(t *testing.T) {
+	setChecks(t, time.Second,
+		ReadinessCheck{Name: "mysql", Probe: pass},
+		ReadinessCheck{Name: "github", Probe: func(context.Context) error { return errors.New("bad credentials") }},
+	)
+
+	code, body := readyz(t)
+	if code != http.StatusServiceUnavailable || body["status"] != "unavailable" {
+		t.Fatalf("%d %v", code, body)
+	}
+	checks := body["checks"].(map[string]interface{})
+	if checks["github"] != "bad credentials" || checks["mysql"] != "ok" {
+		t.Errorf("checks = %v", checks)
+	}
+}
+
+func TestReadyzSlowCheckTimesOut(t *testing.T) {
+	setChecks(t, 50*time.Millisecond, ReadinessCheck{Name: "jenkins", Probe: func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(10 * time.Second):
+			return nil
+		}
+	}})
+
+	start := time.Now()
+	code, body := readyz(t)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("readyz took %s", elapsed)
+	}
+	if code != http.StatusServiceUnavailable {
+		t.Errorf("%d %v", code, body)
+	}
+}
+
+func TestReadyzDraining(t *testing.T) {
+	setChecks(t, time.Second, ReadinessCheck{Name: "mysql", Probe: pass})
+	StartDraining()
+	t.Cleanup(func() { draining.Store(false) })
+
+	code, body := readyz(t)
+	if code != http.StatusServiceUnavailable || body["status"] != "shutting down" {
+		t.Errorf("%d %v", code, body)
+	}
+
+	// Liveness is unaffected: the process is still serving
+	rec := httptest.NewRecorder()
+	Healthz(rec, httptest.NewRequest("GET", "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("/healthz while draining = %d", rec.Code)
+	}
+}
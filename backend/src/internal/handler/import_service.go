@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"src/src/internal/model"
+	"src/src/internal/service"
+	"src/src/internal/validate"
+)
+
+func ImportService(w http.ResponseWriter, r *http.Request) {
+	log.Println("📥 import service request received")
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+
+	var req model.ImportServiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid json body")
+		return
+	}
+
+	if err := validate.ImportService(&req); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	resp, err := service.ImportService(req)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, resp)
+}
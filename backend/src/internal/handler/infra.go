@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"src/src/internal/infra"
+	"src/src/internal/model"
+	"src/src/internal/validate"
+)
+
+/* ===================== INFRASTRUCTURE ===================== */
+
+// GetInfra lists a service's Terraform root modules with their state
+// location and latest plan and apply.
+func GetInfra(w http.ResponseWriter, r *http.Request) {
+	stacks, err := infra.Stacks(r.Context(), r.PathValue("name"))
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stacks)
+}
+
+// GetInfraRuns lists a service's latest plans and applies.
+func GetInfraRuns(w http.ResponseWriter, r *http.Request) {
+	runs, err := infra.Runs(r.Context(), r.PathValue("name"))
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, runs)
+}
+
+// GetInfraRun returns a plan or apply with its output.
+func GetInfraRun(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid run id")
+		return
+	}
+
+	run, err := infra.Run(r.Context(), r.PathValue("name"), id)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, run)
+}
+
+// StartInfraRun plans or applies one environment through the service's CI
+// system. The run is accepted once its pipeline is dispatched; its result
+// arrives later through ReportInfraRun.
+func StartInfraRun(w http.ResponseWriter, r *http.Request) {
+	var req model.InfraRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.InfraRun(&req); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	run, err := infra.Start(r.Context(), r.PathValue("name"), req.Environment, req.Action, actor(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, run)
+}
+
+// ReportInfraRun is called by the plan/apply pipeline with its result.
+func ReportInfraRun(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid run id")
+		return
+	}
+
+	// Plan output is kept up to its last 512KB; allow some headroom
+	r.Body = http.MaxBytesReader(w, r.Body, 8<<20)
+	var res model.InfraRunResult
+	if err := json.NewDecoder(r.Body).Decode(&res); err != nil {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid json body")
+		return
+	}
+	if err := validate.InfraRunResult(&res); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	if err := infra.Report(r.Context(), id, res); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "infra run recorded"})
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"src/src/internal/locks"
+	"src/src/internal/validate"
+)
+
+/* ===================== LIST LOCKS ===================== */
+
+// GetLocks lists active deployment locks, optionally for ?service=.
+func GetLocks(w http.ResponseWriter, r *http.Request) {
+	serviceName := r.URL.Query().Get("service")
+	if serviceName != "" {
+		var errs validate.Errors
+		errs.ServiceName("service", serviceName)
+		if err := errs.Err(); err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+	}
+
+	held, err := locks.List(r.Context(), serviceName)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, held)
+}
+
+/* ===================== FORCE RELEASE ===================== */
+
+// ReleaseLock drops a stuck lock, e.g. after a pipeline died without
+// reporting back. The released lock is returned for the audit trail.
+func ReleaseLock(w http.ResponseWriter, r *http.Request) {
+	serviceName := r.PathValue("name")
+	env := r.PathValue("env")
+
+	var errs validate.Errors
+	errs.ServiceName("name", serviceName)
+	errs.Environment("env", env, validate.Environments)
+	if err := errs.Err(); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	log.Printf("[LOCK] Force release requested: service=%s env=%s by=%s", serviceName, env, actor(r))
+
+	held, err := locks.ForceRelease(r.Context(), serviceName, env, actor(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, held)
+}
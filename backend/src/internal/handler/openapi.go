@@ -0,0 +1,13 @@
+package handler
+
+import (
+	"net/http"
+
+	"src/src/internal/openapi"
+)
+
+// OpenAPI serves the API description.
+func OpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openapi.Spec)
+}
@@ -0,0 +1,955 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/go-sql-driver/mysql"
+
+	"src/src/internal/db"
+	"src/src/internal/openapi"
+)
+
+func loadSpec(t *testing.T) *openapi3.T {
+	t.Helper()
+
+	doc, err := openapi3.NewLoader().LoadFromData(openapi.Spec)
+	if err != nil {
+		t.Fatalf("load spec: %v", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("invalid spec: %v", err)
+	}
+	return doc
+}
+
+var specParam = regexp.MustCompile(`\{[^}]+\}`)
+
+// Every documented operation must reach a registered route with the same
+// method, and every registered route must be documented.
+func TestSpecCoversRoutes(t *testing.T) {
+	doc := loadSpec(t)
+	mux := NewRouter()
+	documented := map[string]bool{}
+
+	for path, item := range doc.Paths {
+		for method := range item.Operations() {
+			req := httptest.NewRequest(method, specParam.ReplaceAllString(path, "1"), nil)
+
+			_, pattern := mux.Handler(req)
+			if !strings.HasPrefix(pattern, method+" ") {
+				t.Errorf("%s %s is documented but not routed (matched %q)", method, path, pattern)
+				continue
+			}
+			documented[pattern] = true
+		}
+	}
+
+	for _, rt := range Routes() {
+		if !documented[rt.Pattern()] {
+			t.Errorf("route %s is not documented in openapi.json", rt.Pattern())
+		}
+	}
+}
+
+type specCase struct {
+	name        string
+	method      string
+	path        string
+	contentType string
+	body        string
+	expect      func(m sqlmock.Sqlmock)
+	status      int
+	invalidReq  bool   // deliberately violates the spec to exercise an error response
+	user        string // X-Forwarded-User set by the trusted proxy
+}
+
+var now = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+var serviceColumns = []string{
+	"id", "service_name", "repo_name", "owner_team", "runtime", "cicd_type",
+	"template_version", "deploy_type", "repo_mode", "repo_path", "environment", "status",
+}
+
+const validServiceYAML = `serviceName: orders
+repoName: orders
+ownerTeam: payments
+runtime: go
+cicdType: github
+templateVersion: v1
+deploytype: microservice
+environments: [dev, prod]
+`
+
+// targetRows is what deploy.Lookup reads for orders.
+func targetRows(deployType string) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"cicd_type", "deploy_type", "owner_team", "repo_owner", "repo_name", "repo_path"}).
+		AddRow("github", deployType, "payments", "acme", "orders", "")
+}
+
+var lockColumns = []string{
+	"service_name", "environment", "operation", "owner", "reason", "acquired_at", "expires_at",
+}
+
+// lockRows is a deploy of orders/dev that is still running.
+func lockRows() *sqlmock.Rows {
+	acquired := time.Now().UTC().Truncate(time.Second)
+	return sqlmock.NewRows(lockColumns).
+		AddRow("orders", "dev", "deploy", "alice", "", acquired, acquired.Add(30*time.Minute))
+}
+
+func expectLockAcquired(m sqlmock.Sqlmock) {
+	m.ExpectBegin()
+	m.ExpectExec("DELETE FROM deployment_locks").WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec("INSERT INTO deployment_locks").WillReturnResult(sqlmock.NewResult(0, 1))
+	m.ExpectCommit()
+}
+
+// tomorrow is a valid scheduledAt.
+var tomorrow = time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+
+var freezeColumns = []string{
+	"id", "name", "environment", "owner_team", "schedule", "duration_minutes", "timezone",
+	"starts_at", "ends_at", "reason", "created_by", "created_at",
+}
+
+// freezeRows is a global one-off freeze running from an hour ago to
+// tomorrow.
+func freezeRows() *sqlmock.Rows {
+	start := time.Now().UTC().Add(-time.Hour)
+	return sqlmock.NewRows(freezeColumns).
+		AddRow(3, "quarter close", "", "", "", 0, "UTC", start, start.Add(24*time.Hour), "Q2 books", "bob", start)
+}
+
+func expectFreezeCheck(m sqlmock.Sqlmock, windows *sqlmock.Rows) {
+	m.ExpectQuery("SELECT owner_team FROM services").WithArgs("orders").
+		WillReturnRows(sqlmock.NewRows([]string{"owner_team"}).AddRow("payments"))
+	m.ExpectQuery("FROM freeze_windows").WithArgs("dev", "payments").WillReturnRows(windows)
+}
+
+func expectNoFreeze(m sqlmock.Sqlmock) {
+	m.ExpectQuery("SELECT owner_team FROM services").
+		WillReturnRows(sqlmock.NewRows([]string{"owner_team"}).AddRow("payments"))
+	m.ExpectQuery("FROM freeze_windows").WillReturnRows(sqlmock.NewRows(freezeColumns))
+}
+
+var healthCheckColumns = []string{
+	"service_name", "environment", "url", "expected_status", "window_seconds",
+	"interval_seconds", "timeout_seconds", "failure_threshold", "updated_by", "updated_at",
+}
+
+var rolloutColumns = []string{
+	"id", "service_name", "environment", "strategy", "steps", "current_step", "step_interval_seconds",
+	"version", "previous_version", "status", "next_step_at", "started_by", "created_at", "finished_at",
+}
+
+var rolloutStepColumns = []string{
+	"rollout_id", "step", "phase", "traffic_percent", "status", "triggered_by", "triggered_at", "finished_at", "error",
+}
+
+// canaryRows is a 10/50/100 canary of orders/dev from 1.2.0 to 1.3.0 whose
+// first step is running.
+func canaryRows(status string) *sqlmock.Rows {
+	return sqlmock.NewRows(rolloutColumns).
+		AddRow(12, "orders", "dev", "canary", "10,50,100", 1, 600, "1.3.0", "1.2.0", status, nil, "alice", now, nil)
+}
+
+func canaryStepRows() *sqlmock.Rows {
+	return sqlmock.NewRows(rolloutStepColumns).
+		AddRow(12, 1, "step", 10, "running", "alice", now, nil, "")
+}
+
+var scheduledColumns = []string{
+	"id", "service_name", "environment", "scheduled_at", "approval_id", "status", "reason",
+	"requested_by", "last_error", "created_at", "executed_at",
+}
+
+func scheduledRows(status string) *sqlmock.Rows {
+	return sqlmock.NewRows(scheduledColumns).
+		AddRow(9, "orders", "prod", now.Add(14*time.Hour), 7, status, "", "alice", "", now, nil)
+}
+
+var infraRunColumns = []string{
+	"id", "service_name", "environment", "action", "status", "state_location", "run_url",
+	"requested_by", "created_at", "finished_at",
+}
+
+const ordersDevState = "s3://acme-terraform-state/services/orders/dev/terraform.tfstate"
+
+func infraPlanRows(status string) *sqlmock.Rows {
+	return sqlmock.NewRows(infraRunColumns).
+		AddRow(21, "orders", "dev", "plan", status, ordersDevState, "https://github.com/acme/orders/actions/runs/1", "alice", now, now)
+}
+
+var configColumns = []string{
+	"service_name", "environment", "version", "config_values", "file", "delivery", "status",
+	"commit_sha", "pull_request_url", "error", "message", "updated_by", "updated_at",
+}
+
+func configRows(version int, values string) *sqlmock.Rows {
+	return sqlmock.NewRows(configColumns).
+		AddRow("orders", "dev", version, values, "config.dev.json", "commit", "committed", "3f2a9c1", "", "", "", "alice", now)
+}
+
+var previewColumns = []string{
+	"service_name", "environment", "pull_request", "repo_owner", "repo_name", "head_ref", "head_sha",
+	"base_ref", "status", "version", "url", "error", "created_at", "updated_at", "expires_at", "closed_at",
+}
+
+func previewRows(status string, closedAt any) *sqlmock.Rows {
+	return sqlmock.NewRows(previewColumns).
+		AddRow("orders", "pr-42", 42, "acme", "orders", "feature/cart", "3f2a9c1d5e", "dev", status,
+			"myservice/orders-3f2a9c1-1234", "https://orders-pr-42.preview.example.com", "", now, now, now.Add(72*time.Hour), closedAt)
+}
+
+var specCases = []specCase{
+	{name: "healthz", method: "GET", path: "/healthz", status: 200},
+	{name: "readyz", method: "GET", path: "/readyz", status: 200},
+	{name: "openapi", method: "GET", path: "/openapi.json", status: 200},
+
+	{
+		name: "list services", method: "GET", path: "/api/v1/services", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM services s").WillReturnRows(sqlmock.NewRows(serviceColumns).
+				AddRow(1, "orders", "orders", "payments", "go", "github", "v1", "microservice", "standalone", nil, "dev", "success").
+				AddRow(1, "orders", "orders", "payments", "go", "github", "v1", "microservice", "standalone", nil, "prod", "not_deployed"))
+		},
+	},
+	{
+		name: "list services empty", method: "GET", path: "/api/v1/services", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM services s").WillReturnRows(sqlmock.NewRows(serviceColumns))
+		},
+	},
+	{
+		name: "list services legacy", method: "GET", path: "/services", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM services s").WillReturnRows(sqlmock.NewRows(serviceColumns))
+		},
+	},
+	{
+		name: "create service wrong content type", method: "POST", path: "/api/v1/services",
+		contentType: "application/json", body: `{}`, status: 415, invalidReq: true,
+	},
+	{
+		name: "create service invalid yaml", method: "POST", path: "/api/v1/services",
+		contentType: "application/x-yaml", body: "serviceName: [", status: 400, invalidReq: true,
+	},
+	{
+		name: "create service missing fields", method: "POST", path: "/api/v1/services",
+		contentType: "application/x-yaml", body: "serviceName: orders\n", status: 400, invalidReq: true,
+	},
+	{
+		name: "create service already exists", method: "POST", path: "/api/v1/services", status: 409,
+		contentType: "application/x-yaml", body: validServiceYAML,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectBegin()
+			m.ExpectQuery("SELECT EXISTS").WithArgs("orders").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			m.ExpectRollback()
+		},
+	},
+	{
+		name: "import service missing fields", method: "POST", path: "/api/v1/services:import",
+		contentType: "application/json", body: `{"serviceName":"orders"}`, status: 400, invalidReq: true,
+	},
+	{
+		name: "dashboard", method: "GET", path: "/api/v1/services/orders/dashboard", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM environment_state").WithArgs("orders").WillReturnRows(
+				sqlmock.NewRows([]string{"environment", "version", "status", "deployed_at"}).
+					AddRow("dev", "1.2.0", "success", now))
+		},
+	},
+	{
+		name: "dashboard not found", method: "GET", path: "/servicesdashboard/orders/dashboard", status: 404,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM environment_state").WillReturnRows(
+				sqlmock.NewRows([]string{"environment", "version", "status", "deployed_at"}))
+		},
+	},
+	{
+		name: "environments", method: "GET", path: "/api/v1/services/orders/environments", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("SELECT DISTINCT environment").WillReturnRows(
+				sqlmock.NewRows([]string{"environment"}).AddRow("dev").AddRow("test"))
+		},
+	},
+	{
+		name: "artifacts", method: "GET", path: "/api/v1/services/orders/artifacts?environment=dev", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM artifacts").WithArgs("orders", "dev").WillReturnRows(
+				sqlmock.NewRows([]string{"version", "created_at"}).AddRow("1.2.0", now))
+		},
+	},
+	{
+		name: "artifacts missing environment", method: "GET", path: "/api/v1/services/orders/artifacts",
+		status: 400, invalidReq: true,
+	},
+	{
+		name: "branch protection unknown service", method: "GET", path: "/api/v1/services/orders/branch-protection", status: 404,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM services").WillReturnRows(sqlmock.NewRows([]string{"repo_owner"}))
+		},
+	},
+	{
+		name: "deploy prod waits for approval", method: "POST", path: "/api/v1/services/orders/deployments",
+		contentType: "application/json", body: `{"environment":"prod"}`, status: 202,
+		expect: func(m sqlmock.Sqlmock) {
+			expectNoFreeze(m)
+			m.ExpectBegin()
+			m.ExpectQuery("FROM deployment_approvals").WithArgs("orders", "prod").
+				WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			m.ExpectExec("INSERT INTO deployment_approvals").WithArgs("orders", "prod").
+				WillReturnResult(sqlmock.NewResult(7, 1))
+			m.ExpectCommit()
+		},
+	},
+	{
+		name: "deploy prod already pending", method: "POST", path: "/api/v1/services/orders/deployments",
+		contentType: "application/json", body: `{"environment":"prod"}`, status: 409,
+		expect: func(m sqlmock.Sqlmock) {
+			expectNoFreeze(m)
+			m.ExpectBegin()
+			m.ExpectQuery("FROM deployment_approvals").WithArgs("orders", "prod").
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+			m.ExpectRollback()
+		},
+	},
+	{
+		name: "deploy invalid environment", method: "POST", path: "/deploy-services/orders/deploy",
+		contentType: "application/json", body: `{"environment":"qa"}`, status: 400, invalidReq: true,
+	},
+	{
+		name: "legacy deploy stub", method: "POST", path: "/services/orders/deploy/dev", status: 202,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectExec("INSERT INTO deployments").WillReturnResult(sqlmock.NewResult(1, 1))
+		},
+	},
+	{
+		name: "rollback unknown version", method: "POST", path: "/api/v1/services/orders/rollbacks",
+		contentType: "application/json", body: `{"environment":"dev","version":"0.9.0"}`, status: 400,
+		expect: func(m sqlmock.Sqlmock) {
+			expectNoFreeze(m)
+			expectLockAcquired(m)
+			m.ExpectQuery("FROM artifacts").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			m.ExpectExec("DELETE FROM deployment_locks").WillReturnResult(sqlmock.NewResult(0, 1))
+		},
+	},
+	{
+		name: "rollback while locked", method: "POST", path: "/api/v1/services/orders/rollbacks",
+		contentType: "application/json", body: `{"environment":"dev","version":"0.9.0","reason":"bad release"}`, status: 409,
+		expect: func(m sqlmock.Sqlmock) {
+			expectNoFreeze(m)
+			m.ExpectBegin()
+			m.ExpectExec("DELETE FROM deployment_locks").WillReturnResult(sqlmock.NewResult(0, 0))
+			m.ExpectExec("INSERT INTO deployment_locks").WillReturnError(&mysql.MySQLError{Number: 1062})
+			m.ExpectQuery("FOR UPDATE").WithArgs("orders", "dev").WillReturnRows(lockRows())
+			m.ExpectRollback()
+		},
+	},
+	{
+		name: "secrets unknown service", method: "PUT", path: "/api/v1/services/orders/secrets/dev",
+		contentType: "application/json", body: `{"secrets":[{"name":"API_KEY","value":"x"}]}`, status: 404,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM services").WillReturnRows(sqlmock.NewRows([]string{"cicd_type"}))
+		},
+	},
+	{
+		name: "register artifact", method: "POST", path: "/api/v1/artifacts", status: 201,
+		contentType: "application/json",
+		body:        `{"serviceName":"orders","environment":"dev","version":"1.2.0","artifactType":"docker","commitSha":"3f2a9c1","pipeline":"github","action":"deploy","status":"success"}`,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectBegin()
+			m.ExpectExec("INSERT INTO artifacts").WillReturnResult(sqlmock.NewResult(1, 1))
+			m.ExpectQuery("FROM rollouts").WithArgs("orders", "dev").WillReturnRows(sqlmock.NewRows(rolloutColumns))
+			m.ExpectExec("REPLACE INTO environment_state").WithArgs("orders", "dev", "1.2.0", "success", sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			m.ExpectCommit()
+			m.ExpectExec("DELETE FROM deployment_locks").WithArgs("orders", "dev").WillReturnResult(sqlmock.NewResult(0, 1))
+			m.ExpectQuery("FROM health_checks").WithArgs("orders", "dev").WillReturnRows(sqlmock.NewRows(healthCheckColumns))
+		},
+	},
+	{
+		name: "register artifact of a canary step", method: "POST", path: "/api/v1/artifacts", status: 201,
+		contentType: "application/json",
+		body:        `{"serviceName":"orders","environment":"dev","version":"1.3.0","artifactType":"docker","commitSha":"9b1e0d4","pipeline":"github","action":"deploy","status":"success"}`,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectBegin()
+			m.ExpectExec("INSERT INTO artifacts").WillReturnResult(sqlmock.NewResult(2, 1))
+			m.ExpectQuery("FROM rollouts").WithArgs("orders", "dev").WillReturnRows(canaryRows("progressing"))
+			m.ExpectExec("UPDATE rollout_steps").WithArgs(sqlmock.AnyArg(), int64(12), 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			m.ExpectExec("UPDATE rollouts").
+				WithArgs("waiting", "1.3.0", sqlmock.AnyArg(), nil, int64(12)).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			m.ExpectCommit()
+			m.ExpectExec("DELETE FROM deployment_locks").WithArgs("orders", "dev").WillReturnResult(sqlmock.NewResult(0, 1))
+			// not the last step: the environment stays on 1.2.0, no health check yet
+		},
+	},
+	{
+		name: "register artifact of the last canary step", method: "POST", path: "/api/v1/artifacts", status: 201,
+		contentType: "application/json",
+		body:        `{"serviceName":"orders","environment":"dev","version":"1.3.0","artifactType":"docker","commitSha":"9b1e0d4","pipeline":"github","action":"deploy","status":"success"}`,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectBegin()
+			m.ExpectExec("INSERT INTO artifacts").WillReturnResult(sqlmock.NewResult(3, 1))
+			m.ExpectQuery("FROM rollouts").WithArgs("orders", "dev").WillReturnRows(sqlmock.NewRows(rolloutColumns).
+				AddRow(12, "orders", "dev", "canary", "10,50,100", 3, 600, "1.3.0", "1.2.0", "progressing", nil, "alice", now, nil))
+			m.ExpectExec("UPDATE rollout_steps").WithArgs(sqlmock.AnyArg(), int64(12), 3).WillReturnResult(sqlmock.NewResult(0, 1))
+			m.ExpectExec("UPDATE rollouts").
+				WithArgs("completed", "1.3.0", nil, sqlmock.AnyArg(), int64(12)).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			m.ExpectExec("REPLACE INTO environment_state").WithArgs("orders", "dev", "1.3.0", "success", sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			m.ExpectCommit()
+			m.ExpectExec("DELETE FROM deployment_locks").WithArgs("orders", "dev").WillReturnResult(sqlmock.NewResult(0, 1))
+			m.ExpectQuery("FROM health_checks").WithArgs("orders", "dev").WillReturnRows(sqlmock.NewRows(healthCheckColumns))
+		},
+	},
+	{
+		name: "register artifact with a failing rollout update", method: "POST", path: "/api/v1/artifacts", status: 500,
+		contentType: "application/json",
+		body:        `{"serviceName":"orders","environment":"dev","version":"1.3.0","artifactType":"docker","commitSha":"9b1e0d4","pipeline":"github","action":"deploy","status":"success"}`,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectBegin()
+			m.ExpectExec("INSERT INTO artifacts").WillReturnResult(sqlmock.NewResult(4, 1))
+			m.ExpectQuery("FROM rollouts").WithArgs("orders", "dev").WillReturnRows(canaryRows("progressing"))
+			m.ExpectExec("UPDATE rollout_steps").WillReturnError(errors.New("connection reset"))
+			// the report is rolled back with it and the step keeps its lock
+			m.ExpectRollback()
+		},
+	},
+	{
+		name: "approvals", method: "GET", path: "/api/v1/approvals?environment=prod", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM deployment_approvals").WithArgs("prod").WillReturnRows(
+				sqlmock.NewRows([]string{"id", "service_name", "environment", "status", "created_at", "approved_at"}).
+					AddRow(7, "orders", "prod", "pending", now, nil).
+					AddRow(6, "orders", "prod", "approved", now, now))
+		},
+	},
+	{
+		name: "approvals empty", method: "GET", path: "/approvals?environment=prod", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM deployment_approvals").WillReturnRows(
+				sqlmock.NewRows([]string{"id", "service_name", "environment", "status", "created_at", "approved_at"}))
+		},
+	},
+	{
+		name: "reject", method: "POST", path: "/api/v1/approvals/7:reject", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectExec("SET status='rejected'").WithArgs(int64(7)).WillReturnResult(sqlmock.NewResult(0, 1))
+		},
+	},
+	{
+		name: "reject processed", method: "POST", path: "/approvals/7/reject", status: 404,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectExec("SET status='rejected'").WillReturnResult(sqlmock.NewResult(0, 0))
+		},
+	},
+	{
+		name: "approve unknown", method: "POST", path: "/api/v1/approvals/8:approve", status: 404,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectBegin()
+			m.ExpectQuery("FROM deployment_approvals").WithArgs(int64(8)).
+				WillReturnRows(sqlmock.NewRows([]string{"service_name", "environment"}))
+			m.ExpectRollback()
+		},
+	},
+	{
+		name: "list locks", method: "GET", path: "/api/v1/locks?service=orders", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM deployment_locks").WillReturnRows(lockRows())
+		},
+	},
+	{
+		name: "release lock", method: "DELETE", path: "/api/v1/services/orders/locks/dev", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectBegin()
+			m.ExpectQuery("FOR UPDATE").WithArgs("orders", "dev").WillReturnRows(lockRows())
+			m.ExpectExec("DELETE FROM deployment_locks").WithArgs("orders", "dev").WillReturnResult(sqlmock.NewResult(0, 1))
+			m.ExpectCommit()
+		},
+	},
+	{
+		name: "release lock not held", method: "DELETE", path: "/api/v1/services/orders/locks/dev", status: 404,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectBegin()
+			m.ExpectQuery("FOR UPDATE").WillReturnRows(sqlmock.NewRows(lockColumns))
+			m.ExpectRollback()
+		},
+	},
+	{
+		name: "deploy during freeze", method: "POST", path: "/api/v1/services/orders/deployments",
+		contentType: "application/json", body: `{"environment":"dev"}`, status: 409,
+		expect: func(m sqlmock.Sqlmock) {
+			expectFreezeCheck(m, freezeRows())
+			m.ExpectQuery("FROM freeze_exemptions").WithArgs(int64(3), "orders", "dev", sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		},
+	},
+	{
+		name: "emergency rollback without reason", method: "POST", path: "/api/v1/services/orders/rollbacks",
+		contentType: "application/json", body: `{"environment":"dev","version":"0.9.0","emergency":true}`, status: 400,
+	},
+	{
+		name: "emergency rollback during freeze is audited", method: "POST", path: "/api/v1/services/orders/rollbacks",
+		contentType: "application/json", body: `{"environment":"dev","version":"0.9.0","emergency":true,"reason":"checkout down"}`, status: 400,
+		expect: func(m sqlmock.Sqlmock) {
+			expectFreezeCheck(m, freezeRows())
+			m.ExpectQuery("FROM freeze_exemptions").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			m.ExpectExec("INSERT INTO freeze_overrides").
+				WithArgs(int64(3), "orders", "dev", "rollback", sqlmock.AnyArg(), "checkout down").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			expectLockAcquired(m)
+			m.ExpectQuery("FROM artifacts").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			m.ExpectExec("DELETE FROM deployment_locks").WillReturnResult(sqlmock.NewResult(0, 1))
+		},
+	},
+	{
+		name: "create freeze window", method: "POST", path: "/api/v1/freeze-windows", status: 201,
+		contentType: "application/json",
+		body:        `{"name":"month end","environment":"prod","schedule":"FREQ=MONTHLY;BYMONTHDAY=-1;BYHOUR=18","durationMinutes":2880,"timezone":"Europe/Berlin"}`,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectExec("INSERT INTO freeze_windows").WillReturnResult(sqlmock.NewResult(4, 1))
+		},
+	},
+	{
+		name: "create freeze window invalid schedule", method: "POST", path: "/api/v1/freeze-windows", status: 400,
+		contentType: "application/json", body: `{"name":"hourly","schedule":"FREQ=HOURLY","durationMinutes":60}`,
+	},
+	{
+		name: "freeze calendar", method: "GET", path: "/api/v1/freeze-calendar?environment=dev", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM freeze_windows").WithArgs("dev").WillReturnRows(freezeRows())
+		},
+	},
+	{
+		name: "request exemption without a signed-in user", method: "POST", path: "/api/v1/freeze-windows/3/exemptions", status: 401,
+		contentType: "application/json", body: `{"serviceName":"orders","environment":"prod","reason":"hotfix","expiresAt":"` + tomorrow + `"}`,
+	},
+	{
+		name: "approve exemption", method: "POST", path: "/api/v1/freeze-exemptions/5:approve", status: 200,
+		user: "bob",
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectBegin()
+			m.ExpectQuery("FROM freeze_exemptions").WithArgs(int64(5)).
+				WillReturnRows(sqlmock.NewRows([]string{"requested_by"}).AddRow("alice"))
+			m.ExpectExec("UPDATE freeze_exemptions").WithArgs("approved", "bob", int64(5)).WillReturnResult(sqlmock.NewResult(0, 1))
+			m.ExpectCommit()
+		},
+	},
+	{
+		name: "approve own exemption", method: "POST", path: "/api/v1/freeze-exemptions/5:approve", status: 403,
+		user: "alice",
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectBegin()
+			m.ExpectQuery("FROM freeze_exemptions").WithArgs(int64(5)).
+				WillReturnRows(sqlmock.NewRows([]string{"requested_by"}).AddRow("alice"))
+			m.ExpectRollback()
+		},
+	},
+	{
+		name: "approve exemption without a signed-in user", method: "POST", path: "/api/v1/freeze-exemptions/5:approve", status: 401,
+	},
+	{
+		name: "schedule dev deploy", method: "POST", path: "/api/v1/services/orders/deployments", status: 202,
+		contentType: "application/json", body: `{"environment":"dev","scheduledAt":"` + tomorrow + `"}`,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM services").WithArgs("orders").WillReturnRows(
+				targetRows("microservice"))
+			m.ExpectExec("INSERT INTO scheduled_deployments").WillReturnResult(sqlmock.NewResult(9, 1))
+		},
+	},
+	{
+		name: "schedule prod deploy", method: "POST", path: "/api/v1/services/orders/deployments", status: 202,
+		contentType: "application/json", body: `{"environment":"prod","scheduledAt":"` + tomorrow + `"}`,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectBegin()
+			m.ExpectQuery("FROM deployment_approvals").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			m.ExpectExec("INSERT INTO deployment_approvals").WillReturnResult(sqlmock.NewResult(7, 1))
+			m.ExpectExec("INSERT INTO scheduled_deployments").
+				WithArgs("orders", "prod", sqlmock.AnyArg(), int64(7), "", sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(9, 1))
+			m.ExpectCommit()
+		},
+	},
+	{
+		name: "schedule in the past", method: "POST", path: "/api/v1/services/orders/deployments", status: 400,
+		contentType: "application/json", body: `{"environment":"dev","scheduledAt":"2020-01-01T02:00:00Z"}`,
+	},
+	{
+		name: "approve scheduled deploy", method: "POST", path: "/api/v1/approvals/7:approve", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectBegin()
+			m.ExpectQuery("FROM deployment_approvals").WithArgs(int64(7)).
+				WillReturnRows(sqlmock.NewRows([]string{"service_name", "environment"}).AddRow("orders", "prod"))
+			m.ExpectQuery("FROM scheduled_deployments").WithArgs(int64(7)).WillReturnRows(scheduledRows("scheduled"))
+			m.ExpectExec("SET status='approved'").WithArgs(int64(7)).WillReturnResult(sqlmock.NewResult(0, 1))
+			m.ExpectCommit()
+		},
+	},
+	{
+		// The pipeline cannot be started, so the approval stays pending
+		name: "approve with failing trigger", method: "POST", path: "/api/v1/approvals/6:approve", status: 502,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectBegin()
+			m.ExpectQuery("FROM deployment_approvals").WithArgs(int64(6)).
+				WillReturnRows(sqlmock.NewRows([]string{"service_name", "environment"}).AddRow("orders", "prod"))
+			m.ExpectQuery("FROM scheduled_deployments").WithArgs(int64(6)).WillReturnRows(sqlmock.NewRows(scheduledColumns))
+			expectNoFreeze(m)
+			expectLockAcquired(m)
+			m.ExpectQuery("FROM rollouts").WithArgs("orders", "prod").WillReturnRows(sqlmock.NewRows(rolloutColumns))
+			m.ExpectExec("SET status='approved'").WithArgs(int64(6)).WillReturnResult(sqlmock.NewResult(0, 1))
+			m.ExpectQuery("FROM services").WithArgs("orders").WillReturnRows(
+				sqlmock.NewRows([]string{"cicd_type", "deploy_type", "owner_team", "repo_owner", "repo_name", "repo_path"}).
+					AddRow("gitlab", "microservice", "payments", "acme", "orders", ""))
+			m.ExpectQuery("FROM deployment_strategies").WithArgs("orders", "prod").
+				WillReturnRows(sqlmock.NewRows([]string{"service_name"}))
+			m.ExpectExec("DELETE FROM deployment_locks").WillReturnResult(sqlmock.NewResult(0, 1))
+			m.ExpectRollback()
+		},
+	},
+	{
+		name: "list scheduled deployments", method: "GET", path: "/api/v1/services/orders/scheduled-deployments", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM scheduled_deployments").WithArgs("orders").WillReturnRows(scheduledRows("skipped"))
+		},
+	},
+	{
+		name: "cancel scheduled deployment", method: "DELETE", path: "/api/v1/services/orders/scheduled-deployments/9", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectBegin()
+			m.ExpectQuery("FROM scheduled_deployments").WithArgs(int64(9), "orders").
+				WillReturnRows(sqlmock.NewRows([]string{"approval_id"}).AddRow(7))
+			m.ExpectExec("SET status = 'cancelled'").WillReturnResult(sqlmock.NewResult(0, 1))
+			m.ExpectExec("SET status='rejected'").WithArgs(int64(7)).WillReturnResult(sqlmock.NewResult(0, 1))
+			m.ExpectCommit()
+		},
+	},
+	{
+		name: "cancel started deployment", method: "DELETE", path: "/api/v1/services/orders/scheduled-deployments/9", status: 404,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectBegin()
+			m.ExpectQuery("FROM scheduled_deployments").WillReturnRows(sqlmock.NewRows([]string{"approval_id"}))
+			m.ExpectRollback()
+		},
+	},
+	{
+		name: "put health check", method: "PUT", path: "/api/v1/services/orders/health-checks/prod", status: 200,
+		contentType: "application/json", body: `{"url":"https://orders.example.com/healthz","windowSeconds":120}`,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectExec("REPLACE INTO health_checks").
+				WithArgs("orders", "prod", "https://orders.example.com/healthz", 200, 120, 10, 5, 3, "anonymous@192.0.2.1", sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		},
+	},
+	{
+		name: "put health check invalid", method: "PUT", path: "/api/v1/services/orders/health-checks/prod", status: 400,
+		contentType: "application/json", body: `{"url":"orders:8080/healthz","timeoutSeconds":30}`,
+	},
+	{
+		name: "list health checks", method: "GET", path: "/api/v1/services/orders/health-checks", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM health_checks").WithArgs("orders").WillReturnRows(
+				sqlmock.NewRows(healthCheckColumns).
+					AddRow("orders", "prod", "https://orders.example.com/healthz", 200, 300, 10, 5, 3, "alice", now))
+		},
+	},
+	{
+		name: "delete missing health check", method: "DELETE", path: "/api/v1/services/orders/health-checks/dev", status: 404,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectExec("DELETE FROM health_checks").WithArgs("orders", "dev").WillReturnResult(sqlmock.NewResult(0, 0))
+		},
+	},
+	{
+		name: "health check runs", method: "GET", path: "/api/v1/services/orders/health-check-runs", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM health_check_runs").WithArgs("orders").WillReturnRows(
+				sqlmock.NewRows([]string{
+					"id", "service_name", "environment", "version", "previous_version", "status", "detail", "started_at", "finished_at",
+				}).
+					AddRow(4, "orders", "prod", "1.3.0", "1.2.0", "rolled_back", "health check failed: 3 consecutive failures; rolled back to 1.2.0", now, now).
+					AddRow(3, "orders", "prod", "1.2.0", "", "passed", "", now, now))
+		},
+	},
+	{
+		name: "put canary strategy", method: "PUT", path: "/api/v1/services/orders/strategies/prod", status: 200,
+		contentType: "application/json", body: `{"strategy":"canary","canarySteps":[10,50,100],"stepIntervalSeconds":600}`,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM services").WithArgs("orders").WillReturnRows(targetRows("microservice"))
+			m.ExpectExec("REPLACE INTO deployment_strategies").
+				WithArgs("orders", "prod", "canary", "10,50,100", 600, "anonymous@192.0.2.1", sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		},
+	},
+	{
+		name: "put canary strategy for kubernetes", method: "PUT", path: "/api/v1/services/orders/strategies/prod", status: 400,
+		contentType: "application/json", body: `{"strategy":"canary","canarySteps":[10,50,100]}`,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM services").WithArgs("orders").WillReturnRows(targetRows("kubernetes"))
+		},
+	},
+	{
+		name: "put canary strategy not ending at 100", method: "PUT", path: "/api/v1/services/orders/strategies/prod", status: 400,
+		contentType: "application/json", body: `{"strategy":"canary","canarySteps":[10,50]}`,
+	},
+	{
+		name: "list strategies", method: "GET", path: "/api/v1/services/orders/strategies", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM deployment_strategies").WithArgs("orders").WillReturnRows(
+				sqlmock.NewRows([]string{
+					"service_name", "environment", "strategy", "canary_steps", "step_interval_seconds", "updated_by", "updated_at",
+				}).
+					AddRow("orders", "prod", "blue-green", "", 0, "alice", now))
+		},
+	},
+	{
+		name: "deploy during a rollout", method: "POST", path: "/api/v1/services/orders/deployments", status: 409,
+		contentType: "application/json", body: `{"environment":"dev"}`,
+		expect: func(m sqlmock.Sqlmock) {
+			expectNoFreeze(m)
+			m.ExpectQuery("FROM services").WithArgs("orders").WillReturnRows(
+				targetRows("microservice"))
+			expectLockAcquired(m)
+			m.ExpectQuery("FROM rollouts").WithArgs("orders", "dev").WillReturnRows(canaryRows("waiting"))
+			m.ExpectExec("DELETE FROM deployment_locks").WillReturnResult(sqlmock.NewResult(0, 1))
+		},
+	},
+	{
+		name: "list rollouts", method: "GET", path: "/api/v1/services/orders/rollouts", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM rollouts").WithArgs("orders").WillReturnRows(canaryRows("progressing"))
+			m.ExpectQuery("FROM rollout_steps").WithArgs(int64(12)).WillReturnRows(canaryStepRows())
+		},
+	},
+	{
+		name: "promote running step", method: "POST", path: "/api/v1/services/orders/rollouts/12:promote", status: 409,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM rollouts").WithArgs(int64(12), "orders").WillReturnRows(canaryRows("progressing"))
+			m.ExpectQuery("FROM rollout_steps").WithArgs(int64(12)).WillReturnRows(canaryStepRows())
+		},
+	},
+	{
+		name: "pause rollout", method: "POST", path: "/api/v1/services/orders/rollouts/12:pause", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectExec("SET status = 'paused'").WithArgs(int64(12), "orders").WillReturnResult(sqlmock.NewResult(0, 1))
+			m.ExpectQuery("FROM rollouts").WithArgs(int64(12), "orders").WillReturnRows(canaryRows("paused"))
+			m.ExpectQuery("FROM rollout_steps").WithArgs(int64(12)).WillReturnRows(canaryStepRows())
+		},
+	},
+	{
+		name: "abort unknown rollout", method: "POST", path: "/api/v1/services/orders/rollouts/13:abort", status: 404,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM rollouts").WithArgs(int64(13), "orders").WillReturnRows(sqlmock.NewRows(rolloutColumns))
+		},
+	},
+	{
+		name: "create service with infra unconfigured", method: "POST", path: "/api/v1/services", status: 400,
+		contentType: "application/x-yaml",
+		body:        strings.Replace(validServiceYAML, "microservice", "ec2", 1) + "infra: {cpu: \"2\", memory: 8Gi}\n",
+	},
+	{
+		name: "infra stacks", method: "GET", path: "/api/v1/services/orders/infra", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM infra_stacks").WithArgs("orders").WillReturnRows(
+				sqlmock.NewRows([]string{
+					"service_name", "environment", "cloud", "compute", "cpu", "memory", "instance_type",
+					"module_version", "root_module", "state_location", "created_at",
+				}).
+					AddRow("orders", "dev", "aws", "ec2", "2", "4Gi", "t3.medium", "v1", "infra/dev", ordersDevState, now))
+			m.ExpectQuery("FROM infra_runs").WithArgs("orders", "dev", "plan").WillReturnRows(infraPlanRows("succeeded"))
+			m.ExpectQuery("FROM infra_runs").WithArgs("orders", "dev", "apply").WillReturnRows(sqlmock.NewRows(infraRunColumns))
+		},
+	},
+	{
+		name: "apply after failed plan", method: "POST", path: "/api/v1/services/orders/infra-runs", status: 409,
+		contentType: "application/json", body: `{"environment":"dev","action":"apply"}`,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectBegin()
+			m.ExpectQuery("FROM infra_stacks").WithArgs("orders", "dev").WillReturnRows(
+				sqlmock.NewRows([]string{"state_location"}).AddRow(ordersDevState))
+			m.ExpectQuery("FROM infra_runs").WithArgs("orders", "dev").WillReturnRows(
+				sqlmock.NewRows([]string{"id", "action", "status", "created_at"}).AddRow(21, "plan", "failed", now))
+			m.ExpectRollback()
+		},
+	},
+	{
+		name: "infra run with output", method: "GET", path: "/api/v1/services/orders/infra-runs/21", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM infra_runs").WithArgs(int64(21), "orders").WillReturnRows(infraPlanRows("succeeded"))
+			m.ExpectQuery("SELECT COALESCE\\(output").WithArgs(int64(21)).WillReturnRows(
+				sqlmock.NewRows([]string{"output"}).AddRow("Plan: 4 to add, 0 to change, 0 to destroy."))
+		},
+	},
+	{
+		name: "report infra run", method: "POST", path: "/api/v1/infra-runs/21/result", status: 200,
+		contentType: "application/json", body: `{"status":"succeeded","output":"Plan: 4 to add","runUrl":"https://jenkins.example.com/job/orders-infra/job/dev/3/"}`,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectExec("UPDATE infra_runs").
+				WithArgs("succeeded", "Plan: 4 to add", "https://jenkins.example.com/job/orders-infra/job/dev/3/", sqlmock.AnyArg(), int64(21), "running").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		},
+	},
+	{
+		name: "report infra run twice", method: "POST", path: "/api/v1/infra-runs/21/result", status: 409,
+		contentType: "application/json", body: `{"status":"failed"}`,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectExec("UPDATE infra_runs").WillReturnResult(sqlmock.NewResult(0, 0))
+			m.ExpectQuery("SELECT status FROM infra_runs").WithArgs(int64(21)).WillReturnRows(
+				sqlmock.NewRows([]string{"status"}).AddRow("succeeded"))
+		},
+	},
+	{
+		name: "current config", method: "GET", path: "/api/v1/services/orders/config/dev", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM service_configs").WithArgs("orders", "dev", "committed", "proposed").
+				WillReturnRows(configRows(3, `{"LOG_LEVEL":"debug"}`))
+		},
+	},
+	{
+		name: "config diff", method: "GET", path: "/api/v1/services/orders/config/dev/diff?to=3", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM service_configs").WithArgs("orders", "dev", 3).
+				WillReturnRows(configRows(3, `{"LOG_LEVEL":"debug","POOL":"20"}`))
+			m.ExpectQuery("version < \\?").WithArgs("orders", "dev", 3, "committed", "proposed").
+				WillReturnRows(configRows(2, `{"LOG_LEVEL":"info","TIMEOUT":"5s"}`))
+		},
+	},
+	{
+		name: "config changed since base version", method: "PUT", path: "/api/v1/services/orders/config/dev", status: 409,
+		contentType: "application/json", body: `{"values":{"LOG_LEVEL":"info"},"baseVersion":2}`,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM services").WithArgs("orders").WillReturnRows(
+				sqlmock.NewRows([]string{"repo_owner", "repo_name", "repo_path", "environments"}).AddRow("acme", "orders", "", `["dev","prod"]`))
+			m.ExpectBegin()
+			m.ExpectQuery("FOR UPDATE").WithArgs("orders", "dev").WillReturnRows(
+				sqlmock.NewRows([]string{"version", "status", "updated_at"}).AddRow(3, "committed", now))
+			m.ExpectQuery("FROM service_configs").WithArgs("orders", "dev", "committed", "proposed").
+				WillReturnRows(configRows(3, `{"LOG_LEVEL":"debug"}`))
+			m.ExpectRollback()
+		},
+	},
+	{
+		name: "config with reserved key", method: "PUT", path: "/api/v1/services/orders/config/dev", status: 400,
+		contentType: "application/json", body: `{"values":{"repoUrl":"https://example.com"}}`,
+	},
+	{
+		name: "github webhook with previews disabled", method: "POST", path: "/api/v1/webhooks/github", status: 404,
+		contentType: "application/json", body: `{"zen":"Keep it logically awesome."}`,
+	},
+	{
+		name: "list previews", method: "GET", path: "/api/v1/services/orders/previews", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM previews").WithArgs("orders").WillReturnRows(previewRows("ready", nil))
+		},
+	},
+	{
+		name: "delete closed preview", method: "DELETE", path: "/api/v1/services/orders/previews/pr-42", status: 200,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM previews").WithArgs("orders", "pr-42").WillReturnRows(previewRows("closed", now))
+		},
+	},
+	{
+		name: "delete unknown preview", method: "DELETE", path: "/api/v1/services/orders/previews/pr-7", status: 404,
+		expect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("FROM previews").WithArgs("orders", "pr-7").WillReturnRows(sqlmock.NewRows(previewColumns))
+		},
+	},
+}
+
+// Drives the real handlers through the router and checks requests and
+// responses against the spec.
+func TestHandlersMatchSpec(t *testing.T) {
+	doc := loadSpec(t)
+	doc.Servers = openapi3.Servers{{URL: "http://platform.test"}}
+
+	specRouter, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		t.Fatalf("spec router: %v", err)
+	}
+	mux := NewRouter()
+
+	// httptest requests come from 192.0.2.1
+	if err := SetTrustedProxies([]string{"192.0.2.0/24"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetTrustedProxies(nil) })
+
+	for _, c := range specCases {
+		t.Run(c.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mockDB.Close()
+			db.DB = mockDB
+			if c.expect != nil {
+				c.expect(mock)
+			}
+
+			req := httptest.NewRequest(c.method, "http://platform.test"+c.path, strings.NewReader(c.body))
+			if c.contentType != "" {
+				req.Header.Set("Content-Type", c.contentType)
+			}
+			if c.user != "" {
+				req.Header.Set("X-Forwarded-User", c.user)
+			}
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != c.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, c.status, rec.Body.String())
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("db: %v", err)
+			}
+
+			// Validate against the spec
+			specReq := httptest.NewRequest(c.method, "http://platform.test"+c.path, strings.NewReader(c.body))
+			if c.contentType != "" {
+				specReq.Header.Set("Content-Type", c.contentType)
+			}
+
+			route, pathParams, err := specRouter.FindRoute(specReq)
+			if err != nil {
+				t.Fatalf("spec has no operation for %s %s: %v", c.method, c.path, err)
+			}
+
+			reqInput := &openapi3filter.RequestValidationInput{
+				Request:    specReq,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc},
+			}
+			reqErr := openapi3filter.ValidateRequest(context.Background(), reqInput)
+			if c.invalidReq && reqErr == nil {
+				t.Errorf("request was expected to violate the spec")
+			}
+			if !c.invalidReq && reqErr != nil {
+				t.Errorf("request does not match spec: %v", reqErr)
+			}
+
+			respInput := &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: reqInput,
+				Status:                 rec.Code,
+				Header:                 rec.Header(),
+				Body:                   io.NopCloser(strings.NewReader(rec.Body.String())),
+			}
+			if err := openapi3filter.ValidateResponse(context.Background(), respInput); err != nil {
+				t.Errorf("response does not match spec: %v\nbody: %s", err, rec.Body.String())
+			}
+		})
+	}
+}
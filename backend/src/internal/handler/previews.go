@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"src/src/internal/preview"
+)
+
+/* ===================== PREVIEW ENVIRONMENTS ===================== */
+
+// GitHubWebhook receives GitHub's webhook deliveries. pull_request events
+// deploy or tear down the preview environments of the repository's
+// services; the previews touched are returned.
+func GitHubWebhook(w http.ResponseWriter, r *http.Request) {
+	// GitHub caps payloads at 25MB; pull_request events are far smaller
+	r.Body = http.MaxBytesReader(w, r.Body, 5<<20)
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid request body")
+		return
+	}
+
+	if err := preview.Verify(r.Context(), body, r.Header.Get("X-Hub-Signature-256")); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	previews, err := preview.HandleEvent(r.Context(), r.Header.Get("X-GitHub-Event"), body)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, previews)
+}
+
+// GetPreviews lists a service's preview environments, open and closed.
+func GetPreviews(w http.ResponseWriter, r *http.Request) {
+	previews, err := preview.List(r.Context(), r.PathValue("name"))
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, previews)
+}
+
+// DeletePreview tears a preview environment down before its pull request
+// closes.
+func DeletePreview(w http.ResponseWriter, r *http.Request) {
+	p, err := preview.Close(r.Context(), r.PathValue("name"), r.PathValue("env"), actor(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
@@ -2,44 +2,37 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
-	"strings"
 
 	"src/src/internal/cicd"
 	"src/src/internal/db"
+	"src/src/internal/deploy"
+	"src/src/internal/freeze"
+	"src/src/internal/locks"
+	"src/src/internal/validate"
 )
 
 type RollbackRequest struct {
 	Environment string `json:"environment"`
 	Version     string `json:"version"`
+	Reason      string `json:"reason,omitempty"` // recorded on the deployment lock
+	Emergency   bool   `json:"emergency,omitempty"` // override a deploy freeze; needs a reason
 }
 
 func RollbackService(w http.ResponseWriter, r *http.Request) {
 	log.Println("[ROLLBACK] Incoming request")
 
-	// 🔒 Allow POST only
-	if r.Method != http.MethodPost {
-		log.Printf("[ROLLBACK][WARN] Invalid method: %s\n", r.Method)
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	// /rollback-services/{serviceName}/rollback
-	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
-	if len(parts) != 3 || parts[0] != "rollback-services" || parts[2] != "rollback" {
-		log.Printf("[ROLLBACK][WARN] Invalid path: %s\n", r.URL.Path)
-		http.Error(w, "invalid path", http.StatusBadRequest)
-		return
-	}
-	serviceName := parts[1]
+	// POST /api/v1/services/{name}/rollbacks
+	serviceName := r.PathValue("name")
 	log.Printf("[ROLLBACK] Service: %s\n", serviceName)
 
 	// Decode body
 	var req RollbackRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Printf("[ROLLBACK][ERROR] Invalid request body: %v\n", err)
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid request body")
 		return
 	}
 
@@ -50,15 +43,43 @@ func RollbackService(w http.ResponseWriter, r *http.Request) {
 		req.Version,
 	)
 
-	if req.Environment == "" || req.Version == "" {
-		log.Printf("[ROLLBACK][WARN] Missing environment or version\n")
-		http.Error(w, "environment and version are required", http.StatusBadRequest)
+	if err := validate.Rollback(req.Environment, req.Version, req.Reason, req.Emergency); err != nil {
+		log.Printf("[ROLLBACK][WARN] Invalid request: %v\n", err)
+		writeServiceError(w, r, err)
+		return
+	}
+
+	// 🧊 Freezes block rollbacks too, unless declared an emergency
+	if err := freeze.Check(r.Context(), serviceName, req.Environment); err != nil {
+		var frozen *freeze.FrozenError
+		if !req.Emergency || !errors.As(err, &frozen) {
+			log.Printf("[ROLLBACK][WARN] %v\n", err)
+			writeServiceError(w, r, err)
+			return
+		}
+		if err := freeze.RecordOverride(r.Context(), frozen, serviceName, req.Environment, "rollback", actor(r), req.Reason); err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+	}
+
+	// 🔒 Hold the environment while its state is read and CI is triggered
+	lock, err := locks.Acquire(r.Context(), serviceName, req.Environment, "rollback", actor(r), req.Reason)
+	if err != nil {
+		log.Printf("[ROLLBACK][WARN] %v\n", err)
+		writeServiceError(w, r, err)
 		return
 	}
+	triggered := false
+	defer func() {
+		if !triggered {
+			locks.Release(r.Context(), lock)
+		}
+	}()
 
 	// 🔍 Validate artifact exists
 	var exists bool
-	err := db.DB.QueryRow(`
+	err = db.DB.QueryRow(`
 		SELECT EXISTS (
 		  SELECT 1 FROM artifacts
 		  WHERE service_name = ? AND environment = ? AND version = ?
@@ -67,7 +88,7 @@ func RollbackService(w http.ResponseWriter, r *http.Request) {
 	).Scan(&exists)
 	if err != nil {
 		log.Printf("[ROLLBACK][ERROR] DB error checking artifact: %v\n", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeInternalError(w, r, err)
 		return
 	}
 	if !exists {
@@ -77,7 +98,7 @@ func RollbackService(w http.ResponseWriter, r *http.Request) {
 			req.Environment,
 			req.Version,
 		)
-		http.Error(w, "invalid version for environment", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid version for environment")
 		return
 	}
 
@@ -98,7 +119,7 @@ func RollbackService(w http.ResponseWriter, r *http.Request) {
 			req.Environment,
 			err,
 		)
-		http.Error(w, "failed to fetch current environment state", http.StatusInternalServerError)
+		writeInternalError(w, r, err)
 		return
 	}
 
@@ -116,69 +137,31 @@ func RollbackService(w http.ResponseWriter, r *http.Request) {
 			req.Environment,
 			req.Version,
 		)
-		http.Error(w, "this is the current running version", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "this is the current running version")
 		return
 	}
 
 	// 🔍 Get CICD type & repo info
-	var cicdType, repo string
-	err = db.DB.QueryRow(`
-		SELECT cicd_type, repo_name
-		FROM services
-		WHERE service_name = ?
-	`,
-		serviceName,
-	).Scan(&cicdType, &repo)
+	target, err := deploy.Lookup(r.Context(), serviceName)
 	if err != nil {
-		log.Printf("[ROLLBACK][ERROR] Service not found: %s\n", serviceName)
-		http.Error(w, "service not found", http.StatusNotFound)
+		log.Printf("[ROLLBACK][ERROR] Service lookup failed: %s: %v\n", serviceName, err)
+		writeServiceError(w, r, err)
 		return
 	}
 
-	log.Printf(
-		"[ROLLBACK] CICD type=%s repo=%s\n",
-		cicdType,
-		repo,
-	)
-
 	// 🚀 Trigger rollback via CICD
-	switch cicdType {
-	case "jenkins":
-		log.Printf(
-			"[ROLLBACK] Triggering Jenkins rollback: service=%s env=%s version=%s\n",
-			serviceName,
-			req.Environment,
-			req.Version,
-		)
-		err = cicd.TriggerJenkinsRollback(serviceName, req.Environment, req.Version)
-
-	case "github":
-		log.Printf(
-			"[ROLLBACK] Triggering GitHub rollback: repo=%s env=%s version=%s\n",
-			repo,
-			req.Environment,
-			req.Version,
-		)
-		err = cicd.TriggerGitHubRollback(repo, req.Environment, req.Version)
-
-	default:
-		log.Printf(
-			"[ROLLBACK][WARN] Unsupported CICD type: %s\n",
-			cicdType,
-		)
-		http.Error(w, "unsupported cicd type", http.StatusBadRequest)
-		return
-	}
-
-	if err != nil {
+	if err := deploy.Rollback(target, req.Environment, req.Version, cicd.RolloutParams{}); err != nil {
 		log.Printf(
 			"[ROLLBACK][ERROR] CICD trigger failed: %v\n",
 			err,
 		)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusBadGateway, CodeUpstreamFailed, err.Error())
 		return
 	}
 
+	// Lock stays held until the pipeline reports the rollback artifact
+	triggered = true
+
 	// Async response
 	log.Printf(
 		"[ROLLBACK][SUCCESS] Rollback triggered: service=%s env=%s version=%s\n",
@@ -187,6 +170,5 @@ func RollbackService(w http.ResponseWriter, r *http.Request) {
 		req.Version,
 	)
 
-	w.WriteHeader(http.StatusAccepted)
-	w.Write([]byte(`{"message":"rollback triggered"}`))
+	writeJSON(w, http.StatusAccepted, map[string]string{"message": "rollback triggered"})
 }
\ No newline at end of file
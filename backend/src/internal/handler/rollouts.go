@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"src/src/internal/deploy"
+	"src/src/internal/model"
+	"src/src/internal/rollout"
+	"src/src/internal/validate"
+)
+
+/* ===================== DEPLOYMENT STRATEGIES ===================== */
+
+// GetDeploymentStrategies lists the strategies set for a service;
+// environments without one deploy all at once.
+func GetDeploymentStrategies(w http.ResponseWriter, r *http.Request) {
+	strategies, err := rollout.ListStrategies(r.Context(), r.PathValue("name"))
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, strategies)
+}
+
+func PutDeploymentStrategy(w http.ResponseWriter, r *http.Request) {
+	var s model.DeploymentStrategy
+	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid request body")
+		return
+	}
+	s.ServiceName = r.PathValue("name")
+	s.Environment = r.PathValue("env")
+
+	if err := validate.DeploymentStrategy(&s); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	// ☸️ GitOps deploys pin one image; traffic shifting is up to the cluster
+	target, err := deploy.Lookup(r.Context(), s.ServiceName)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if target.DeployType == model.DeployTypeKubernetes && s.Strategy != model.StrategyAllAtOnce {
+		var errs validate.Errors
+		errs.Add("strategy", "kubernetes services deploy all-at-once")
+		writeServiceError(w, r, errs.Err())
+		return
+	}
+
+	s.UpdatedBy = actor(r)
+	if err := rollout.PutStrategy(r.Context(), &s); err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s)
+}
+
+/* ===================== ROLLOUTS ===================== */
+
+// GetRollouts lists a service's latest rollouts with every step they ran.
+func GetRollouts(w http.ResponseWriter, r *http.Request) {
+	rollouts, err := rollout.List(r.Context(), r.PathValue("name"))
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, rollouts)
+}
+
+// RolloutAction serves POST /api/v1/services/{name}/rollouts/{id}:promote,
+// {id}:pause and {id}:abort.
+func RolloutAction(w http.ResponseWriter, r *http.Request) {
+	idStr, verb, ok := strings.Cut(r.PathValue("action"), ":")
+	if !ok {
+		writeError(w, r, http.StatusNotFound, CodeNotFound, "expected /rollouts/{id}:promote, {id}:pause or {id}:abort")
+		return
+	}
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid rollout id")
+		return
+	}
+
+	serviceName := r.PathValue("name")
+	var ro *model.Rollout
+	switch verb {
+	case "promote":
+		ro, err = rollout.Promote(r.Context(), serviceName, id, actor(r))
+	case "pause":
+		ro, err = rollout.Pause(r.Context(), serviceName, id, actor(r))
+	case "abort":
+		ro, err = rollout.Abort(r.Context(), serviceName, id, actor(r))
+	default:
+		writeError(w, r, http.StatusNotFound, CodeNotFound, "unknown rollout action: "+verb)
+		return
+	}
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ro)
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+)
+
+// Route is one ServeMux pattern. Successor is set on deprecated aliases and
+// names the /api/v1 path that replaces them.
+type Route struct {
+	Method    string
+	Path      string
+	Handler   http.HandlerFunc
+	Successor string
+}
+
+// Pattern is the Go 1.22 ServeMux pattern, e.g. "GET /api/v1/services".
+func (rt Route) Pattern() string {
+	return rt.Method + " " + rt.Path
+}
+
+var routes = []Route{
+	// Probes and API description (unversioned, not proxied through /api)
+	{Method: "GET", Path: "/healthz", Handler: Healthz},
+	{Method: "GET", Path: "/readyz", Handler: Readyz},
+	{Method: "GET", Path: "/openapi.json", Handler: OpenAPI},
+
+	/* ===== /api/v1 ===== */
+	{Method: "GET", Path: "/api/v1/services", Handler: GetServices},
+	{Method: "POST", Path: "/api/v1/services", Handler: CreateService},
+	{Method: "POST", Path: "/api/v1/services:import", Handler: ImportService},
+	{Method: "GET", Path: "/api/v1/services/{name}/dashboard", Handler: GetServiceDashboard},
+	{Method: "GET", Path: "/api/v1/services/{name}/environments", Handler: GetServiceEnvironments},
+	{Method: "GET", Path: "/api/v1/services/{name}/artifacts", Handler: GetServiceArtifacts},
+	{Method: "GET", Path: "/api/v1/services/{name}/branch-protection", Handler: GetBranchProtection},
+	{Method: "POST", Path: "/api/v1/services/{name}/deployments", Handler: DeployServices},
+	{Method: "GET", Path: "/api/v1/services/{name}/scheduled-deployments", Handler: GetScheduledDeployments},
+	{Method: "DELETE", Path: "/api/v1/services/{name}/scheduled-deployments/{id}", Handler: CancelScheduledDeployment},
+	{Method: "POST", Path: "/api/v1/services/{name}/rollbacks", Handler: RollbackService},
+	{Method: "GET", Path: "/api/v1/services/{name}/strategies", Handler: GetDeploymentStrategies},
+	{Method: "PUT", Path: "/api/v1/services/{name}/strategies/{env}", Handler: PutDeploymentStrategy},
+	{Method: "GET", Path: "/api/v1/services/{name}/rollouts", Handler: GetRollouts},
+	{Method: "POST", Path: "/api/v1/services/{name}/rollouts/{action}", Handler: RolloutAction}, // {id}:promote | {id}:pause | {id}:abort
+	{Method: "PUT", Path: "/api/v1/services/{name}/secrets/{env}", Handler: PutServiceSecrets},
+	{Method: "GET", Path: "/api/v1/services/{name}/health-checks", Handler: GetHealthChecks},
+	{Method: "PUT", Path: "/api/v1/services/{name}/health-checks/{env}", Handler: PutHealthCheck},
+	{Method: "DELETE", Path: "/api/v1/services/{name}/health-checks/{env}", Handler: DeleteHealthCheck},
+	{Method: "GET", Path: "/api/v1/services/{name}/health-check-runs", Handler: GetHealthCheckRuns},
+	{Method: "DELETE", Path: "/api/v1/services/{name}/locks/{env}", Handler: ReleaseLock},
+	{Method: "GET", Path: "/api/v1/services/{name}/infra", Handler: GetInfra},
+	{Method: "GET", Path: "/api/v1/services/{name}/infra-runs", Handler: GetInfraRuns},
+	{Method: "POST", Path: "/api/v1/services/{name}/infra-runs", Handler: StartInfraRun},
+	{Method: "GET", Path: "/api/v1/services/{name}/infra-runs/{id}", Handler: GetInfraRun},
+	{Method: "GET", Path: "/api/v1/services/{name}/config/{env}", Handler: GetServiceConfig},
+	{Method: "PUT", Path: "/api/v1/services/{name}/config/{env}", Handler: PutServiceConfig},
+	{Method: "GET", Path: "/api/v1/services/{name}/config/{env}/history", Handler: GetServiceConfigHistory},
+	{Method: "GET", Path: "/api/v1/services/{name}/config/{env}/diff", Handler: GetServiceConfigDiff},
+	{Method: "GET", Path: "/api/v1/services/{name}/previews", Handler: GetPreviews},
+	{Method: "DELETE", Path: "/api/v1/services/{name}/previews/{env}", Handler: DeletePreview},
+	{Method: "POST", Path: "/api/v1/artifacts", Handler: RegisterArtifact},
+	{Method: "POST", Path: "/api/v1/infra-runs/{id}/result", Handler: ReportInfraRun},
+	{Method: "POST", Path: "/api/v1/webhooks/github", Handler: GitHubWebhook},
+	{Method: "GET", Path: "/api/v1/approvals", Handler: GetApprovals},
+	{Method: "POST", Path: "/api/v1/approvals/{action}", Handler: DecideApproval}, // {id}:approve | {id}:reject
+	{Method: "GET", Path: "/api/v1/locks", Handler: GetLocks},
+	{Method: "GET", Path: "/api/v1/freeze-windows", Handler: GetFreezeWindows},
+	{Method: "POST", Path: "/api/v1/freeze-windows", Handler: CreateFreezeWindow},
+	{Method: "DELETE", Path: "/api/v1/freeze-windows/{id}", Handler: DeleteFreezeWindow},
+	{Method: "POST", Path: "/api/v1/freeze-windows/{id}/exemptions", Handler: RequestFreezeExemption},
+	{Method: "GET", Path: "/api/v1/freeze-exemptions", Handler: GetFreezeExemptions},
+	{Method: "POST", Path: "/api/v1/freeze-exemptions/{action}", Handler: DecideFreezeExemption}, // {id}:approve | {id}:reject
+	{Method: "GET", Path: "/api/v1/freeze-calendar", Handler: GetFreezeCalendar},
+
+	/* ===== Deprecated aliases ===== */
+	{Method: "POST", Path: "/create-service", Handler: CreateService, Successor: "/api/v1/services"},
+	{Method: "GET", Path: "/services", Handler: GetServices, Successor: "/api/v1/services"},
+	{Method: "POST", Path: "/services/import", Handler: ImportService, Successor: "/api/v1/services:import"},
+	{Method: "POST", Path: "/services/{name}/deploy/{env}", Handler: DeployService, Successor: "/api/v1/services/{name}/deployments"},
+	{Method: "PUT", Path: "/services/{name}/secrets/{env}", Handler: PutServiceSecrets, Successor: "/api/v1/services/{name}/secrets/{env}"},
+	{Method: "GET", Path: "/service-by-env/{name}/environments", Handler: GetServiceEnvironments, Successor: "/api/v1/services/{name}/environments"},
+	{Method: "GET", Path: "/servicesdashboard/{name}/dashboard", Handler: GetServiceDashboard, Successor: "/api/v1/services/{name}/dashboard"},
+	{Method: "GET", Path: "/artifact-by-env/{name}/artifacts", Handler: GetServiceArtifacts, Successor: "/api/v1/services/{name}/artifacts"},
+	{Method: "GET", Path: "/branch-protection/{name}", Handler: GetBranchProtection, Successor: "/api/v1/services/{name}/branch-protection"},
+	{Method: "POST", Path: "/deploy-services/{name}/deploy", Handler: DeployServices, Successor: "/api/v1/services/{name}/deployments"},
+	{Method: "POST", Path: "/rollback-services/{name}/rollback", Handler: RollbackService, Successor: "/api/v1/services/{name}/rollbacks"},
+	{Method: "POST", Path: "/artifacts", Handler: RegisterArtifact, Successor: "/api/v1/artifacts"},
+	{Method: "GET", Path: "/approvals", Handler: GetApprovals, Successor: "/api/v1/approvals"},
+	{Method: "POST", Path: "/approvals/{id}/approve", Handler: ApproveDeployment, Successor: "/api/v1/approvals/{id}:approve"},
+	{Method: "POST", Path: "/approvals/{id}/reject", Handler: RejectDeployment, Successor: "/api/v1/approvals/{id}:reject"},
+}
+
+// Routes lists every registered route, deprecated aliases included.
+func Routes() []Route {
+	return append([]Route(nil), routes...)
+}
+
+// NewRouter builds the API route tree. Everything lives under /api/v1;
+// the original unversioned paths stay registered as deprecated aliases.
+func NewRouter() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	for _, rt := range routes {
+		h := rt.Handler
+		if rt.Successor != "" {
+			h = deprecated(rt.Successor, h)
+		}
+		mux.HandleFunc(rt.Pattern(), withRequestID(h))
+	}
+
+	return mux
+}
+
+var pathWildcard = regexp.MustCompile(`\{(\w+)\}`)
+
+// deprecated marks responses from a legacy path (RFC 8594 style) and points
+// clients at the /api/v1 route that replaces it.
+func deprecated(successor string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		link := pathWildcard.ReplaceAllStringFunc(successor, func(m string) string {
+			return r.PathValue(m[1 : len(m)-1])
+		})
+
+		log.Printf("⚠️ Deprecated route %s %s → use %s", r.Method, r.URL.Path, link)
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", link))
+		h(w, r)
+	}
+}
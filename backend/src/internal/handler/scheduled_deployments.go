@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"src/src/internal/scheduler"
+)
+
+/* ===================== SCHEDULED DEPLOYMENTS ===================== */
+
+// GetScheduledDeployments lists a service's scheduled deployments, latest
+// first, including finished ones and why they were skipped.
+func GetScheduledDeployments(w http.ResponseWriter, r *http.Request) {
+	runs, err := scheduler.List(r.Context(), r.PathValue("name"))
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, runs)
+}
+
+// CancelScheduledDeployment cancels a run that has not started, together
+// with its pending approval.
+func CancelScheduledDeployment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid scheduled deployment id")
+		return
+	}
+
+	if err := scheduler.Cancel(r.Context(), r.PathValue("name"), id, actor(r)); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "scheduled deployment cancelled"})
+}
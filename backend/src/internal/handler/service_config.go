@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"src/src/internal/model"
+	"src/src/internal/svcconfig"
+	"src/src/internal/validate"
+)
+
+/* ===================== SERVICE CONFIGURATION ===================== */
+
+// GetServiceConfig returns the current configuration of an environment, or
+// the version given by ?version.
+func GetServiceConfig(w http.ResponseWriter, r *http.Request) {
+	version, ok := queryVersion(w, r, "version")
+	if !ok {
+		return
+	}
+
+	c, err := svcconfig.Get(r.Context(), r.PathValue("name"), r.PathValue("env"), version)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, c)
+}
+
+// PutServiceConfig records a new configuration version and writes it to the
+// service's repository, by a commit to the environment's branch or a pull
+// request into it.
+func PutServiceConfig(w http.ResponseWriter, r *http.Request) {
+	env := r.PathValue("env")
+
+	var u model.ConfigUpdate
+	if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.ConfigUpdate(env, &u); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	c, err := svcconfig.Put(r.Context(), r.PathValue("name"), env, u, actor(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, c)
+}
+
+// GetServiceConfigHistory lists the configuration versions of an
+// environment, newest first.
+func GetServiceConfigHistory(w http.ResponseWriter, r *http.Request) {
+	history, err := svcconfig.History(r.Context(), r.PathValue("name"), r.PathValue("env"))
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, history)
+}
+
+// GetServiceConfigDiff compares two configuration versions; ?to defaults to
+// the current one and ?from to the one before it.
+func GetServiceConfigDiff(w http.ResponseWriter, r *http.Request) {
+	from, ok := queryVersion(w, r, "from")
+	if !ok {
+		return
+	}
+	to, ok := queryVersion(w, r, "to")
+	if !ok {
+		return
+	}
+
+	d, err := svcconfig.DiffVersions(r.Context(), r.PathValue("name"), r.PathValue("env"), from, to)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, d)
+}
+
+// queryVersion reads an optional version parameter; 0 when absent.
+func queryVersion(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return 0, true
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < 1 {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid "+name)
+		return 0, false
+	}
+	return v, true
+}
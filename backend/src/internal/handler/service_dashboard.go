@@ -3,18 +3,20 @@ package handler
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
+	"log"
 	"net/http"
-	"strings"
+	"sort"
 	"time"
 
+	"src/src/internal/argocd"
 	"src/src/internal/db"
 )
 
 type EnvironmentDashboard struct {
-	CurrentVersion *string    `json:"currentVersion"`
-	Status         string     `json:"status"`
-	DeployedAt     *time.Time `json:"deployedAt"`
+	CurrentVersion *string        `json:"currentVersion"`
+	Status         string         `json:"status"`
+	DeployedAt     *time.Time     `json:"deployedAt"`
+	ArgoCD         *argocd.Status `json:"argocd,omitempty"` // live Application status, microservices only
 }
 
 type ServiceDashboardResponse struct {
@@ -23,24 +25,12 @@ type ServiceDashboardResponse struct {
 }
 
 func GetServiceDashboard(w http.ResponseWriter, r *http.Request) {
-	// 🔒 Allow GET only
-	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	// ⏱️ Timeout protection
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	// 🔍 Extract serviceName from URL
-	// Expected: /api/services/{serviceName}/dashboard
-	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
-	if len(parts) != 3 || parts[0] != "servicesdashboard" || parts[2] != "dashboard" {
-		http.Error(w, "invalid URL", http.StatusBadRequest)
-		return
-	}
-	serviceName := parts[1]
+	// GET /api/v1/services/{name}/dashboard
+	serviceName := r.PathValue("name")
 
 	rows, err := db.DB.QueryContext(
 		ctx,
@@ -50,7 +40,7 @@ func GetServiceDashboard(w http.ResponseWriter, r *http.Request) {
 		serviceName,
 	)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeInternalError(w, r, err)
 		return
 	}
 	defer rows.Close()
@@ -78,7 +68,7 @@ func GetServiceDashboard(w http.ResponseWriter, r *http.Request) {
 		)
 
 		if err := rows.Scan(&env, &version, &status, &deployedAt); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeInternalError(w, r, err)
 			return
 		}
 
@@ -90,12 +80,28 @@ func GetServiceDashboard(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if !found {
-		http.Error(w, "service not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, CodeNotFound, "service not found")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp)
+	// 🐙 Argo CD's view next to ours; the dashboard still loads without it
+	envs := make([]string, 0, len(resp.Environments))
+	for env := range resp.Environments {
+		envs = append(envs, env)
+	}
+	sort.Strings(envs)
+
+	statuses, err := argocd.Statuses(ctx, serviceName, envs)
+	if err != nil {
+		log.Printf("⚠️ [%s] argocd status of %s: %v", RequestID(r.Context()), serviceName, err)
+	}
+	for env, st := range statuses {
+		e := resp.Environments[env]
+		e.ArgoCD = st
+		resp.Environments[env] = e
+	}
+
+	writeJSON(w, http.StatusOK, resp)
 }
 
 
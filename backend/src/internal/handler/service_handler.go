@@ -1,33 +1,28 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
-	"strings"
 	"src/src/internal/service"
 )
 
-func GetServices(w http.ResponseWriter, _ *http.Request) {
+func GetServices(w http.ResponseWriter, r *http.Request) {
 	data, err := service.ListServices()
 	if err != nil {
-		http.Error(w, "failed to fetch services", 500)
+		writeInternalError(w, r, err)
 		return
 	}
-	json.NewEncoder(w).Encode(data)
+	writeJSON(w, http.StatusOK, data)
 }
 
+// DeployService marks a deployment in progress without triggering CI.
+// Only reachable through the deprecated /services/{name}/deploy/{env} alias;
+// use POST /api/v1/services/{name}/deployments.
 func DeployService(w http.ResponseWriter, r *http.Request) {
-	parts := strings.Split(r.URL.Path, "/")
-	if len(parts) < 6 {
-		http.Error(w, "invalid path", 400)
-		return
-	}
-
-	serviceName := parts[3]
-	env := parts[5]
+	serviceName := r.PathValue("name")
+	env := r.PathValue("env")
 
 	if err := service.TriggerDeploy(serviceName, env); err != nil {
-		http.Error(w, "deploy failed", 500)
+		writeInternalError(w, r, err)
 		return
 	}
 
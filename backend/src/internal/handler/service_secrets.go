@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"src/src/internal/model"
+	"src/src/internal/service"
+	"src/src/internal/validate"
+)
+
+func PutServiceSecrets(w http.ResponseWriter, r *http.Request) {
+	// PUT /api/v1/services/{name}/secrets/{env}
+	var req model.PutSecretsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "invalid json body")
+		return
+	}
+
+	var errs validate.Errors
+	errs.ServiceName("name", r.PathValue("name"))
+	errs.Environment("env", r.PathValue("env"), validate.Environments)
+	if len(req.Secrets) == 0 {
+		errs.Add("secrets", "is required")
+	}
+	if err := errs.Err(); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	written, err := service.PutServiceSecrets(r.PathValue("name"), r.PathValue("env"), req.Secrets)
+	switch {
+	case errors.Is(err, service.ErrServiceNotFound), errors.Is(err, service.ErrInvalidSecrets):
+		writeServiceError(w, r, err)
+		return
+	case err != nil:
+		// GitHub / Jenkins rejected the write
+		writeError(w, r, http.StatusBadGateway, CodeUpstreamFailed, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, written)
+}
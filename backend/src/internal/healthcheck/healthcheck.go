@@ -0,0 +1,261 @@
+// Package healthcheck verifies deploys. When a pipeline reports a
+// successful deploy, the service's health check for that environment (if
+// any) is probed in the background; if it fails, the environment is rolled
+// back to the version it ran before and the owner team is notified.
+//
+// A verification runs on the replica that received the artifact report. If
+// that replica stops mid-way the run stays "running" and nothing is rolled
+// back.
+package healthcheck
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"src/src/internal/cicd"
+	"src/src/internal/db"
+	"src/src/internal/deploy"
+	"src/src/internal/freeze"
+	"src/src/internal/locks"
+	"src/src/internal/model"
+	"src/src/internal/notify"
+)
+
+// actor owns the locks and freeze overrides of automatic rollbacks.
+const actor = "health-check"
+
+// maxReason matches the lock and freeze override reason columns.
+const maxReason = 255
+
+var (
+	client = &http.Client{}
+
+	// Replaced in tests.
+	now   = func() time.Time { return time.Now().UTC().Truncate(time.Second) }
+	sleep = func(ctx context.Context, d time.Duration) {
+		select {
+		case <-ctx.Done():
+		case <-time.After(d):
+		}
+	}
+)
+
+// Start begins verifying version of serviceName in env. It returns nil
+// without a check configured; otherwise the run is recorded and probed in
+// the background, so the caller does not wait for the success window.
+func Start(ctx context.Context, serviceName, env, version string) (*model.HealthCheckRun, error) {
+	c, err := Get(ctx, serviceName, env)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	run := &model.HealthCheckRun{
+		ServiceName: serviceName,
+		Environment: env,
+		Version:     version,
+		Status:      model.HealthRunning,
+		StartedAt:   now(),
+	}
+	if err := insertRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🩺 Verifying %s %s/%s against %s (run %d)", version, serviceName, env, c.URL, run.ID)
+	go Verify(context.Background(), c, *run)
+	return run, nil
+}
+
+// Verify probes c and records the outcome of run, rolling back on failure.
+func Verify(ctx context.Context, c *model.HealthCheck, run model.HealthCheckRun) {
+	// Worst case: unhealthy at the end of the window, then failing slowly
+	budget := time.Duration(c.WindowSeconds+c.FailureThreshold*(c.IntervalSeconds+c.TimeoutSeconds)) * time.Second
+	probeCtx, cancel := context.WithTimeout(ctx, budget+time.Minute)
+	cause := probe(probeCtx, c)
+	cancel()
+
+	if cause == nil {
+		run.Status = model.HealthPassed
+		log.Printf("✅ Health check of %s %s/%s passed", run.Version, run.ServiceName, run.Environment)
+	} else {
+		log.Printf("⚠️ Health check of %s %s/%s failed: %v", run.Version, run.ServiceName, run.Environment, cause)
+		rollBack(ctx, &run, cause)
+		alert(ctx, &run)
+	}
+
+	if err := finishRun(ctx, &run); err != nil {
+		log.Printf("⚠️ Health check run %d: %v", run.ID, err)
+	}
+}
+
+/* ===================== PROBING ===================== */
+
+// probe returns nil once c has stayed healthy for its window, or the last
+// error after FailureThreshold failures in a row. A window that ends on a
+// failure is extended until the service recovers or the threshold is hit.
+func probe(ctx context.Context, c *model.HealthCheck) error {
+	deadline := now().Add(time.Duration(c.WindowSeconds) * time.Second)
+	interval := time.Duration(c.IntervalSeconds) * time.Second
+
+	failures := 0
+	for {
+		if err := probeOnce(ctx, c); err != nil {
+			failures++
+			if failures >= c.FailureThreshold {
+				return fmt.Errorf("%d consecutive failures, last: %w", failures, err)
+			}
+		} else {
+			failures = 0
+		}
+
+		if failures == 0 && !now().Before(deadline) {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		sleep(ctx, interval)
+	}
+}
+
+func probeOnce(ctx context.Context, c *model.HealthCheck) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(c.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode != c.ExpectedStatus {
+		return fmt.Errorf("GET %s returned %d, expected %d", c.URL, resp.StatusCode, c.ExpectedStatus)
+	}
+	return nil
+}
+
+/* ===================== ROLLBACK ===================== */
+
+// rollBack takes run's environment back to the version it ran before,
+// going through the same freeze, lock and CI steps as a manual rollback.
+// It sets run's status, previous version and detail.
+func rollBack(ctx context.Context, run *model.HealthCheckRun, cause error) {
+	run.Status = model.HealthFailed
+	detail := func(format string, args ...interface{}) {
+		run.Detail = fmt.Sprintf("health check failed: %v; ", cause) + fmt.Sprintf(format, args...)
+	}
+
+	// 1️⃣ A newer deploy or a manual rollback already replaced this version
+	var current string
+	err := db.DB.QueryRowContext(ctx, `
+		SELECT version FROM environment_state
+		WHERE service_name = ? AND environment = ?`,
+		run.ServiceName, run.Environment,
+	).Scan(&current)
+	if err != nil {
+		detail("not rolled back: read current version: %v", err)
+		return
+	}
+	if current != run.Version {
+		detail("not rolled back: %s is running now", current)
+		return
+	}
+
+	// 2️⃣ The version that ran before this one
+	err = db.DB.QueryRowContext(ctx, `
+		SELECT version FROM artifacts
+		WHERE service_name = ? AND environment = ? AND version <> ?
+		ORDER BY id DESC
+		LIMIT 1`,
+		run.ServiceName, run.Environment, run.Version,
+	).Scan(&run.PreviousVersion)
+	if errors.Is(err, sql.ErrNoRows) {
+		detail("not rolled back: no previous version")
+		return
+	}
+	if err != nil {
+		detail("not rolled back: find previous version: %v", err)
+		return
+	}
+
+	reason := truncate(fmt.Sprintf("automatic rollback: health check of %s failed", run.Version), maxReason)
+
+	// 3️⃣ Undoing a broken deploy is an emergency; a freeze is overridden
+	// and audited like a manual emergency rollback
+	if err := freeze.Check(ctx, run.ServiceName, run.Environment); err != nil {
+		var frozen *freeze.FrozenError
+		if !errors.As(err, &frozen) {
+			detail("not rolled back: %v", err)
+			return
+		}
+		if err := freeze.RecordOverride(ctx, frozen, run.ServiceName, run.Environment, "rollback", actor, reason); err != nil {
+			detail("not rolled back: record freeze override: %v", err)
+			return
+		}
+	}
+
+	// 4️⃣ Lock and trigger; the lock is released when the pipeline reports back
+	lock, err := locks.Acquire(ctx, run.ServiceName, run.Environment, "rollback", actor, reason)
+	if err != nil {
+		detail("not rolled back: %v", err)
+		return
+	}
+	target, err := deploy.Lookup(ctx, run.ServiceName)
+	if err == nil {
+		err = deploy.Rollback(target, run.Environment, run.PreviousVersion, cicd.RolloutParams{})
+	}
+	if err != nil {
+		locks.Release(ctx, lock)
+		detail("rollback to %s failed: %v", run.PreviousVersion, err)
+		return
+	}
+
+	run.Status = model.HealthRolledBack
+	detail("rolled back to %s", run.PreviousVersion)
+}
+
+// alert tells the owner team what happened to their deploy.
+func alert(ctx context.Context, run *model.HealthCheckRun) {
+	var team sql.NullString
+	if err := db.DB.QueryRowContext(ctx, `
+		SELECT owner_team FROM services WHERE service_name = ?`,
+		run.ServiceName,
+	).Scan(&team); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("⚠️ Health check run %d: owner team: %v", run.ID, err)
+	}
+
+	subject := fmt.Sprintf("%s failed its health check and was rolled back", run.Version)
+	if run.Status != model.HealthRolledBack {
+		subject = fmt.Sprintf("%s failed its health check; manual action needed", run.Version)
+	}
+
+	if err := notify.Send(ctx, notify.Message{
+		Team:        team.String,
+		ServiceName: run.ServiceName,
+		Environment: run.Environment,
+		Subject:     subject,
+		Text:        run.Detail,
+	}); err != nil {
+		log.Printf("⚠️ Health check run %d: %v", run.ID, err)
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
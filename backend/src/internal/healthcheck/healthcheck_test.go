@@ -0,0 +1,117 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"src/src/internal/db"
+	"src/src/internal/model"
+)
+
+// fakeClock advances by each sleep instead of waiting.
+func fakeClock(t *testing.T) {
+	t.Helper()
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	prevNow, prevSleep := now, sleep
+	now = func() time.Time { return at }
+	sleep = func(_ context.Context, d time.Duration) { at = at.Add(d) }
+	t.Cleanup(func() { now, sleep = prevNow, prevSleep })
+}
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int // answered in turn, the last one repeated
+		wantErr  bool
+		probes   int
+	}{
+		{"healthy for the window", []int{200}, false, 7},
+		{"down", []int{503}, true, 3},
+		{"recovers before the threshold", []int{200, 503, 503, 200}, false, 7},
+		{"window ends unhealthy", []int{200, 200, 200, 200, 200, 200, 503, 503, 503}, true, 9},
+		{"unexpected status", []int{301}, true, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClock(t)
+
+			probes := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[min(probes, len(tt.statuses)-1)]
+				probes++
+				w.WriteHeader(status)
+			}))
+			defer srv.Close()
+
+			err := probe(context.Background(), &model.HealthCheck{
+				URL:              srv.URL,
+				ExpectedStatus:   200,
+				WindowSeconds:    60,
+				IntervalSeconds:  10,
+				TimeoutSeconds:   5,
+				FailureThreshold: 3,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("probe() = %v, wantErr %v", err, tt.wantErr)
+			}
+			if probes != tt.probes {
+				t.Errorf("probes = %d, want %d", probes, tt.probes)
+			}
+		})
+	}
+}
+
+func TestRollBackNeedsSomethingToRollBackTo(t *testing.T) {
+	tests := []struct {
+		name       string
+		expect     func(m sqlmock.Sqlmock)
+		wantDetail string
+	}{
+		{
+			name: "superseded",
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("FROM environment_state").WithArgs("orders", "prod").
+					WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("1.4.0"))
+			},
+			wantDetail: "health check failed: down; not rolled back: 1.4.0 is running now",
+		},
+		{
+			name: "first deploy",
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("FROM environment_state").WithArgs("orders", "prod").
+					WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("1.3.0"))
+				m.ExpectQuery("FROM artifacts").WithArgs("orders", "prod", "1.3.0").
+					WillReturnRows(sqlmock.NewRows([]string{"version"}))
+			},
+			wantDetail: "health check failed: down; not rolled back: no previous version",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			db.DB = conn
+			tt.expect(mock)
+
+			run := &model.HealthCheckRun{ServiceName: "orders", Environment: "prod", Version: "1.3.0"}
+			rollBack(context.Background(), run, errors.New("down"))
+
+			if run.Status != model.HealthFailed || run.Detail != tt.wantDetail {
+				t.Errorf("run = %s %q, want %s %q", run.Status, run.Detail, model.HealthFailed, tt.wantDetail)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
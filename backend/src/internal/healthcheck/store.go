@@ -0,0 +1,168 @@
+package healthcheck
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"src/src/internal/db"
+	"src/src/internal/model"
+)
+
+var ErrNotFound = errors.New("health check not found")
+
+const checkColumns = `
+	service_name, environment, url, expected_status, window_seconds,
+	interval_seconds, timeout_seconds, failure_threshold, updated_by, updated_at`
+
+const runColumns = `
+	id, service_name, environment, version, previous_version, status,
+	COALESCE(detail, ''), started_at, finished_at`
+
+// Get returns the check of serviceName/env, or ErrNotFound.
+func Get(ctx context.Context, serviceName, env string) (*model.HealthCheck, error) {
+	found, err := queryChecks(ctx, `
+		SELECT `+checkColumns+`
+		FROM health_checks
+		WHERE service_name = ? AND environment = ?`,
+		serviceName, env,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, ErrNotFound
+	}
+	return &found[0], nil
+}
+
+// List returns the checks of a service.
+func List(ctx context.Context, serviceName string) ([]model.HealthCheck, error) {
+	return queryChecks(ctx, `
+		SELECT `+checkColumns+`
+		FROM health_checks
+		WHERE service_name = ?
+		ORDER BY environment`,
+		serviceName,
+	)
+}
+
+// Put creates or replaces the check of c.ServiceName/c.Environment.
+func Put(ctx context.Context, c *model.HealthCheck) error {
+	c.UpdatedAt = now()
+
+	_, err := db.DB.ExecContext(ctx, `
+		REPLACE INTO health_checks
+		(`+checkColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.ServiceName, c.Environment, c.URL, c.ExpectedStatus, c.WindowSeconds,
+		c.IntervalSeconds, c.TimeoutSeconds, c.FailureThreshold, c.UpdatedBy, c.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("🩺 Health check of %s/%s set to %s by %s", c.ServiceName, c.Environment, c.URL, c.UpdatedBy)
+	return nil
+}
+
+// Delete removes a check; later deploys are no longer verified.
+func Delete(ctx context.Context, serviceName, env, by string) error {
+	res, err := db.DB.ExecContext(ctx, `
+		DELETE FROM health_checks
+		WHERE service_name = ? AND environment = ?`,
+		serviceName, env,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	log.Printf("🩺 Health check of %s/%s deleted by %s", serviceName, env, by)
+	return nil
+}
+
+// Runs returns the latest verifications of a service, newest first.
+func Runs(ctx context.Context, serviceName string) ([]model.HealthCheckRun, error) {
+	rows, err := db.DB.QueryContext(ctx, `
+		SELECT `+runColumns+`
+		FROM health_check_runs
+		WHERE service_name = ?
+		ORDER BY id DESC
+		LIMIT 100`,
+		serviceName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.HealthCheckRun{}
+	for rows.Next() {
+		var run model.HealthCheckRun
+		if err := rows.Scan(
+			&run.ID, &run.ServiceName, &run.Environment, &run.Version, &run.PreviousVersion, &run.Status,
+			&run.Detail, &run.StartedAt, &run.FinishedAt,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, run)
+	}
+	return out, rows.Err()
+}
+
+func queryChecks(ctx context.Context, sqlText string, args ...interface{}) ([]model.HealthCheck, error) {
+	rows, err := db.DB.QueryContext(ctx, sqlText, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.HealthCheck{}
+	for rows.Next() {
+		var c model.HealthCheck
+		if err := rows.Scan(
+			&c.ServiceName, &c.Environment, &c.URL, &c.ExpectedStatus, &c.WindowSeconds,
+			&c.IntervalSeconds, &c.TimeoutSeconds, &c.FailureThreshold, &c.UpdatedBy, &c.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+func insertRun(ctx context.Context, run *model.HealthCheckRun) error {
+	res, err := db.DB.ExecContext(ctx, `
+		INSERT INTO health_check_runs
+		(service_name, environment, version, status, started_at)
+		VALUES (?, ?, ?, 'running', ?)`,
+		run.ServiceName, run.Environment, run.Version, run.StartedAt,
+	)
+	if err != nil {
+		return err
+	}
+	run.ID, err = res.LastInsertId()
+	return err
+}
+
+func finishRun(ctx context.Context, run *model.HealthCheckRun) error {
+	finished := now()
+	run.FinishedAt = &finished
+
+	var detail sql.NullString
+	if run.Detail != "" {
+		detail = sql.NullString{String: run.Detail, Valid: true}
+	}
+
+	_, err := db.DB.ExecContext(ctx, `
+		UPDATE health_check_runs
+		SET status = ?, previous_version = ?, detail = ?, finished_at = ?
+		WHERE id = ?`,
+		run.Status, run.PreviousVersion, detail, finished, run.ID,
+	)
+	return err
+}
@@ -0,0 +1,92 @@
+// Package infra provisions the infrastructure of ec2 services with
+// Terraform. A service that asks for infra gets a root module per
+// environment in its repository, built on the versioned platform modules
+// in template_data, with its state in the configured S3 bucket. Plans and
+// applies run in the service's CI system, which reports their output back
+// to the platform. Everything here is off until terraform.stateBucket is
+// configured.
+package infra
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"src/src/internal/config"
+	"src/src/internal/git"
+	"src/src/internal/model"
+	"src/src/internal/templates"
+)
+
+var (
+	ErrNotConfigured = errors.New("terraform.stateBucket is not configured")
+	ErrNotFound      = errors.New("infrastructure not found")
+	ErrInProgress    = errors.New("infrastructure run in progress")
+	ErrPlanRequired  = errors.New("apply needs a succeeded plan")
+	ErrFinished      = errors.New("infrastructure run already finished")
+	ErrTrigger       = errors.New("infrastructure pipeline could not be started")
+)
+
+var (
+	settings = config.Default().Terraform
+	platform = config.Default().Platform
+	tokens   = git.DefaultTokenSource()
+)
+
+// Configure sets the module version, region and state backend, the
+// platform URL pipelines report results to and the credentials plan/apply
+// workflows are dispatched with.
+func Configure(cfg config.Terraform, p config.Platform, ts git.TokenSource) {
+	settings, platform, tokens = cfg, p, ts
+}
+
+// Enabled reports whether a state bucket is configured.
+func Enabled() bool {
+	return settings.StateBucket != ""
+}
+
+// StateLocation is where the state of serviceName in env lives.
+func StateLocation(serviceName, env string) string {
+	return "s3://" + settings.StateBucket + "/" + templates.TerraformStateKey(serviceName, env)
+}
+
+// Template is the Terraform rendered into a new service, or nil when req
+// does not ask for infra. req.Infra has been validated and defaulted.
+func Template(req model.CreateServiceRequest) (*templates.TerraformRequest, error) {
+	spec := req.Infra
+	if spec == nil {
+		return nil, nil
+	}
+	if !Enabled() {
+		return nil, ErrNotConfigured
+	}
+
+	instanceType, err := InstanceType(spec.CPU, spec.Memory)
+	if err != nil {
+		return nil, err
+	}
+
+	version := spec.ModuleVersion
+	if version == "" {
+		version = settings.ModuleVersion
+	}
+
+	return &templates.TerraformRequest{
+		ModuleVersion: version,
+		ServiceName:   req.ServiceName,
+		Environments:  req.Environments,
+		Cloud:         spec.Cloud,
+		Compute:       spec.Compute,
+		CPU:           spec.CPU,
+		Memory:        spec.Memory,
+		InstanceType:  instanceType,
+		Region:        settings.Region,
+		StateBucket:   settings.StateBucket,
+		LockTable:     settings.LockTable,
+	}, nil
+}
+
+// resultURL is where the pipeline of run id reports its result.
+func resultURL(id int64) string {
+	return strings.TrimRight(platform.URL, "/") + "/api/v1/infra-runs/" + strconv.FormatInt(id, 10) + "/result"
+}
@@ -0,0 +1,343 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"time"
+	"unicode/utf8"
+
+	"src/src/internal/cicd"
+	"src/src/internal/db"
+	"src/src/internal/model"
+	"src/src/internal/templates"
+)
+
+// outputLimit caps the plan / apply output kept per run; the end of the
+// output, where Terraform reports what it did, is kept.
+const outputLimit = 512 << 10
+
+// runTimeout is how long a run that never reported blocks new ones.
+const runTimeout = 2 * time.Hour
+
+// now is replaced in tests.
+var now = func() time.Time { return time.Now().UTC().Truncate(time.Second) }
+
+const stackColumns = `
+	service_name, environment, cloud, compute, cpu, memory, instance_type,
+	module_version, root_module, state_location, created_at`
+
+const runColumns = `
+	id, service_name, environment, action, status, state_location, run_url,
+	requested_by, created_at, finished_at`
+
+/* ===================== STACKS ===================== */
+
+// SaveStacks records the root modules rendered from tf, in the transaction
+// that completes the service.
+func SaveStacks(ctx context.Context, tx *sql.Tx, tf *templates.TerraformRequest) error {
+	for _, env := range tf.Environments {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO infra_stacks
+			(`+stackColumns+`)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			tf.ServiceName, env, tf.Cloud, tf.Compute, tf.CPU, tf.Memory, tf.InstanceType,
+			tf.ModuleVersion, templates.InfraRootModule(env), StateLocation(tf.ServiceName, env), now(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stacks returns the root modules of a service with their latest plan and
+// apply.
+func Stacks(ctx context.Context, serviceName string) ([]model.InfraStack, error) {
+	rows, err := db.DB.QueryContext(ctx, `
+		SELECT `+stackColumns+`
+		FROM infra_stacks
+		WHERE service_name = ?
+		ORDER BY environment`,
+		serviceName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stacks := []model.InfraStack{}
+	for rows.Next() {
+		var s model.InfraStack
+		if err := rows.Scan(&s.ServiceName, &s.Environment, &s.Cloud, &s.Compute, &s.CPU, &s.Memory,
+			&s.InstanceType, &s.ModuleVersion, &s.RootModule, &s.StateLocation, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		stacks = append(stacks, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range stacks {
+		s := &stacks[i]
+		if s.LastPlan, err = latestRun(ctx, s.ServiceName, s.Environment, model.InfraPlan); err != nil {
+			return nil, err
+		}
+		if s.LastApply, err = latestRun(ctx, s.ServiceName, s.Environment, model.InfraApply); err != nil {
+			return nil, err
+		}
+	}
+	return stacks, nil
+}
+
+func latestRun(ctx context.Context, serviceName, env, action string) (*model.InfraRun, error) {
+	found, err := queryRuns(ctx, `
+		SELECT `+runColumns+`
+		FROM infra_runs
+		WHERE service_name = ? AND environment = ? AND action = ?
+		ORDER BY id DESC
+		LIMIT 1`,
+		serviceName, env, action,
+	)
+	if err != nil || len(found) == 0 {
+		return nil, err
+	}
+	return &found[0], nil
+}
+
+/* ===================== RUNS ===================== */
+
+// Start runs action on the root module of serviceName/env through the
+// service's CI system. An apply needs the environment's latest run to be a
+// succeeded plan, so what is applied has been reviewed; only one run of an
+// environment is in flight at a time.
+func Start(ctx context.Context, serviceName, env, action, by string) (*model.InfraRun, error) {
+	run, err := reserveRun(ctx, serviceName, env, action, by)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := trigger(ctx, run); err != nil {
+		log.Printf("❌ Terraform %s of %s/%s could not start: %v", action, serviceName, env, err)
+		if ferr := finish(ctx, run.ID, model.InfraRunResult{Status: model.InfraFailed, Output: err.Error()}); ferr != nil {
+			log.Printf("⚠️ mark infra run %d failed: %v", run.ID, ferr)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrTrigger, err)
+	}
+
+	log.Printf("🏗️ Terraform %s of %s/%s started by %s (run %d)", action, serviceName, env, by, run.ID)
+	return run, nil
+}
+
+// reserveRun records a running run once the stack is free for it.
+func reserveRun(ctx context.Context, serviceName, env, action, by string) (*model.InfraRun, error) {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// 1️⃣ Stack, locked against concurrent starts
+	run := &model.InfraRun{
+		ServiceName: serviceName,
+		Environment: env,
+		Action:      action,
+		Status:      model.InfraRunning,
+		RequestedBy: by,
+		CreatedAt:   now(),
+	}
+	err = tx.QueryRowContext(ctx, `
+		SELECT state_location
+		FROM infra_stacks
+		WHERE service_name = ? AND environment = ?
+		FOR UPDATE`,
+		serviceName, env,
+	).Scan(&run.StateLocation)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s has no infrastructure in %s", ErrNotFound, serviceName, env)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 2️⃣ Latest run
+	var (
+		last      model.InfraRun
+		lastFound = true
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, action, status, created_at
+		FROM infra_runs
+		WHERE service_name = ? AND environment = ?
+		ORDER BY id DESC
+		LIMIT 1`,
+		serviceName, env,
+	).Scan(&last.ID, &last.Action, &last.Status, &last.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		lastFound = false
+	} else if err != nil {
+		return nil, err
+	}
+
+	if lastFound && last.Status == model.InfraRunning && run.CreatedAt.Sub(last.CreatedAt) < runTimeout {
+		return nil, fmt.Errorf("%w: %s %d of %s/%s has not reported yet", ErrInProgress, last.Action, last.ID, serviceName, env)
+	}
+	if action == model.InfraApply && (!lastFound || last.Action != model.InfraPlan || last.Status != model.InfraSucceeded) {
+		return nil, fmt.Errorf("%w: run a plan of %s/%s and review it first", ErrPlanRequired, serviceName, env)
+	}
+
+	// 3️⃣ Record
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO infra_runs
+		(service_name, environment, action, status, state_location, requested_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		run.ServiceName, run.Environment, run.Action, run.Status, run.StateLocation, run.RequestedBy, run.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if run.ID, err = res.LastInsertId(); err != nil {
+		return nil, err
+	}
+
+	return run, tx.Commit()
+}
+
+// trigger dispatches run to the plan/apply pipeline of its service, on the
+// environment's branch.
+func trigger(ctx context.Context, run *model.InfraRun) error {
+	var cicdType, owner, repo, repoPath string
+	err := db.DB.QueryRowContext(ctx, `
+		SELECT cicd_type, COALESCE(repo_owner, ''), repo_name, COALESCE(repo_path, '')
+		FROM services
+		WHERE service_name = ?`,
+		run.ServiceName,
+	).Scan(&cicdType, &owner, &repo, &repoPath)
+	if err != nil {
+		return err
+	}
+
+	p := cicd.TerraformParams{
+		Action:      run.Action,
+		Environment: run.Environment,
+		Dir:         path.Join(repoPath, templates.InfraRootModule(run.Environment)),
+		RunID:       run.ID,
+		ResultURL:   resultURL(run.ID),
+	}
+	branch := cicd.EnvironmentBranch(run.Environment)
+
+	switch cicdType {
+	case "jenkins":
+		return cicd.TriggerJenkinsTerraform(cicd.InfraJobName(run.ServiceName), branch, p)
+	case "github":
+		return cicd.TriggerGitHubTerraform(tokens, owner, repo, branch, repoPath, p)
+	default:
+		return fmt.Errorf("unsupported cicd type %q", cicdType)
+	}
+}
+
+// Report records what the pipeline of run id reported. A run reports once.
+func Report(ctx context.Context, id int64, result model.InfraRunResult) error {
+	if err := finish(ctx, id, result); err != nil {
+		return err
+	}
+	log.Printf("🏗️ Infra run %d %s", id, result.Status)
+	return nil
+}
+
+func finish(ctx context.Context, id int64, result model.InfraRunResult) error {
+	res, err := db.DB.ExecContext(ctx, `
+		UPDATE infra_runs
+		SET status = ?, output = ?, run_url = ?, finished_at = ?
+		WHERE id = ? AND status = ?`,
+		result.Status, tail(result.Output, outputLimit), result.RunURL, now(),
+		id, model.InfraRunning,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return nil
+	}
+
+	var status string
+	err = db.DB.QueryRowContext(ctx, `SELECT status FROM infra_runs WHERE id = ?`, id).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: run %d", ErrNotFound, id)
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: run %d is %s", ErrFinished, id, status)
+}
+
+// tail keeps the last n bytes of s, cut at a character boundary.
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	s = s[len(s)-n:]
+	for len(s) > 0 && !utf8.RuneStart(s[0]) {
+		s = s[1:]
+	}
+	return "[output truncated]\n" + s
+}
+
+// Runs returns a service's latest plans and applies, newest first, without
+// their output.
+func Runs(ctx context.Context, serviceName string) ([]model.InfraRun, error) {
+	return queryRuns(ctx, `
+		SELECT `+runColumns+`
+		FROM infra_runs
+		WHERE service_name = ?
+		ORDER BY id DESC
+		LIMIT 50`,
+		serviceName,
+	)
+}
+
+// Run returns a run of serviceName with its output, or ErrNotFound.
+func Run(ctx context.Context, serviceName string, id int64) (*model.InfraRun, error) {
+	found, err := queryRuns(ctx, `
+		SELECT `+runColumns+`
+		FROM infra_runs
+		WHERE id = ? AND service_name = ?`,
+		id, serviceName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("%w: run %d", ErrNotFound, id)
+	}
+
+	run := &found[0]
+	err = db.DB.QueryRowContext(ctx, `SELECT COALESCE(output, '') FROM infra_runs WHERE id = ?`, id).Scan(&run.Output)
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+func queryRuns(ctx context.Context, sqlText string, args ...interface{}) ([]model.InfraRun, error) {
+	rows, err := db.DB.QueryContext(ctx, sqlText, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.InfraRun{}
+	for rows.Next() {
+		var r model.InfraRun
+		if err := rows.Scan(&r.ID, &r.ServiceName, &r.Environment, &r.Action, &r.Status, &r.StateLocation,
+			&r.RunURL, &r.RequestedBy, &r.CreatedAt, &r.FinishedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
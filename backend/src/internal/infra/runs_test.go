@@ -0,0 +1,65 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"src/src/internal/db"
+	"src/src/internal/model"
+)
+
+func TestReserveRunWaitsForUnreportedRun(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		age     time.Duration // of the running plan
+		wantErr error
+	}{
+		{"still running", runTimeout - time.Minute, ErrInProgress},
+		{"never reported", runTimeout, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, m, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			db.DB = conn
+
+			prev := now
+			now = func() time.Time { return t0 }
+			t.Cleanup(func() { now = prev })
+
+			m.ExpectBegin()
+			m.ExpectQuery("FROM infra_stacks").WithArgs("orders", "dev").
+				WillReturnRows(sqlmock.NewRows([]string{"state_location"}).AddRow("s3://state/orders/dev"))
+			m.ExpectQuery("FROM infra_runs").WithArgs("orders", "dev").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "action", "status", "created_at"}).
+					AddRow(4, model.InfraPlan, model.InfraRunning, t0.Add(-tt.age)))
+			if tt.wantErr == nil {
+				m.ExpectExec("INSERT INTO infra_runs").
+					WithArgs("orders", "dev", model.InfraPlan, model.InfraRunning, "s3://state/orders/dev", "alice", t0).
+					WillReturnResult(sqlmock.NewResult(5, 1))
+				m.ExpectCommit()
+			} else {
+				m.ExpectRollback()
+			}
+
+			run, err := reserveRun(context.Background(), "orders", "dev", model.InfraPlan, "alice")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && run.ID != 5 {
+				t.Errorf("run = %+v", run)
+			}
+			if err := m.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package infra
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type instanceType struct {
+	name   string
+	vCPUs  float64
+	memMiB int
+}
+
+// instanceTypes are the sizes the platform provisions, cheapest first.
+var instanceTypes = []instanceType{
+	{"t3.micro", 2, 1024},
+	{"t3.small", 2, 2048},
+	{"t3.medium", 2, 4096},
+	{"t3.large", 2, 8192},
+	{"t3.xlarge", 4, 16384},
+	{"t3.2xlarge", 8, 32768},
+	{"m5.4xlarge", 16, 65536},
+	{"m5.8xlarge", 32, 131072},
+}
+
+// InstanceType returns the cheapest instance type with at least cpu vCPUs
+// ("2", "0.5" or "500m") and memory ("4Gi" or "512Mi").
+func InstanceType(cpu, memory string) (string, error) {
+	vCPUs, err := parseCPU(cpu)
+	if err != nil {
+		return "", err
+	}
+	memMiB, err := parseMemory(memory)
+	if err != nil {
+		return "", err
+	}
+
+	for _, t := range instanceTypes {
+		if t.vCPUs >= vCPUs && t.memMiB >= memMiB {
+			return t.name, nil
+		}
+	}
+	largest := instanceTypes[len(instanceTypes)-1]
+	return "", fmt.Errorf("no instance type has %s vCPUs and %s memory (largest is %s: %g vCPUs, %dGi)",
+		cpu, memory, largest.name, largest.vCPUs, largest.memMiB/1024)
+}
+
+func parseCPU(s string) (float64, error) {
+	v, milli := strings.CutSuffix(s, "m")
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("cpu %q must be a number of vCPUs, e.g. 2 or 500m", s)
+	}
+	if milli {
+		n /= 1000
+	}
+	return n, nil
+}
+
+func parseMemory(s string) (int, error) {
+	unit := 0
+	v := s
+	switch {
+	case strings.HasSuffix(s, "Gi"):
+		unit, v = 1024, strings.TrimSuffix(s, "Gi")
+	case strings.HasSuffix(s, "Mi"):
+		unit, v = 1, strings.TrimSuffix(s, "Mi")
+	}
+	n, err := strconv.Atoi(v)
+	if unit == 0 || err != nil || n <= 0 {
+		return 0, fmt.Errorf("memory %q must be in Mi or Gi, e.g. 512Mi or 4Gi", s)
+	}
+	return n * unit, nil
+}
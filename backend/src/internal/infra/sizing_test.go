@@ -0,0 +1,29 @@
+package infra
+
+import "testing"
+
+func TestInstanceType(t *testing.T) {
+	tests := []struct {
+		cpu, memory string
+		want        string
+	}{
+		{"500m", "512Mi", "t3.micro"},
+		{"2", "4Gi", "t3.medium"},
+		{"2", "4097Mi", "t3.large"},
+		{"0.5", "16Gi", "t3.xlarge"},
+		{"3", "1Gi", "t3.xlarge"},
+		{"32", "128Gi", "m5.8xlarge"},
+	}
+	for _, tt := range tests {
+		got, err := InstanceType(tt.cpu, tt.memory)
+		if err != nil || got != tt.want {
+			t.Errorf("InstanceType(%q, %q) = %q, %v; want %q", tt.cpu, tt.memory, got, err, tt.want)
+		}
+	}
+
+	for _, bad := range [][2]string{{"64", "4Gi"}, {"2", "256Gi"}, {"two", "4Gi"}, {"2", "4GB"}, {"0", "4Gi"}} {
+		if got, err := InstanceType(bad[0], bad[1]); err == nil {
+			t.Errorf("InstanceType(%q, %q) = %q, want error", bad[0], bad[1], got)
+		}
+	}
+}
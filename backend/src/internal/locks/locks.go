@@ -0,0 +1,226 @@
+// Package locks serialises deploys and rollbacks per service/environment.
+// A lock row lives in MySQL so every backend replica sees it; it is taken
+// when a pipeline is triggered and dropped when the pipeline reports back
+// through the artifact API, or when its TTL runs out.
+package locks
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"src/src/internal/config"
+	"src/src/internal/db"
+)
+
+var ErrNotLocked = errors.New("no active deployment lock")
+
+// Lock is an active claim on a service/environment.
+type Lock struct {
+	ServiceName string    `json:"serviceName"`
+	Environment string    `json:"environment"`
+	Operation   string    `json:"operation"` // deploy | rollback
+	Owner       string    `json:"owner"`
+	Reason      string    `json:"reason"`
+	AcquiredAt  time.Time `json:"acquiredAt"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+
+	token string // identifies this holder for Release
+}
+
+// LockedError is returned by Acquire when another run holds the lock.
+type LockedError struct {
+	Held Lock
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf(
+		"%s/%s is locked by %s (%s) until %s",
+		e.Held.ServiceName, e.Held.Environment, e.Held.Owner, e.Held.Operation,
+		e.Held.ExpiresAt.UTC().Format(time.RFC3339),
+	)
+}
+
+var ttl = config.Default().Deployments.LockTTL
+
+// Configure sets how long a lock survives without its pipeline reporting back.
+func Configure(cfg config.Deployments) {
+	ttl = cfg.LockTTL
+}
+
+// now is replaced in tests.
+var now = func() time.Time { return time.Now().UTC().Truncate(time.Second) }
+
+// Acquire claims serviceName/env for one deploy or rollback. An expired
+// lock is taken over; a live one yields *LockedError.
+func Acquire(ctx context.Context, serviceName, env, operation, owner, reason string) (*Lock, error) {
+	l := &Lock{
+		ServiceName: serviceName,
+		Environment: env,
+		Operation:   operation,
+		Owner:       owner,
+		Reason:      reason,
+		AcquiredAt:  now(),
+		token:       newToken(),
+	}
+	l.ExpiresAt = l.AcquiredAt.Add(ttl)
+
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// 1️⃣ Drop a lock whose run never reported back
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM deployment_locks
+		WHERE service_name = ? AND environment = ? AND expires_at <= ?`,
+		serviceName, env, l.AcquiredAt,
+	); err != nil {
+		return nil, err
+	}
+
+	// 2️⃣ Claim; the primary key makes this the arbiter between replicas
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO deployment_locks
+		(service_name, environment, token, operation, owner, reason, acquired_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		l.ServiceName, l.Environment, l.token, l.Operation, l.Owner, l.Reason, l.AcquiredAt, l.ExpiresAt,
+	)
+	if db.IsDuplicateKey(err) {
+		held, getErr := get(ctx, tx, serviceName, env)
+		if getErr != nil {
+			return nil, getErr
+		}
+		return nil, &LockedError{Held: *held}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🔒 Locked %s/%s for %s by %s until %s", serviceName, env, operation, owner, l.ExpiresAt.Format(time.RFC3339))
+	return l, nil
+}
+
+// Release drops l if it is still held by the same run. Used when the
+// trigger itself fails; a lock already taken over is left alone.
+func Release(ctx context.Context, l *Lock) error {
+	_, err := db.DB.ExecContext(ctx, `
+		DELETE FROM deployment_locks
+		WHERE service_name = ? AND environment = ? AND token = ?`,
+		l.ServiceName, l.Environment, l.token,
+	)
+	if err == nil {
+		log.Printf("🔓 Released %s/%s", l.ServiceName, l.Environment)
+	}
+	return err
+}
+
+// Finish releases whatever run holds serviceName/env. Called when a
+// pipeline reports its result.
+func Finish(ctx context.Context, serviceName, env string) error {
+	res, err := db.DB.ExecContext(ctx, `
+		DELETE FROM deployment_locks
+		WHERE service_name = ? AND environment = ?`,
+		serviceName, env,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		log.Printf("🔓 Run finished, released %s/%s", serviceName, env)
+	}
+	return nil
+}
+
+// ForceRelease drops the lock regardless of holder and returns what was
+// held, or ErrNotLocked.
+func ForceRelease(ctx context.Context, serviceName, env, by string) (*Lock, error) {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	held, err := get(ctx, tx, serviceName, env)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !held.ExpiresAt.After(now())) {
+		return nil, ErrNotLocked
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM deployment_locks
+		WHERE service_name = ? AND environment = ?`,
+		serviceName, env,
+	); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("⚠️ Lock on %s/%s (held by %s) force-released by %s", serviceName, env, held.Owner, by)
+	return held, nil
+}
+
+// List returns unexpired locks, for one service when serviceName is set.
+func List(ctx context.Context, serviceName string) ([]Lock, error) {
+	query := `
+		SELECT service_name, environment, operation, owner, reason, acquired_at, expires_at
+		FROM deployment_locks
+		WHERE expires_at > ?`
+	args := []interface{}{now()}
+	if serviceName != "" {
+		query += ` AND service_name = ?`
+		args = append(args, serviceName)
+	}
+	query += ` ORDER BY service_name, environment`
+
+	rows, err := db.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Lock{}
+	for rows.Next() {
+		var l Lock
+		if err := rows.Scan(&l.ServiceName, &l.Environment, &l.Operation, &l.Owner, &l.Reason, &l.AcquiredAt, &l.ExpiresAt); err != nil {
+			return nil, err
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
+
+func get(ctx context.Context, tx *sql.Tx, serviceName, env string) (*Lock, error) {
+	l := &Lock{}
+	err := tx.QueryRowContext(ctx, `
+		SELECT service_name, environment, operation, owner, reason, acquired_at, expires_at
+		FROM deployment_locks
+		WHERE service_name = ? AND environment = ?
+		FOR UPDATE`,
+		serviceName, env,
+	).Scan(&l.ServiceName, &l.Environment, &l.Operation, &l.Owner, &l.Reason, &l.AcquiredAt, &l.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,182 @@
+package locks
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+
+	"src/src/internal/db"
+)
+
+var (
+	t0          = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	lockColumns = []string{"service_name", "environment", "operation", "owner", "reason", "acquired_at", "expires_at"}
+)
+
+func mockDB(t *testing.T) sqlmock.Sqlmock {
+	t.Helper()
+
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	db.DB = conn
+	return mock
+}
+
+// at sets the clock; tests move it forward to run a lock past its TTL.
+func at(t *testing.T, when time.Time) {
+	t.Helper()
+
+	prev := now
+	now = func() time.Time { return when }
+	t.Cleanup(func() { now = prev })
+}
+
+func expectAcquired(m sqlmock.Sqlmock, when time.Time, expiredRows int64) {
+	m.ExpectBegin()
+	m.ExpectExec("DELETE FROM deployment_locks").WithArgs("orders", "dev", when).
+		WillReturnResult(sqlmock.NewResult(0, expiredRows))
+	m.ExpectExec("INSERT INTO deployment_locks").
+		WithArgs("orders", "dev", sqlmock.AnyArg(), "deploy", "alice", "", when, when.Add(ttl)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	m.ExpectCommit()
+}
+
+func TestAcquire(t *testing.T) {
+	m := mockDB(t)
+	at(t, t0)
+	expectAcquired(m, t0, 0)
+
+	l, err := Acquire(context.Background(), "orders", "dev", "deploy", "alice", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !l.AcquiredAt.Equal(t0) || !l.ExpiresAt.Equal(t0.Add(ttl)) || l.token == "" {
+		t.Errorf("lock = %+v", l)
+	}
+	if err := m.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAcquireHeld(t *testing.T) {
+	m := mockDB(t)
+	at(t, t0.Add(time.Minute))
+
+	m.ExpectBegin()
+	m.ExpectExec("DELETE FROM deployment_locks").WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec("INSERT INTO deployment_locks").WillReturnError(&mysql.MySQLError{Number: 1062})
+	m.ExpectQuery("FOR UPDATE").WithArgs("orders", "dev").WillReturnRows(sqlmock.NewRows(lockColumns).
+		AddRow("orders", "dev", "rollback", "bob", "", t0, t0.Add(ttl)))
+	m.ExpectRollback()
+
+	_, err := Acquire(context.Background(), "orders", "dev", "deploy", "alice", "")
+	var locked *LockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("got %v, want *LockedError", err)
+	}
+	if locked.Held.Owner != "bob" || locked.Held.Operation != "rollback" {
+		t.Errorf("held = %+v", locked.Held)
+	}
+	if err := m.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestExpiredLockIsTakenOver(t *testing.T) {
+	m := mockDB(t)
+
+	// alice's run never reports back
+	at(t, t0)
+	expectAcquired(m, t0, 0)
+	stale, err := Acquire(context.Background(), "orders", "dev", "deploy", "alice", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Once the TTL has run out her row is dropped and the lock re-taken
+	later := t0.Add(ttl)
+	at(t, later)
+	expectAcquired(m, later, 1)
+	current, err := Acquire(context.Background(), "orders", "dev", "deploy", "alice", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stale.token == current.token {
+		t.Fatal("takeover reused the stale token")
+	}
+
+	// A late Release from the stale run only matches its own token
+	m.ExpectExec("DELETE FROM deployment_locks").WithArgs("orders", "dev", stale.token).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := Release(context.Background(), stale); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestForceRelease(t *testing.T) {
+	tests := []struct {
+		name    string
+		at      time.Time
+		wantErr error
+	}{
+		{"live lock", t0.Add(time.Minute), nil},
+		{"expired lock", t0.Add(ttl), ErrNotLocked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mockDB(t)
+			at(t, tt.at)
+
+			m.ExpectBegin()
+			m.ExpectQuery("FOR UPDATE").WithArgs("orders", "dev").WillReturnRows(sqlmock.NewRows(lockColumns).
+				AddRow("orders", "dev", "deploy", "bob", "", t0, t0.Add(ttl)))
+			if tt.wantErr == nil {
+				m.ExpectExec("DELETE FROM deployment_locks").WithArgs("orders", "dev").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			} else {
+				m.ExpectRollback()
+			}
+
+			held, err := ForceRelease(context.Background(), "orders", "dev", "admin")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && held.Owner != "bob" {
+				t.Errorf("held = %+v", held)
+			}
+			if err := m.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestListHidesExpired(t *testing.T) {
+	m := mockDB(t)
+	at(t, t0)
+
+	m.ExpectQuery("WHERE expires_at > \\? AND service_name = \\?").WithArgs(t0, "orders").
+		WillReturnRows(sqlmock.NewRows(lockColumns))
+
+	found, err := List(context.Background(), "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found == nil || len(found) != 0 {
+		t.Errorf("found = %#v, want an empty list", found)
+	}
+	if err := m.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,76 @@
+package model
+
+import "time"
+
+// Where a configuration version is rendered, relative to the service.
+// ConfigFileShared merges the values into the template's config.json on
+// the environment's branch; the default is a file of the environment's own.
+const ConfigFileShared = "config.json"
+
+// EnvConfigFile is the environment's own configuration file.
+func EnvConfigFile(env string) string {
+	return "config." + env + ".json"
+}
+
+// How a configuration version reaches the repository.
+const (
+	ConfigCommit      = "commit"       // pushed to the environment's branch
+	ConfigPullRequest = "pull_request" // opened as a pull request into it
+)
+
+// Configuration version states. A version is Pending while it is written to
+// the repository; Failed versions never reached it and are skipped by
+// Current, history keeps them.
+const (
+	ConfigPending   = "pending"
+	ConfigCommitted = "committed"
+	ConfigProposed  = "proposed" // pull request opened
+	ConfigFailed    = "failed"
+)
+
+// ServiceConfig is one version of a service's configuration in an
+// environment.
+type ServiceConfig struct {
+	ServiceName    string            `json:"serviceName"`
+	Environment    string            `json:"environment"`
+	Version        int               `json:"version"`
+	Values         map[string]string `json:"values"`
+	File           string            `json:"file"` // path in the repository
+	Delivery       string            `json:"delivery"`
+	Status         string            `json:"status"`
+	CommitSHA      string            `json:"commitSha,omitempty"`
+	PullRequestURL string            `json:"pullRequestUrl,omitempty"`
+	Error          string            `json:"error,omitempty"`
+	Message        string            `json:"message,omitempty"`
+	UpdatedBy      string            `json:"updatedBy"`
+	UpdatedAt      time.Time         `json:"updatedAt"`
+}
+
+// ConfigUpdate replaces the configuration of an environment.
+type ConfigUpdate struct {
+	Values   map[string]string `json:"values"`
+	File     string            `json:"file"`     // ConfigFileShared or EnvConfigFile(env); defaults to the current file
+	Delivery string            `json:"delivery"` // commit | pull_request; prod defaults to pull_request, protected branches always get one
+	Message  string            `json:"message"`
+
+	// Version the change was made against; a newer current version
+	// rejects it instead of overwriting someone else's change
+	BaseVersion *int `json:"baseVersion,omitempty"`
+}
+
+// ConfigDiff is what changed between two versions of an environment's
+// configuration. Version 0 is the empty configuration.
+type ConfigDiff struct {
+	ServiceName string                  `json:"serviceName"`
+	Environment string                  `json:"environment"`
+	From        int                     `json:"from"`
+	To          int                     `json:"to"`
+	Added       map[string]string       `json:"added"`
+	Removed     map[string]string       `json:"removed"`
+	Changed     map[string]ConfigChange `json:"changed"`
+}
+
+type ConfigChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// FreezeWindow blocks deploys, rollbacks and approvals while it is active.
+// Empty Environment / OwnerTeam match every environment / team, so a window
+// with neither is global.
+//
+// A one-off window has no Schedule and runs from StartsAt to EndsAt. A
+// recurring window has a cron ("0 18 * * FRI") or RRULE
+// ("FREQ=MONTHLY;BYMONTHDAY=-1") Schedule, evaluated in Timezone, and each
+// occurrence lasts DurationMinutes; StartsAt/EndsAt then bound when the
+// schedule applies.
+type FreezeWindow struct {
+	ID              int64      `json:"id"`
+	Name            string     `json:"name"`
+	Environment     string     `json:"environment,omitempty"`
+	OwnerTeam       string     `json:"ownerTeam,omitempty"`
+	Schedule        string     `json:"schedule,omitempty"`
+	DurationMinutes int        `json:"durationMinutes,omitempty"`
+	Timezone        string     `json:"timezone"`
+	StartsAt        *time.Time `json:"startsAt,omitempty"`
+	EndsAt          *time.Time `json:"endsAt,omitempty"`
+	Reason          string     `json:"reason"`
+	CreatedBy       string     `json:"createdBy"`
+	CreatedAt       time.Time  `json:"createdAt"`
+}
+
+// FreezeExemption lets one service/environment through one window until
+// ExpiresAt, once someone other than the requester approves it.
+type FreezeExemption struct {
+	ID          int64      `json:"id"`
+	WindowID    int64      `json:"windowId"`
+	ServiceName string     `json:"serviceName"`
+	Environment string     `json:"environment"`
+	Reason      string     `json:"reason"`
+	RequestedBy string     `json:"requestedBy"`
+	Status      string     `json:"status"` // pending | approved | rejected
+	DecidedBy   string     `json:"decidedBy,omitempty"`
+	ExpiresAt   time.Time  `json:"expiresAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	DecidedAt   *time.Time `json:"decidedAt,omitempty"`
+}
@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// HealthCheck is probed after every successful deploy of ServiceName to
+// Environment. URL must answer ExpectedStatus within TimeoutSeconds; it is
+// probed every IntervalSeconds and the deploy passes once it has stayed
+// healthy for WindowSeconds. FailureThreshold consecutive failures roll the
+// environment back to the version it ran before.
+type HealthCheck struct {
+	ServiceName      string    `json:"serviceName"`
+	Environment      string    `json:"environment"`
+	URL              string    `json:"url"`
+	ExpectedStatus   int       `json:"expectedStatus"`
+	WindowSeconds    int       `json:"windowSeconds"`
+	IntervalSeconds  int       `json:"intervalSeconds"`
+	TimeoutSeconds   int       `json:"timeoutSeconds"`
+	FailureThreshold int       `json:"failureThreshold"`
+	UpdatedBy        string    `json:"updatedBy"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// Health check run states. Running is the only non-final one.
+const (
+	HealthRunning    = "running"
+	HealthPassed     = "passed"
+	HealthRolledBack = "rolled_back" // failed; rollback triggered
+	HealthFailed     = "failed"      // failed; not rolled back (see Detail)
+)
+
+// HealthCheckRun is one verification of a deployed Version. Detail says
+// why it failed and what the platform did about it.
+type HealthCheckRun struct {
+	ID              int64      `json:"id"`
+	ServiceName     string     `json:"serviceName"`
+	Environment     string     `json:"environment"`
+	Version         string     `json:"version"`
+	PreviousVersion string     `json:"previousVersion,omitempty"`
+	Status          string     `json:"status"`
+	Detail          string     `json:"detail,omitempty"`
+	StartedAt       time.Time  `json:"startedAt"`
+	FinishedAt      *time.Time `json:"finishedAt,omitempty"`
+}
@@ -0,0 +1,75 @@
+package model
+
+import "time"
+
+// InfraSpec is the infrastructure an ec2 service asks for. The platform
+// sizes an instance type from CPU (vCPUs, e.g. "2" or "500m") and Memory
+// (e.g. "4Gi" or "512Mi") and renders a Terraform root module per
+// environment on the platform modules of ModuleVersion.
+type InfraSpec struct {
+	Cloud         string `json:"cloud" yaml:"cloud"`                 // aws
+	Compute       string `json:"compute" yaml:"compute"`             // ec2
+	CPU           string `json:"cpu" yaml:"cpu"`                     // defaults to 2
+	Memory        string `json:"memory" yaml:"memory"`               // defaults to 4Gi
+	ModuleVersion string `json:"moduleVersion" yaml:"moduleVersion"` // defaults to terraform.moduleVersion
+}
+
+// InfraStack is an environment's Terraform root module and where its state
+// lives.
+type InfraStack struct {
+	ServiceName   string    `json:"serviceName"`
+	Environment   string    `json:"environment"`
+	Cloud         string    `json:"cloud"`
+	Compute       string    `json:"compute"`
+	CPU           string    `json:"cpu"`
+	Memory        string    `json:"memory"`
+	InstanceType  string    `json:"instanceType"`
+	ModuleVersion string    `json:"moduleVersion"`
+	RootModule    string    `json:"rootModule"`    // path in the repository
+	StateLocation string    `json:"stateLocation"` // s3://bucket/key
+	CreatedAt     time.Time `json:"createdAt"`
+	LastPlan      *InfraRun `json:"lastPlan,omitempty"`
+	LastApply     *InfraRun `json:"lastApply,omitempty"`
+}
+
+// Infra run actions.
+const (
+	InfraPlan  = "plan"
+	InfraApply = "apply"
+)
+
+// Infra run states. A run is Running from dispatch until its pipeline
+// reports Succeeded or Failed.
+const (
+	InfraRunning   = "running"
+	InfraSucceeded = "succeeded"
+	InfraFailed    = "failed"
+)
+
+// InfraRun is one plan or apply of an environment's root module through
+// the service's CI system.
+type InfraRun struct {
+	ID            int64      `json:"id"`
+	ServiceName   string     `json:"serviceName"`
+	Environment   string     `json:"environment"`
+	Action        string     `json:"action"`
+	Status        string     `json:"status"`
+	StateLocation string     `json:"stateLocation"`
+	Output        string     `json:"output,omitempty"` // plan / apply output
+	RunURL        string     `json:"runUrl,omitempty"` // CI run
+	RequestedBy   string     `json:"requestedBy"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	FinishedAt    *time.Time `json:"finishedAt,omitempty"`
+}
+
+type InfraRunRequest struct {
+	Environment string `json:"environment"`
+	Action      string `json:"action"` // plan | apply
+}
+
+// InfraRunResult is what the plan/apply pipeline reports back.
+type InfraRunResult struct {
+	Status string `json:"status"` // succeeded | failed
+	Output string `json:"output"`
+	RunURL string `json:"runUrl"`
+}
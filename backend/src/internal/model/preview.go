@@ -0,0 +1,53 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Preview environments are named after their pull request.
+const PreviewPrefix = "pr-"
+
+// PreviewEnvironment is the environment of pull request number.
+func PreviewEnvironment(number int) string {
+	return PreviewPrefix + strconv.Itoa(number)
+}
+
+// IsPreviewEnvironment reports whether env is a pr-<number> environment.
+func IsPreviewEnvironment(env string) bool {
+	n, ok := strings.CutPrefix(env, PreviewPrefix)
+	if !ok || n == "" || n[0] == '0' {
+		return false
+	}
+	_, err := strconv.ParseUint(n, 10, 31)
+	return err == nil
+}
+
+// Preview states. A preview is Deploying from the webhook until its
+// pipeline reports the deploy, then Ready; Closed ones have been torn down.
+const (
+	PreviewDeploying = "deploying"
+	PreviewReady     = "ready"
+	PreviewFailed    = "failed" // the pipeline could not be started
+	PreviewClosed    = "closed"
+)
+
+// Preview is a service deployed from a pull request's head.
+type Preview struct {
+	ServiceName string     `json:"serviceName"`
+	Environment string     `json:"environment"` // pr-<number>
+	PullRequest int        `json:"pullRequest"`
+	Repository  string     `json:"repository"` // owner/name
+	HeadRef     string     `json:"headRef"`
+	HeadSHA     string     `json:"headSha"`
+	BaseRef     string     `json:"baseRef"`
+	Status      string     `json:"status"`
+	Version     string     `json:"version,omitempty"` // reported by the pipeline
+	URL         string     `json:"url,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	ExpiresAt   time.Time  `json:"expiresAt"` // pushes to the pull request extend it
+	ClosedAt    *time.Time `json:"closedAt,omitempty"`
+}
@@ -0,0 +1,78 @@
+package model
+
+import "time"
+
+// Deployment strategies.
+const (
+	StrategyAllAtOnce = "all-at-once"
+	StrategyCanary    = "canary"
+	StrategyBlueGreen = "blue-green"
+)
+
+// DeploymentStrategy is how deploys of ServiceName to Environment roll out.
+// A canary shifts traffic to the new version in CanarySteps (percentages
+// ending at 100); blue-green deploys next to the live version, then
+// switches all traffic at once. Each step is its own pipeline run; the next
+// one starts on promote, or StepIntervalSeconds after the previous step
+// reported back when that is set.
+type DeploymentStrategy struct {
+	ServiceName         string    `json:"serviceName"`
+	Environment         string    `json:"environment"`
+	Strategy            string    `json:"strategy"`
+	CanarySteps         []int     `json:"canarySteps,omitempty"`
+	StepIntervalSeconds int       `json:"stepIntervalSeconds,omitempty"`
+	UpdatedBy           string    `json:"updatedBy"`
+	UpdatedAt           time.Time `json:"updatedAt"`
+}
+
+// Rollout states. Progressing means a step's pipeline is running; Waiting
+// means it reported back and the next step has not started. Completed,
+// Aborted and Failed are final.
+const (
+	RolloutProgressing = "progressing"
+	RolloutWaiting     = "waiting"
+	RolloutPaused      = "paused"
+	RolloutAborting    = "aborting"
+	RolloutCompleted   = "completed"
+	RolloutAborted     = "aborted"
+	RolloutFailed      = "failed"
+)
+
+// Rollout is one canary or blue-green deploy, step by step.
+type Rollout struct {
+	ID                  int64         `json:"id"`
+	ServiceName         string        `json:"serviceName"`
+	Environment         string        `json:"environment"`
+	Strategy            string        `json:"strategy"`
+	Steps               []int         `json:"steps"`       // traffic percent after each step
+	CurrentStep         int           `json:"currentStep"` // 1-based; running or last finished
+	StepIntervalSeconds int           `json:"stepIntervalSeconds,omitempty"`
+	Version             string        `json:"version,omitempty"`         // built by step 1
+	PreviousVersion     string        `json:"previousVersion,omitempty"` // restored by abort
+	Status              string        `json:"status"`
+	NextStepAt          *time.Time    `json:"nextStepAt,omitempty"`
+	StartedBy           string        `json:"startedBy"`
+	CreatedAt           time.Time     `json:"createdAt"`
+	FinishedAt          *time.Time    `json:"finishedAt,omitempty"`
+	History             []RolloutStep `json:"history"`
+}
+
+// Rollout step states.
+const (
+	StepRunning   = "running"
+	StepSucceeded = "succeeded"
+	StepFailed    = "failed"
+)
+
+// RolloutStep is one pipeline run of a rollout: a traffic step, or the
+// abort that took the environment back to the previous version.
+type RolloutStep struct {
+	Step           int        `json:"step"`
+	Phase          string     `json:"phase"` // step | abort
+	TrafficPercent int        `json:"trafficPercent"`
+	Status         string     `json:"status"`
+	TriggeredBy    string     `json:"triggeredBy"`
+	TriggeredAt    time.Time  `json:"triggeredAt"`
+	FinishedAt     *time.Time `json:"finishedAt,omitempty"`
+	Error          string     `json:"error,omitempty"`
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// Scheduled deployment states. Scheduled runs may be cancelled; the rest
+// are final except Running, which the scheduler sets while it works on one.
+const (
+	ScheduleScheduled = "scheduled"
+	ScheduleRunning   = "running"
+	ScheduleTriggered = "triggered"
+	ScheduleSkipped   = "skipped"
+	ScheduleFailed    = "failed"
+	ScheduleCancelled = "cancelled"
+)
+
+// ScheduledDeployment is a deploy queued for ScheduledAt. A prod run is
+// tied to the approval requested with it and only starts once that is
+// approved.
+type ScheduledDeployment struct {
+	ID          int64      `json:"id"`
+	ServiceName string     `json:"serviceName"`
+	Environment string     `json:"environment"`
+	ScheduledAt time.Time  `json:"scheduledAt"`
+	ApprovalID  *int64     `json:"approvalId,omitempty"`
+	Status      string     `json:"status"`
+	Reason      string     `json:"reason,omitempty"`
+	RequestedBy string     `json:"requestedBy"`
+	LastError   string     `json:"lastError,omitempty"` // why it is waiting, or did not run
+	CreatedAt   time.Time  `json:"createdAt"`
+	ExecutedAt  *time.Time `json:"executedAt,omitempty"`
+}
@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 type EnvironmentStatus string
 
 const (
@@ -19,32 +21,33 @@ type CreateServiceRequest struct {
 	DeployType 		string `yaml:"deploytype" json:"deploytype"`
 	Environments    []string `json:"environments" yaml:"environments"`
 	EnableWebhook   bool     `json:"enableWebhook" yaml:"enableWebhook"`
+	RepoMode        string   `json:"repoMode" yaml:"repoMode"`     // standalone | monorepo
+	TargetRepo      string   `json:"targetRepo" yaml:"targetRepo"` // monorepo: existing repo, "owner/name" or "name"
+	RepoPath        string   `json:"repoPath" yaml:"repoPath"`     // monorepo: service directory inside TargetRepo
+	Repository      RepositorySpec `json:"repository" yaml:"repository"`
+	Secrets         []ServiceSecret `json:"secrets" yaml:"secrets"`
+	Infra           *InfraSpec      `json:"infra,omitempty" yaml:"infra"` // ec2: Terraform per environment
 	// RuntimeVersion string `yaml:"runtimeVersion"`
 	// Environment string `yaml:"environment"`
 	// Region      string `yaml:"region"`
-	// Repository RepositorySpec `yaml:"repository"`
 	// CI         CISpec         `yaml:"ci"`
-	// Infra      InfraSpec      `yaml:"infra"`
 	// Metadata   MetadataSpec   `yaml:"metadata"`
 }
 
-// type RepositorySpec struct {
-// 	Visibility    string `yaml:"visibility"`
-// 	DefaultBranch string `yaml:"defaultBranch"`
-// }
+type RepositorySpec struct {
+	Org            string   `json:"org" yaml:"org"`                       // empty → token owner's account
+	Visibility     string   `json:"visibility" yaml:"visibility"`         // private (default) | internal | public
+	DefaultBranch  string   `json:"defaultBranch" yaml:"defaultBranch"`   // defaults to dev
+	Description    string   `json:"description" yaml:"description"`
+	Topics         []string `json:"topics" yaml:"topics"`
+	TeamPermission string   `json:"teamPermission" yaml:"teamPermission"` // ownerTeam access, defaults to push
+}
 
 // type CISpec struct {
 // 	Enabled bool   `yaml:"enabled"`
 // 	Tool    string `yaml:"tool"`
 // }
 
-// type InfraSpec struct {
-// 	Cloud   string `yaml:"cloud"`
-// 	Compute string `yaml:"compute"`
-// 	CPU     string `yaml:"cpu"`
-// 	Memory  string `yaml:"memory"`
-// }
-
 // type MetadataSpec struct {
 // 	Description  string `yaml:"description"`
 // 	CostCenter   string `yaml:"costCenter"`
@@ -53,6 +56,46 @@ type CreateServiceRequest struct {
 
 
 
+const (
+	RepoModeStandalone = "standalone"
+	RepoModeMonorepo   = "monorepo"
+)
+
+// Deploy types. A kubernetes service's pipeline only builds its image; the
+// platform deploys it by committing the image tag to a GitOps repository.
+const (
+	DeployTypeEC2          = "ec2"
+	DeployTypeMicroservice = "microservice"
+	DeployTypeKubernetes   = "kubernetes"
+)
+
+type CreateServiceResponse struct {
+	RepoURL        string `json:"repoUrl"`
+	PullRequestURL string `json:"pullRequestUrl,omitempty"`
+}
+
+type ImportServiceRequest struct {
+	ServiceName       string   `json:"serviceName"`
+	Repo              string   `json:"repo"` // "owner/name" or "name"
+	OwnerTeam         string   `json:"ownerTeam"`
+	Environments      []string `json:"environments"`
+	Runtime           string   `json:"runtime,omitempty"`    // detected when empty
+	CICDType          string   `json:"cicdType,omitempty"`   // detected when empty
+	DeployType        string   `json:"deploytype,omitempty"` // defaults to microservice
+	RegisterJenkins   bool     `json:"registerJenkins"`
+	EnableWebhook     bool     `json:"enableWebhook"`
+	AddPlatformConfig bool     `json:"addPlatformConfig"` // PR adding config.json + artifact callback
+}
+
+type ImportServiceResponse struct {
+	ServiceName    string            `json:"serviceName"`
+	RepoURL        string            `json:"repoUrl"`
+	Runtime        string            `json:"runtime"`
+	CICDType       string            `json:"cicdType"`
+	PullRequestURL string            `json:"pullRequestUrl,omitempty"`
+	Environments   map[string]string `json:"environments"` // env → seeded version
+}
+
 type ArtifactEvent struct {
 	ServiceName  string `json:"serviceName"`
 	Environment  string `json:"environment"`
@@ -70,3 +113,40 @@ type ArtifactEvent struct {
 
 
 
+
+type BranchCompliance struct {
+	Environment string   `json:"environment"`
+	Branch      string   `json:"branch"`
+	Exists      bool     `json:"exists"`
+	Protected   bool     `json:"protected"`
+	Compliant   bool     `json:"compliant"`
+	Issues      []string `json:"issues,omitempty"`
+}
+
+type ProtectionReport struct {
+	ServiceName string             `json:"serviceName"`
+	Repo        string             `json:"repo"` // owner/name
+	Compliant   bool               `json:"compliant"`
+	Branches    []BranchCompliance `json:"branches"`
+}
+
+// ServiceSecret is a value written to the service's CI (GitHub Actions
+// environment secret or Jenkins folder credential). Exactly one of Value
+// and ValueFrom (a platform secret reference) is set.
+type ServiceSecret struct {
+	Name        string `json:"name" yaml:"name"`
+	Environment string `json:"environment,omitempty" yaml:"environment"` // "" → every environment
+	Value       string `json:"value,omitempty" yaml:"value"`
+	ValueFrom   string `json:"valueFrom,omitempty" yaml:"valueFrom"` // e.g. vault:teams/payments#registry
+}
+
+type PutSecretsRequest struct {
+	Secrets []ServiceSecret `json:"secrets"`
+}
+
+type SecretMetadata struct {
+	Name        string    `json:"name"`
+	Environment string    `json:"environment"`
+	Store       string    `json:"store"` // github | jenkins
+	RotatedAt   time.Time `json:"rotatedAt"`
+}
@@ -0,0 +1,73 @@
+// Package notify tells a service's owner team about things the platform did
+// on its own, such as an automatic rollback. Messages are posted as JSON to
+// the configured webhook (a chat or paging integration); without one they
+// are only logged.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"src/src/internal/config"
+)
+
+// Message is the webhook payload.
+type Message struct {
+	Team        string `json:"team"`
+	ServiceName string `json:"serviceName"`
+	Environment string `json:"environment"`
+	Subject     string `json:"subject"`
+	Text        string `json:"text"`
+}
+
+var (
+	webhookURL string
+	client     = &http.Client{Timeout: 10 * time.Second}
+)
+
+// Configure sets the webhook messages are posted to.
+func Configure(cfg config.Notifications) {
+	webhookURL = cfg.WebhookURL
+}
+
+// Send delivers m. It is always logged, so a failed webhook loses nothing
+// but the push.
+func Send(ctx context.Context, m Message) error {
+	log.Printf("📣 [%s] %s/%s: %s — %s", orUnowned(m.Team), m.ServiceName, m.Environment, m.Subject, m.Text)
+	if webhookURL == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("notify webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("notify webhook: %s", resp.Status)
+	}
+	return nil
+}
+
+func orUnowned(team string) string {
+	if team == "" {
+		return "no owner team"
+	}
+	return team
+}
@@ -0,0 +1,638 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+/* ===================== SPEC MODEL ===================== */
+
+// Only the parts of OpenAPI 3 the platform spec uses.
+
+type document struct {
+	Paths      orderedMap `json:"paths"`
+	Components struct {
+		Schemas    orderedMap           `json:"schemas"`
+		Parameters map[string]parameter `json:"parameters"`
+	} `json:"components"`
+}
+
+type schema struct {
+	Ref                  string     `json:"$ref"`
+	Type                 string     `json:"type"`
+	Format               string     `json:"format"`
+	Description          string     `json:"description"`
+	Enum                 []string   `json:"enum"`
+	Nullable             bool       `json:"nullable"`
+	Required             []string   `json:"required"`
+	Properties           orderedMap `json:"properties"`
+	Items                *schema    `json:"items"`
+	AdditionalProperties *schema    `json:"additionalProperties"`
+}
+
+type parameter struct {
+	Ref      string  `json:"$ref"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *schema `json:"schema"`
+}
+
+type operation struct {
+	OperationID string      `json:"operationId"`
+	Summary     string      `json:"summary"`
+	Deprecated  bool        `json:"deprecated"`
+	Parameters  []parameter `json:"parameters"`
+	RequestBody *struct {
+		Content map[string]struct {
+			Schema *schema `json:"schema"`
+		} `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]struct {
+			Schema *schema `json:"schema"`
+		} `json:"content"`
+	} `json:"responses"`
+}
+
+// orderedMap keeps object keys in document order so generated fields and
+// methods follow the spec instead of map iteration order.
+type orderedMap struct {
+	keys   []string
+	values map[string]json.RawMessage
+}
+
+func (m *orderedMap) UnmarshalJSON(data []byte) error {
+	m.values = map[string]json.RawMessage{}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil { // {
+		return err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key := tok.(string)
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		m.keys = append(m.keys, key)
+		m.values[key] = raw
+	}
+	return nil
+}
+
+/* ===================== GENERATOR ===================== */
+
+var methodOrder = []string{"get", "post", "put", "patch", "delete"}
+
+// GenerateClient renders a typed Go client for every non-deprecated
+// operation in spec. Schemas become structs (or string enums) with json and
+// yaml tags; errors come back as *APIError, which embeds the spec's
+// ErrorResponse schema.
+func GenerateClient(spec []byte, pkg string) ([]byte, error) {
+	var doc document
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("parse spec: %w", err)
+	}
+
+	g := &generator{doc: &doc, imports: map[string]bool{
+		"bytes":         true,
+		"context":       true,
+		"encoding/json": true,
+		"fmt":           true,
+		"io":            true,
+		"net/http":      true,
+		"net/url":       true,
+		"strings":       true,
+	}}
+
+	if _, ok := doc.Components.Schemas.values["ErrorResponse"]; !ok {
+		return nil, fmt.Errorf("spec has no ErrorResponse schema for APIError")
+	}
+
+	// 1️⃣ Types
+	for _, name := range doc.Components.Schemas.keys {
+		var s schema
+		if err := json.Unmarshal(doc.Components.Schemas.values[name], &s); err != nil {
+			return nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+		if err := g.typeDecl(name, &s); err != nil {
+			return nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+	}
+
+	// 2️⃣ Operations
+	for _, path := range doc.Paths.keys {
+		var item map[string]json.RawMessage
+		if err := json.Unmarshal(doc.Paths.values[path], &item); err != nil {
+			return nil, fmt.Errorf("path %s: %w", path, err)
+		}
+		for _, method := range methodOrder {
+			raw, ok := item[method]
+			if !ok {
+				continue
+			}
+			var op operation
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+			if op.Deprecated {
+				continue
+			}
+			if err := g.method(strings.ToUpper(method), path, &op); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+		}
+	}
+
+	// 3️⃣ Assemble
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by `platform-backend openapi client`. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\nimport (\n", pkg)
+	var std, external []string
+	for imp := range g.imports {
+		if strings.Contains(imp, ".") {
+			external = append(external, imp)
+		} else {
+			std = append(std, imp)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(external)
+	for _, imp := range std {
+		fmt.Fprintf(&out, "\t%q\n", imp)
+	}
+	if len(external) > 0 {
+		out.WriteString("\n")
+	}
+	for _, imp := range external {
+		fmt.Fprintf(&out, "\t%q\n", imp)
+	}
+	out.WriteString(")\n\n")
+	out.WriteString(clientRuntime)
+	out.Write(g.types.Bytes())
+	out.Write(g.methods.Bytes())
+	out.WriteString(encoder(g.imports["gopkg.in/yaml.v3"]))
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated client: %w", err)
+	}
+	return src, nil
+}
+
+type generator struct {
+	doc     *document
+	imports map[string]bool
+	types   bytes.Buffer
+	methods bytes.Buffer
+}
+
+func (g *generator) typeDecl(name string, s *schema) error {
+	w := &g.types
+	comment(w, "", name, s.Description)
+
+	if s.Type == "string" && len(s.Enum) > 0 {
+		fmt.Fprintf(w, "type %s string\n\nconst (\n", name)
+		for _, v := range s.Enum {
+			fmt.Fprintf(w, "\t%s%s %s = %q\n", name, goName(v), name, v)
+		}
+		w.WriteString(")\n\n")
+		return nil
+	}
+
+	if s.Type != "object" {
+		typ, err := g.goType(s, true)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "type %s %s\n\n", name, typ)
+		return nil
+	}
+
+	fmt.Fprintf(w, "type %s struct {\n", name)
+	for _, prop := range s.Properties.keys {
+		var ps schema
+		if err := json.Unmarshal(s.Properties.values[prop], &ps); err != nil {
+			return fmt.Errorf("property %s: %w", prop, err)
+		}
+
+		required := contains(s.Required, prop)
+		typ, err := g.goType(&ps, required)
+		if err != nil {
+			return fmt.Errorf("property %s: %w", prop, err)
+		}
+
+		tag := prop
+		if !required {
+			tag += ",omitempty"
+		}
+		comment(w, "\t", "", ps.Description)
+		fmt.Fprintf(w, "\t%s %s `json:%q yaml:%q`\n", goName(prop), typ, tag, tag)
+	}
+	w.WriteString("}\n\n")
+	return nil
+}
+
+// goType maps a schema to a Go type. Optional or nullable values that have
+// no usable zero value (times, nested structs) become pointers.
+func (g *generator) goType(s *schema, required bool) (string, error) {
+	pointer := s.Nullable || !required
+
+	if s.Ref != "" {
+		name := refName(s.Ref)
+		var target schema
+		if raw, ok := g.doc.Components.Schemas.values[name]; ok {
+			_ = json.Unmarshal(raw, &target)
+		}
+		if target.Type == "object" && pointer {
+			return "*" + name, nil
+		}
+		return name, nil
+	}
+
+	switch s.Type {
+	case "string":
+		if s.Format == "date-time" {
+			g.imports["time"] = true
+			if pointer {
+				return "*time.Time", nil
+			}
+			return "time.Time", nil
+		}
+		if s.Nullable {
+			return "*string", nil
+		}
+		return "string", nil
+	case "integer":
+		if s.Format == "int32" {
+			return "int32", nil
+		}
+		return "int64", nil
+	case "number":
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		if s.Items == nil {
+			return "[]json.RawMessage", nil
+		}
+		elem, err := g.goType(s.Items, true)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elem, nil
+	case "object":
+		if s.AdditionalProperties != nil {
+			elem, err := g.goType(s.AdditionalProperties, true)
+			if err != nil {
+				return "", err
+			}
+			return "map[string]" + elem, nil
+		}
+		return "map[string]interface{}", nil
+	}
+	return "", fmt.Errorf("unsupported schema type %q", s.Type)
+}
+
+func (g *generator) method(httpMethod, path string, op *operation) error {
+	if op.OperationID == "" {
+		return fmt.Errorf("missing operationId")
+	}
+	name := goName(op.OperationID)
+	route := path
+
+	// Parameters
+	var (
+		args     []string
+		pathArgs []string
+		query    []string
+	)
+	for _, p := range op.Parameters {
+		if p.Ref != "" {
+			resolved, ok := g.doc.Components.Parameters[refName(p.Ref)]
+			if !ok {
+				return fmt.Errorf("unknown parameter %s", p.Ref)
+			}
+			p = resolved
+		}
+
+		typ, err := g.goType(p.Schema, true)
+		if err != nil {
+			return fmt.Errorf("parameter %s: %w", p.Name, err)
+		}
+		arg := lowerFirst(goName(p.Name))
+		args = append(args, arg+" "+typ)
+
+		str := arg
+		switch typ {
+		case "string":
+		case "int64", "int32", "bool", "float64":
+			str = "fmt.Sprint(" + arg + ")"
+		case "time.Time":
+			str = arg + ".Format(time.RFC3339)"
+		default:
+			str = "string(" + arg + ")"
+		}
+
+		switch p.In {
+		case "path":
+			path = strings.Replace(path, "{"+p.Name+"}", "%s", 1)
+			pathArgs = append(pathArgs, "url.PathEscape("+str+")")
+		case "query":
+			query = append(query, fmt.Sprintf("if %s {\n\tq.Set(%q, %s)\n}\n", isSet(arg, typ), p.Name, str))
+		default:
+			return fmt.Errorf("unsupported parameter location %q", p.In)
+		}
+	}
+
+	// Request body
+	contentType := ""
+	if op.RequestBody != nil {
+		for _, ct := range []string{"application/json", "application/x-yaml"} {
+			media, ok := op.RequestBody.Content[ct]
+			if !ok {
+				continue
+			}
+			typ, err := g.goType(media.Schema, false)
+			if err != nil {
+				return fmt.Errorf("request body: %w", err)
+			}
+			args = append(args, "body "+typ)
+			contentType = ct
+			if ct == "application/x-yaml" {
+				g.imports["gopkg.in/yaml.v3"] = true
+			}
+			break
+		}
+		if contentType == "" {
+			return fmt.Errorf("unsupported request content type")
+		}
+	}
+
+	// Success response
+	result := ""
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		if media, ok := op.Responses[code].Content["application/json"]; ok {
+			typ, err := g.goType(media.Schema, true)
+			if err != nil {
+				return fmt.Errorf("response %s: %w", code, err)
+			}
+			result = typ
+		}
+		break
+	}
+
+	// Render
+	w := &g.methods
+	fmt.Fprintf(w, "// %s calls %s %s.\n", name, httpMethod, route)
+	if op.Summary != "" {
+		fmt.Fprintf(w, "//\n// %s.\n", strings.TrimSuffix(op.Summary, "."))
+	}
+
+	returns := "error"
+	if result != "" {
+		returns = "(" + result + ", error)"
+	}
+	fmt.Fprintf(w, "func (c *Client) %s(%s) %s {\n",
+		name, strings.Join(append([]string{"ctx context.Context"}, args...), ", "), returns)
+
+	if len(pathArgs) > 0 {
+		fmt.Fprintf(w, "path := fmt.Sprintf(%q, %s)\n", path, strings.Join(pathArgs, ", "))
+	} else {
+		fmt.Fprintf(w, "path := %q\n", path)
+	}
+
+	queryArg := "nil"
+	if len(query) > 0 {
+		w.WriteString("q := url.Values{}\n")
+		for _, q := range query {
+			w.WriteString(q)
+		}
+		queryArg = "q"
+	}
+
+	bodyArg := "nil"
+	if contentType != "" {
+		bodyArg = "body"
+	}
+
+	if result == "" {
+		fmt.Fprintf(w, "return c.do(ctx, %q, path, %s, %q, %s, nil)\n}\n\n",
+			httpMethod, queryArg, contentType, bodyArg)
+		return nil
+	}
+
+	fmt.Fprintf(w, "var out %s\n", result)
+	fmt.Fprintf(w, "err := c.do(ctx, %q, path, %s, %q, %s, &out)\n", httpMethod, queryArg, contentType, bodyArg)
+	w.WriteString("return out, err\n}\n\n")
+	return nil
+}
+
+/* ===================== HELPERS ===================== */
+
+// Initialisms kept upper-case in generated identifiers.
+var initialisms = map[string]string{
+	"api": "API", "id": "ID", "json": "JSON", "sha": "SHA", "url": "URL",
+}
+
+// goName turns serviceName, repo-url or pre-prod into ServiceName, RepoURL
+// and PreProd.
+func goName(s string) string {
+	var words []string
+	var cur []rune
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, string(cur))
+			cur = nil
+		}
+	}
+	for _, r := range s {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && len(cur) > 0 && !unicode.IsUpper(cur[len(cur)-1]):
+			flush()
+			cur = append(cur, r)
+		default:
+			cur = append(cur, r)
+		}
+	}
+	flush()
+
+	var b strings.Builder
+	for _, w := range words {
+		if up, ok := initialisms[strings.ToLower(w)]; ok {
+			b.WriteString(up)
+			continue
+		}
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	if b.Len() == 0 {
+		return "Empty"
+	}
+	return b.String()
+}
+
+func lowerFirst(s string) string {
+	for i, r := range s {
+		if !unicode.IsUpper(r) {
+			if i == 0 {
+				return s
+			}
+			return strings.ToLower(s[:i]) + s[i:]
+		}
+	}
+	return strings.ToLower(s)
+}
+
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// isSet is the condition under which an optional query argument is sent.
+func isSet(arg, typ string) string {
+	switch typ {
+	case "int64", "int32", "float64":
+		return arg + " != 0"
+	case "bool":
+		return arg
+	case "time.Time":
+		return "!" + arg + ".IsZero()"
+	}
+	return arg + ` != ""`
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+func comment(w *bytes.Buffer, indent, name, text string) {
+	if text == "" {
+		return
+	}
+	if name != "" {
+		text = name + ": " + text
+	}
+	fmt.Fprintf(w, "%s// %s\n", indent, text)
+}
+
+// encoder renders the request body marshaller; YAML support is only emitted
+// when some operation takes a YAML body.
+func encoder(withYAML bool) string {
+	var b strings.Builder
+	b.WriteString("func encode(contentType string, body interface{}) ([]byte, error) {\n")
+	if withYAML {
+		b.WriteString("if contentType == \"application/x-yaml\" {\nreturn yaml.Marshal(body)\n}\n")
+	}
+	b.WriteString("return json.Marshal(body)\n}\n")
+	return b.String()
+}
+
+// clientRuntime is emitted verbatim ahead of the generated types.
+const clientRuntime = `// Client calls the platform backend API.
+type Client struct {
+	// BaseURL is the backend root, e.g. https://platform.internal (no /api/v1).
+	BaseURL string
+	// HTTP defaults to http.DefaultClient.
+	HTTP *http.Client
+}
+
+// New returns a Client for baseURL.
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// APIError is returned for any non-2xx response. Code, Details and
+// RequestID come from the server's error envelope.
+type APIError struct {
+	StatusCode int
+	ErrorResponse
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("platform API: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+	if e.RequestID != "" {
+		msg += " (request " + e.RequestID + ")"
+	}
+	return msg
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, contentType string, body, out interface{}) error {
+	var reqBody io.Reader
+	if contentType != "" {
+		data, err := encode(contentType, body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	u := strings.TrimRight(c.BaseURL, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := c.HTTP
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		if json.Unmarshal(data, &apiErr.ErrorResponse) != nil || apiErr.Code == "" {
+			// Not from a handler (proxy, unmatched route)
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		return apiErr
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+`
@@ -0,0 +1,24 @@
+package openapi
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// The committed client must be regenerated whenever openapi.json changes.
+func TestGeneratedClientUpToDate(t *testing.T) {
+	want, err := GenerateClient(Spec, "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile("../../client/client.gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Fatal("src/client/client.gen.go is stale; run `go generate ./src/client`")
+	}
+}
@@ -0,0 +1,11 @@
+// Package openapi holds the platform API description and the generator for
+// the typed Go client in src/client.
+package openapi
+
+import _ "embed"
+
+// Spec is the OpenAPI 3 document served at /openapi.json. Keep it in step
+// with handler.Routes; TestSpecCoversRoutes fails when they drift.
+//
+//go:embed openapi.json
+var Spec []byte
@@ -0,0 +1,73 @@
+package preview
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"src/src/internal/db"
+	"src/src/internal/git"
+	"src/src/internal/github"
+	"src/src/internal/model"
+)
+
+// comment posts body on p's pull request. Each preview keeps one comment,
+// edited as the preview changes, so pushes do not flood the conversation.
+func comment(ctx context.Context, p *model.Preview, body string) error {
+	owner, repo, _ := strings.Cut(p.Repository, "/")
+	client, err := git.Client(tokens, owner)
+	if err != nil {
+		return err
+	}
+
+	var id sql.NullInt64
+	err = db.DB.QueryRowContext(ctx, `
+		SELECT comment_id FROM previews WHERE service_name = ? AND environment = ?`,
+		p.ServiceName, p.Environment,
+	).Scan(&id)
+	if err != nil {
+		return err
+	}
+
+	// 1️⃣ Edit ours, unless someone deleted it
+	if id.Valid {
+		err := client.Patch(ctx, fmt.Sprintf("/repos/%s/%s/issues/comments/%d", owner, repo, id.Int64),
+			map[string]string{"body": body}, nil)
+		if !github.IsNotFound(err) {
+			return err
+		}
+	}
+
+	// 2️⃣ New comment
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	err = client.Post(ctx, fmt.Sprintf("/repos/%s/%s/issues/%d/comments", owner, repo, p.PullRequest),
+		map[string]string{"body": body}, &created)
+	if err != nil {
+		return err
+	}
+	_, err = db.DB.ExecContext(ctx, `
+		UPDATE previews SET comment_id = ? WHERE service_name = ? AND environment = ?`,
+		created.ID, p.ServiceName, p.Environment,
+	)
+	return err
+}
+
+func readyComment(p *model.Preview) string {
+	var b strings.Builder
+	if p.URL != "" {
+		fmt.Fprintf(&b, "🔍 Preview of **%s** is ready: %s\n\n", p.ServiceName, p.URL)
+	} else {
+		fmt.Fprintf(&b, "🔍 Preview of **%s** is ready.\n\n", p.ServiceName)
+	}
+	fmt.Fprintf(&b, "Environment `%s` runs `%s`, built from %s.\n", p.Environment, p.Version, git.ShortSHA(p.HeadSHA))
+	fmt.Fprintf(&b, "It is torn down when this pull request closes, or at %s UTC without further pushes.\n",
+		p.ExpiresAt.UTC().Format("2006-01-02 15:04"))
+	return b.String()
+}
+
+func closedComment(p *model.Preview, reason string) string {
+	return fmt.Sprintf("🧹 Preview `%s` of **%s** was torn down (%s).\n", p.Environment, p.ServiceName, reason)
+}
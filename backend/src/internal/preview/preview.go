@@ -0,0 +1,150 @@
+// Package preview runs short-lived environments for pull requests. When a
+// pull request is opened or pushed to, GitHub's webhook makes the platform
+// deploy its head to pr-<number> for every service in the repository,
+// through the service's own deploy pipeline. Once the pipeline reports the
+// deploy, the preview's URL is posted on the pull request. Previews are torn
+// down when the pull request closes, or by the scheduler once they have not
+// been pushed to for the configured TTL. Pull requests from forks are not
+// deployed: their code has not been reviewed by anyone with write access.
+// Everything here is off until previews.enabled is set.
+package preview
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"src/src/internal/config"
+	"src/src/internal/git"
+	"src/src/internal/model"
+	"src/src/internal/secrets"
+)
+
+var (
+	ErrNotConfigured = errors.New("previews are not enabled")
+	ErrSignature     = errors.New("invalid webhook signature")
+	ErrBadPayload    = errors.New("invalid webhook payload")
+	ErrNotFound      = errors.New("preview not found")
+	ErrTeardown      = errors.New("preview teardown could not be started")
+)
+
+var (
+	settings = config.Default().Previews
+	tokens   = git.DefaultTokenSource()
+)
+
+// Configure sets the webhook secret, URL pattern and TTL, and the
+// credentials pull requests are commented on with.
+func Configure(cfg config.Previews, ts git.TokenSource) {
+	settings, tokens = cfg, ts
+}
+
+// Enabled reports whether previews are switched on.
+func Enabled() bool {
+	return settings.Enabled
+}
+
+// now is replaced in tests.
+var now = func() time.Time { return time.Now().UTC().Truncate(time.Second) }
+
+// URL is where serviceName is reachable in env, "" when no pattern is
+// configured.
+func URL(serviceName, env string) string {
+	return strings.NewReplacer("{service}", serviceName, "{env}", env).Replace(settings.URL)
+}
+
+/* ===================== WEBHOOK ===================== */
+
+// Verify checks the X-Hub-Signature-256 header of a webhook delivery
+// against the configured secret.
+func Verify(ctx context.Context, body []byte, signature string) error {
+	if !Enabled() {
+		return ErrNotConfigured
+	}
+
+	secret, err := secrets.Get(ctx, settings.WebhookSecret)
+	if err != nil {
+		return err
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(signature), []byte(want)) {
+		return ErrSignature
+	}
+	return nil
+}
+
+// pullRequestEvent is the part of GitHub's pull_request payload previews
+// use.
+type pullRequestEvent struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Draft bool      `json:"draft"`
+		Head  branchRef `json:"head"`
+		Base  branchRef `json:"base"`
+	} `json:"pull_request"`
+	Repository struct {
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"repository"`
+}
+
+type branchRef struct {
+	Ref  string `json:"ref"`
+	SHA  string `json:"sha"`
+	Repo *struct {
+		FullName string `json:"full_name"`
+	} `json:"repo"` // null once a fork is deleted
+}
+
+// HandleEvent acts on a verified webhook delivery of type event and returns
+// the previews it deployed or tore down. Events other than pull_request,
+// such as the ping sent when the webhook is created, change nothing.
+func HandleEvent(ctx context.Context, event string, body []byte) ([]model.Preview, error) {
+	if event != "pull_request" {
+		return []model.Preview{}, nil
+	}
+
+	var ev pullRequestEvent
+	if err := json.Unmarshal(body, &ev); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadPayload, err)
+	}
+	if ev.Number <= 0 || ev.Repository.Name == "" || ev.Repository.Owner.Login == "" {
+		return nil, fmt.Errorf("%w: missing pull request number or repository", ErrBadPayload)
+	}
+
+	switch ev.Action {
+	case "opened", "reopened", "synchronize", "ready_for_review":
+		return open(ctx, ev)
+	case "closed":
+		return closeAll(ctx, ev.Repository.Owner.Login, ev.Repository.Name, ev.Number, "pull request closed")
+	default:
+		return []model.Preview{}, nil
+	}
+}
+
+// deployable reports why ev is not deployed, "" when it is.
+func deployable(ev pullRequestEvent) string {
+	pr := ev.PullRequest
+	switch {
+	case pr.Draft:
+		return "draft"
+	case pr.Head.Repo == nil || pr.Base.Repo == nil || !strings.EqualFold(pr.Head.Repo.FullName, pr.Base.Repo.FullName):
+		return "head is in a fork"
+	case pr.Head.Ref == "" || pr.Base.Ref == "":
+		return "missing head or base branch"
+	default:
+		return ""
+	}
+}
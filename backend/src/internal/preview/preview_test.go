@@ -0,0 +1,155 @@
+package preview
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"src/src/internal/config"
+	"src/src/internal/db"
+	"src/src/internal/model"
+	"src/src/internal/secrets"
+)
+
+const testSecret = "webhook-test-secret"
+
+func enable(t *testing.T) {
+	t.Helper()
+
+	if err := secrets.Init(secrets.Config{Backend: "env"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_WEBHOOK_SECRET", testSecret)
+
+	prev := settings
+	cfg := config.Default().Previews
+	cfg.Enabled = true
+	Configure(cfg, tokens)
+	t.Cleanup(func() { Configure(prev, tokens) })
+}
+
+func sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"action":"opened"}`)
+
+	if err := Verify(context.Background(), body, sign(body)); !errors.Is(err, ErrNotConfigured) {
+		t.Fatalf("disabled: got %v", err)
+	}
+
+	enable(t)
+	if err := Verify(context.Background(), body, sign(body)); err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+	for name, sig := range map[string]string{
+		"missing":  "",
+		"tampered": sign([]byte(`{"action":"closed"}`)),
+		"sha1":     "sha1=" + sign(body)[len("sha256="):],
+	} {
+		if err := Verify(context.Background(), body, sig); !errors.Is(err, ErrSignature) {
+			t.Errorf("%s signature: got %v", name, err)
+		}
+	}
+}
+
+func TestHandleEventSkips(t *testing.T) {
+	enable(t)
+
+	const repo = `"repository":{"name":"shop","owner":{"login":"acme"}}`
+	tests := []struct {
+		name  string
+		event string
+		body  string
+		err   error
+	}{
+		{name: "ping", event: "ping", body: `{"zen":"Keep it logically awesome."}`},
+		{name: "labeled", event: "pull_request", body: `{"action":"labeled","number":7,` + repo + `}`},
+		{
+			name: "draft", event: "pull_request",
+			body: `{"action":"opened","number":7,"pull_request":{"draft":true,` +
+				`"head":{"ref":"feature","repo":{"full_name":"acme/shop"}},` +
+				`"base":{"ref":"dev","repo":{"full_name":"acme/shop"}}},` + repo + `}`,
+		},
+		{
+			name: "fork", event: "pull_request",
+			body: `{"action":"synchronize","number":7,"pull_request":{` +
+				`"head":{"ref":"feature","repo":{"full_name":"mallory/shop"}},` +
+				`"base":{"ref":"dev","repo":{"full_name":"acme/shop"}}},` + repo + `}`,
+		},
+		{
+			name: "deleted fork", event: "pull_request",
+			body: `{"action":"opened","number":7,"pull_request":{` +
+				`"head":{"ref":"feature","repo":null},` +
+				`"base":{"ref":"dev","repo":{"full_name":"acme/shop"}}},` + repo + `}`,
+		},
+		{name: "not json", event: "pull_request", body: `{`, err: ErrBadPayload},
+		{name: "no number", event: "pull_request", body: `{"action":"opened",` + repo + `}`, err: ErrBadPayload},
+		{name: "no repository", event: "pull_request", body: `{"action":"opened","number":7}`, err: ErrBadPayload},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := HandleEvent(context.Background(), tt.event, []byte(tt.body))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if err == nil && len(got) != 0 {
+				t.Errorf("touched %v", got)
+			}
+		})
+	}
+}
+
+func TestExpireUsesClock(t *testing.T) {
+	enable(t)
+
+	conn, m, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	db.DB = conn
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	prev := now
+	now = func() time.Time { return at }
+	t.Cleanup(func() { now = prev })
+
+	// Only previews whose TTL has run out by now are torn down
+	m.ExpectQuery("expires_at <= ").WithArgs(model.PreviewClosed, at, expireBatch).
+		WillReturnRows(sqlmock.NewRows([]string{"service_name"}))
+
+	if err := Expire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestIsPreviewEnvironment(t *testing.T) {
+	for env, want := range map[string]bool{
+		model.PreviewEnvironment(42): true,
+		"pr-1":                       true,
+		"pr-0":                       false,
+		"pr-012":                     false,
+		"pr-":                        false,
+		"pr-1a":                      false,
+		"pr-99999999999":             false,
+		"prod":                       false,
+		"dev":                        false,
+	} {
+		if got := model.IsPreviewEnvironment(env); got != want {
+			t.Errorf("IsPreviewEnvironment(%q) = %v, want %v", env, got, want)
+		}
+	}
+}
@@ -0,0 +1,371 @@
+package preview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"src/src/internal/argocd"
+	"src/src/internal/cicd"
+	"src/src/internal/db"
+	"src/src/internal/deploy"
+	"src/src/internal/model"
+	"src/src/internal/service"
+)
+
+// expireBatch caps the previews torn down per scheduler tick.
+const expireBatch = 20
+
+const previewColumns = `
+	service_name, environment, pull_request, repo_owner, repo_name, head_ref, head_sha,
+	base_ref, status, version, url, COALESCE(error, ''), created_at, updated_at, expires_at, closed_at`
+
+/* ===================== DEPLOY ===================== */
+
+// open deploys the head of ev to the preview environment of every service
+// in the repository. A pipeline that cannot be started marks its preview
+// failed; it is retried on the next push.
+func open(ctx context.Context, ev pullRequestEvent) ([]model.Preview, error) {
+	owner, repo := ev.Repository.Owner.Login, ev.Repository.Name
+	if reason := deployable(ev); reason != "" {
+		log.Printf("🔍 No preview for %s/%s#%d: %s", owner, repo, ev.Number, reason)
+		return []model.Preview{}, nil
+	}
+
+	targets, err := services(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []model.Preview{}
+	for _, t := range targets {
+		p, err := deployTarget(ctx, t, owner, repo, ev)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *p)
+	}
+	return out, nil
+}
+
+func deployTarget(ctx context.Context, t *deploy.Target, owner, repo string, ev pullRequestEvent) (*model.Preview, error) {
+	env := model.PreviewEnvironment(ev.Number)
+	at := now()
+
+	// 1️⃣ Record; a reopened pull request gets its preview back
+	_, err := db.DB.ExecContext(ctx, `
+		INSERT INTO previews
+		(service_name, environment, pull_request, repo_owner, repo_name, head_ref, head_sha,
+		 base_ref, status, url, created_at, updated_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			head_ref = VALUES(head_ref), head_sha = VALUES(head_sha), base_ref = VALUES(base_ref),
+			status = VALUES(status), url = VALUES(url), error = NULL,
+			updated_at = VALUES(updated_at), expires_at = VALUES(expires_at), closed_at = NULL`,
+		t.ServiceName, env, ev.Number, owner, repo, ev.PullRequest.Head.Ref, ev.PullRequest.Head.SHA,
+		ev.PullRequest.Base.Ref, model.PreviewDeploying, URL(t.ServiceName, env), at, at, at.Add(settings.TTL),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2️⃣ Pipeline, on the pull request's branch
+	err = deploy.Preview(t, ev.PullRequest.Head.Ref, cicd.PreviewParams{Environment: env})
+	if err != nil {
+		log.Printf("❌ Preview %s of %s could not start: %v", env, t.ServiceName, err)
+		if _, dbErr := db.DB.ExecContext(ctx, `
+			UPDATE previews SET status = ?, error = ?
+			WHERE service_name = ? AND environment = ?`,
+			model.PreviewFailed, err.Error(), t.ServiceName, env,
+		); dbErr != nil {
+			return nil, dbErr
+		}
+	}
+
+	return Get(ctx, t.ServiceName, env)
+}
+
+// services returns the services whose repository is owner/repo. Services
+// without an owner live under the default one.
+func services(ctx context.Context, owner, repo string) ([]*deploy.Target, error) {
+	rows, err := db.DB.QueryContext(ctx, `
+		SELECT service_name, COALESCE(repo_owner, '')
+		FROM services
+		WHERE repo_name = ?
+		ORDER BY service_name`,
+		repo,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name, serviceOwner string
+		if err := rows.Scan(&name, &serviceOwner); err != nil {
+			return nil, err
+		}
+		if serviceOwner == "" {
+			if serviceOwner, _, err = tokens.DefaultOwner(); err != nil {
+				return nil, err
+			}
+		}
+		if strings.EqualFold(serviceOwner, owner) {
+			names = append(names, name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	targets := make([]*deploy.Target, 0, len(names))
+	for _, name := range names {
+		t, err := deploy.Lookup(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// OnArtifact marks a preview ready once its pipeline reported the deploy,
+// syncs its Argo CD application and posts its URL on the pull request. A
+// report arriving after the preview was torn down is dropped from the
+// environment state again.
+func OnArtifact(ctx context.Context, a model.ArtifactEvent) error {
+	if !model.IsPreviewEnvironment(a.Environment) {
+		return nil
+	}
+
+	p, err := Get(ctx, a.ServiceName, a.Environment)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if p.Status == model.PreviewClosed {
+		_, err := db.DB.ExecContext(ctx, `
+			DELETE FROM environment_state WHERE service_name = ? AND environment = ?`,
+			a.ServiceName, a.Environment,
+		)
+		return err
+	}
+	if a.Action != "deploy" {
+		return nil
+	}
+
+	p.Status, p.Version, p.Error, p.UpdatedAt = model.PreviewReady, a.Version, "", now()
+	_, err = db.DB.ExecContext(ctx, `
+		UPDATE previews SET status = ?, version = ?, error = NULL, updated_at = ?
+		WHERE service_name = ? AND environment = ? AND status <> ?`,
+		p.Status, p.Version, p.UpdatedAt, p.ServiceName, p.Environment, model.PreviewClosed,
+	)
+	if err != nil {
+		return err
+	}
+	log.Printf("🔍 Preview %s of %s ready with %s", p.Environment, p.ServiceName, p.Version)
+
+	if err := argocd.SyncPreview(ctx, p.ServiceName, p.Environment, p.HeadRef); err != nil {
+		log.Printf("⚠️ argocd sync of preview %s/%s: %v", p.ServiceName, p.Environment, err)
+	}
+	return comment(ctx, p, readyComment(p))
+}
+
+/* ===================== TEARDOWN ===================== */
+
+// Close tears down a preview before its pull request closes.
+func Close(ctx context.Context, serviceName, env, by string) (*model.Preview, error) {
+	p, err := Get(ctx, serviceName, env)
+	if err != nil || p.Status == model.PreviewClosed {
+		return p, err
+	}
+	if err := teardown(ctx, p, "closed by "+by); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Expire tears down the previews whose pull request has not been pushed to
+// for the TTL. Only the scheduler's leader calls it.
+func Expire(ctx context.Context) error {
+	if !Enabled() {
+		return nil
+	}
+
+	found, err := queryPreviews(ctx, `
+		SELECT `+previewColumns+`
+		FROM previews
+		WHERE status <> ? AND expires_at <= ?
+		ORDER BY expires_at
+		LIMIT ?`,
+		model.PreviewClosed, now(), expireBatch,
+	)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range found {
+		if err := teardown(ctx, &found[i], "expired"); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// closeAll tears down the previews of a pull request.
+func closeAll(ctx context.Context, owner, repo string, number int, reason string) ([]model.Preview, error) {
+	found, err := queryPreviews(ctx, `
+		SELECT `+previewColumns+`
+		FROM previews
+		WHERE repo_owner = ? AND repo_name = ? AND pull_request = ? AND status <> ?`,
+		owner, repo, number, model.PreviewClosed,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for i := range found {
+		if err := teardown(ctx, &found[i], reason); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// teardown removes p and forgets its environment. If the pipeline cannot be
+// started the preview stays open, so the scheduler retries once it expires.
+func teardown(ctx context.Context, p *model.Preview, reason string) error {
+	// 1️⃣ Pipeline, from the base branch: the head is often deleted on merge
+	t, err := deploy.Lookup(ctx, p.ServiceName)
+	switch {
+	case errors.Is(err, service.ErrServiceNotFound):
+		// Service deleted; nothing left to run
+	case err != nil:
+		return err
+	default:
+		err = deploy.Preview(t, p.BaseRef, cicd.PreviewParams{Environment: p.Environment, Teardown: true})
+		if err != nil && !errors.Is(err, deploy.ErrNoPreviews) {
+			if _, dbErr := db.DB.ExecContext(ctx, `
+				UPDATE previews SET error = ? WHERE service_name = ? AND environment = ?`,
+				err.Error(), p.ServiceName, p.Environment,
+			); dbErr != nil {
+				log.Printf("⚠️ record teardown failure of preview %s/%s: %v", p.ServiceName, p.Environment, dbErr)
+			}
+			return fmt.Errorf("%w: %s of %s: %v", ErrTeardown, p.Environment, p.ServiceName, err)
+		}
+	}
+
+	// 2️⃣ Argo CD application, if any
+	if err := argocd.DeletePreview(ctx, p.ServiceName, p.Environment); err != nil {
+		log.Printf("⚠️ delete Argo CD application of preview %s/%s: %v", p.ServiceName, p.Environment, err)
+	}
+
+	// 3️⃣ Record
+	if err := markClosed(ctx, p); err != nil {
+		return err
+	}
+	log.Printf("🧹 Preview %s of %s torn down (%s)", p.Environment, p.ServiceName, reason)
+
+	// 4️⃣ Tell the pull request
+	if err := comment(ctx, p, closedComment(p, reason)); err != nil {
+		log.Printf("⚠️ comment on %s#%d: %v", p.Repository, p.PullRequest, err)
+	}
+	return nil
+}
+
+func markClosed(ctx context.Context, p *model.Preview) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM environment_state WHERE service_name = ? AND environment = ?`,
+		p.ServiceName, p.Environment,
+	)
+	if err != nil {
+		return err
+	}
+
+	at := now()
+	_, err = tx.ExecContext(ctx, `
+		UPDATE previews SET status = ?, error = NULL, updated_at = ?, closed_at = ?
+		WHERE service_name = ? AND environment = ?`,
+		model.PreviewClosed, at, at, p.ServiceName, p.Environment,
+	)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	p.Status, p.Error, p.UpdatedAt, p.ClosedAt = model.PreviewClosed, "", at, &at
+	return nil
+}
+
+/* ===================== READ ===================== */
+
+// List returns the previews of a service, newest pull request first.
+func List(ctx context.Context, serviceName string) ([]model.Preview, error) {
+	return queryPreviews(ctx, `
+		SELECT `+previewColumns+`
+		FROM previews
+		WHERE service_name = ?
+		ORDER BY pull_request DESC
+		LIMIT 100`,
+		serviceName,
+	)
+}
+
+// Get returns the preview of serviceName in env, or ErrNotFound.
+func Get(ctx context.Context, serviceName, env string) (*model.Preview, error) {
+	found, err := queryPreviews(ctx, `
+		SELECT `+previewColumns+`
+		FROM previews
+		WHERE service_name = ? AND environment = ?`,
+		serviceName, env,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("%w: %s has no preview %s", ErrNotFound, serviceName, env)
+	}
+	return &found[0], nil
+}
+
+func queryPreviews(ctx context.Context, sqlText string, args ...interface{}) ([]model.Preview, error) {
+	rows, err := db.DB.QueryContext(ctx, sqlText, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.Preview{}
+	for rows.Next() {
+		var (
+			p           model.Preview
+			owner, repo string
+		)
+		if err := rows.Scan(&p.ServiceName, &p.Environment, &p.PullRequest, &owner, &repo, &p.HeadRef, &p.HeadSHA,
+			&p.BaseRef, &p.Status, &p.Version, &p.URL, &p.Error, &p.CreatedAt, &p.UpdatedAt, &p.ExpiresAt, &p.ClosedAt); err != nil {
+			return nil, err
+		}
+		p.Repository = owner + "/" + repo
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
@@ -9,6 +9,7 @@ func GetServices() ([]map[string]interface{}, error) {
 	rows, err := db.DB.Query(`
 		SELECT s.id, s.service_name, s.repo_name, s.owner_team,
 		       s.runtime, s.cicd_type, s.template_version, s.deploy_type,
+		       s.repo_mode, s.repo_path,
 		       d.environment, d.status
 		FROM services s
 		LEFT JOIN deployments d ON s.id = d.service_id
@@ -23,13 +24,14 @@ func GetServices() ([]map[string]interface{}, error) {
 	for rows.Next() {
 		var (
 			id int64
-			env, status sql.NullString
-			serviceName, repoName, ownerTeam, runtime, cicd, tpl, deploy string
+			env, status, repoPath sql.NullString
+			serviceName, repoName, ownerTeam, runtime, cicd, tpl, deploy, repoMode string
 		)
 
 		rows.Scan(
 			&id, &serviceName, &repoName, &ownerTeam,
 			&runtime, &cicd, &tpl, &deploy,
+			&repoMode, &repoPath,
 			&env, &status,
 		)
 
@@ -42,6 +44,8 @@ func GetServices() ([]map[string]interface{}, error) {
 				"cicdType":        cicd,
 				"templateVersion": tpl,
 				"deployType":      deploy,
+				"repoMode":        repoMode,
+				"repoPath":        repoPath.String,
 				"environments":    map[string]string{},
 			}
 		}
@@ -51,7 +55,7 @@ func GetServices() ([]map[string]interface{}, error) {
 		}
 	}
 
-	var services []map[string]interface{}
+	services := []map[string]interface{}{}
 	for _, v := range result {
 		services = append(services, v)
 	}
@@ -0,0 +1,441 @@
+// Package rollout deploys services with their environment's strategy. An
+// all-at-once deploy is a single pipeline run. A canary or blue-green
+// deploy is a rollout: one pipeline run per step, each told its step and
+// traffic share through cicd.RolloutParams. A step holds the deployment
+// lock until its pipeline reports back through the artifact API; the next
+// step starts on promote, or after the strategy's step interval, from the
+// scheduler's elected replica.
+package rollout
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"src/src/internal/cicd"
+	"src/src/internal/db"
+	"src/src/internal/deploy"
+	"src/src/internal/freeze"
+	"src/src/internal/locks"
+	"src/src/internal/model"
+)
+
+// batchSize caps the rollouts advanced per scheduler tick.
+const batchSize = 20
+
+// now is replaced in tests.
+var now = func() time.Time { return time.Now().UTC().Truncate(time.Second) }
+
+// Idle returns ErrInProgress while serviceName/env has an unfinished
+// rollout. Callers check it while holding the deployment lock, so no
+// other deploy can start a rollout between the check and Start.
+func Idle(ctx context.Context, serviceName, env string) error {
+	r, err := active(ctx, db.DB, serviceName, env)
+	if err != nil {
+		return err
+	}
+	if r != nil {
+		return fmt.Errorf("%w: rollout %d of %s/%s is %s; promote or abort it first",
+			ErrInProgress, r.ID, serviceName, env, r.Status)
+	}
+	return nil
+}
+
+// Start deploys t to env with the environment's strategy. An all-at-once
+// deploy returns a nil rollout; otherwise the rollout's first step is
+// triggered. The caller holds the deployment lock and has checked Idle.
+func Start(ctx context.Context, t *deploy.Target, env, by string) (*model.Rollout, error) {
+	s, err := GetStrategy(ctx, t.ServiceName, env)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Strategy == model.StrategyAllAtOnce {
+		if err := deploy.Trigger(t, env, cicd.RolloutParams{}); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrTrigger, err)
+		}
+		return nil, nil
+	}
+
+	r := &model.Rollout{
+		ServiceName:         t.ServiceName,
+		Environment:         env,
+		Strategy:            s.Strategy,
+		Steps:               s.CanarySteps,
+		CurrentStep:         1,
+		StepIntervalSeconds: s.StepIntervalSeconds,
+		Status:              model.RolloutProgressing,
+		StartedBy:           by,
+		CreatedAt:           now(),
+	}
+	if s.Strategy == model.StrategyBlueGreen {
+		r.Steps = []int{0, 100} // deploy next to the live version, then switch
+	}
+
+	// 1️⃣ Remember what an abort goes back to
+	err = db.DB.QueryRowContext(ctx, `
+		SELECT version FROM environment_state
+		WHERE service_name = ? AND environment = ?`,
+		t.ServiceName, env,
+	).Scan(&r.PreviousVersion)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	if err := create(ctx, r); err != nil {
+		return nil, err
+	}
+	log.Printf("🚦 Rollout %d: %s of %s to %s, steps %v", r.ID, r.Strategy, r.ServiceName, env, r.Steps)
+
+	// 2️⃣ First step
+	step, err := runStep(ctx, t, r, 1, by)
+	r.History = []model.RolloutStep{step}
+	if err != nil {
+		finished := now()
+		if _, dbErr := db.DB.ExecContext(ctx, `
+			UPDATE rollouts SET status = 'failed', finished_at = ? WHERE id = ?`,
+			finished, r.ID,
+		); dbErr != nil {
+			log.Printf("⚠️ Rollout %d: %v", r.ID, dbErr)
+		}
+		return nil, err
+	}
+	return r, nil
+}
+
+// runStep triggers step n of r and records it in the history.
+func runStep(ctx context.Context, t *deploy.Target, r *model.Rollout, n int, by string) (model.RolloutStep, error) {
+	step := model.RolloutStep{
+		Step:           n,
+		Phase:          "step",
+		TrafficPercent: r.Steps[n-1],
+		Status:         model.StepRunning,
+		TriggeredBy:    by,
+		TriggeredAt:    now(),
+	}
+
+	err := deploy.Trigger(t, r.Environment, params(r, step))
+	if err != nil {
+		step.Status = model.StepFailed
+		step.FinishedAt = &step.TriggeredAt
+		step.Error = err.Error()
+		err = fmt.Errorf("%w: %v", ErrTrigger, err)
+	}
+
+	if dbErr := insertStep(ctx, r.ID, step); dbErr != nil {
+		log.Printf("⚠️ Rollout %d step %d: %v", r.ID, n, dbErr)
+	}
+	return step, err
+}
+
+func params(r *model.Rollout, step model.RolloutStep) cicd.RolloutParams {
+	return cicd.RolloutParams{
+		Strategy:       r.Strategy,
+		ID:             r.ID,
+		Step:           step.Step,
+		Phase:          step.Phase,
+		TrafficPercent: step.TrafficPercent,
+		Version:        r.Version,
+	}
+}
+
+/* ===================== PROMOTE / PAUSE / ABORT ===================== */
+
+// Promote starts the next step of a waiting or paused rollout.
+func Promote(ctx context.Context, serviceName string, id int64, by string) (*model.Rollout, error) {
+	r, err := Get(ctx, serviceName, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := canAdvance(r); err != nil {
+		return nil, err
+	}
+
+	// 1️⃣ Every step is a deploy: freezes and locks apply
+	if err := freeze.Check(ctx, serviceName, r.Environment); err != nil {
+		return nil, err
+	}
+	next := r.CurrentStep + 1
+	lock, err := locks.Acquire(ctx, serviceName, r.Environment, "deploy", by, fmt.Sprintf("rollout %d step %d", id, next))
+	if err != nil {
+		return nil, err
+	}
+
+	// 2️⃣ Re-read under the lock; the scheduler may have promoted it meanwhile
+	if r, err = Get(ctx, serviceName, id); err == nil {
+		err = canAdvance(r)
+	}
+	var target *deploy.Target
+	if err == nil {
+		target, err = deploy.Lookup(ctx, serviceName)
+	}
+	if err != nil {
+		locks.Release(ctx, lock)
+		return nil, err
+	}
+
+	// 3️⃣ Trigger; the lock is released when the step reports back
+	step, err := runStep(ctx, target, r, next, by)
+	r.History = append(r.History, step)
+	if err != nil {
+		locks.Release(ctx, lock)
+		return nil, err
+	}
+
+	if _, err := db.DB.ExecContext(ctx, `
+		UPDATE rollouts SET current_step = ?, status = 'progressing', next_step_at = NULL
+		WHERE id = ?`,
+		next, id,
+	); err != nil {
+		return nil, err
+	}
+	r.CurrentStep, r.Status, r.NextStepAt = next, model.RolloutProgressing, nil
+
+	log.Printf("🚦 Rollout %d promoted to step %d (%d%%) by %s", id, next, step.TrafficPercent, by)
+	return r, nil
+}
+
+// canAdvance allows a promote once the current step has reported back.
+func canAdvance(r *model.Rollout) error {
+	if r.Status != model.RolloutWaiting && r.Status != model.RolloutPaused {
+		return &StateError{ID: r.ID, Status: r.Status, Action: "promote"}
+	}
+	for i := len(r.History) - 1; i >= 0; i-- {
+		if s := r.History[i]; s.Phase == "step" && s.Step == r.CurrentStep && s.Status == model.StepSucceeded {
+			return nil
+		}
+	}
+	return &StateError{ID: r.ID, Status: fmt.Sprintf("%s with step %d still running", r.Status, r.CurrentStep), Action: "promote"}
+}
+
+// Pause stops a rollout from advancing on its own. A running step still
+// finishes; promote resumes.
+func Pause(ctx context.Context, serviceName string, id int64, by string) (*model.Rollout, error) {
+	res, err := db.DB.ExecContext(ctx, `
+		UPDATE rollouts SET status = 'paused', next_step_at = NULL
+		WHERE id = ? AND service_name = ? AND status IN ('progressing', 'waiting')`,
+		id, serviceName,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := Get(ctx, serviceName, id)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, &StateError{ID: id, Status: r.Status, Action: "pause"}
+	}
+
+	log.Printf("⏸️ Rollout %d paused by %s", id, by)
+	return r, nil
+}
+
+// Abort takes the environment back to the version it ran before the
+// rollout through the rollback pipeline, which is told to tear down the
+// canary or idle environment. It is allowed during a freeze and audited
+// like an emergency rollback.
+func Abort(ctx context.Context, serviceName string, id int64, by string) (*model.Rollout, error) {
+	r, err := Get(ctx, serviceName, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := canAbort(r); err != nil {
+		return nil, err
+	}
+	reason := fmt.Sprintf("abort of rollout %d", id)
+
+	// 1️⃣ Freezes do not block undoing a rollout
+	if err := freeze.Check(ctx, serviceName, r.Environment); err != nil {
+		var frozen *freeze.FrozenError
+		if !errors.As(err, &frozen) {
+			return nil, err
+		}
+		if err := freeze.RecordOverride(ctx, frozen, serviceName, r.Environment, "rollback", by, reason); err != nil {
+			return nil, err
+		}
+	}
+
+	// 2️⃣ A running step holds the lock; abort once it reports back
+	lock, err := locks.Acquire(ctx, serviceName, r.Environment, "rollback", by, reason)
+	if err != nil {
+		return nil, err
+	}
+	if r, err = Get(ctx, serviceName, id); err == nil {
+		err = canAbort(r)
+	}
+	var target *deploy.Target
+	if err == nil {
+		target, err = deploy.Lookup(ctx, serviceName)
+	}
+	if err != nil {
+		locks.Release(ctx, lock)
+		return nil, err
+	}
+
+	// 3️⃣ Roll back; the pipeline's rollback report finishes the abort
+	step := model.RolloutStep{
+		Step:        r.CurrentStep,
+		Phase:       "abort",
+		Status:      model.StepRunning,
+		TriggeredBy: by,
+		TriggeredAt: now(),
+	}
+	if err := deploy.Rollback(target, r.Environment, r.PreviousVersion, params(r, step)); err != nil {
+		step.Status, step.FinishedAt, step.Error = model.StepFailed, &step.TriggeredAt, err.Error()
+		if dbErr := insertStep(ctx, id, step); dbErr != nil {
+			log.Printf("⚠️ Rollout %d abort: %v", id, dbErr)
+		}
+		locks.Release(ctx, lock)
+		return nil, fmt.Errorf("%w: %v", ErrTrigger, err)
+	}
+	if err := insertStep(ctx, id, step); err != nil {
+		log.Printf("⚠️ Rollout %d abort: %v", id, err)
+	}
+	r.History = append(r.History, step)
+
+	if _, err := db.DB.ExecContext(ctx, `
+		UPDATE rollouts SET status = 'aborting', next_step_at = NULL WHERE id = ?`,
+		id,
+	); err != nil {
+		return nil, err
+	}
+	r.Status, r.NextStepAt = model.RolloutAborting, nil
+
+	log.Printf("⏹️ Rollout %d aborted by %s; rolling back to %s", id, by, r.PreviousVersion)
+	return r, nil
+}
+
+func canAbort(r *model.Rollout) error {
+	switch r.Status {
+	case model.RolloutProgressing, model.RolloutWaiting, model.RolloutPaused:
+	default:
+		return &StateError{ID: r.ID, Status: r.Status, Action: "abort"}
+	}
+	if r.PreviousVersion == "" {
+		return ErrNothingToAbort
+	}
+	return nil
+}
+
+/* ===================== PIPELINE REPORTS ===================== */
+
+// OnArtifact records a pipeline report against the environment's rollout.
+// It returns true when the deploy is complete: a plain deploy, or the last
+// step of a rollout. Until then the environment keeps its previous version.
+// A rollback report ends a rollout as aborted, whether it came from Abort
+// or a manual rollback. It writes through tx, so the caller commits the
+// report and the rollout's progress together.
+func OnArtifact(ctx context.Context, tx *sql.Tx, a model.ArtifactEvent) (bool, error) {
+	r, err := active(ctx, tx, a.ServiceName, a.Environment)
+	if err != nil || r == nil {
+		return true, err
+	}
+	at := now()
+
+	if a.Action == "rollback" {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE rollout_steps SET status = 'succeeded', finished_at = ?
+			WHERE rollout_id = ? AND status = 'running'`,
+			at, r.ID,
+		); err != nil {
+			return true, err
+		}
+		_, err := tx.ExecContext(ctx, `
+			UPDATE rollouts SET status = 'aborted', next_step_at = NULL, finished_at = ?
+			WHERE id = ?`,
+			at, r.ID,
+		)
+		log.Printf("⏹️ Rollout %d ended by rollback to %s", r.ID, a.Version)
+		return true, err
+	}
+
+	// 1️⃣ Only the running step's report belongs to the rollout
+	res, err := tx.ExecContext(ctx, `
+		UPDATE rollout_steps SET status = 'succeeded', finished_at = ?
+		WHERE rollout_id = ? AND step = ? AND phase = 'step' AND status = 'running'`,
+		at, r.ID, r.CurrentStep,
+	)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		// A repeated report of a finished step: the rollout is still going
+		log.Printf("🚦 Rollout %d: no step running, report of %s ignored", r.ID, a.Version)
+		return false, nil
+	}
+
+	// 2️⃣ Last step completes it; otherwise wait for promote or the interval
+	var (
+		status     = r.Status
+		nextStepAt *time.Time
+		finishedAt *time.Time
+	)
+	switch {
+	case r.CurrentStep == len(r.Steps):
+		status, finishedAt = model.RolloutCompleted, &at
+	case r.Status == model.RolloutProgressing:
+		status = model.RolloutWaiting
+		if r.StepIntervalSeconds > 0 {
+			next := at.Add(time.Duration(r.StepIntervalSeconds) * time.Second)
+			nextStepAt = &next
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE rollouts
+		SET status = ?, version = IF(version = '', ?, version), next_step_at = ?, finished_at = ?
+		WHERE id = ?`,
+		status, a.Version, nextStepAt, finishedAt, r.ID,
+	); err != nil {
+		return false, err
+	}
+
+	log.Printf("🚦 Rollout %d step %d/%d done (%s) → %s", r.ID, r.CurrentStep, len(r.Steps), a.Version, status)
+	return status == model.RolloutCompleted, nil
+}
+
+// AdvanceDue promotes rollouts whose step interval has passed. Only the
+// scheduler's leader calls it; a rollout that cannot advance yet (locked,
+// frozen) is retried on the next tick.
+func AdvanceDue(ctx context.Context) error {
+	rows, err := db.DB.QueryContext(ctx, `
+		SELECT id, service_name FROM rollouts
+		WHERE status = 'waiting' AND next_step_at <= ?
+		ORDER BY next_step_at
+		LIMIT ?`,
+		now(), batchSize,
+	)
+	if err != nil {
+		return err
+	}
+
+	type due struct {
+		id          int64
+		serviceName string
+	}
+	var pending []due
+	for rows.Next() {
+		var d due
+		if err := rows.Scan(&d.id, &d.serviceName); err != nil {
+			rows.Close()
+			return err
+		}
+		pending = append(pending, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, d := range pending {
+		if _, err := Promote(ctx, d.serviceName, d.id, "scheduler"); err != nil {
+			log.Printf("⚠️ Rollout %d: %v", d.id, err)
+		}
+	}
+	return nil
+}
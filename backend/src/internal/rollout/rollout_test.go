@@ -0,0 +1,261 @@
+package rollout
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+
+	"src/src/internal/db"
+	"src/src/internal/deploy"
+	"src/src/internal/git"
+	"src/src/internal/github"
+	"src/src/internal/locks"
+	"src/src/internal/model"
+)
+
+var (
+	t0                = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	rolloutRowColumns = []string{
+		"id", "service_name", "environment", "strategy", "steps", "current_step", "step_interval_seconds",
+		"version", "previous_version", "status", "next_step_at", "started_by", "created_at", "finished_at",
+	}
+	stepColumns = []string{
+		"rollout_id", "step", "phase", "traffic_percent", "status", "triggered_by", "triggered_at", "finished_at", "error",
+	}
+	lockColumns = []string{"service_name", "environment", "operation", "owner", "reason", "acquired_at", "expires_at"}
+)
+
+func mockDB(t *testing.T, at time.Time) sqlmock.Sqlmock {
+	t.Helper()
+
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	db.DB = conn
+	prev := now
+	now = func() time.Time { return at }
+	t.Cleanup(func() { now = prev })
+	return mock
+}
+
+type staticTokens struct{ conn github.Connection }
+
+func (s staticTokens) Token(string) (string, error)        { return "test-token", nil }
+func (s staticTokens) DefaultOwner() (string, bool, error) { return "acme", false, nil }
+func (s staticTokens) Connection() github.Connection       { return s.conn }
+
+// fakeActions records the workflow_dispatch inputs pipelines are started
+// with.
+type fakeActions struct {
+	mu         sync.Mutex
+	dispatches []map[string]string
+}
+
+func startActions(t *testing.T) *fakeActions {
+	t.Helper()
+
+	f := &fakeActions{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || !strings.HasSuffix(r.URL.Path, "/dispatches") {
+			http.NotFound(w, r)
+			return
+		}
+		var body struct {
+			Inputs map[string]string `json:"inputs"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		f.mu.Lock()
+		f.dispatches = append(f.dispatches, body.Inputs)
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	deploy.SetTokenSource(staticTokens{conn: github.Connection{APIURL: srv.URL, WebURL: srv.URL}})
+	t.Cleanup(func() { deploy.SetTokenSource(git.DefaultTokenSource()) })
+	return f
+}
+
+// canaryRows is rollout 12 of orders/dev, 10% → 50% → 100%, on step.
+func canaryRows(status string, step int) *sqlmock.Rows {
+	return sqlmock.NewRows(rolloutRowColumns).
+		AddRow(12, "orders", "dev", "canary", "10,50,100", step, 600, "1.3.0", "1.2.0", status, nil, "alice", t0.Add(-time.Hour), nil)
+}
+
+func expectRollout(m sqlmock.Sqlmock, status, stepStatus string) {
+	m.ExpectQuery("FROM rollouts").WithArgs(int64(12), "orders").WillReturnRows(canaryRows(status, 1))
+	m.ExpectQuery("FROM rollout_steps").WithArgs(int64(12)).WillReturnRows(sqlmock.NewRows(stepColumns).
+		AddRow(12, 1, "step", 10, stepStatus, "alice", t0.Add(-time.Hour), nil, ""))
+}
+
+func expectNoFreeze(m sqlmock.Sqlmock) {
+	m.ExpectQuery("SELECT owner_team FROM services").WillReturnRows(sqlmock.NewRows([]string{"owner_team"}))
+	m.ExpectQuery("FROM freeze_windows").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+}
+
+func TestAdvanceDuePromotesNextStep(t *testing.T) {
+	m := mockDB(t, t0)
+	actions := startActions(t)
+
+	m.ExpectQuery("WHERE status = 'waiting' AND next_step_at <= ?").WithArgs(t0, batchSize).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "service_name"}).AddRow(12, "orders"))
+	expectRollout(m, "waiting", "succeeded")
+	expectNoFreeze(m)
+	m.ExpectBegin()
+	m.ExpectExec("DELETE FROM deployment_locks").WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec("INSERT INTO deployment_locks").
+		WithArgs("orders", "dev", sqlmock.AnyArg(), "deploy", "scheduler", "rollout 12 step 2", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	m.ExpectCommit()
+	expectRollout(m, "waiting", "succeeded")
+	m.ExpectQuery("FROM services").WithArgs("orders").WillReturnRows(
+		sqlmock.NewRows([]string{"cicd_type", "deploy_type", "owner_team", "repo_owner", "repo_name", "repo_path"}).
+			AddRow("github", "microservice", "payments", "acme", "orders", ""))
+	m.ExpectExec("INSERT INTO rollout_steps").
+		WithArgs(int64(12), 2, "step", 50, model.StepRunning, "scheduler", t0, nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	m.ExpectExec("SET current_step = ?").WithArgs(2, int64(12)).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := AdvanceDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	if len(actions.dispatches) != 1 {
+		t.Fatalf("dispatches = %v", actions.dispatches)
+	}
+	got := actions.dispatches[0]
+	if got["rollout_step"] != "2" || got["traffic_percent"] != "50" || got["rollout_version"] != "1.3.0" {
+		t.Errorf("inputs = %v", got)
+	}
+}
+
+func TestPromoteWaitsForRunningStep(t *testing.T) {
+	m := mockDB(t, t0)
+	actions := startActions(t)
+
+	expectRollout(m, "waiting", "running")
+
+	var state *StateError
+	if _, err := Promote(context.Background(), "orders", 12, "alice"); !errors.As(err, &state) {
+		t.Fatalf("got %v, want *StateError", err)
+	}
+	if err := m.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	if len(actions.dispatches) != 0 {
+		t.Errorf("dispatched %v", actions.dispatches)
+	}
+}
+
+func TestAbortWhileStepHoldsLock(t *testing.T) {
+	m := mockDB(t, t0)
+	actions := startActions(t)
+
+	expectRollout(m, "progressing", "running")
+	expectNoFreeze(m)
+	m.ExpectBegin()
+	m.ExpectExec("DELETE FROM deployment_locks").WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec("INSERT INTO deployment_locks").WillReturnError(&mysql.MySQLError{Number: 1062})
+	m.ExpectQuery("FROM deployment_locks").WithArgs("orders", "dev").WillReturnRows(sqlmock.NewRows(lockColumns).
+		AddRow("orders", "dev", "deploy", "alice", "rollout 12 step 1", t0.Add(-time.Minute), t0.Add(29*time.Minute)))
+	m.ExpectRollback()
+
+	_, err := Abort(context.Background(), "orders", 12, "bob")
+	var locked *locks.LockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("got %v, want *locks.LockedError", err)
+	}
+	if locked.Held.Reason != "rollout 12 step 1" {
+		t.Errorf("held = %+v", locked.Held)
+	}
+	if err := m.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	if len(actions.dispatches) != 0 {
+		t.Errorf("rolled back while the step was running: %v", actions.dispatches)
+	}
+}
+
+func TestOnArtifact(t *testing.T) {
+	next := t0.Add(600 * time.Second)
+	tests := []struct {
+		name     string
+		action   string
+		step     int            // current step of the active rollout; 0 for none
+		running  int64          // running steps the report finishes
+		update   []driver.Value // args of the rollouts update; nil for none
+		complete bool
+	}{
+		{name: "no rollout", action: "deploy", complete: true},
+		{
+			name: "first step", action: "deploy", step: 1, running: 1,
+			update: []driver.Value{model.RolloutWaiting, "1.3.0", next, nil, int64(12)},
+		},
+		{
+			name: "last step", action: "deploy", step: 3, running: 1,
+			update:   []driver.Value{model.RolloutCompleted, "1.3.0", nil, t0, int64(12)},
+			complete: true,
+		},
+		{name: "repeated report", action: "deploy", step: 2},
+		{name: "rollback", action: "rollback", step: 2, running: 1, complete: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mockDB(t, t0)
+			m.ExpectBegin()
+
+			rows := sqlmock.NewRows(rolloutRowColumns)
+			if tt.step > 0 {
+				rows = canaryRows(model.RolloutProgressing, tt.step)
+			}
+			m.ExpectQuery("FROM rollouts").WithArgs("orders", "dev").WillReturnRows(rows)
+			switch {
+			case tt.step == 0:
+			case tt.action == "rollback":
+				m.ExpectExec("UPDATE rollout_steps").WithArgs(t0, int64(12)).WillReturnResult(sqlmock.NewResult(0, tt.running))
+				m.ExpectExec("SET status = 'aborted'").WithArgs(t0, int64(12)).WillReturnResult(sqlmock.NewResult(0, 1))
+			default:
+				m.ExpectExec("UPDATE rollout_steps").WithArgs(t0, int64(12), tt.step).
+					WillReturnResult(sqlmock.NewResult(0, tt.running))
+			}
+			if tt.update != nil {
+				m.ExpectExec("UPDATE rollouts").WithArgs(tt.update...).WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+
+			tx, err := db.DB.Begin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer tx.Rollback()
+
+			complete, err := OnArtifact(context.Background(), tx, model.ArtifactEvent{
+				ServiceName: "orders", Environment: "dev", Version: "1.3.0", Action: tt.action,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if complete != tt.complete {
+				t.Errorf("complete = %v, want %v", complete, tt.complete)
+			}
+			if err := m.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
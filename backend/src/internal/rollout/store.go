@@ -0,0 +1,294 @@
+package rollout
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"src/src/internal/db"
+	"src/src/internal/model"
+)
+
+var (
+	ErrNotFound       = errors.New("rollout not found")
+	ErrInProgress     = errors.New("a rollout is in progress")
+	ErrNothingToAbort = errors.New("the environment ran no version before this rollout; nothing to abort to")
+	ErrTrigger        = errors.New("pipeline trigger failed")
+)
+
+// StateError is returned when a rollout's status does not allow an action.
+type StateError struct {
+	ID     int64
+	Status string
+	Action string
+}
+
+func (e *StateError) Error() string {
+	return fmt.Sprintf("cannot %s rollout %d: it is %s", e.Action, e.ID, e.Status)
+}
+
+// unfinished lists the statuses that keep other deploys out.
+const unfinished = `'progressing', 'waiting', 'paused', 'aborting'`
+
+const rolloutColumns = `
+	id, service_name, environment, strategy, steps, current_step, step_interval_seconds,
+	version, previous_version, status, next_step_at, started_by, created_at, finished_at`
+
+/* ===================== STRATEGIES ===================== */
+
+// GetStrategy returns the strategy of serviceName/env; all-at-once when
+// none is set.
+func GetStrategy(ctx context.Context, serviceName, env string) (*model.DeploymentStrategy, error) {
+	found, err := queryStrategies(ctx, `
+		SELECT service_name, environment, strategy, canary_steps, step_interval_seconds, updated_by, updated_at
+		FROM deployment_strategies
+		WHERE service_name = ? AND environment = ?`,
+		serviceName, env,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return &model.DeploymentStrategy{ServiceName: serviceName, Environment: env, Strategy: model.StrategyAllAtOnce}, nil
+	}
+	return &found[0], nil
+}
+
+// ListStrategies returns the strategies set for a service.
+func ListStrategies(ctx context.Context, serviceName string) ([]model.DeploymentStrategy, error) {
+	return queryStrategies(ctx, `
+		SELECT service_name, environment, strategy, canary_steps, step_interval_seconds, updated_by, updated_at
+		FROM deployment_strategies
+		WHERE service_name = ?
+		ORDER BY environment`,
+		serviceName,
+	)
+}
+
+// PutStrategy sets the strategy of s.ServiceName/s.Environment. A rollout
+// already started keeps the steps it started with.
+func PutStrategy(ctx context.Context, s *model.DeploymentStrategy) error {
+	s.UpdatedAt = now()
+
+	_, err := db.DB.ExecContext(ctx, `
+		REPLACE INTO deployment_strategies
+		(service_name, environment, strategy, canary_steps, step_interval_seconds, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		s.ServiceName, s.Environment, s.Strategy, formatSteps(s.CanarySteps), s.StepIntervalSeconds, s.UpdatedBy, s.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("🚦 Deployment strategy of %s/%s set to %s by %s", s.ServiceName, s.Environment, s.Strategy, s.UpdatedBy)
+	return nil
+}
+
+func queryStrategies(ctx context.Context, sqlText string, args ...interface{}) ([]model.DeploymentStrategy, error) {
+	rows, err := db.DB.QueryContext(ctx, sqlText, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.DeploymentStrategy{}
+	for rows.Next() {
+		var (
+			s     model.DeploymentStrategy
+			steps string
+		)
+		if err := rows.Scan(&s.ServiceName, &s.Environment, &s.Strategy, &steps, &s.StepIntervalSeconds, &s.UpdatedBy, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if s.CanarySteps, err = parseSteps(steps); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+/* ===================== ROLLOUTS ===================== */
+
+// Get returns a rollout of serviceName with its history, or ErrNotFound.
+func Get(ctx context.Context, serviceName string, id int64) (*model.Rollout, error) {
+	found, err := queryRollouts(ctx, db.DB, `
+		SELECT `+rolloutColumns+`
+		FROM rollouts
+		WHERE id = ? AND service_name = ?`,
+		id, serviceName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, ErrNotFound
+	}
+	if err := loadHistory(ctx, found); err != nil {
+		return nil, err
+	}
+	return &found[0], nil
+}
+
+// List returns a service's latest rollouts with their history, newest first.
+func List(ctx context.Context, serviceName string) ([]model.Rollout, error) {
+	found, err := queryRollouts(ctx, db.DB, `
+		SELECT `+rolloutColumns+`
+		FROM rollouts
+		WHERE service_name = ?
+		ORDER BY id DESC
+		LIMIT 50`,
+		serviceName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return found, loadHistory(ctx, found)
+}
+
+// active returns the unfinished rollout of serviceName/env, or nil.
+func active(ctx context.Context, q querier, serviceName, env string) (*model.Rollout, error) {
+	found, err := queryRollouts(ctx, q, `
+		SELECT `+rolloutColumns+`
+		FROM rollouts
+		WHERE service_name = ? AND environment = ? AND status IN (`+unfinished+`)
+		ORDER BY id DESC
+		LIMIT 1`,
+		serviceName, env,
+	)
+	if err != nil || len(found) == 0 {
+		return nil, err
+	}
+	return &found[0], nil
+}
+
+func create(ctx context.Context, r *model.Rollout) error {
+	res, err := db.DB.ExecContext(ctx, `
+		INSERT INTO rollouts
+		(service_name, environment, strategy, steps, current_step, step_interval_seconds,
+		 previous_version, status, started_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.ServiceName, r.Environment, r.Strategy, formatSteps(r.Steps), r.CurrentStep, r.StepIntervalSeconds,
+		r.PreviousVersion, r.Status, r.StartedBy, r.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	r.ID, err = res.LastInsertId()
+	return err
+}
+
+func insertStep(ctx context.Context, rolloutID int64, s model.RolloutStep) error {
+	var stepErr sql.NullString
+	if s.Error != "" {
+		stepErr = sql.NullString{String: s.Error, Valid: true}
+	}
+	_, err := db.DB.ExecContext(ctx, `
+		INSERT INTO rollout_steps
+		(rollout_id, step, phase, traffic_percent, status, triggered_by, triggered_at, finished_at, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rolloutID, s.Step, s.Phase, s.TrafficPercent, s.Status, s.TriggeredBy, s.TriggeredAt, s.FinishedAt, stepErr,
+	)
+	return err
+}
+
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func queryRollouts(ctx context.Context, q querier, sqlText string, args ...interface{}) ([]model.Rollout, error) {
+	rows, err := q.QueryContext(ctx, sqlText, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.Rollout{}
+	for rows.Next() {
+		var (
+			r     model.Rollout
+			steps string
+		)
+		if err := rows.Scan(
+			&r.ID, &r.ServiceName, &r.Environment, &r.Strategy, &steps, &r.CurrentStep, &r.StepIntervalSeconds,
+			&r.Version, &r.PreviousVersion, &r.Status, &r.NextStepAt, &r.StartedBy, &r.CreatedAt, &r.FinishedAt,
+		); err != nil {
+			return nil, err
+		}
+		if r.Steps, err = parseSteps(steps); err != nil {
+			return nil, err
+		}
+		r.History = []model.RolloutStep{}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// loadHistory fills in the steps of rollouts with one query.
+func loadHistory(ctx context.Context, rollouts []model.Rollout) error {
+	if len(rollouts) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*model.Rollout, len(rollouts))
+	args := make([]interface{}, 0, len(rollouts))
+	for i := range rollouts {
+		byID[rollouts[i].ID] = &rollouts[i]
+		args = append(args, rollouts[i].ID)
+	}
+
+	rows, err := db.DB.QueryContext(ctx, `
+		SELECT rollout_id, step, phase, traffic_percent, status, triggered_by, triggered_at, finished_at, COALESCE(error, '')
+		FROM rollout_steps
+		WHERE rollout_id IN (?`+strings.Repeat(", ?", len(args)-1)+`)
+		ORDER BY id`,
+		args...,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id int64
+			s  model.RolloutStep
+		)
+		if err := rows.Scan(&id, &s.Step, &s.Phase, &s.TrafficPercent, &s.Status, &s.TriggeredBy, &s.TriggeredAt, &s.FinishedAt, &s.Error); err != nil {
+			return err
+		}
+		if r := byID[id]; r != nil {
+			r.History = append(r.History, s)
+		}
+	}
+	return rows.Err()
+}
+
+// Steps are stored as "10,50,100".
+func formatSteps(steps []int) string {
+	parts := make([]string, len(steps))
+	for i, s := range steps {
+		parts[i] = strconv.Itoa(s)
+	}
+	return strings.Join(parts, ",")
+}
+
+func parseSteps(s string) ([]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	steps := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rollout steps %q", s)
+		}
+		steps[i] = n
+	}
+	return steps, nil
+}
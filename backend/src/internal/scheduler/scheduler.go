@@ -0,0 +1,264 @@
+// Package scheduler runs deployments queued for a later time, advances
+// rollouts whose step interval has passed and tears down expired previews.
+// Every replica runs the loop, but only the one holding a MySQL named lock
+// (GET_LOCK) does the work; the lock is tied to that replica's connection,
+// so it passes to another replica when the leader dies.
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"src/src/internal/config"
+	"src/src/internal/db"
+	"src/src/internal/deploy"
+	"src/src/internal/freeze"
+	"src/src/internal/locks"
+	"src/src/internal/model"
+	"src/src/internal/preview"
+	"src/src/internal/rollout"
+)
+
+// leaderLock is the GET_LOCK name shared by every replica.
+const leaderLock = "platform.scheduled_deployments"
+
+// batchSize caps the runs started per tick.
+const batchSize = 20
+
+var (
+	interval = config.Default().Deployments.SchedulerInterval
+	grace    = config.Default().Deployments.ScheduleGrace
+)
+
+// Configure sets the poll interval and how late a run may still start.
+func Configure(cfg config.Deployments) {
+	interval = cfg.SchedulerInterval
+	grace = cfg.ScheduleGrace
+}
+
+// now is replaced in tests.
+var now = func() time.Time { return time.Now().UTC().Truncate(time.Second) }
+
+// Run polls for due deployments until ctx is cancelled.
+func Run(ctx context.Context) {
+	log.Printf("⏰ Scheduler started (every %s)", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var leader *sql.Conn
+	defer func() { resign(leader) }()
+
+	for {
+		leader = lead(ctx, leader)
+		if leader != nil {
+			if err := RunDue(ctx); err != nil {
+				log.Printf("⚠️ Scheduler: %v", err)
+			}
+			if err := rollout.AdvanceDue(ctx); err != nil {
+				log.Printf("⚠️ Scheduler: advance rollouts: %v", err)
+			}
+			if err := preview.Expire(ctx); err != nil {
+				log.Printf("⚠️ Scheduler: expire previews: %v", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+/* ===================== LEADER ELECTION ===================== */
+
+// lead returns the connection holding the leader lock, trying to take it
+// if this replica does not lead yet. nil means another replica leads.
+func lead(ctx context.Context, conn *sql.Conn) *sql.Conn {
+	// 1️⃣ Still leading? The lock dies with the connection.
+	if conn != nil {
+		var mine sql.NullBool
+		err := conn.QueryRowContext(ctx, `SELECT IS_USED_LOCK(?) = CONNECTION_ID()`, leaderLock).Scan(&mine)
+		if err == nil && mine.Bool {
+			return conn
+		}
+		log.Printf("⚠️ Scheduler lost leadership: %v", err)
+		conn.Close()
+	}
+
+	// 2️⃣ Try to take over without waiting
+	conn, err := db.DB.Conn(ctx)
+	if err != nil {
+		return nil
+	}
+	var got sql.NullInt64
+	if err := conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, 0)`, leaderLock).Scan(&got); err != nil || got.Int64 != 1 {
+		conn.Close()
+		return nil
+	}
+	log.Println("👑 This replica now runs scheduled deployments")
+
+	// 3️⃣ A run left "running" was interrupted mid-trigger by the previous
+	// leader; whether CI started is unknown, so do not start it twice
+	if _, err := db.DB.ExecContext(ctx, `
+		UPDATE scheduled_deployments
+		SET status = 'failed', last_error = 'scheduler stopped while starting this run', executed_at = ?
+		WHERE status = 'running'`,
+		now(),
+	); err != nil {
+		log.Printf("⚠️ Scheduler: recover interrupted runs: %v", err)
+	}
+	return conn
+}
+
+// resign hands leadership to another replica on shutdown. Closing a
+// sql.Conn only returns it to the pool, so the lock is released explicitly.
+func resign(conn *sql.Conn) {
+	if conn == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := conn.ExecContext(ctx, `SELECT RELEASE_LOCK(?)`, leaderLock); err != nil {
+		log.Printf("⚠️ Scheduler: release leadership: %v", err)
+	}
+	conn.Close()
+}
+
+/* ===================== EXECUTION ===================== */
+
+// RunDue starts every run whose time has come. Only the leader calls it.
+func RunDue(ctx context.Context) error {
+	due, err := query(ctx, db.DB, `
+		SELECT `+columns+`
+		FROM scheduled_deployments
+		WHERE status = 'scheduled' AND scheduled_at <= ?
+		ORDER BY scheduled_at
+		LIMIT ?`,
+		now(), batchSize,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range due {
+		// Claim first so a run is never started twice
+		res, err := db.DB.ExecContext(ctx, `
+			UPDATE scheduled_deployments SET status = 'running'
+			WHERE id = ? AND status = 'scheduled'`,
+			d.ID,
+		)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			continue // cancelled meanwhile
+		}
+
+		status, runErr := execute(ctx, d)
+		if err := finish(ctx, d.ID, status, runErr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execute re-checks everything the request was checked against, since
+// the world may have changed since it was scheduled, then triggers CI.
+func execute(ctx context.Context, d model.ScheduledDeployment) (status string, err error) {
+	log.Printf("⏰ Running scheduled deployment %d: %s/%s", d.ID, d.ServiceName, d.Environment)
+
+	// 1️⃣ Prod still needs its approval
+	if d.ApprovalID != nil {
+		var approval string
+		err := db.DB.QueryRowContext(ctx, `
+			SELECT status FROM deployment_approvals WHERE id = ?`,
+			*d.ApprovalID,
+		).Scan(&approval)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return model.ScheduleScheduled, err
+		}
+		if approval != "approved" {
+			return model.ScheduleSkipped, fmt.Errorf("approval %d is %s at execution time", *d.ApprovalID, orNone(approval))
+		}
+	}
+
+	// 2️⃣ Freezes apply at execution time, not when scheduled
+	if err := freeze.Check(ctx, d.ServiceName, d.Environment); err != nil {
+		var frozen *freeze.FrozenError
+		if errors.As(err, &frozen) {
+			return model.ScheduleSkipped, err
+		}
+		return model.ScheduleScheduled, err
+	}
+
+	// 3️⃣ Wait for a run in flight, within the grace period
+	lock, err := locks.Acquire(ctx, d.ServiceName, d.Environment, "deploy", "scheduler",
+		fmt.Sprintf("scheduled deployment %d", d.ID))
+	var locked *locks.LockedError
+	if errors.As(err, &locked) && !now().Before(d.ScheduledAt.Add(grace)) {
+		return model.ScheduleSkipped, err
+	}
+	if err != nil {
+		return model.ScheduleScheduled, err
+	}
+
+	// 4️⃣ A canary or blue-green rollout in progress waits the same way
+	if err := rollout.Idle(ctx, d.ServiceName, d.Environment); err != nil {
+		locks.Release(ctx, lock)
+		if errors.Is(err, rollout.ErrInProgress) && !now().Before(d.ScheduledAt.Add(grace)) {
+			return model.ScheduleSkipped, err
+		}
+		return model.ScheduleScheduled, err
+	}
+
+	// 5️⃣ Trigger, with the environment's strategy
+	target, err := deploy.Lookup(ctx, d.ServiceName)
+	if err == nil {
+		_, err = rollout.Start(ctx, target, d.Environment, d.RequestedBy)
+	}
+	if err != nil {
+		locks.Release(ctx, lock)
+		return model.ScheduleFailed, err
+	}
+	return model.ScheduleTriggered, nil
+}
+
+// finish records the outcome. A run put back to scheduled keeps the
+// error so callers can see why it is waiting.
+func finish(ctx context.Context, id int64, status string, runErr error) error {
+	var (
+		lastError  interface{}
+		executedAt interface{}
+	)
+	if runErr != nil {
+		lastError = runErr.Error()
+		log.Printf("⚠️ Scheduled deployment %d → %s: %v", id, status, runErr)
+	} else {
+		log.Printf("✅ Scheduled deployment %d → %s", id, status)
+	}
+	if status != model.ScheduleScheduled {
+		executedAt = now()
+	}
+
+	_, err := db.DB.ExecContext(ctx, `
+		UPDATE scheduled_deployments
+		SET status = ?, last_error = ?, executed_at = ?
+		WHERE id = ?`,
+		status, lastError, executedAt, id,
+	)
+	return err
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "missing"
+	}
+	return s
+}
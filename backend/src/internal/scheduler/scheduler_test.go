@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+
+	"src/src/internal/db"
+)
+
+var (
+	t0         = time.Date(2024, 5, 1, 2, 0, 0, 0, time.UTC)
+	dueColumns = []string{
+		"id", "service_name", "environment", "scheduled_at", "approval_id", "status", "reason",
+		"requested_by", "last_error", "created_at", "executed_at",
+	}
+)
+
+func mockDB(t *testing.T, at time.Time) sqlmock.Sqlmock {
+	t.Helper()
+
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	db.DB = conn
+	prev := now
+	now = func() time.Time { return at }
+	t.Cleanup(func() { now = prev })
+	return mock
+}
+
+func expectDue(m sqlmock.Sqlmock, env string, approvalID interface{}) {
+	m.ExpectQuery("FROM scheduled_deployments").WillReturnRows(sqlmock.NewRows(dueColumns).
+		AddRow(9, "orders", env, t0, approvalID, "scheduled", "", "alice", "", t0.Add(-time.Hour), nil))
+	m.ExpectExec("SET status = 'running'").WithArgs(int64(9)).WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func expectNoFreeze(m sqlmock.Sqlmock) {
+	m.ExpectQuery("SELECT owner_team FROM services").WillReturnRows(sqlmock.NewRows([]string{"owner_team"}))
+	m.ExpectQuery("FROM freeze_windows").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+}
+
+func TestRunDueSkipsWithoutApproval(t *testing.T) {
+	m := mockDB(t, t0)
+
+	expectDue(m, "prod", 7)
+	m.ExpectQuery("FROM deployment_approvals").WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("rejected"))
+	m.ExpectExec("UPDATE scheduled_deployments").
+		WithArgs("skipped", "approval 7 is rejected at execution time", t0, int64(9)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := RunDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRunDueWaitsForLock(t *testing.T) {
+	tests := []struct {
+		name   string
+		at     time.Time
+		status string
+	}{
+		{"within grace", t0.Add(10 * time.Minute), "scheduled"},
+		{"after grace", t0.Add(grace), "skipped"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mockDB(t, tt.at)
+
+			expectDue(m, "dev", nil)
+			expectNoFreeze(m)
+			m.ExpectBegin()
+			m.ExpectExec("DELETE FROM deployment_locks").WillReturnResult(sqlmock.NewResult(0, 0))
+			m.ExpectExec("INSERT INTO deployment_locks").WillReturnError(&mysql.MySQLError{Number: 1062})
+			m.ExpectQuery("FROM deployment_locks").WillReturnRows(
+				sqlmock.NewRows([]string{"service_name", "environment", "operation", "owner", "reason", "acquired_at", "expires_at"}).
+					AddRow("orders", "dev", "deploy", "bob", "", t0, t0.Add(2*grace)))
+			m.ExpectRollback()
+
+			var executedAt interface{}
+			if tt.status != "scheduled" {
+				executedAt = tt.at
+			}
+			m.ExpectExec("UPDATE scheduled_deployments").
+				WithArgs(tt.status, sqlmock.AnyArg(), executedAt, int64(9)).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			if err := RunDue(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if err := m.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
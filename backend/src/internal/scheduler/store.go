@@ -0,0 +1,155 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"src/src/internal/db"
+	"src/src/internal/model"
+)
+
+var ErrNotFound = errors.New("scheduled deployment not found or no longer scheduled")
+
+const columns = `
+	id, service_name, environment, scheduled_at, approval_id, status, reason,
+	requested_by, COALESCE(last_error, ''), created_at, executed_at`
+
+// Create stores d as scheduled. Pass the transaction that created d's
+// approval so both are written or neither; tx may be nil.
+func Create(ctx context.Context, tx *sql.Tx, d *model.ScheduledDeployment) error {
+	exec := db.DB.ExecContext
+	if tx != nil {
+		exec = tx.ExecContext
+	}
+
+	res, err := exec(ctx, `
+		INSERT INTO scheduled_deployments
+		(service_name, environment, scheduled_at, approval_id, status, reason, requested_by)
+		VALUES (?, ?, ?, ?, 'scheduled', ?, ?)`,
+		d.ServiceName, d.Environment, d.ScheduledAt, d.ApprovalID, d.Reason, d.RequestedBy,
+	)
+	if err != nil {
+		return err
+	}
+
+	if d.ID, err = res.LastInsertId(); err != nil {
+		return err
+	}
+	d.Status = model.ScheduleScheduled
+	d.CreatedAt = now()
+
+	log.Printf("⏰ Scheduled deployment %d: %s/%s at %s by %s",
+		d.ID, d.ServiceName, d.Environment, d.ScheduledAt.UTC().Format(time.RFC3339), d.RequestedBy)
+	return nil
+}
+
+// List returns a service's scheduled deployments, latest first.
+func List(ctx context.Context, serviceName string) ([]model.ScheduledDeployment, error) {
+	return query(ctx, db.DB, `
+		SELECT `+columns+`
+		FROM scheduled_deployments
+		WHERE service_name = ?
+		ORDER BY scheduled_at DESC`,
+		serviceName,
+	)
+}
+
+// ForApproval returns the still-scheduled run tied to approvalID, or nil
+// when the approval is for an immediate deploy.
+func ForApproval(ctx context.Context, tx *sql.Tx, approvalID int64) (*model.ScheduledDeployment, error) {
+	found, err := query(ctx, tx, `
+		SELECT `+columns+`
+		FROM scheduled_deployments
+		WHERE approval_id = ? AND status = 'scheduled'`,
+		approvalID,
+	)
+	if err != nil || len(found) == 0 {
+		return nil, err
+	}
+	return &found[0], nil
+}
+
+// Cancel stops a run that has not started yet. Its approval, if still
+// pending, is rejected with it.
+func Cancel(ctx context.Context, serviceName string, id int64, by string) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var approvalID sql.NullInt64
+	err = tx.QueryRowContext(ctx, `
+		SELECT approval_id FROM scheduled_deployments
+		WHERE id = ? AND service_name = ? AND status = 'scheduled'
+		FOR UPDATE`,
+		id, serviceName,
+	).Scan(&approvalID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE scheduled_deployments
+		SET status = 'cancelled', last_error = ?
+		WHERE id = ?`,
+		"cancelled by "+by, id,
+	); err != nil {
+		return err
+	}
+
+	if approvalID.Valid {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE deployment_approvals
+			SET status='rejected', approved_at=NOW()
+			WHERE id=? AND status='pending'`,
+			approvalID.Int64,
+		); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	log.Printf("⏰ Scheduled deployment %d of %s cancelled by %s", id, serviceName, by)
+	return nil
+}
+
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func query(ctx context.Context, q querier, sqlText string, args ...interface{}) ([]model.ScheduledDeployment, error) {
+	rows, err := q.QueryContext(ctx, sqlText, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.ScheduledDeployment{}
+	for rows.Next() {
+		var (
+			d          model.ScheduledDeployment
+			approvalID sql.NullInt64
+		)
+		if err := rows.Scan(
+			&d.ID, &d.ServiceName, &d.Environment, &d.ScheduledAt, &approvalID, &d.Status, &d.Reason,
+			&d.RequestedBy, &d.LastError, &d.CreatedAt, &d.ExecutedAt,
+		); err != nil {
+			return nil, err
+		}
+		if approvalID.Valid {
+			d.ApprovalID = &approvalID.Int64
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,88 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"src/src/internal/aws"
+)
+
+/* ===================== AWS SECRETS MANAGER ===================== */
+
+type AWSProvider struct{}
+
+func (AWSProvider) Get(ctx context.Context, name string) (string, error) {
+	value, err := aws.GetSecret(ctx, name)
+	if errors.Is(err, aws.ErrSecretNotFound) {
+		return "", ErrNotFound
+	}
+	return value, err
+}
+
+func (AWSProvider) Ping(ctx context.Context) error {
+	return aws.Ping(ctx)
+}
+
+/* ===================== ENVIRONMENT ===================== */
+
+var envInvalid = regexp.MustCompile(`[^A-Z0-9_]+`)
+
+// EnvProvider reads PREFIX + NAME, where "git-token" becomes GIT_TOKEN.
+type EnvProvider struct {
+	Prefix string
+}
+
+func (e EnvProvider) Get(_ context.Context, name string) (string, error) {
+	key := e.Prefix + envInvalid.ReplaceAllString(strings.ToUpper(name), "_")
+
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return "", fmt.Errorf("%w: env %s", ErrNotFound, key)
+	}
+	return value, nil
+}
+
+/* ===================== FILES ===================== */
+
+// FileProvider reads one secret per file (Docker/Kubernetes style). Relative
+// names are resolved against Dir.
+type FileProvider struct {
+	Dir string
+}
+
+func (f FileProvider) Get(_ context.Context, name string) (string, error) {
+	path := name
+	if !filepath.IsAbs(path) {
+		clean := filepath.Clean(name)
+		if strings.HasPrefix(clean, "..") {
+			return "", fmt.Errorf("secret file %q escapes the secrets directory", name)
+		}
+		path = filepath.Join(f.Dir, clean)
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: file %s", ErrNotFound, path)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func (f FileProvider) Ping(context.Context) error {
+	info, err := os.Stat(f.Dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("secrets directory %s is not a directory", f.Dir)
+	}
+	return nil
+}
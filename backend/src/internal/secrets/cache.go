@@ -0,0 +1,75 @@
+package secrets
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// now is replaced in tests to expire entries.
+var now = time.Now
+
+type cacheEntry struct {
+	value   string
+	fetched time.Time
+}
+
+// Cached wraps a provider with a TTL cache. Rotated secrets are picked up
+// once the entry expires (or is invalidated).
+//
+// A backend error after expiry does not fail the read: the expired value is
+// logged and served again, and the backend is retried on the next Get. A
+// flaky backend therefore never takes the platform down, at the cost of
+// serving a stale value for as long as it stays down. Invalidate removes
+// the fallback, so callers that know the value is bad see the error.
+type Cached struct {
+	provider Provider
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+func NewCached(p Provider, ttl time.Duration) *Cached {
+	return &Cached{provider: p, ttl: ttl, entries: map[string]cacheEntry{}}
+}
+
+func (c *Cached) Get(ctx context.Context, name string) (string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[name]
+	c.mu.Unlock()
+
+	if ok && now().Sub(entry.fetched) < c.ttl {
+		return entry.value, nil
+	}
+
+	value, err := c.provider.Get(ctx, name)
+	if err != nil {
+		if ok {
+			log.Printf("⚠️ Secret refresh failed for %s, serving cached value: %v", name, err)
+			return entry.value, nil
+		}
+		return "", err
+	}
+
+	c.mu.Lock()
+	c.entries[name] = cacheEntry{value: value, fetched: now()}
+	c.mu.Unlock()
+
+	return value, nil
+}
+
+func (c *Cached) Invalidate(name string) {
+	c.mu.Lock()
+	delete(c.entries, name)
+	c.mu.Unlock()
+}
+
+// Ping forwards to the provider when it can check reachability.
+func (c *Cached) Ping(ctx context.Context) error {
+	if p, ok := c.provider.(Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeProvider returns value, or err when set, and counts reads.
+type fakeProvider struct {
+	value string
+	err   error
+	reads int
+}
+
+func (f *fakeProvider) Get(context.Context, string) (string, error) {
+	f.reads++
+	return f.value, f.err
+}
+
+// clock pins now and returns a function that moves it forward.
+func clock(t *testing.T) func(time.Duration) {
+	t.Helper()
+
+	current := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	prev := now
+	now = func() time.Time { return current }
+	t.Cleanup(func() { now = prev })
+
+	return func(d time.Duration) { current = current.Add(d) }
+}
+
+func TestCachedTTL(t *testing.T) {
+	advance := clock(t)
+	p := &fakeProvider{value: "v1"}
+	c := NewCached(p, time.Minute)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if v, err := c.Get(ctx, "token"); err != nil || v != "v1" {
+			t.Fatalf("got %q, %v", v, err)
+		}
+	}
+	if p.reads != 1 {
+		t.Errorf("backend read %d times within the ttl", p.reads)
+	}
+
+	// Rotated in the backend: picked up once the entry expires
+	p.value = "v2"
+	advance(59 * time.Second)
+	if v, _ := c.Get(ctx, "token"); v != "v1" {
+		t.Errorf("before expiry: %q", v)
+	}
+	advance(time.Second)
+	if v, _ := c.Get(ctx, "token"); v != "v2" {
+		t.Errorf("after expiry: %q", v)
+	}
+	if p.reads != 2 {
+		t.Errorf("reads = %d, want 2", p.reads)
+	}
+}
+
+func TestCachedInvalidate(t *testing.T) {
+	clock(t)
+	p := &fakeProvider{value: "v1"}
+	c := NewCached(p, time.Hour)
+	ctx := context.Background()
+
+	c.Get(ctx, "token")
+	p.value = "v2"
+	c.Invalidate("token")
+
+	if v, _ := c.Get(ctx, "token"); v != "v2" {
+		t.Errorf("after invalidate: %q", v)
+	}
+}
+
+func TestCachedServesStaleValueWhenBackendFails(t *testing.T) {
+	advance := clock(t)
+	p := &fakeProvider{value: "v1"}
+	c := NewCached(p, time.Minute)
+	ctx := context.Background()
+
+	c.Get(ctx, "token")
+	p.err = errors.New("backend down")
+	advance(time.Hour)
+
+	// Expired, backend down: the stale value is served, no error
+	for i := 0; i < 2; i++ {
+		if v, err := c.Get(ctx, "token"); err != nil || v != "v1" {
+			t.Fatalf("got %q, %v; want the stale value", v, err)
+		}
+	}
+	if p.reads != 3 {
+		t.Errorf("reads = %d; the backend should be retried on every Get", p.reads)
+	}
+
+	// Invalidated: no fallback left
+	c.Invalidate("token")
+	if _, err := c.Get(ctx, "token"); !errors.Is(err, p.err) {
+		t.Errorf("after invalidate: got %v, want the backend error", err)
+	}
+
+	// Never read: the error surfaces
+	if _, err := c.Get(ctx, "other"); !errors.Is(err, p.err) {
+		t.Errorf("uncached: got %v", err)
+	}
+}
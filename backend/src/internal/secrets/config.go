@@ -0,0 +1,55 @@
+package secrets
+
+import "time"
+
+// Config selects the default backend and maps logical secret names to
+// references. Example:
+//
+//	backend: vault
+//	cacheTTL: 5m
+//	vault: {addr: https://vault:8200, mount: kv}
+//	refs:
+//	  git-token: vault:platform/github#token
+//	  jenkins-api-token: file:jenkins-token
+type Config struct {
+	Backend  string        `yaml:"backend"` // aws (default) | vault | env | file
+	CacheTTL time.Duration `yaml:"cacheTTL"`
+	Vault    VaultConfig   `yaml:"vault"`
+	File     struct {
+		Dir string `yaml:"dir"`
+	} `yaml:"file"`
+	Env struct {
+		Prefix string `yaml:"prefix"`
+	} `yaml:"env"`
+	Refs map[string]string `yaml:"refs"`
+}
+
+// Secrets the platform reads and where they live unless configured
+// otherwise. Jenkins and DB credentials keep their historical env vars.
+var defaultRefs = map[string]string{
+	"jenkins-user":      "env:JENKINS_USER",
+	"jenkins-api-token": "env:JENKINS_API_TOKEN",
+	"db-password":       "env:DB_PASSWORD",
+}
+
+func (c Config) withDefaults() Config {
+	if c.Backend == "" {
+		c.Backend = "aws"
+	}
+	if c.CacheTTL <= 0 {
+		c.CacheTTL = 5 * time.Minute
+	}
+	if c.File.Dir == "" {
+		c.File.Dir = "/run/secrets"
+	}
+
+	refs := map[string]string{}
+	for k, v := range defaultRefs {
+		refs[k] = v
+	}
+	for k, v := range c.Refs {
+		refs[k] = v
+	}
+	c.Refs = refs
+	return c
+}
@@ -0,0 +1,188 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+var ErrNotFound = errors.New("secret not found")
+
+// Provider is a secrets backend. name is backend specific: a Secrets
+// Manager id, a Vault path, an env var or a file.
+type Provider interface {
+	Get(ctx context.Context, name string) (string, error)
+}
+
+// Pinger is implemented by providers that can report whether their backend
+// is reachable without reading a secret.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Manager resolves logical secret names ("git-token") to references
+// ("vault:platform/github#token") and reads them through cached backends.
+type Manager struct {
+	backends       map[string]*Cached
+	defaultBackend string
+	refs           map[string]string
+}
+
+// NewManager builds the backends described by cfg.
+func NewManager(cfg Config) (*Manager, error) {
+	cfg = cfg.withDefaults()
+
+	m := &Manager{
+		backends:       map[string]*Cached{},
+		defaultBackend: cfg.Backend,
+		refs:           cfg.Refs,
+	}
+
+	m.backends["env"] = NewCached(EnvProvider{Prefix: cfg.Env.Prefix}, cfg.CacheTTL)
+	m.backends["file"] = NewCached(FileProvider{Dir: cfg.File.Dir}, cfg.CacheTTL)
+	m.backends["aws"] = NewCached(AWSProvider{}, cfg.CacheTTL)
+
+	if cfg.Vault.Addr != "" {
+		m.backends["vault"] = NewCached(NewVaultProvider(cfg.Vault), cfg.CacheTTL)
+	}
+
+	if _, ok := m.backends[m.defaultBackend]; !ok {
+		return nil, fmt.Errorf("secrets backend %q is not configured", m.defaultBackend)
+	}
+
+	return m, nil
+}
+
+// Get returns the value of a logical secret name or an explicit reference.
+func (m *Manager) Get(ctx context.Context, name string) (string, error) {
+	backend, key, err := m.resolve(name)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := backend.Get(ctx, key)
+	if err != nil {
+		return "", fmt.Errorf("secret %s: %w", name, err)
+	}
+	return value, nil
+}
+
+// Invalidate drops a cached value so the next Get re-reads it, e.g. after
+// the backend reports the credential was rotated.
+func (m *Manager) Invalidate(name string) {
+	if backend, key, err := m.resolve(name); err == nil {
+		backend.Invalidate(key)
+	}
+}
+
+// Ping checks every backend the manager can actually read from: the
+// default one and any named by a reference.
+func (m *Manager) Ping(ctx context.Context) error {
+	used := map[string]bool{m.defaultBackend: true}
+	for _, ref := range m.refs {
+		scheme, _ := m.parseRef(ref)
+		used[scheme] = true
+	}
+
+	for scheme := range used {
+		backend, ok := m.backends[scheme]
+		if !ok {
+			return fmt.Errorf("secrets backend %q is not configured", scheme)
+		}
+		if err := backend.Ping(ctx); err != nil {
+			return fmt.Errorf("secrets backend %s: %w", scheme, err)
+		}
+	}
+	return nil
+}
+
+// resolve maps name → reference → (backend, key). References look like
+// "<backend>:<key>"; anything else is a key in the default backend.
+func (m *Manager) resolve(name string) (*Cached, string, error) {
+	ref := name
+	if r, ok := m.refs[name]; ok {
+		ref = r
+	}
+
+	scheme, key := m.parseRef(ref)
+
+	backend, ok := m.backends[scheme]
+	if !ok {
+		return nil, "", fmt.Errorf("secret %s: backend %q is not configured", name, scheme)
+	}
+	return backend, key, nil
+}
+
+func (m *Manager) parseRef(ref string) (scheme, key string) {
+	if i := strings.Index(ref, ":"); i > 0 && knownBackend(ref[:i]) {
+		return ref[:i], ref[i+1:]
+	}
+	return m.defaultBackend, ref
+}
+
+func knownBackend(s string) bool {
+	switch s {
+	case "aws", "vault", "env", "file":
+		return true
+	}
+	return false
+}
+
+/* ===================== PACKAGE DEFAULT ===================== */
+
+var (
+	mu      sync.RWMutex
+	manager *Manager
+)
+
+// Init installs the process-wide manager.
+func Init(cfg Config) error {
+	m, err := NewManager(cfg)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	manager = m
+	mu.Unlock()
+
+	log.Printf("🔐 Secrets backend: %s (cache ttl %s)", m.defaultBackend, cfg.withDefaults().CacheTTL)
+	return nil
+}
+
+func current() *Manager {
+	mu.RLock()
+	m := manager
+	mu.RUnlock()
+	if m != nil {
+		return m
+	}
+
+	// Not initialised (e.g. CLI commands) → defaults
+	m, _ = NewManager(Config{})
+	mu.Lock()
+	if manager == nil {
+		manager = m
+	}
+	m = manager
+	mu.Unlock()
+	return m
+}
+
+// Get reads a secret through the process-wide manager.
+func Get(ctx context.Context, name string) (string, error) {
+	return current().Get(ctx, name)
+}
+
+// Ping checks the process-wide manager's backends are reachable.
+func Ping(ctx context.Context) error {
+	return current().Ping(ctx)
+}
+
+// Invalidate drops a cached secret from the process-wide manager.
+func Invalidate(name string) {
+	current().Invalidate(name)
+}
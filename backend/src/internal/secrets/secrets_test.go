@@ -0,0 +1,152 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeVault serves KV v2 reads of platform/github and the health endpoint.
+func fakeVault(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/sys/health":
+			w.WriteHeader(http.StatusOK)
+		case "/v1/kv/data/platform/github":
+			if r.Header.Get("X-Vault-Token") != "root" {
+				http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+				return
+			}
+			fmt.Fprint(w, `{"data":{"data":{"token":"ghp_vault","value":"default-field"}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestNewManagerBackendSelection(t *testing.T) {
+	m, err := NewManager(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.defaultBackend != "aws" {
+		t.Errorf("default backend = %s", m.defaultBackend)
+	}
+	if _, ok := m.backends["vault"]; ok {
+		t.Error("vault built without an address")
+	}
+
+	if _, err := NewManager(Config{Backend: "vault"}); err == nil {
+		t.Error("vault without an address: want error")
+	}
+	if _, err := NewManager(Config{Backend: "keychain"}); err == nil {
+		t.Error("unknown backend: want error")
+	}
+}
+
+func TestManagerResolvesRefs(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "jenkins-token"), []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GIT_TOKEN", "from-env")
+	t.Setenv("JENKINS_USER", "jenkins")
+
+	cfg := Config{
+		Backend: "env",
+		Vault:   VaultConfig{Addr: fakeVault(t).URL, Token: "root", Mount: "kv"},
+		Refs: map[string]string{
+			"jenkins-api-token": "file:jenkins-token", // overrides the default ref
+			"github-token":      "vault:platform/github#token",
+		},
+	}
+	cfg.File.Dir = dir
+
+	m, err := NewManager(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{
+		"git-token":             "from-env",      // default backend
+		"jenkins-api-token":     "from-file",     // configured ref
+		"jenkins-user":          "jenkins",       // built-in ref
+		"github-token":          "ghp_vault",     // vault field
+		"vault:platform/github": "default-field", // explicit ref, field defaults to value
+		"file:jenkins-token":    "from-file",
+		"env:GIT_TOKEN":         "from-env",
+	} {
+		got, err := m.Get(context.Background(), name)
+		if err != nil || got != want {
+			t.Errorf("Get(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+
+	if _, err := m.Get(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing: got %v", err)
+	}
+	if _, err := m.Get(context.Background(), "file:../etc/passwd"); err == nil {
+		t.Error("path escaping the secrets directory: want error")
+	}
+	if err := m.Ping(context.Background()); err != nil {
+		t.Errorf("Ping: %v", err)
+	}
+}
+
+func TestEnvProviderNames(t *testing.T) {
+	t.Setenv("PLATFORM_GIT_TOKEN", "from-env")
+
+	p := EnvProvider{Prefix: "PLATFORM_"}
+	for _, name := range []string{"git-token", "git.token", "GIT_TOKEN"} {
+		if v, err := p.Get(context.Background(), name); err != nil || v != "from-env" {
+			t.Errorf("Get(%q) = %q, %v", name, v, err)
+		}
+	}
+}
+
+func TestManagerUnconfiguredRefBackend(t *testing.T) {
+	m, err := NewManager(Config{
+		Backend: "env",
+		Refs:    map[string]string{"github-token": "vault:platform/github#token"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Get(context.Background(), "github-token"); err == nil {
+		t.Error("ref to an unconfigured backend: want error")
+	}
+	if err := m.Ping(context.Background()); err == nil {
+		t.Error("Ping with a ref to an unconfigured backend: want error")
+	}
+}
+
+func TestManagerInvalidate(t *testing.T) {
+	m, err := NewManager(Config{Backend: "env"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	t.Setenv("GIT_TOKEN", "old")
+	m.Get(ctx, "git-token")
+
+	t.Setenv("GIT_TOKEN", "rotated")
+	if v, _ := m.Get(ctx, "git-token"); v != "old" {
+		t.Errorf("cached read = %q", v)
+	}
+
+	m.Invalidate("git-token")
+	if v, _ := m.Get(ctx, "git-token"); v != "rotated" {
+		t.Errorf("after invalidate = %q", v)
+	}
+}
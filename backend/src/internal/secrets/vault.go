@@ -0,0 +1,108 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type VaultConfig struct {
+	Addr      string `yaml:"addr"`
+	Token     string `yaml:"token"`
+	Mount     string `yaml:"mount"`     // KV mount, default "secret"
+	KVVersion int    `yaml:"kvVersion"` // 1 or 2 (default)
+}
+
+// VaultProvider reads HashiCorp Vault KV secrets. Names are "path#field";
+// the field defaults to "value".
+type VaultProvider struct {
+	cfg  VaultConfig
+	http *http.Client
+}
+
+func NewVaultProvider(cfg VaultConfig) *VaultProvider {
+	if cfg.Mount == "" {
+		cfg.Mount = "secret"
+	}
+	if cfg.KVVersion == 0 {
+		cfg.KVVersion = 2
+	}
+	return &VaultProvider{cfg: cfg, http: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (v *VaultProvider) Get(ctx context.Context, name string) (string, error) {
+	path, field := name, "value"
+	if i := strings.LastIndex(name, "#"); i >= 0 {
+		path, field = name[:i], name[i+1:]
+	}
+	path = strings.Trim(path, "/")
+
+	url := fmt.Sprintf("%s/v1/%s/%s", strings.TrimRight(v.cfg.Addr, "/"), v.cfg.Mount, path)
+	if v.cfg.KVVersion == 2 {
+		url = fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimRight(v.cfg.Addr, "/"), v.cfg.Mount, path)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.cfg.Token)
+
+	resp, err := v.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%w: vault %s", ErrNotFound, path)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("vault read %s failed: status=%d body=%s", path, resp.StatusCode, string(body))
+	}
+
+	var res struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return "", err
+	}
+
+	data := res.Data
+	if v.cfg.KVVersion == 2 {
+		inner, _ := data["data"].(map[string]interface{})
+		data = inner
+	}
+
+	value, ok := data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("%w: vault %s has no field %q", ErrNotFound, path, field)
+	}
+	return value, nil
+}
+
+// Ping checks Vault is up and unsealed (standbys count as healthy).
+func (v *VaultProvider) Ping(ctx context.Context) error {
+	url := strings.TrimRight(v.cfg.Addr, "/") + "/v1/sys/health?standbyok=true"
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := v.http.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("vault health: %s", resp.Status)
+	}
+	return nil
+}
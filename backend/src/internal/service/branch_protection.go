@@ -0,0 +1,196 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"src/src/internal/cicd"
+	"src/src/internal/db"
+	"src/src/internal/git"
+	"src/src/internal/model"
+	"src/src/internal/templates"
+)
+
+var ErrServiceNotFound = errors.New("service not found")
+
+// environmentBranches maps a service's environments to the branches its
+// pipelines deploy from (deduplicated, in environment order).
+func environmentBranches(environments []string) []string {
+	seen := map[string]bool{}
+	var branches []string
+
+	for _, env := range environments {
+		b := cicd.EnvironmentBranch(env)
+		if !seen[b] {
+			seen[b] = true
+			branches = append(branches, b)
+		}
+	}
+	return branches
+}
+
+// branchPolicy is the protection every environment branch must carry: a
+// reviewed PR with green CI and no force-pushes or deletions. The prod
+// branch also binds admins, so nobody can bypass it.
+func branchPolicy(branch string, checks []string) git.BranchProtection {
+	return git.BranchProtection{
+		RequiredReviews: 1,
+		StatusChecks:    checks,
+		EnforceAdmins:   branch == cicd.EnvironmentBranch("prod"),
+	}
+}
+
+func templateChecks(runtime, version, cicdType, deployType string) ([]string, error) {
+	return templates.StatusChecks(templates.TemplateRequest{
+		Language:   runtime,
+		Version:    version,
+		CICD:       cicdType,
+		DeployType: deployType,
+	})
+}
+
+// protectBranches applies branchPolicy to every environment branch. GitHub
+// refuses protection on some plans (e.g. private repos on free accounts), so
+// failures are logged and surface in the compliance report instead of
+// failing provisioning.
+func protectBranches(owner, repo string, req model.CreateServiceRequest) {
+	checks, err := templateChecks(req.Runtime, req.TemplateVersion, req.CICDType, req.DeployType)
+	if err != nil {
+		log.Println("⚠️ Could not resolve template status checks:", err)
+	}
+
+	for _, branch := range environmentBranches(req.Environments) {
+		if err := git.ProtectBranch(tokens, owner, repo, branch, branchPolicy(branch, checks)); err != nil {
+			log.Printf("⚠️ Branch protection not applied to %s/%s@%s: %v", owner, repo, branch, err)
+		}
+	}
+}
+
+// ============================================================
+// ProtectionReport – compare live branch protection with policy
+// ============================================================
+func ProtectionReport(serviceName string) (*model.ProtectionReport, error) {
+	var (
+		owner, repo, runtime, version, cicdType, deployType string
+		envJSON                                             sql.NullString
+	)
+
+	err := db.DB.QueryRow(`
+		SELECT COALESCE(repo_owner, ''), COALESCE(repo_name, ''), COALESCE(runtime, ''),
+		       COALESCE(template_version, ''), COALESCE(cicd_type, ''), COALESCE(deploy_type, ''),
+		       environments
+		FROM services
+		WHERE service_name = ?
+	`, serviceName).Scan(&owner, &repo, &runtime, &version, &cicdType, &deployType, &envJSON)
+	if err == sql.ErrNoRows {
+		return nil, ErrServiceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var environments []string
+	if envJSON.Valid {
+		if err := json.Unmarshal([]byte(envJSON.String), &environments); err != nil {
+			return nil, fmt.Errorf("invalid environments for %s: %w", serviceName, err)
+		}
+	}
+
+	if owner == "" {
+		owner, _, err = tokens.DefaultOwner()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+
+	// Imported services may use templates the platform does not know
+	checks, err := templateChecks(runtime, version, cicdType, deployType)
+	if err != nil {
+		checks = nil
+	}
+
+	report := &model.ProtectionReport{
+		ServiceName: serviceName,
+		Repo:        owner + "/" + repo,
+		Compliant:   true,
+	}
+
+	for _, env := range environments {
+		branch := cicd.EnvironmentBranch(env)
+
+		bc, err := branchCompliance(owner, repo, branch, branchPolicy(branch, checks))
+		if err != nil {
+			return nil, err
+		}
+		bc.Environment = env
+
+		if !bc.Compliant {
+			report.Compliant = false
+		}
+		report.Branches = append(report.Branches, bc)
+	}
+
+	return report, nil
+}
+
+func branchCompliance(owner, repo, branch string, want git.BranchProtection) (model.BranchCompliance, error) {
+	bc := model.BranchCompliance{Branch: branch}
+
+	exists, err := git.BranchExists(tokens, owner, repo, branch)
+	if err != nil {
+		return bc, err
+	}
+	bc.Exists = exists
+	if !exists {
+		bc.Issues = append(bc.Issues, "branch does not exist")
+		return bc, nil
+	}
+
+	have, err := git.GetBranchProtection(tokens, owner, repo, branch)
+	if err != nil {
+		return bc, err
+	}
+	if have == nil {
+		bc.Issues = append(bc.Issues, "branch is not protected")
+		return bc, nil
+	}
+	bc.Protected = true
+
+	if have.RequiredReviews < want.RequiredReviews {
+		bc.Issues = append(bc.Issues, fmt.Sprintf(
+			"requires %d approving reviews, policy requires %d",
+			have.RequiredReviews, want.RequiredReviews))
+	}
+
+	for _, check := range want.StatusChecks {
+		if !containsString(have.StatusChecks, check) {
+			bc.Issues = append(bc.Issues, fmt.Sprintf("status check %q is not required", check))
+		}
+	}
+
+	if want.EnforceAdmins && !have.EnforceAdmins {
+		bc.Issues = append(bc.Issues, "protection is not enforced for admins")
+	}
+	if !want.AllowForcePushes && have.AllowForcePushes {
+		bc.Issues = append(bc.Issues, "force pushes are allowed")
+	}
+	if !want.AllowDeletions && have.AllowDeletions {
+		bc.Issues = append(bc.Issues, "branch deletion is allowed")
+	}
+
+	bc.Compliant = len(bc.Issues) == 0
+	return bc, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"os"
+
+	"src/src/internal/config"
+	"src/src/internal/git"
+)
+
+var (
+	// Scratch space for clones and rendered templates
+	workDir = os.TempDir()
+
+	// Artifact callback used by generated pipelines
+	artifactCallbackURL = config.Default().Platform.ArtifactURL
+
+	// GitHub credentials repositories are created and onboarded with
+	tokens = git.DefaultTokenSource()
+)
+
+// Configure applies the workspace and platform settings and the GitHub
+// credentials to act with.
+func Configure(cfg *config.Config, ts git.TokenSource) {
+	workDir = cfg.Workspace.Dir
+	artifactCallbackURL = cfg.Platform.ArtifactURL
+	tokens = ts
+}
@@ -6,157 +6,160 @@ import (
 	"errors"
 	"log"
 	"os"
+	"path"
+	"path/filepath"
 	"time"
 
-	"src/src/internal/aws"
+	"src/src/internal/argocd"
 	"src/src/internal/cicd"
 	"src/src/internal/db"
 	"src/src/internal/git"
+	"src/src/internal/infra"
 	"src/src/internal/model"
 	"src/src/internal/templates"
 )
 
-var ErrServiceAlreadyExists = errors.New("service already exists")
+var (
+	ErrServiceAlreadyExists = errors.New("service already exists")
+	ErrRepoAlreadyExists    = errors.New("repository already exists")
+)
 
 // ============================================================
 // CreateService – PRODUCTION-GRADE IMPLEMENTATION
 // ============================================================
-func CreateService(req model.CreateServiceRequest) (string, error) {
+func CreateService(req model.CreateServiceRequest) (*model.CreateServiceResponse, error) {
 	log.Println("🚀 CreateService started:", req.ServiceName)
 
-	// ============================================================
-	// PHASE 1: DB RESERVATION (FAST, SAFE)
-	// ============================================================
-	ctxDB, cancelDB := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancelDB()
-
-	tx, err := db.DB.BeginTx(ctxDB, nil)
+	// Terraform root modules (ec2 services asking for infra)
+	tf, err := infra.Template(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer tx.Rollback()
 
-	var exists bool
-	err = tx.QueryRowContext(
-		ctxDB,
-		`SELECT EXISTS (SELECT 1 FROM services WHERE service_name = ?)`,
-		req.ServiceName,
-	).Scan(&exists)
-	if err != nil {
-		return "", err
+	// ============================================================
+	// PHASE 1: DB RESERVATION (FAST, SAFE)
+	// ============================================================
+	if err := reserveService(req.ServiceName); err != nil {
+		return nil, err
 	}
 
-	if exists {
-		log.Println("⚠️ Service already exists:", req.ServiceName)
-		return "", ErrServiceAlreadyExists
-	}
+	log.Println("✅ Service reserved in DB")
 
-	// Reserve service row
-	_, err = tx.ExecContext(
-		ctxDB,
-		`INSERT INTO services (service_name, status)
-		 VALUES (?, 'creating')`,
-		req.ServiceName,
-	)
+	resp, err := createService(req, tf)
 	if err != nil {
-		return "", err
-	}
-
-	if err := tx.Commit(); err != nil {
-		return "", err
+		releaseReservation(req.ServiceName)
+		return nil, err
 	}
 
-	log.Println("✅ Service reserved in DB")
+	log.Println("🎉 CreateService completed successfully:", resp.RepoURL)
+	return resp, nil
+}
 
+func createService(req model.CreateServiceRequest, tf *templates.TerraformRequest) (*model.CreateServiceResponse, error) {
 	// ============================================================
 	// PHASE 2: EXTERNAL PROVISIONING (NO DB TX)
 	// ============================================================
 
-	// 1️⃣ Fetch GitHub token
-	log.Println("🔐 Fetching GitHub token")
-	token, err := aws.GetGitToken("git-token")
-	if err != nil {
-		return "", err
-	}
-
-	// 2️⃣ GitHub owner
-	owner, err := git.GetAuthenticatedUser(token)
+	// 1️⃣ GitHub owner (token user for PATs, the installation org for apps)
+	owner, ownerIsOrg, err := tokens.DefaultOwner()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-
-	// 3️⃣ Repo existence check
-	repoExists, err := git.RepoExists(token, owner, req.RepoName)
-	if err != nil {
-		return "", err
-	}
-	if repoExists {
-		return "", errors.New("repository already exists")
-	}
-
-	// 4️⃣ Create repo
-	log.Println("📦 Creating GitHub repo:", req.RepoName)
-	repoURL, err := git.CreateRepo(token, req.RepoName)
-	if err != nil {
-		return "", err
+	if req.Repository.Org == "" && ownerIsOrg {
+		req.Repository.Org = owner
 	}
 
-	// Cleanup on failure
-	cleanupRepo := func() {
-		log.Println("🗑️ Cleaning up GitHub repo:", req.RepoName)
-		_ = git.DeleteRepo(token, req.RepoName)
+	repoOwner, repoName := firstNonEmpty(req.Repository.Org, owner), req.RepoName
+	if req.RepoMode == model.RepoModeMonorepo {
+		repoOwner, repoName = splitTargetRepo(req.TargetRepo, owner)
 	}
 
-	repoPath := "/tmp/" + req.RepoName
-	defer os.RemoveAll(repoPath)
 
-	// 5️⃣ Apply golden template
-	log.Println("📐 Applying golden template")
-	err = templates.CreateServiceFromTemplate(
-		templates.TemplateRequest{
-			Language:   req.Runtime,
-			Version:    req.TemplateVersion,
-			CICD:       req.CICDType,
-			DeployType: req.DeployType,
-		},
-		repoPath,
+	// 3️⃣ Repository + golden template
+	var (
+		repoURL, prURL string
+		cleanupRepo    func()
 	)
-	if err != nil {
-		cleanupRepo()
-		return "", err
-	}
+	scriptPath := "Jenkinsfile"
 
-	// After template copy
-	err = UpdateConfigJSON(repoPath, req.ServiceName, repoURL)
-	if err != nil {
-		cleanupRepo()
-		return "", err
+	if req.RepoMode == model.RepoModeMonorepo {
+		repoURL, prURL, cleanupRepo, err = provisionMonorepo(owner, req, tf)
+		scriptPath = path.Join(req.RepoPath, "Jenkinsfile")
+	} else {
+		repoOwner, repoURL, cleanupRepo, err = provisionStandalone(owner, req, tf)
 	}
-
-	// 6️⃣ Push code
-	log.Println("⬆️ Pushing code")
-	err = git.PushRepo(token, req.RepoName, repoPath,"dev")
 	if err != nil {
-		cleanupRepo()
-		return "", err
+		return nil, err
 	}
 
-	// 7️⃣ Jenkins (optional)
+	// 4️⃣ Jenkins (optional)
 	var webhookToken string
 	if req.CICDType == "jenkins" {
 		log.Println("🏗️ Registering Jenkins job")
 
 		webhookToken, err = cicd.RegisterJenkins(
+			tokens,
 			repoURL,
 			req.ServiceName,
+			scriptPath,
 			req.EnableWebhook,
 		)
 		if err != nil {
 			cleanupRepo()
-			return "", err
+			return nil, err
+		}
+
+		// Plan/apply job; only the platform starts it
+		if tf != nil {
+			log.Println("🏗️ Registering Jenkins infra job")
+
+			_, err = cicd.RegisterJenkins(
+			tokens,
+				repoURL,
+				cicd.InfraJobName(req.ServiceName),
+				path.Join(req.RepoPath, templates.InfraJenkinsfile),
+				false,
+			)
+			if err != nil {
+				cleanupRepo()
+				return nil, err
+			}
 		}
 	}
 
+	// 5️⃣ Argo CD applications (microservices, when Argo CD is configured)
+	if argocd.Manages(req.DeployType) {
+		log.Println("🐙 Creating Argo CD applications")
+
+		cleanupApps, err := argocd.Provision(context.Background(), argocd.Service{
+			Name:     req.ServiceName,
+			RepoURL:  repoURL,
+			RepoPath: req.RepoPath,
+		}, req.Environments)
+		if err != nil {
+			cleanupRepo()
+			return nil, err
+		}
+
+		removeRepo := cleanupRepo
+		cleanupRepo = func() {
+			cleanupApps()
+			removeRepo()
+		}
+	}
+
+	// 6️⃣ Secrets → Actions environments / Jenkins folder credentials
+	secretsWritten, err := writeSecrets(secretTarget{
+		CICDType: req.CICDType,
+		Owner:    repoOwner,
+		Repo:     repoName,
+		JobName:  req.ServiceName,
+	}, req.Environments, req.Secrets)
+	if err != nil {
+		cleanupRepo()
+		return nil, err
+	}
+
 	// ============================================================
 	// PHASE 3: FINAL DB UPDATE + DEPLOYMENTS
 	// ============================================================
@@ -165,7 +168,7 @@ func CreateService(req model.CreateServiceRequest) (string, error) {
 
 	tx2, err := db.DB.BeginTx(ctxDB2, nil)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer tx2.Rollback()
 
@@ -182,10 +185,15 @@ func CreateService(req model.CreateServiceRequest) (string, error) {
 		     environments=?,
 		     enablewebhook=?,
 		     webhook_token=?,
+		     repo_mode=?,
+		     repo_path=?,
+		     pull_request_url=?,
+		     repo_owner=?,
+		     visibility=?,
 		     status='ready'
 		 WHERE service_name=?`,
 		repoURL,
-		req.RepoName,
+		repoName,
 		req.OwnerTeam,
 		req.Runtime,
 		req.CICDType,
@@ -194,10 +202,15 @@ func CreateService(req model.CreateServiceRequest) (string, error) {
 		mustJSON(req.Environments),
 		req.EnableWebhook,
 		webhookToken,
+		repoMode(req),
+		nullIfEmpty(req.RepoPath),
+		nullIfEmpty(prURL),
+		repoOwner,
+		nullIfEmpty(req.Repository.Visibility),
 		req.ServiceName,
 	)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// 🔥 Correct way to fetch service_id
@@ -208,7 +221,7 @@ func CreateService(req model.CreateServiceRequest) (string, error) {
 		req.ServiceName,
 	).Scan(&serviceID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Insert deployments
@@ -218,23 +231,195 @@ func CreateService(req model.CreateServiceRequest) (string, error) {
 		 VALUES (?, ?, ?)`,
 	)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer stmt.Close()
 
 	for _, env := range req.Environments {
 		_, err := stmt.ExecContext(ctxDB2, serviceID, env, "not_deployed")
 		if err != nil {
-			return "", err
+			return nil, err
+		}
+	}
+
+	if err := recordSecrets(ctxDB2, tx2, req.ServiceName, secretsWritten); err != nil {
+		return nil, err
+	}
+
+	if tf != nil {
+		if err := infra.SaveStacks(ctxDB2, tx2, tf); err != nil {
+			return nil, err
 		}
 	}
 
 	if err := tx2.Commit(); err != nil {
-		return "", err
+		return nil, err
+	}
+
+	return &model.CreateServiceResponse{
+		RepoURL:        repoURL,
+		PullRequestURL: prURL,
+	}, nil
+}
+
+// reserveService inserts the 'creating' row that guards against two requests
+// provisioning the same service name concurrently.
+func reserveService(serviceName string) error {
+	ctxDB, cancelDB := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelDB()
+
+	tx, err := db.DB.BeginTx(ctxDB, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRowContext(
+		ctxDB,
+		`SELECT EXISTS (SELECT 1 FROM services WHERE service_name = ?)`,
+		serviceName,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		log.Println("⚠️ Service already exists:", serviceName)
+		return ErrServiceAlreadyExists
+	}
+
+	// Reserve service row
+	_, err = tx.ExecContext(
+		ctxDB,
+		`INSERT INTO services (service_name, status)
+		 VALUES (?, 'creating')`,
+		serviceName,
+	)
+	if db.IsDuplicateKey(err) {
+		// Lost the race with a concurrent request for the same name
+		return ErrServiceAlreadyExists
+	}
+	if err != nil {
+		return err
 	}
 
-	log.Println("🎉 CreateService completed successfully:", repoURL)
-	return repoURL, nil
+	return tx.Commit()
+}
+
+// ------------------------------------------------------------
+// Standalone: new repo per service, pushed to dev
+// ------------------------------------------------------------
+func provisionStandalone(authUser string, req model.CreateServiceRequest, tf *templates.TerraformRequest) (string, string, func(), error) {
+	spec := req.Repository
+
+	owner := spec.Org
+	if owner == "" {
+		owner = authUser
+	}
+
+	// 1️⃣ Repo existence check
+	repoExists, err := git.RepoExists(tokens, owner, req.RepoName)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if repoExists {
+		return "", "", nil, ErrRepoAlreadyExists
+	}
+
+	// 2️⃣ Create repo
+	log.Println("📦 Creating GitHub repo:", owner+"/"+req.RepoName)
+	owner, repoURL, err := git.CreateRepo(tokens, git.RepoOptions{
+		Name:        req.RepoName,
+		Org:         spec.Org,
+		Visibility:  spec.Visibility,
+		Description: spec.Description,
+		Topics:      spec.Topics,
+	})
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	// Cleanup on failure
+	cleanupRepo := func() {
+		log.Println("🗑️ Cleaning up GitHub repo:", req.RepoName)
+		_ = git.DeleteRepo(tokens, owner, req.RepoName)
+	}
+
+	workspace, err := os.MkdirTemp(workDir, "service-"+req.RepoName+"-")
+	if err != nil {
+		cleanupRepo()
+		return "", "", nil, err
+	}
+	defer os.RemoveAll(workspace)
+
+	repoPath := filepath.Join(workspace, req.RepoName)
+
+	// 3️⃣ Apply golden template
+	log.Println("📐 Applying golden template")
+	err = templates.CreateServiceFromTemplate(
+		templates.TemplateRequest{
+			Language:   req.Runtime,
+			Version:    req.TemplateVersion,
+			CICD:       req.CICDType,
+			DeployType: req.DeployType,
+			Terraform:  tf,
+		},
+		repoPath,
+	)
+	if err != nil {
+		cleanupRepo()
+		return "", "", nil, err
+	}
+
+	// After template copy
+	err = UpdateConfigJSON(repoPath, req.ServiceName, repoURL)
+	if err != nil {
+		cleanupRepo()
+		return "", "", nil, err
+	}
+
+	if handle := teamHandle(spec.Org, req.OwnerTeam); handle != "" {
+		if err := templates.AddCodeowners(repoPath, "*", handle); err != nil {
+			cleanupRepo()
+			return "", "", nil, err
+		}
+	}
+
+	// 4️⃣ Push code (dev + every environment branch + default branch)
+	log.Println("⬆️ Pushing code")
+	branches := append(environmentBranches(req.Environments), spec.DefaultBranch)
+	err = git.PushRepo(tokens, owner, req.RepoName, repoPath, "dev", branches...)
+	if err != nil {
+		cleanupRepo()
+		return "", "", nil, err
+	}
+
+	// 5️⃣ Repository settings
+	if spec.DefaultBranch != "" && spec.DefaultBranch != "dev" {
+		if err := git.SetDefaultBranch(tokens, owner, req.RepoName, spec.DefaultBranch); err != nil {
+			cleanupRepo()
+			return "", "", nil, err
+		}
+	}
+
+	if spec.Org != "" && req.OwnerTeam != "" {
+		permission := spec.TeamPermission
+		if permission == "" {
+			permission = "push"
+		}
+
+		err := git.GrantTeamAccess(tokens, spec.Org, git.TeamSlug(req.OwnerTeam), owner, req.RepoName, permission)
+		if err != nil {
+			cleanupRepo()
+			return "", "", nil, err
+		}
+	}
+
+	// 6️⃣ Branch protection (best effort, see protectBranches)
+	protectBranches(owner, req.RepoName, req)
+
+	return owner, repoURL, cleanupRepo, nil
 }
 
 // ------------------------------------------------------------
@@ -243,4 +428,27 @@ func CreateService(req model.CreateServiceRequest) (string, error) {
 func mustJSON(v interface{}) []byte {
 	b, _ := json.Marshal(v)
 	return b
+}
+
+func repoMode(req model.CreateServiceRequest) string {
+	if req.RepoMode == "" {
+		return model.RepoModeStandalone
+	}
+	return req.RepoMode
+}
+
+// teamHandle returns the CODEOWNERS handle for an org team, or "" when the
+// repository is not org-owned (personal accounts have no teams).
+func teamHandle(org, team string) string {
+	if org == "" || team == "" {
+		return ""
+	}
+	return "@" + org + "/" + git.TeamSlug(team)
+}
+
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
 }
\ No newline at end of file
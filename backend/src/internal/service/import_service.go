@@ -0,0 +1,506 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"src/src/internal/cicd"
+	"src/src/internal/db"
+	"src/src/internal/git"
+	"src/src/internal/model"
+)
+
+var ErrRepoNotFound = errors.New("repository not found")
+
+
+// GitHub environment names → platform environments
+var githubEnvironments = map[string]string{
+	"dev":         "dev",
+	"development": "dev",
+	"test":        "test",
+	"qa":          "test",
+	"staging":     "test",
+	"prod":        "prod",
+	"production":  "prod",
+}
+
+type repoDetection struct {
+	Runtime   string
+	CICD      string
+	Workflows []string // .github/workflows files
+}
+
+// ============================================================
+// ImportService – adopt an existing repository into the catalog
+// ============================================================
+func ImportService(req model.ImportServiceRequest) (*model.ImportServiceResponse, error) {
+	log.Println("📥 ImportService started:", req.ServiceName, "repo:", req.Repo)
+
+	// ============================================================
+	// PHASE 1: DB RESERVATION
+	// ============================================================
+	if err := reserveService(req.ServiceName); err != nil {
+		return nil, err
+	}
+
+	resp, err := importService(req)
+	if err != nil {
+		releaseReservation(req.ServiceName)
+		return nil, err
+	}
+
+	log.Println("🎉 ImportService completed successfully:", resp.RepoURL)
+	return resp, nil
+}
+
+func importService(req model.ImportServiceRequest) (*model.ImportServiceResponse, error) {
+	// ============================================================
+	// PHASE 2: DISCOVERY + OPTIONAL ONBOARDING (NO DB TX)
+	// ============================================================
+	defaultOwner, _, err := tokens.DefaultOwner()
+	if err != nil {
+		return nil, err
+	}
+
+	owner, repoName := splitTargetRepo(req.Repo, defaultOwner)
+	repoURL := tokens.Connection().RepoURL(owner, repoName)
+
+
+	// 1️⃣ Repo must exist
+	exists, err := git.RepoExists(tokens, owner, repoName)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrRepoNotFound
+	}
+
+	branch, err := git.GetDefaultBranch(tokens, owner, repoName)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2️⃣ Detect runtime + CI
+	files, err := git.ListRepoFiles(tokens, owner, repoName, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	detected := detectRepo(files)
+	log.Printf("🔍 Detected runtime=%q cicd=%q workflows=%v",
+		detected.Runtime, detected.CICD, detected.Workflows)
+
+	runtime := firstNonEmpty(req.Runtime, detected.Runtime)
+	cicdType := firstNonEmpty(req.CICDType, detected.CICD)
+	deployType := firstNonEmpty(req.DeployType, "microservice")
+
+	if runtime == "" {
+		return nil, errors.New("could not detect runtime; set runtime explicitly")
+	}
+	if cicdType == "" {
+		return nil, errors.New("could not detect CI type (no Jenkinsfile or .github/workflows); set cicdType explicitly")
+	}
+
+	// 3️⃣ Platform config + artifact callback (PR)
+	var prURL string
+	if req.AddPlatformConfig {
+		prURL, err = openPlatformConfigPR(owner, repoName, branch, req.ServiceName, repoURL, cicdType)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 4️⃣ Jenkins (optional)
+	var webhookToken string
+	if cicdType == "jenkins" && req.RegisterJenkins {
+		log.Println("🏗️ Registering Jenkins job")
+		webhookToken, err = cicd.RegisterJenkins(tokens, repoURL, req.ServiceName, "Jenkinsfile", req.EnableWebhook)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 5️⃣ Current state from GitHub deployments (best effort)
+	seeded, err := latestDeployments(owner, repoName)
+	if err != nil {
+		log.Println("⚠️ Could not read GitHub deployments, skipping state seed:", err)
+		seeded = map[string]git.Deployment{}
+	}
+
+	environments := req.Environments
+	if len(environments) == 0 {
+		environments = []string{"dev", "test", "prod"}
+	}
+
+	// ============================================================
+	// PHASE 3: CATALOG REGISTRATION
+	// ============================================================
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE services
+		 SET repo_url=?,
+		     repo_name=?,
+		     owner_team=?,
+		     runtime=?,
+		     cicd_type=?,
+		     deploy_type=?,
+		     environments=?,
+		     enablewebhook=?,
+		     webhook_token=?,
+		     pull_request_url=?,
+		     repo_owner=?,
+		     origin='imported',
+		     status='ready'
+		 WHERE service_name=?`,
+		repoURL,
+		repoName,
+		req.OwnerTeam,
+		runtime,
+		cicdType,
+		deployType,
+		mustJSON(environments),
+		req.EnableWebhook,
+		webhookToken,
+		nullIfEmpty(prURL),
+		owner,
+		req.ServiceName,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := map[string]string{}
+
+	for _, env := range environments {
+		status := string(model.NotDeployed)
+		var deployedAt interface{}
+
+		if d, ok := seeded[env]; ok {
+			// Pipelines report 7-character versions; match them
+			version := git.ShortSHA(d.SHA)
+			status = string(model.Success)
+			deployedAt = d.CreatedAt
+			versions[env] = version
+
+			_, err = tx.ExecContext(
+				ctx,
+				`REPLACE INTO environment_state
+				 (service_name, environment, version, status, deployed_at)
+				 VALUES (?, ?, ?, ?, ?)`,
+				req.ServiceName, env, version, status, d.CreatedAt,
+			)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		_, err = tx.ExecContext(
+			ctx,
+			`INSERT INTO deployments (service_id, environment, status, last_deployed_at)
+			 SELECT id, ?, ?, ? FROM services WHERE service_name = ?`,
+			env, status, deployedAt, req.ServiceName,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &model.ImportServiceResponse{
+		ServiceName:    req.ServiceName,
+		RepoURL:        repoURL,
+		Runtime:        runtime,
+		CICDType:       cicdType,
+		PullRequestURL: prURL,
+		Environments:   versions,
+	}, nil
+}
+
+// ------------------------------------------------------------
+// Detection
+// ------------------------------------------------------------
+func detectRepo(files []string) repoDetection {
+	var d repoDetection
+	hasJenkinsfile := false
+
+	// Root markers decide the runtime; nested ones (e.g. docs/package.json
+	// in a Go repo) only count when the root has none, shallowest first
+	runtimeDepth := -1
+
+	for _, f := range files {
+		if runtime := runtimeMarker(path.Base(f)); runtime != "" {
+			depth := strings.Count(f, "/")
+			if runtimeDepth < 0 || depth < runtimeDepth {
+				d.Runtime, runtimeDepth = runtime, depth
+			}
+		}
+
+		if f == "Jenkinsfile" {
+			hasJenkinsfile = true
+		}
+
+		if path.Dir(f) == ".github/workflows" &&
+			(strings.HasSuffix(f, ".yml") || strings.HasSuffix(f, ".yaml")) {
+			d.Workflows = append(d.Workflows, f)
+		}
+	}
+
+	// A root Jenkinsfile wins: repos often keep lint-only workflows next to it
+	switch {
+	case hasJenkinsfile:
+		d.CICD = "jenkins"
+	case len(d.Workflows) > 0:
+		d.CICD = "github"
+	}
+
+	return d
+}
+
+// runtimeMarker maps a build file name to the runtime it implies.
+func runtimeMarker(base string) string {
+	switch {
+	case base == "go.mod":
+		return "go"
+	case base == "package.json":
+		return "node"
+	case base == "pom.xml", base == "build.gradle", base == "build.gradle.kts":
+		return "java"
+	case base == "requirements.txt", base == "pyproject.toml", base == "setup.py":
+		return "python"
+	case base == "Cargo.toml":
+		return "rust"
+	case strings.HasSuffix(base, ".csproj"), strings.HasSuffix(base, ".sln"):
+		return "dotnet"
+	}
+	return ""
+}
+
+func latestDeployments(owner, repo string) (map[string]git.Deployment, error) {
+	deployments, err := git.ListDeployments(tokens, owner, repo, 100)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := map[string]git.Deployment{}
+
+	// newest first → first successful deployment per env wins
+	for _, d := range deployments {
+		env, ok := githubEnvironments[strings.ToLower(d.Environment)]
+		if !ok {
+			continue
+		}
+		if _, seen := latest[env]; seen {
+			continue
+		}
+
+		state, err := git.GetDeploymentState(tokens, owner, repo, d.ID)
+		if err != nil {
+			return nil, err
+		}
+		if state == "success" {
+			latest[env] = d
+		}
+	}
+
+	return latest, nil
+}
+
+// ------------------------------------------------------------
+// Onboarding PR: config.json + artifact callback workflow
+// ------------------------------------------------------------
+func openPlatformConfigPR(owner, repoName, baseBranch, serviceName, repoURL, cicdType string) (string, error) {
+	localPath, err := os.MkdirTemp(workDir, "import-"+repoName+"-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(localPath)
+
+	repo, err := git.CloneRepo(tokens, owner, repoName, baseBranch, localPath)
+	if err != nil {
+		return "", err
+	}
+
+	// 1️⃣ config.json (merge into an existing one)
+	configPath := filepath.Join(localPath, "config.json")
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		if err := os.WriteFile(configPath, []byte("{}"), 0644); err != nil {
+			return "", err
+		}
+	}
+	if err := UpdateConfigJSON(localPath, serviceName, repoURL); err != nil {
+		return "", err
+	}
+
+	// 2️⃣ Artifact callback
+	notes := "Jenkins pipelines should POST to `" + artifactCallbackURL + "` after a successful deploy (see the platform templates)."
+	if cicdType == "github" {
+		names, err := workflowNames(filepath.Join(localPath, ".github", "workflows"))
+		if err != nil {
+			return "", err
+		}
+
+		workflow := artifactCallbackWorkflow(names)
+		dest := filepath.Join(localPath, ".github", "workflows", "platform-artifact-callback.yaml")
+		if err := os.WriteFile(dest, []byte(workflow), 0644); err != nil {
+			return "", err
+		}
+		notes = "Adds `.github/workflows/platform-artifact-callback.yaml`, which reports successful runs of " +
+			strings.Join(names, ", ") + " to the platform."
+	}
+
+	// 3️⃣ Branch + PR
+	branch := "platform/onboard-" + serviceName
+	if err := git.PushNewBranch(tokens, owner, repo, branch, "Onboard "+serviceName+" to the platform"); err != nil {
+		return "", err
+	}
+
+	prURL, err := git.CreatePullRequest(
+		tokens,
+		owner,
+		repoName,
+		branch,
+		baseBranch,
+		fmt.Sprintf("Onboard %s to the platform", serviceName),
+		"Generated by the platform import.\n\n- Adds `config.json` with the platform service name\n- "+notes+"\n",
+	)
+	if err != nil {
+		_ = git.DeleteBranch(tokens, owner, repoName, branch)
+		return "", err
+	}
+
+	return prURL, nil
+}
+
+func workflowNames(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() || !(strings.HasSuffix(e.Name(), ".yml") || strings.HasSuffix(e.Name(), ".yaml")) {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		var wf struct {
+			Name string `yaml:"name"`
+		}
+		if err := yaml.Unmarshal(data, &wf); err != nil {
+			log.Println("⚠️ Skipping unparsable workflow:", e.Name(), err)
+			continue
+		}
+
+		// GitHub falls back to the file path when a workflow has no name
+		if wf.Name == "" {
+			wf.Name = ".github/workflows/" + e.Name()
+		}
+		names = append(names, wf.Name)
+	}
+
+	if len(names) == 0 {
+		return nil, errors.New("no workflows found to attach the artifact callback to")
+	}
+
+	return names, nil
+}
+
+func artifactCallbackWorkflow(workflows []string) string {
+	list, _ := json.Marshal(workflows)
+
+	return fmt.Sprintf(`name: Platform artifact callback
+
+on:
+  workflow_run:
+    workflows: %s
+    types: [completed]
+
+jobs:
+  notify:
+    if: ${{ github.event.workflow_run.conclusion == 'success' }}
+    runs-on: ubuntu-latest
+
+    steps:
+      - name: Checkout repository
+        uses: actions/checkout@v4
+        with:
+          ref: ${{ github.event.workflow_run.head_sha }}
+
+      - name: Notify platform
+        run: |
+          BRANCH="${{ github.event.workflow_run.head_branch }}"
+          case "$BRANCH" in
+            dev) ENVIRONMENT="dev" ;;
+            test) ENVIRONMENT="test" ;;
+            main|master) ENVIRONMENT="prod" ;;
+            *) echo "Branch $BRANCH is not mapped to an environment"; exit 0 ;;
+          esac
+
+          SERVICE_NAME=$(jq -r '.serviceName' config.json)
+          COMMIT_SHA=$(echo "${{ github.event.workflow_run.head_sha }}" | cut -c1-7)
+
+          curl -X POST %s \
+            -H "Content-Type: application/json" \
+            -d "{
+              \"serviceName\": \"$SERVICE_NAME\",
+              \"environment\": \"$ENVIRONMENT\",
+              \"version\": \"$COMMIT_SHA\",
+              \"artifactType\": \"docker\",
+              \"commitSha\": \"$COMMIT_SHA\",
+              \"pipeline\": \"github\",
+              \"action\": \"deploy\",
+              \"status\": \"success\"
+            }"
+`, list, artifactCallbackURL)
+}
+
+// ------------------------------------------------------------
+// Helpers
+// ------------------------------------------------------------
+
+// releaseReservation drops the 'creating' row so the name can be retried.
+func releaseReservation(serviceName string) {
+	_, err := db.DB.Exec(
+		`DELETE FROM services WHERE service_name = ? AND status = 'creating'`,
+		serviceName,
+	)
+	if err != nil {
+		log.Println("⚠️ Failed to release service reservation:", err)
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
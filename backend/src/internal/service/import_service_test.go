@@ -0,0 +1,43 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectRepo(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  repoDetection
+	}{
+		{
+			name:  "root marker beats an earlier nested one",
+			files: []string{"docs/package.json", "docs/index.md", "go.mod", "main.go"},
+			want:  repoDetection{Runtime: "go"},
+		},
+		{
+			name:  "nested marker when the root has none",
+			files: []string{"README.md", "services/api/deep/go.mod", "app/pom.xml"},
+			want:  repoDetection{Runtime: "java"},
+		},
+		{
+			name:  "root jenkinsfile wins over workflows",
+			files: []string{".github/workflows/lint.yml", "Jenkinsfile", "package.json"},
+			want:  repoDetection{Runtime: "node", CICD: "jenkins", Workflows: []string{".github/workflows/lint.yml"}},
+		},
+		{
+			name:  "workflows",
+			files: []string{".github/workflows/ci.yaml", ".github/workflows/README.md", "ci/Jenkinsfile", "api.csproj"},
+			want:  repoDetection{Runtime: "dotnet", CICD: "github", Workflows: []string{".github/workflows/ci.yaml"}},
+		},
+		{name: "nothing recognisable", files: []string{"README.md"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectRepo(tt.files); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"src/src/internal/git"
+	"src/src/internal/model"
+	"src/src/internal/templates"
+)
+
+// ------------------------------------------------------------
+// Monorepo: render into a subdirectory of an existing repo and
+// open a pull request instead of pushing directly
+// ------------------------------------------------------------
+func provisionMonorepo(authUser string, req model.CreateServiceRequest, tf *templates.TerraformRequest) (string, string, func(), error) {
+	owner, repoName := splitTargetRepo(req.TargetRepo, authUser)
+	repoURL := tokens.Connection().RepoURL(owner, repoName)
+
+	log.Printf("🧩 Adding service %s to monorepo %s/%s at %s",
+		req.ServiceName, owner, repoName, req.RepoPath)
+
+	// 1️⃣ Target repo must exist
+	exists, err := git.RepoExists(tokens, owner, repoName)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if !exists {
+		return "", "", nil, fmt.Errorf("target repository %s/%s not found", owner, repoName)
+	}
+
+	baseBranch, err := git.GetDefaultBranch(tokens, owner, repoName)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	// 2️⃣ Clone
+	localPath, err := os.MkdirTemp(workDir, "monorepo-"+repoName+"-")
+	if err != nil {
+		return "", "", nil, err
+	}
+	defer os.RemoveAll(localPath)
+
+	repo, err := git.CloneRepo(tokens, owner, repoName, baseBranch, localPath)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	// 3️⃣ Apply golden template into the service subdirectory
+	log.Println("📐 Applying golden template")
+	err = templates.CreateServiceInMonorepo(
+		templates.TemplateRequest{
+			Language:   req.Runtime,
+			Version:    req.TemplateVersion,
+			CICD:       req.CICDType,
+			DeployType: req.DeployType,
+			Terraform:  tf,
+		},
+		localPath,
+		req.RepoPath,
+	)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	if err := UpdateConfigJSON(filepath.Join(localPath, req.RepoPath), req.ServiceName, repoURL); err != nil {
+		return "", "", nil, err
+	}
+
+	// Org-owned monorepo → the owner team owns the service directory
+	// (a repo outside the token user's account, or under the default org)
+	org := ""
+	if owner != authUser || req.Repository.Org != "" {
+		org = owner
+	}
+	if handle := teamHandle(org, req.OwnerTeam); handle != "" {
+		if err := templates.AddCodeowners(localPath, "/"+req.RepoPath+"/", handle); err != nil {
+			return "", "", nil, err
+		}
+	}
+
+	// 4️⃣ Push to a feature branch
+	branch := "platform/add-" + req.ServiceName
+
+	log.Println("⬆️ Pushing branch", branch)
+	err = git.PushNewBranch(
+		tokens,
+		owner,
+		repo,
+		branch,
+		fmt.Sprintf("Add %s service at %s", req.ServiceName, req.RepoPath),
+	)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	cleanupBranch := func() {
+		log.Println("🗑️ Cleaning up branch:", branch)
+		_ = git.DeleteBranch(tokens, owner, repoName, branch)
+	}
+
+	// 5️⃣ Pull request
+	prURL, err := git.CreatePullRequest(
+		tokens,
+		owner,
+		repoName,
+		branch,
+		baseBranch,
+		fmt.Sprintf("Add %s service", req.ServiceName),
+		fmt.Sprintf(
+			"Generated by the platform.\n\n- Service: `%s`\n- Path: `%s`\n- Runtime: `%s` (%s)\n- CI/CD: `%s`\n- Owner team: `%s`\n",
+			req.ServiceName, req.RepoPath, req.Runtime, req.TemplateVersion, req.CICDType, req.OwnerTeam,
+		),
+	)
+	if err != nil {
+		cleanupBranch()
+		return "", "", nil, err
+	}
+
+	// 6️⃣ Environment branches the pipelines deploy from, cut from the base
+	// branch so nothing unreviewed reaches them; the service arrives once the
+	// pull request is merged and promoted. Existing ones are kept, and only
+	// the ones created here are removed again on failure.
+	var created []string
+	cleanup := func() {
+		for _, env := range created {
+			log.Println("🗑️ Cleaning up branch:", env)
+			_ = git.DeleteBranch(tokens, owner, repoName, env)
+		}
+		cleanupBranch()
+	}
+
+	for _, env := range environmentBranches(req.Environments) {
+		exists, err := git.BranchExists(tokens, owner, repoName, env)
+		if err != nil {
+			cleanup()
+			return "", "", nil, err
+		}
+		if exists {
+			continue
+		}
+		if err := git.CreateBranch(tokens, owner, repoName, env, baseBranch); err != nil {
+			cleanup()
+			return "", "", nil, err
+		}
+		created = append(created, env)
+	}
+
+	return repoURL, prURL, cleanup, nil
+}
+
+// splitTargetRepo accepts "owner/name" or "name" (owned by the token user).
+func splitTargetRepo(target, defaultOwner string) (string, string) {
+	if owner, name, ok := strings.Cut(target, "/"); ok {
+		return owner, name
+	}
+	return defaultOwner, target
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"src/src/internal/cicd"
+	"src/src/internal/db"
+	"src/src/internal/git"
+	"src/src/internal/model"
+	"src/src/internal/secrets"
+)
+
+var ErrInvalidSecrets = errors.New("invalid secrets")
+
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// secretTarget is where a service's CI reads its secrets from.
+type secretTarget struct {
+	CICDType string
+	Owner    string
+	Repo     string
+	JobName  string
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// ValidateSecrets checks names, value sources and environments.
+func ValidateSecrets(items []model.ServiceSecret, environments []string) error {
+	seen := map[string]bool{}
+
+	for _, s := range items {
+		if !secretNamePattern.MatchString(s.Name) {
+			return fmt.Errorf("secret name %q must be letters, digits and underscores", s.Name)
+		}
+		if strings.HasPrefix(strings.ToUpper(s.Name), "GITHUB_") {
+			return fmt.Errorf("secret name %q must not start with GITHUB_", s.Name)
+		}
+		if (s.Value == "") == (s.ValueFrom == "") {
+			return fmt.Errorf("secret %s needs exactly one of value or valueFrom", s.Name)
+		}
+		if s.Environment != "" && !containsString(environments, s.Environment) {
+			return fmt.Errorf("secret %s targets unknown environment %q", s.Name, s.Environment)
+		}
+
+		key := s.Environment + "/" + s.Name
+		if seen[key] {
+			return fmt.Errorf("secret %s is listed twice", s.Name)
+		}
+		seen[key] = true
+	}
+
+	return nil
+}
+
+// writeSecrets stores each secret in every environment it applies to.
+func writeSecrets(target secretTarget, environments []string, items []model.ServiceSecret) ([]model.SecretMetadata, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	var (
+		jenkins *cicd.JenkinsClient
+		err     error
+	)
+
+	switch target.CICDType {
+	case "github":
+		// written with the platform's GitHub credentials
+	case "jenkins":
+		if jenkins, err = cicd.NewJenkinsClient(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported cicd type %q for secrets", target.CICDType)
+	}
+
+	ready := map[string]bool{}
+	var written []model.SecretMetadata
+
+	for _, s := range items {
+		value, err := secretValue(s)
+		if err != nil {
+			return written, err
+		}
+
+		envs := environments
+		if s.Environment != "" {
+			envs = []string{s.Environment}
+		}
+
+		for _, env := range envs {
+			switch target.CICDType {
+			case "github":
+				if !ready[env] {
+					if err := git.EnsureEnvironment(tokens, target.Owner, target.Repo, env); err != nil {
+						return written, err
+					}
+					ready[env] = true
+				}
+				err = git.SetEnvironmentSecret(tokens, target.Owner, target.Repo, env, s.Name, value)
+
+			case "jenkins":
+				err = jenkins.UpsertFolderSecret(
+					target.JobName,
+					cicd.JenkinsCredentialID(env, s.Name),
+					fmt.Sprintf("%s (%s) – managed by platform", s.Name, env),
+					value,
+				)
+			}
+			if err != nil {
+				return written, fmt.Errorf("write secret %s (%s): %w", s.Name, env, err)
+			}
+
+			written = append(written, model.SecretMetadata{
+				Name:        s.Name,
+				Environment: env,
+				Store:       target.CICDType,
+				RotatedAt:   time.Now().UTC(),
+			})
+		}
+	}
+
+	return written, nil
+}
+
+func secretValue(s model.ServiceSecret) (string, error) {
+	if s.ValueFrom == "" {
+		return s.Value, nil
+	}
+	return secrets.Get(context.Background(), s.ValueFrom)
+}
+
+// recordSecrets upserts secret names and rotation times (never values).
+func recordSecrets(ctx context.Context, tx execer, serviceName string, written []model.SecretMetadata) error {
+	for _, m := range written {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO service_secrets (service_name, environment, name, store, rotated_at)
+			 VALUES (?, ?, ?, ?, ?)
+			 ON DUPLICATE KEY UPDATE store = VALUES(store), rotated_at = VALUES(rotated_at)`,
+			serviceName, m.Environment, m.Name, m.Store, m.RotatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ============================================================
+// PutServiceSecrets – create or rotate secrets for one environment
+// ============================================================
+func PutServiceSecrets(serviceName, env string, items []model.ServiceSecret) ([]model.SecretMetadata, error) {
+	log.Printf("🔑 Updating %d secrets for %s (%s)", len(items), serviceName, env)
+
+	var (
+		target  secretTarget
+		envJSON sql.NullString
+	)
+	err := db.DB.QueryRow(`
+		SELECT COALESCE(cicd_type, ''), COALESCE(repo_owner, ''), COALESCE(repo_name, ''), environments
+		FROM services
+		WHERE service_name = ? AND status = 'ready'
+	`, serviceName).Scan(&target.CICDType, &target.Owner, &target.Repo, &envJSON)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrServiceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	target.JobName = serviceName
+
+	var environments []string
+	if envJSON.Valid {
+		_ = json.Unmarshal([]byte(envJSON.String), &environments)
+	}
+	if !containsString(environments, env) {
+		return nil, fmt.Errorf("%w: %s has no environment %q", ErrInvalidSecrets, serviceName, env)
+	}
+
+	for i := range items {
+		items[i].Environment = env
+	}
+	if err := ValidateSecrets(items, environments); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSecrets, err)
+	}
+
+	if target.Owner == "" && target.CICDType == "github" {
+		if target.Owner, _, err = tokens.DefaultOwner(); err != nil {
+			return nil, err
+		}
+	}
+
+	written, err := writeSecrets(target, environments, items)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := recordSecrets(ctx, db.DB, serviceName, written); err != nil {
+		return nil, err
+	}
+
+	return written, nil
+}
@@ -0,0 +1,276 @@
+package svcconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+
+	"src/src/internal/cicd"
+	"src/src/internal/git"
+	"src/src/internal/model"
+)
+
+// pushAttempts bounds the retries when the branch moved on between our
+// clone and push.
+const pushAttempts = 3
+
+// publish writes c into the repository of t: pushed to the environment's
+// branch, or proposed on a branch of its own. A protected branch rejects
+// direct pushes, so a commit to one is proposed instead. prev is the
+// version it replaces, nil for the first.
+func publish(t target, c, prev *model.ServiceConfig) error {
+	if c.Delivery != model.ConfigPullRequest {
+		branch := cicd.EnvironmentBranch(c.Environment)
+		protection, err := git.GetBranchProtection(tokens, t.Owner, t.Repo, branch)
+		if err != nil {
+			return err
+		}
+		if protection != nil {
+			log.Printf("🛡️ %s@%s is protected; proposing config v%d of %s instead", t.Repo, branch, c.Version, t.ServiceName)
+			c.Delivery = model.ConfigPullRequest
+		}
+	}
+	if c.Delivery == model.ConfigPullRequest {
+		return propose(t, c, prev)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := commit(t, c, prev)
+		if !errors.Is(err, gogit.ErrForceNeeded) || attempt == pushAttempts {
+			return err
+		}
+		log.Printf("🔁 %s moved on while writing config of %s; retrying (%d/%d)", t.Repo, t.ServiceName, attempt, pushAttempts)
+	}
+}
+
+// checkout clones the environment's branch and renders c into it.
+func checkout(t target, c, prev *model.ServiceConfig) (*gogit.Repository, func(), error) {
+	workspace, err := os.MkdirTemp(workDir, "config-"+t.Repo+"-")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { os.RemoveAll(workspace) }
+
+	repo, err := git.CloneRepo(tokens, t.Owner, t.Repo, cicd.EnvironmentBranch(c.Environment), workspace)
+	if err == nil {
+		err = render(filepath.Join(workspace, filepath.FromSlash(t.RepoPath)), c, prev)
+	}
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return repo, cleanup, nil
+}
+
+func commit(t target, c, prev *model.ServiceConfig) error {
+	repo, cleanup, err := checkout(t, c, prev)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	err = git.PushBranch(tokens, t.Owner, repo, cicd.EnvironmentBranch(c.Environment), commitMessage(c))
+	if err != nil && !errors.Is(err, git.ErrNothingToCommit) {
+		return err
+	}
+
+	c.Status = model.ConfigCommitted
+	c.CommitSHA, err = headSHA(repo)
+	return err
+}
+
+func propose(t target, c, prev *model.ServiceConfig) error {
+	repo, cleanup, err := checkout(t, c, prev)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// Already in the branch (e.g. edited by hand): nothing to review
+	clean, err := worktreeClean(repo)
+	if err != nil {
+		return err
+	}
+	if clean {
+		c.Status = model.ConfigCommitted
+		c.CommitSHA, err = headSHA(repo)
+		return err
+	}
+
+	base := cicd.EnvironmentBranch(c.Environment)
+	branch := fmt.Sprintf("platform/config-%s-%s-v%d", t.ServiceName, c.Environment, c.Version)
+	if err := git.PushNewBranch(tokens, t.Owner, repo, branch, commitMessage(c)); err != nil {
+		return err
+	}
+
+	d := Diff(prevValues(prev), c.Values)
+	c.PullRequestURL, err = git.CreatePullRequest(tokens, t.Owner, t.Repo, branch, base,
+		fmt.Sprintf("Update %s config for %s (v%d)", t.ServiceName, c.Environment, c.Version),
+		pullRequestBody(c, d),
+	)
+	if err != nil {
+		_ = git.DeleteBranch(tokens, t.Owner, t.Repo, branch)
+		return err
+	}
+
+	c.Status = model.ConfigProposed
+	c.CommitSHA, err = headSHA(repo)
+	return err
+}
+
+func commitMessage(c *model.ServiceConfig) string {
+	msg := fmt.Sprintf("Update %s config for %s to v%d", c.ServiceName, c.Environment, c.Version)
+	if c.Message != "" {
+		msg += "\n\n" + c.Message
+	}
+	return msg + "\n\nRequested by " + c.UpdatedBy
+}
+
+func pullRequestBody(c *model.ServiceConfig, d model.ConfigDiff) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Configuration v%d of `%s` in `%s`, requested by %s.\n\n", c.Version, c.ServiceName, c.Environment, c.UpdatedBy)
+	if c.Message != "" {
+		b.WriteString(c.Message + "\n\n")
+	}
+	for _, section := range []struct {
+		title string
+		keys  []string
+	}{
+		{"Added", sortedKeys(d.Added)},
+		{"Changed", sortedKeys(d.Changed)},
+		{"Removed", sortedKeys(d.Removed)},
+	} {
+		for _, k := range section.keys {
+			fmt.Fprintf(&b, "- %s `%s`\n", section.title, k)
+		}
+	}
+	return b.String()
+}
+
+/* ===================== RENDERING ===================== */
+
+// render writes c into serviceDir. The environment's own file holds
+// exactly c's values; in the shared config.json they are merged over what
+// is there, and keys prev set that c drops are removed. When c moves to
+// another file, prev's is cleaned up.
+func render(serviceDir string, c, prev *model.ServiceConfig) error {
+	if prev != nil && prev.File != c.File {
+		if err := unrender(serviceDir, prev); err != nil {
+			return err
+		}
+		prev = nil
+	}
+
+	path := filepath.Join(serviceDir, filepath.FromSlash(c.File))
+	if c.File != model.ConfigFileShared {
+		return writeJSON(path, c.Values)
+	}
+
+	cfg, err := readJSON(path)
+	if err != nil {
+		return err
+	}
+	for k := range prevValues(prev) {
+		if _, ok := c.Values[k]; !ok {
+			delete(cfg, k)
+		}
+	}
+	for k, v := range c.Values {
+		cfg[k] = v
+	}
+	return writeJSON(path, cfg)
+}
+
+// unrender removes what c wrote.
+func unrender(serviceDir string, c *model.ServiceConfig) error {
+	path := filepath.Join(serviceDir, filepath.FromSlash(c.File))
+	if c.File != model.ConfigFileShared {
+		err := os.Remove(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	cfg, err := readJSON(path)
+	if err != nil {
+		return err
+	}
+	for k := range c.Values {
+		delete(cfg, k)
+	}
+	return writeJSON(path, cfg)
+}
+
+// readJSON reads a JSON object; a missing file is an empty one.
+func readJSON(path string) (map[string]interface{}, error) {
+	cfg := map[string]interface{}{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return cfg, nil
+}
+
+// writeJSON writes v with sorted keys, so versions diff cleanly.
+func writeJSON(path string, v interface{}) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(out, '\n'), 0644)
+}
+
+/* ===================== HELPERS ===================== */
+
+func prevValues(prev *model.ServiceConfig) map[string]string {
+	if prev == nil {
+		return nil
+	}
+	return prev.Values
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func worktreeClean(repo *gogit.Repository) (bool, error) {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return false, err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return false, err
+	}
+	return status.IsClean(), nil
+}
+
+func headSHA(repo *gogit.Repository) (string, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+	return head.Hash().String(), nil
+}
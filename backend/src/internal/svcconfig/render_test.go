@@ -0,0 +1,251 @@
+package svcconfig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"src/src/internal/config"
+	"src/src/internal/github"
+	"src/src/internal/model"
+)
+
+func TestDiff(t *testing.T) {
+	d := Diff(
+		map[string]string{"A": "1", "B": "2", "C": "3"},
+		map[string]string{"A": "1", "B": "20", "D": "4"},
+	)
+	if len(d.Added) != 1 || d.Added["D"] != "4" {
+		t.Errorf("added = %v", d.Added)
+	}
+	if len(d.Removed) != 1 || d.Removed["C"] != "3" {
+		t.Errorf("removed = %v", d.Removed)
+	}
+	if len(d.Changed) != 1 || d.Changed["B"] != (model.ConfigChange{From: "2", To: "20"}) {
+		t.Errorf("changed = %v", d.Changed)
+	}
+}
+
+func TestRenderEnvFile(t *testing.T) {
+	dir := t.TempDir()
+	prev := &model.ServiceConfig{File: "config.dev.json", Values: map[string]string{"A": "1", "B": "2"}}
+	c := &model.ServiceConfig{File: "config.dev.json", Values: map[string]string{"B": "3"}}
+
+	if err := render(dir, c, prev); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, dir, "config.dev.json"); got != "{\n  \"B\": \"3\"\n}\n" {
+		t.Errorf("config.dev.json = %q", got)
+	}
+}
+
+func TestRenderSharedFileKeepsTemplateKeys(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.json", `{"serviceName": "orders", "A": "1"}`)
+	prev := &model.ServiceConfig{File: "config.json", Values: map[string]string{"A": "1"}}
+	c := &model.ServiceConfig{File: "config.json", Values: map[string]string{"B": "2"}}
+
+	if err := render(dir, c, prev); err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"B\": \"2\",\n  \"serviceName\": \"orders\"\n}\n"
+	if got := readFile(t, dir, "config.json"); got != want {
+		t.Errorf("config.json = %q, want %q", got, want)
+	}
+}
+
+func TestRenderMovesFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.json", `{"serviceName": "orders", "A": "1"}`)
+	prev := &model.ServiceConfig{File: "config.json", Values: map[string]string{"A": "1"}}
+	c := &model.ServiceConfig{File: "config.test.json", Values: map[string]string{"A": "2"}}
+
+	if err := render(dir, c, prev); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, dir, "config.json"); got != "{\n  \"serviceName\": \"orders\"\n}\n" {
+		t.Errorf("config.json = %q", got)
+	}
+	if got := readFile(t, dir, "config.test.json"); got != "{\n  \"A\": \"2\"\n}\n" {
+		t.Errorf("config.test.json = %q", got)
+	}
+
+	// And back: the environment's own file goes away
+	if err := render(dir, prev, c); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "config.test.json")); !os.IsNotExist(err) {
+		t.Errorf("config.test.json left behind: %v", err)
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+type staticTokens struct{ conn github.Connection }
+
+func (s staticTokens) Token(string) (string, error)        { return "ghp_test", nil }
+func (s staticTokens) DefaultOwner() (string, bool, error) { return "acme", false, nil }
+func (s staticTokens) Connection() github.Connection       { return s.conn }
+
+// fakeRemote serves acme/orders over smart HTTP through git http-backend,
+// and the GitHub API calls publishing makes. A protected dev branch
+// rejects pushes with a pre-receive hook, as GitHub does.
+type fakeRemote struct {
+	t    *testing.T
+	bare string
+
+	mu    sync.Mutex
+	pulls []map[string]string
+}
+
+func newFakeRemote(t *testing.T, protected bool) *fakeRemote {
+	t.Helper()
+
+	gitBin, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not installed")
+	}
+
+	root := t.TempDir()
+	f := &fakeRemote{t: t, bare: filepath.Join(root, "acme", "orders.git")}
+
+	// 1️⃣ Repository with a dev branch
+	work := t.TempDir()
+	writeFile(t, work, "README.md", "orders\n")
+	f.git(work, "init", "-q", "-b", "dev")
+	f.git(work, "add", ".")
+	f.git(work, "commit", "-q", "-m", "initial")
+	f.git(root, "init", "-q", "--bare", f.bare)
+	f.git(f.bare, "config", "http.receivepack", "true")
+	f.git(work, "push", "-q", f.bare, "dev")
+	if protected {
+		writeFile(t, f.bare, "hooks/pre-receive", `#!/bin/sh
+while read old new ref; do
+  if [ "$ref" = "refs/heads/dev" ]; then
+    echo "GH006: Protected branch update failed for $ref." >&2
+    exit 1
+  fi
+done
+`)
+		if err := os.Chmod(filepath.Join(f.bare, "hooks", "pre-receive"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 2️⃣ Git over HTTP, API under /api/v3
+	mux := http.NewServeMux()
+	mux.Handle("/", &cgi.Handler{
+		Path: gitBin,
+		Args: []string{"http-backend"},
+		Env:  []string{"GIT_PROJECT_ROOT=" + root, "GIT_HTTP_EXPORT_ALL=1"},
+	})
+	mux.HandleFunc("GET /api/v3/repos/acme/orders/branches/dev/protection", func(w http.ResponseWriter, r *http.Request) {
+		if !protected {
+			http.Error(w, `{"message":"Branch not protected"}`, http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"required_pull_request_reviews":{"required_approving_review_count":1},"enforce_admins":{"enabled":true}}`))
+	})
+	mux.HandleFunc("POST /api/v3/repos/acme/orders/pulls", func(w http.ResponseWriter, r *http.Request) {
+		var pr map[string]string
+		json.NewDecoder(r.Body).Decode(&pr)
+		f.mu.Lock()
+		f.pulls = append(f.pulls, pr)
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"html_url":"https://ghe.example.com/acme/orders/pull/1"}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	prevDir, prevTokens := workDir, tokens
+	Configure(config.Workspace{Dir: t.TempDir()}, staticTokens{conn: github.Connection{APIURL: srv.URL + "/api/v3", WebURL: srv.URL}})
+	t.Cleanup(func() { workDir, tokens = prevDir, prevTokens })
+	return f
+}
+
+func (f *fakeRemote) git(dir string, args ...string) string {
+	f.t.Helper()
+
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		f.t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestPublishCommit(t *testing.T) {
+	tests := []struct {
+		name      string
+		protected bool
+	}{
+		{"unprotected branch", false},
+		{"protected branch", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remote := newFakeRemote(t, tt.protected)
+			devBefore := remote.git(remote.bare, "rev-parse", "dev")
+
+			c := &model.ServiceConfig{
+				ServiceName: "orders", Environment: "dev", Version: 2, File: "config.dev.json",
+				Values: map[string]string{"LOG_LEVEL": "debug"}, Delivery: model.ConfigCommit, UpdatedBy: "alice",
+			}
+			if err := publish(target{ServiceName: "orders", Owner: "acme", Repo: "orders"}, c, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			want := "{\n  \"LOG_LEVEL\": \"debug\"\n}"
+			if !tt.protected {
+				if c.Status != model.ConfigCommitted || c.CommitSHA != remote.git(remote.bare, "rev-parse", "dev") {
+					t.Errorf("status %s, commit %s", c.Status, c.CommitSHA)
+				}
+				if got := remote.git(remote.bare, "show", "dev:config.dev.json"); got != want {
+					t.Errorf("config.dev.json = %q", got)
+				}
+				if len(remote.pulls) != 0 {
+					t.Errorf("opened %v", remote.pulls)
+				}
+				return
+			}
+
+			// Proposed for review; dev itself is untouched
+			if c.Status != model.ConfigProposed || c.Delivery != model.ConfigPullRequest || c.PullRequestURL == "" {
+				t.Errorf("status %s, delivery %s, pull request %q", c.Status, c.Delivery, c.PullRequestURL)
+			}
+			if got := remote.git(remote.bare, "rev-parse", "dev"); got != devBefore {
+				t.Errorf("dev moved to %s", got)
+			}
+			const branch = "platform/config-orders-dev-v2"
+			if got := remote.git(remote.bare, "show", branch+":config.dev.json"); got != want {
+				t.Errorf("config.dev.json on %s = %q", branch, got)
+			}
+			if len(remote.pulls) != 1 || remote.pulls[0]["head"] != branch || remote.pulls[0]["base"] != "dev" {
+				t.Errorf("pull requests = %v", remote.pulls)
+			}
+		})
+	}
+}
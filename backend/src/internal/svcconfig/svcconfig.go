@@ -0,0 +1,392 @@
+// Package svcconfig keeps the configuration of a service's environments:
+// versioned key-value pairs with history and diffs. Every version is
+// rendered into the service repository on the environment's branch, as a
+// commit or a pull request, so configuration ships with the next deploy
+// the same way code does. Secrets do not belong here; they have their own
+// API and never reach the repository.
+package svcconfig
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"src/src/internal/config"
+	"src/src/internal/db"
+	"src/src/internal/git"
+	"src/src/internal/model"
+)
+
+var (
+	ErrNotFound   = errors.New("configuration not found")
+	ErrConflict   = errors.New("configuration changed since the base version")
+	ErrInProgress = errors.New("configuration change in progress")
+	ErrPublish    = errors.New("configuration could not be written to the repository")
+)
+
+// pendingTimeout is how long a version being written blocks the next one.
+const pendingTimeout = 10 * time.Minute
+
+var (
+	workDir = os.TempDir()
+	tokens  = git.DefaultTokenSource()
+)
+
+// now is replaced in tests.
+var now = func() time.Time { return time.Now().UTC().Truncate(time.Second) }
+
+// Configure sets where service repositories are cloned and the
+// credentials configuration is written with.
+func Configure(workspace config.Workspace, ts git.TokenSource) {
+	workDir = workspace.Dir
+	tokens = ts
+}
+
+const configColumns = `
+	service_name, environment, version, config_values, file, delivery, status,
+	commit_sha, pull_request_url, COALESCE(error, ''), message, updated_by, updated_at`
+
+/* ===================== READ ===================== */
+
+// Get returns version of the configuration of serviceName in env; version
+// 0 is the current one. ErrNotFound when there is none.
+func Get(ctx context.Context, serviceName, env string, version int) (*model.ServiceConfig, error) {
+	if version == 0 {
+		c, err := current(ctx, db.DB, serviceName, env)
+		if err == nil && c == nil {
+			err = fmt.Errorf("%w: %s has no configuration in %s", ErrNotFound, serviceName, env)
+		}
+		return c, err
+	}
+
+	found, err := queryConfigs(ctx, db.DB, `
+		SELECT `+configColumns+`
+		FROM service_configs
+		WHERE service_name = ? AND environment = ? AND version = ?`,
+		serviceName, env, version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("%w: %s has no version %d in %s", ErrNotFound, serviceName, version, env)
+	}
+	return &found[0], nil
+}
+
+// History returns the versions of an environment's configuration, newest
+// first, including those that failed to reach the repository.
+func History(ctx context.Context, serviceName, env string) ([]model.ServiceConfig, error) {
+	return queryConfigs(ctx, db.DB, `
+		SELECT `+configColumns+`
+		FROM service_configs
+		WHERE service_name = ? AND environment = ?
+		ORDER BY version DESC
+		LIMIT 100`,
+		serviceName, env,
+	)
+}
+
+// DiffVersions compares two versions of an environment's configuration.
+// to 0 is the current version; from 0 is the version before to.
+func DiffVersions(ctx context.Context, serviceName, env string, from, to int) (*model.ConfigDiff, error) {
+	toConfig, err := Get(ctx, serviceName, env, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var fromValues map[string]string
+	if from == 0 {
+		prev, err := previous(ctx, serviceName, env, toConfig.Version)
+		if err != nil {
+			return nil, err
+		}
+		if prev != nil {
+			from, fromValues = prev.Version, prev.Values
+		}
+	} else {
+		fromConfig, err := Get(ctx, serviceName, env, from)
+		if err != nil {
+			return nil, err
+		}
+		fromValues = fromConfig.Values
+	}
+
+	d := Diff(fromValues, toConfig.Values)
+	d.ServiceName, d.Environment, d.From, d.To = serviceName, env, from, toConfig.Version
+	return &d, nil
+}
+
+// Diff compares two sets of values.
+func Diff(from, to map[string]string) model.ConfigDiff {
+	d := model.ConfigDiff{
+		Added:   map[string]string{},
+		Removed: map[string]string{},
+		Changed: map[string]model.ConfigChange{},
+	}
+	for k, v := range to {
+		old, ok := from[k]
+		switch {
+		case !ok:
+			d.Added[k] = v
+		case old != v:
+			d.Changed[k] = model.ConfigChange{From: old, To: v}
+		}
+	}
+	for k, v := range from {
+		if _, ok := to[k]; !ok {
+			d.Removed[k] = v
+		}
+	}
+	return d
+}
+
+/* ===================== WRITE ===================== */
+
+// Put makes u the configuration of serviceName in env: a new version is
+// recorded and rendered into the repository. Values equal to the current
+// version change nothing and return it.
+func Put(ctx context.Context, serviceName, env string, u model.ConfigUpdate, by string) (*model.ServiceConfig, error) {
+	// 1️⃣ Service
+	t, err := lookup(ctx, serviceName, env)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2️⃣ Reserve the next version
+	c, prev, err := reserve(ctx, serviceName, env, u, by)
+	if err != nil || c.Status != model.ConfigPending {
+		return c, err
+	}
+
+	// 3️⃣ Repository
+	if err := publish(t, c, prev); err != nil {
+		log.Printf("❌ Config v%d of %s/%s not written: %v", c.Version, serviceName, env, err)
+		c.Status, c.Error = model.ConfigFailed, err.Error()
+		if ferr := finish(context.Background(), c); ferr != nil {
+			log.Printf("⚠️ mark config v%d of %s/%s failed: %v", c.Version, serviceName, env, ferr)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrPublish, err)
+	}
+
+	// 4️⃣ Record where it went
+	if err := finish(ctx, c); err != nil {
+		return nil, err
+	}
+
+	log.Printf("⚙️ Config of %s/%s is now v%d (%s by %s)", serviceName, env, c.Version, c.Status, by)
+	return c, nil
+}
+
+// reserve records the next version as pending. It returns the current
+// version instead when u changes nothing.
+func reserve(ctx context.Context, serviceName, env string, u model.ConfigUpdate, by string) (c, prev *model.ServiceConfig, err error) {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	// 1️⃣ Latest version, locked against concurrent changes
+	var (
+		lastVersion int
+		lastStatus  string
+		lastAt      time.Time
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT version, status, updated_at
+		FROM service_configs
+		WHERE service_name = ? AND environment = ?
+		ORDER BY version DESC
+		LIMIT 1
+		FOR UPDATE`,
+		serviceName, env,
+	).Scan(&lastVersion, &lastStatus, &lastAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, err
+	}
+	if lastStatus == model.ConfigPending && now().Sub(lastAt) < pendingTimeout {
+		return nil, nil, fmt.Errorf("%w: v%d of %s/%s is being written", ErrInProgress, lastVersion, serviceName, env)
+	}
+
+	// 2️⃣ Current version
+	prev, err = current(ctx, tx, serviceName, env)
+	if err != nil {
+		return nil, nil, err
+	}
+	currentVersion := 0
+	if prev != nil {
+		currentVersion = prev.Version
+	}
+	if u.BaseVersion != nil && *u.BaseVersion != currentVersion {
+		return nil, nil, fmt.Errorf("%w: %s/%s is at v%d, not v%d", ErrConflict, serviceName, env, currentVersion, *u.BaseVersion)
+	}
+
+	c = &model.ServiceConfig{
+		ServiceName: serviceName,
+		Environment: env,
+		Version:     lastVersion + 1,
+		Values:      u.Values,
+		File:        u.File,
+		Delivery:    u.Delivery,
+		Status:      model.ConfigPending,
+		Message:     u.Message,
+		UpdatedBy:   by,
+		UpdatedAt:   now(),
+	}
+	if c.Values == nil {
+		c.Values = map[string]string{}
+	}
+	if c.File == "" {
+		c.File = model.EnvConfigFile(env)
+		if prev != nil {
+			c.File = prev.File
+		}
+	}
+	if prev != nil && prev.File == c.File && maps.Equal(prev.Values, c.Values) {
+		return prev, nil, nil
+	}
+
+	// 3️⃣ Record
+	values, err := json.Marshal(c.Values)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO service_configs
+		(service_name, environment, version, config_values, file, delivery, status, message, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.ServiceName, c.Environment, c.Version, string(values), c.File, c.Delivery, c.Status, c.Message, c.UpdatedBy, c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return c, prev, tx.Commit()
+}
+
+func finish(ctx context.Context, c *model.ServiceConfig) error {
+	_, err := db.DB.ExecContext(ctx, `
+		UPDATE service_configs
+		SET delivery = ?, status = ?, commit_sha = ?, pull_request_url = ?, error = ?
+		WHERE service_name = ? AND environment = ? AND version = ?`,
+		c.Delivery, c.Status, c.CommitSHA, c.PullRequestURL, nullIfEmpty(c.Error),
+		c.ServiceName, c.Environment, c.Version,
+	)
+	return err
+}
+
+/* ===================== QUERIES ===================== */
+
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// current is the latest version that reached the repository, committed or
+// proposed; nil when there is none.
+func current(ctx context.Context, q querier, serviceName, env string) (*model.ServiceConfig, error) {
+	found, err := queryConfigs(ctx, q, `
+		SELECT `+configColumns+`
+		FROM service_configs
+		WHERE service_name = ? AND environment = ? AND status IN (?, ?)
+		ORDER BY version DESC
+		LIMIT 1`,
+		serviceName, env, model.ConfigCommitted, model.ConfigProposed,
+	)
+	if err != nil || len(found) == 0 {
+		return nil, err
+	}
+	return &found[0], nil
+}
+
+// previous is the version that was current before version.
+func previous(ctx context.Context, serviceName, env string, version int) (*model.ServiceConfig, error) {
+	found, err := queryConfigs(ctx, db.DB, `
+		SELECT `+configColumns+`
+		FROM service_configs
+		WHERE service_name = ? AND environment = ? AND version < ? AND status IN (?, ?)
+		ORDER BY version DESC
+		LIMIT 1`,
+		serviceName, env, version, model.ConfigCommitted, model.ConfigProposed,
+	)
+	if err != nil || len(found) == 0 {
+		return nil, err
+	}
+	return &found[0], nil
+}
+
+func queryConfigs(ctx context.Context, q querier, sqlText string, args ...any) ([]model.ServiceConfig, error) {
+	rows, err := q.QueryContext(ctx, sqlText, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.ServiceConfig{}
+	for rows.Next() {
+		var (
+			c      model.ServiceConfig
+			values string
+		)
+		if err := rows.Scan(&c.ServiceName, &c.Environment, &c.Version, &values, &c.File, &c.Delivery, &c.Status,
+			&c.CommitSHA, &c.PullRequestURL, &c.Error, &c.Message, &c.UpdatedBy, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(values), &c.Values); err != nil {
+			return nil, fmt.Errorf("config v%d of %s/%s: %w", c.Version, c.ServiceName, c.Environment, err)
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// target is where a service's configuration is rendered.
+type target struct {
+	ServiceName string
+	Owner       string
+	Repo        string
+	RepoPath    string // service directory in a monorepo, "" otherwise
+}
+
+// lookup reads the repository of serviceName and checks it has env.
+func lookup(ctx context.Context, serviceName, env string) (target, error) {
+	t := target{ServiceName: serviceName}
+	var envJSON sql.NullString
+	err := db.DB.QueryRowContext(ctx, `
+		SELECT COALESCE(repo_owner, ''), repo_name, COALESCE(repo_path, ''), environments
+		FROM services
+		WHERE service_name = ?`,
+		serviceName,
+	).Scan(&t.Owner, &t.Repo, &t.RepoPath, &envJSON)
+	if errors.Is(err, sql.ErrNoRows) {
+		return t, fmt.Errorf("%w: service %s", ErrNotFound, serviceName)
+	}
+	if err != nil {
+		return t, err
+	}
+
+	var envs []string
+	if envJSON.Valid {
+		if err := json.Unmarshal([]byte(envJSON.String), &envs); err != nil {
+			return t, err
+		}
+	}
+	if !slices.Contains(envs, env) {
+		return t, fmt.Errorf("%w: %s has no %s environment", ErrNotFound, serviceName, env)
+	}
+	return t, nil
+}
+
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,65 @@
+package svcconfig
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"src/src/internal/db"
+	"src/src/internal/model"
+)
+
+var t0 = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func TestReserveWaitsForPendingVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		age     time.Duration // of the pending v2
+		wantErr error
+	}{
+		{"still being written", pendingTimeout - time.Minute, ErrInProgress},
+		{"abandoned", pendingTimeout, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, m, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			db.DB = conn
+
+			prev := now
+			now = func() time.Time { return t0 }
+			t.Cleanup(func() { now = prev })
+
+			m.ExpectBegin()
+			m.ExpectQuery("FOR UPDATE").WithArgs("orders", "dev").WillReturnRows(
+				sqlmock.NewRows([]string{"version", "status", "updated_at"}).AddRow(2, model.ConfigPending, t0.Add(-tt.age)))
+			if tt.wantErr == nil {
+				m.ExpectQuery("FROM service_configs").WillReturnRows(sqlmock.NewRows([]string{"service_name"}))
+				m.ExpectExec("INSERT INTO service_configs").
+					WithArgs("orders", "dev", 3, `{"LOG_LEVEL":"debug"}`, model.EnvConfigFile("dev"), "", model.ConfigPending, "", "alice", t0).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			} else {
+				m.ExpectRollback()
+			}
+
+			u := model.ConfigUpdate{Values: map[string]string{"LOG_LEVEL": "debug"}}
+			c, _, err := reserve(context.Background(), "orders", "dev", u, "alice")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && c.Version != 3 {
+				t.Errorf("reserved v%d, want v3", c.Version)
+			}
+			if err := m.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+var builder = WebApplication.CreateBuilder(args);
+var app = builder.Build();
+
+var port = Environment.GetEnvironmentVariable("PORT") ?? "8080";
+
+app.MapGet("/healthz", () => Results.Json(new { status = "ok" }));
+
+app.Run($"http://0.0.0.0:{port}");
@@ -0,0 +1,15 @@
+const http = require("http")
+
+const port = process.env.PORT || 8080
+
+const server = http.createServer((req, res) => {
+  if (req.url === "/healthz") {
+    res.writeHead(200, { "Content-Type": "application/json" })
+    res.end(JSON.stringify({ status: "ok" }))
+    return
+  }
+  res.writeHead(404)
+  res.end()
+})
+
+server.listen(port, () => console.log(`service listening on :${port}`))
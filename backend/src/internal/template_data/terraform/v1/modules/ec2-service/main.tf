@@ -0,0 +1,109 @@
+# Platform module ec2-service v1: one instance running the service's
+# container, reachable on its port from inside the VPC and managed through
+# SSM (no SSH keys).
+
+locals {
+  name = "${var.service_name}-${var.environment}"
+  tags = merge(var.tags, {
+    "platform/service"     = var.service_name
+    "platform/environment" = var.environment
+    "platform/module"      = "ec2-service/v1"
+  })
+}
+
+/* ===================== NETWORK ===================== */
+
+data "aws_vpc" "selected" {
+  id      = var.vpc_id
+  default = var.vpc_id == null
+}
+
+data "aws_subnets" "selected" {
+  filter {
+    name   = "vpc-id"
+    values = [data.aws_vpc.selected.id]
+  }
+}
+
+resource "aws_security_group" "service" {
+  name        = local.name
+  description = "${local.name} service traffic"
+  vpc_id      = data.aws_vpc.selected.id
+  tags        = local.tags
+
+  ingress {
+    description = "service port"
+    from_port   = var.port
+    to_port     = var.port
+    protocol    = "tcp"
+    cidr_blocks = length(var.allowed_cidrs) > 0 ? var.allowed_cidrs : [data.aws_vpc.selected.cidr_block]
+  }
+
+  egress {
+    from_port   = 0
+    to_port     = 0
+    protocol    = "-1"
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+}
+
+/* ===================== IAM ===================== */
+
+resource "aws_iam_role" "service" {
+  name = local.name
+  tags = local.tags
+
+  assume_role_policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [{
+      Effect    = "Allow"
+      Action    = "sts:AssumeRole"
+      Principal = { Service = "ec2.amazonaws.com" }
+    }]
+  })
+}
+
+resource "aws_iam_role_policy_attachment" "ssm" {
+  role       = aws_iam_role.service.name
+  policy_arn = "arn:aws:iam::aws:policy/AmazonSSMManagedInstanceCore"
+}
+
+resource "aws_iam_instance_profile" "service" {
+  name = local.name
+  role = aws_iam_role.service.name
+  tags = local.tags
+}
+
+/* ===================== INSTANCE ===================== */
+
+data "aws_ssm_parameter" "al2023" {
+  name = "/aws/service/ami-amazon-linux-latest/al2023-ami-kernel-default-x86_64"
+}
+
+resource "aws_instance" "service" {
+  ami                    = coalesce(var.ami_id, data.aws_ssm_parameter.al2023.value)
+  instance_type          = var.instance_type
+  subnet_id              = coalesce(var.subnet_id, sort(data.aws_subnets.selected.ids)[0])
+  vpc_security_group_ids = [aws_security_group.service.id]
+  iam_instance_profile   = aws_iam_instance_profile.service.name
+  tags                   = merge(local.tags, { Name = local.name })
+
+  metadata_options {
+    http_tokens = "required"
+  }
+
+  root_block_device {
+    encrypted = true
+  }
+
+  user_data = <<-EOT
+    #!/bin/bash
+    dnf install -y docker
+    systemctl enable --now docker
+  EOT
+
+  lifecycle {
+    # A new AMI release must not replace a running instance
+    ignore_changes = [ami]
+  }
+}
@@ -0,0 +1,11 @@
+output "instance_id" {
+  value = aws_instance.service.id
+}
+
+output "private_ip" {
+  value = aws_instance.service.private_ip
+}
+
+output "security_group_id" {
+  value = aws_security_group.service.id
+}
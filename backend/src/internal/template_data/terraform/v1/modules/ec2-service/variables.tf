@@ -0,0 +1,50 @@
+variable "service_name" {
+  description = "Platform service name; prefixes every resource"
+  type        = string
+}
+
+variable "environment" {
+  description = "dev, test or prod"
+  type        = string
+}
+
+variable "instance_type" {
+  description = "EC2 instance type sized from the service's cpu/memory"
+  type        = string
+}
+
+variable "port" {
+  description = "Port the service listens on"
+  type        = number
+  default     = 8080
+}
+
+variable "vpc_id" {
+  description = "VPC to run in; the account's default VPC when null"
+  type        = string
+  default     = null
+}
+
+variable "subnet_id" {
+  description = "Subnet of the instance; the first subnet of the VPC when null"
+  type        = string
+  default     = null
+}
+
+variable "ami_id" {
+  description = "AMI of the instance; the latest Amazon Linux 2023 when null"
+  type        = string
+  default     = null
+}
+
+variable "allowed_cidrs" {
+  description = "CIDRs allowed to reach the service port; the VPC's CIDR when empty"
+  type        = list(string)
+  default     = []
+}
+
+variable "tags" {
+  description = "Extra tags for every resource"
+  type        = map(string)
+  default     = {}
+}
@@ -0,0 +1,90 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Commit status the Jenkins GitHub Branch Source plugin reports for PR builds
+const JenkinsPRCheck = "continuous-integration/jenkins/pr-merge"
+
+// StatusChecks returns the check names a template's CI reports on pull
+// requests, i.e. what branch protection can require before merging.
+func StatusChecks(req TemplateRequest) ([]string, error) {
+	_, cicdPath, err := GetTemplatePaths(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.CICD == "jenkins" {
+		return []string{JenkinsPRCheck}, nil
+	}
+
+	return workflowChecks(filepath.Join(cicdPath, "workflows"))
+}
+
+// workflowChecks collects the job names of every workflow that runs on
+// pull_request. GitHub reports a job by its `name`, falling back to its key.
+func workflowChecks(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var checks []string
+	for _, e := range entries {
+		if e.IsDir() || !(strings.HasSuffix(e.Name(), ".yml") || strings.HasSuffix(e.Name(), ".yaml")) {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		var wf struct {
+			On   yaml.Node `yaml:"on"`
+			Jobs map[string]struct {
+				Name string `yaml:"name"`
+			} `yaml:"jobs"`
+		}
+		if err := yaml.Unmarshal(data, &wf); err != nil {
+			return nil, err
+		}
+
+		if !hasTrigger(&wf.On, "pull_request") {
+			continue
+		}
+
+		for key, job := range wf.Jobs {
+			if job.Name != "" {
+				checks = append(checks, job.Name)
+			} else {
+				checks = append(checks, key)
+			}
+		}
+	}
+
+	sort.Strings(checks)
+	return checks, nil
+}
+
+func hasTrigger(on *yaml.Node, name string) bool {
+	switch on.Kind {
+	case yaml.ScalarNode:
+		return on.Value == name
+	case yaml.SequenceNode:
+		for _, n := range on.Content {
+			if n.Value == name {
+				return true
+			}
+		}
+	case yaml.MappingNode:
+		return mappingValue(on, name) != nil
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package templates
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Locations GitHub reads CODEOWNERS from, in precedence order
+var codeownersPaths = []string{
+	filepath.Join(".github", "CODEOWNERS"),
+	"CODEOWNERS",
+	filepath.Join("docs", "CODEOWNERS"),
+}
+
+// AddCodeowners assigns owners to pattern ("*" for a whole repo, "/path/" for
+// a monorepo service). An existing CODEOWNERS file is appended to; otherwise
+// .github/CODEOWNERS is created.
+func AddCodeowners(repoRoot, pattern string, owners ...string) error {
+	target := filepath.Join(repoRoot, codeownersPaths[0])
+
+	for _, p := range codeownersPaths {
+		candidate := filepath.Join(repoRoot, p)
+		if _, err := os.Stat(candidate); err == nil {
+			target = candidate
+			break
+		}
+	}
+
+	existing, err := os.ReadFile(target)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	content := string(existing)
+	if content == "" {
+		content = "# Generated by the platform. Later rules take precedence.\n"
+	} else if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += fmt.Sprintf("%s %s\n", pattern, strings.Join(owners, " "))
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(target, []byte(content), 0644)
+}
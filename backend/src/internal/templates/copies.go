@@ -8,13 +8,22 @@ import (
 
 
 func CreateServiceFromTemplate(req TemplateRequest, targetRepo string) error {
+	root, err := templateRoot()
+	if err != nil {
+		return err
+	}
+
+	return renderTemplate(root, req, targetRepo)
+}
+
+func renderTemplate(root string, req TemplateRequest, targetRepo string) error {
 	// 1️⃣ Ensure repo exists
 	if err := os.MkdirAll(targetRepo, 0755); err != nil {
 		return err
 	}
 
 	// 2️⃣ Resolve template version path
-	versionPath, _, err := GetTemplatePaths(req)
+	versionPath, _, err := templatePaths(root, req)
 	if err != nil {
 		return err
 	}
@@ -57,6 +66,15 @@ func CreateServiceFromTemplate(req TemplateRequest, targetRepo string) error {
 			return fmt.Errorf("copy github workflows failed: %w", err)
 		}
 
+		if dispatchWorkflow != templateWorkflow {
+			if err := os.Rename(
+				filepath.Join(dest, templateWorkflow),
+				filepath.Join(dest, dispatchWorkflow),
+			); err != nil {
+				return fmt.Errorf("rename dispatch workflow failed: %w", err)
+			}
+		}
+
 	// =======================
 	// Jenkins
 	// =======================
@@ -0,0 +1,236 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var servicePathParam = regexp.MustCompile(`(name:\s*'SERVICE_PATH',\s*defaultValue:\s*)'[^']*'`)
+
+// WorkflowFileName returns the workflow the platform dispatches for a service.
+// Standalone repos use the template's cicd.yaml; monorepo services get a
+// workflow named after their subpath so several services can share one repo.
+func WorkflowFileName(servicePath string) string {
+	if servicePath == "" {
+		return dispatchWorkflow
+	}
+	return pathSlug(servicePath) + "-" + dispatchWorkflow
+}
+
+// InfraWorkflowFileName is WorkflowFileName for the plan/apply workflow.
+func InfraWorkflowFileName(servicePath string) string {
+	if servicePath == "" {
+		return InfraWorkflow
+	}
+	return pathSlug(servicePath) + "-" + InfraWorkflow
+}
+
+// CreateServiceInMonorepo renders a template into servicePath inside an
+// existing checkout. GitHub workflows are hoisted to the repository root and
+// scoped to the service directory; Jenkinsfiles stay next to the service and
+// default their SERVICE_PATH parameter to it.
+func CreateServiceInMonorepo(req TemplateRequest, repoRoot, servicePath string) error {
+	serviceDir := filepath.Join(repoRoot, servicePath)
+
+	if _, err := os.Stat(serviceDir); err == nil {
+		return fmt.Errorf("path '%s' already exists in repository", servicePath)
+	}
+
+	if err := CreateServiceFromTemplate(req, serviceDir); err != nil {
+		return err
+	}
+
+	switch req.CICD {
+	case "github":
+		return hoistWorkflows(repoRoot, servicePath)
+	case "jenkins":
+		return scopeJenkinsfile(filepath.Join(serviceDir, "Jenkinsfile"), servicePath)
+	}
+
+	return nil
+}
+
+func hoistWorkflows(repoRoot, servicePath string) error {
+	src := filepath.Join(repoRoot, servicePath, ".github", "workflows")
+	dest := filepath.Join(repoRoot, ".github", "workflows")
+
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(src, e.Name()))
+		if err != nil {
+			return err
+		}
+
+		scoped, err := ScopeWorkflow(data, servicePath)
+		if err != nil {
+			return fmt.Errorf("scope workflow %s: %w", e.Name(), err)
+		}
+
+		name := pathSlug(servicePath) + "-" + e.Name()
+		if err := os.WriteFile(filepath.Join(dest, name), scoped, 0644); err != nil {
+			return err
+		}
+	}
+
+	// GitHub only reads workflows from the repository root
+	return os.RemoveAll(filepath.Join(repoRoot, servicePath, ".github"))
+}
+
+// ScopeWorkflow rewrites a workflow so it only runs for changes under
+// servicePath, runs its steps from that directory and accepts the
+// service_path dispatch input the platform sends for monorepo services.
+// Triggers are only narrowed, never added: a pull-request check stays one.
+func ScopeWorkflow(data []byte, servicePath string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("workflow is not a mapping")
+	}
+	top := doc.Content[0]
+
+	// 1️⃣ Name
+	if name := mappingValue(top, "name"); name != nil && name.Kind == yaml.ScalarNode {
+		name.Value = servicePath + ": " + name.Value
+	}
+
+	// 2️⃣ Triggers
+	on := mappingValue(top, "on")
+	if on == nil {
+		return nil, fmt.Errorf("missing 'on' triggers")
+	}
+	triggersToMapping(on)
+
+	for _, event := range []string{"push", "pull_request"} {
+		if mappingValue(on, event) != nil {
+			setValue(ensureMapping(on, event), "paths", sequenceNode(servicePath+"/**"))
+		}
+	}
+
+	if mappingValue(on, "workflow_dispatch") != nil {
+		inputs := ensureMapping(ensureMapping(on, "workflow_dispatch"), "inputs")
+		input := ensureMapping(inputs, "service_path")
+		setValue(input, "description", scalarNode("Service directory inside the repository"))
+		setValue(input, "required", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "false"})
+		setValue(input, "default", scalarNode(servicePath))
+	}
+
+	// 3️⃣ Working directory
+	run := ensureMapping(ensureMapping(top, "defaults"), "run")
+	setValue(run, "working-directory", scalarNode(servicePath))
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func scopeJenkinsfile(path, servicePath string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if !servicePathParam.Match(data) {
+		return fmt.Errorf("Jenkinsfile does not declare a SERVICE_PATH parameter")
+	}
+
+	out := servicePathParam.ReplaceAll(data, []byte("${1}'"+servicePath+"'"))
+	return os.WriteFile(path, out, 0644)
+}
+
+/* ===================== YAML HELPERS ===================== */
+
+func pathSlug(p string) string {
+	return strings.ReplaceAll(strings.Trim(filepath.ToSlash(p), "/"), "/", "-")
+}
+
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func setValue(m *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content[i+1] = value
+			return
+		}
+	}
+	m.Content = append(m.Content, scalarNode(key), value)
+}
+
+func ensureMapping(m *yaml.Node, key string) *yaml.Node {
+	v := mappingValue(m, key)
+	if v != nil && v.Kind == yaml.MappingNode {
+		return v
+	}
+	v = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	setValue(m, key, v)
+	return v
+}
+
+// triggersToMapping normalises `on: push` / `on: [push, pull_request]` into
+// the mapping form so individual triggers can be configured.
+func triggersToMapping(on *yaml.Node) {
+	var names []string
+	switch on.Kind {
+	case yaml.ScalarNode:
+		names = []string{on.Value}
+	case yaml.SequenceNode:
+		for _, n := range on.Content {
+			names = append(names, n.Value)
+		}
+	default:
+		return
+	}
+
+	*on = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, n := range names {
+		on.Content = append(on.Content, scalarNode(n), &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+	}
+}
+
+func scalarNode(v string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+}
+
+func sequenceNode(values ...string) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, v := range values {
+		n.Content = append(n.Content, scalarNode(v))
+	}
+	return n
+}
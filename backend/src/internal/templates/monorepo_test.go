@@ -0,0 +1,103 @@
+package templates
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestScopeWorkflow(t *testing.T) {
+	const jobs = `
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps: [{run: make}]
+`
+	tests := []struct {
+		name     string
+		on       string
+		triggers []string
+		paths    []string // triggers that get the path filter
+		inputs   []string // workflow_dispatch inputs afterwards
+	}{
+		{
+			name:     "push only",
+			on:       "on:\n  push:\n    branches: [dev]\n",
+			triggers: []string{"push"},
+			paths:    []string{"push"},
+		},
+		{
+			name:     "pull request only",
+			on:       "on:\n  pull_request:\n    branches: [dev, test, master]\n",
+			triggers: []string{"pull_request"},
+			paths:    []string{"pull_request"},
+		},
+		{
+			name:     "dispatch only",
+			on:       "on:\n  workflow_dispatch:\n    inputs:\n      rollback: {default: \"false\"}\n",
+			triggers: []string{"workflow_dispatch"},
+			inputs:   []string{"rollback", "service_path"},
+		},
+		{
+			name:     "scalar and list forms",
+			on:       "on: [push, workflow_dispatch]\n",
+			triggers: []string{"push", "workflow_dispatch"},
+			paths:    []string{"push"},
+			inputs:   []string{"service_path"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := ScopeWorkflow([]byte("name: CI\n"+tt.on+jobs), "services/api")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var wf struct {
+				Name     string
+				On       map[string]map[string]interface{} `yaml:"on"`
+				Defaults struct {
+					Run map[string]string
+				}
+			}
+			if err := yaml.Unmarshal(out, &wf); err != nil {
+				t.Fatalf("%v\n%s", err, out)
+			}
+
+			var triggers, paths []string
+			for name, trigger := range wf.On {
+				triggers = append(triggers, name)
+				if p, ok := trigger["paths"]; ok {
+					paths = append(paths, name)
+					if !reflect.DeepEqual(p, []interface{}{"services/api/**"}) {
+						t.Errorf("%s paths = %v", name, p)
+					}
+				}
+			}
+			sort.Strings(triggers)
+			sort.Strings(paths)
+			if !reflect.DeepEqual(triggers, tt.triggers) {
+				t.Errorf("triggers = %v, want %v\n%s", triggers, tt.triggers, out)
+			}
+			if !reflect.DeepEqual(paths, tt.paths) {
+				t.Errorf("path filters on %v, want %v", paths, tt.paths)
+			}
+
+			var inputs []string
+			declared, _ := wf.On["workflow_dispatch"]["inputs"].(map[string]interface{})
+			for name := range declared {
+				inputs = append(inputs, name)
+			}
+			sort.Strings(inputs)
+			if !reflect.DeepEqual(inputs, tt.inputs) {
+				t.Errorf("dispatch inputs = %v, want %v", inputs, tt.inputs)
+			}
+
+			if wf.Name != "services/api: CI" || wf.Defaults.Run["working-directory"] != "services/api" {
+				t.Errorf("name %q, defaults %v", wf.Name, wf.Defaults.Run)
+			}
+		})
+	}
+}
@@ -26,21 +26,28 @@ type TemplateRequest struct {
 	Language   string
 	Version    string
 	CICD       string          // github | jenkins
-	DeployType string          // ec2 | microservice
+	DeployType string          // ec2 | microservice | kubernetes
+	Terraform  *TerraformRequest // ec2 infrastructure, when requested
 }
 
 
 // ✅ Validate & return template paths
 func GetTemplatePaths(req TemplateRequest) (versionPath, cicdPath string, err error) {
-	if req.Language == "" || req.Version == "" || req.CICD == "" || req.DeployType == "" {
-		return "", "", fmt.Errorf("language, version, cicd, and deployType are required")
-	}
-
 	root, err := templateRoot()
 	if err != nil {
 		return "", "", err
 	}
 
+	return templatePaths(root, req)
+}
+
+// templatePaths resolves a template request against an explicit root so the
+// verifier can run from any working directory.
+func templatePaths(root string, req TemplateRequest) (versionPath, cicdPath string, err error) {
+	if req.Language == "" || req.Version == "" || req.CICD == "" || req.DeployType == "" {
+		return "", "", fmt.Errorf("language, version, cicd, and deployType are required")
+	}
+
 	// 1️⃣ Language
 	langPath := filepath.Join(root, req.Language)
 	if _, err := os.Stat(langPath); err != nil {
@@ -0,0 +1,214 @@
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Layout: <root>/terraform/<version>/
+//
+//	modules/<module>/    platform modules, vendored into the service
+//	root/*.tmpl          root module rendered per environment
+//	pipelines/github/    plan/apply workflow
+//	pipelines/jenkins/   plan/apply Jenkinsfile
+const terraformDir = "terraform"
+
+const (
+	// InfraDir holds a service's Terraform, relative to the service:
+	// modules/ plus one root module per environment.
+	InfraDir = "infra"
+
+	// InfraWorkflow is the workflow the platform dispatches for plan/apply.
+	InfraWorkflow = "infra.yaml"
+
+	// InfraJenkinsfile is the plan/apply pipeline of jenkins services,
+	// relative to the service.
+	InfraJenkinsfile = InfraDir + "/Jenkinsfile"
+)
+
+// TerraformRequest describes the infrastructure rendered into a service.
+type TerraformRequest struct {
+	ModuleVersion string // e.g. v1
+	ServiceName   string
+	Environments  []string
+
+	// As requested, and the instance type they were sized to
+	Cloud        string
+	Compute      string
+	CPU          string
+	Memory       string
+	InstanceType string
+
+	Region      string
+	StateBucket string
+	LockTable   string // state locking; none when empty
+}
+
+// TerraformStateKey is where an environment's state lives in the bucket.
+func TerraformStateKey(serviceName, env string) string {
+	return path.Join("services", serviceName, env, "terraform.tfstate")
+}
+
+// InfraRootModule is an environment's root module, relative to the service.
+func InfraRootModule(env string) string {
+	return path.Join(InfraDir, env)
+}
+
+type rootModuleData struct {
+	TerraformRequest
+	Environment string
+	StateKey    string
+}
+
+// renderTerraform vendors the platform modules of req's version into
+// targetRepo/infra, renders a root module per environment and adds the
+// plan/apply pipeline of the service's CI system.
+func renderTerraform(root string, req TerraformRequest, cicd, targetRepo string) error {
+	versionPath := filepath.Join(root, terraformDir, req.ModuleVersion)
+	if _, err := os.Stat(versionPath); err != nil {
+		return fmt.Errorf("terraform modules '%s' not found", req.ModuleVersion)
+	}
+
+	infraDir := filepath.Join(targetRepo, InfraDir)
+
+	// 1️⃣ Platform modules
+	if err := CopyDir(filepath.Join(versionPath, "modules"), filepath.Join(infraDir, "modules")); err != nil {
+		return fmt.Errorf("copy terraform modules failed: %w", err)
+	}
+
+	// 2️⃣ Root module per environment
+	tmpl, err := template.ParseGlob(filepath.Join(versionPath, "root", "*.tmpl"))
+	if err != nil {
+		return fmt.Errorf("parse terraform root module: %w", err)
+	}
+
+	for _, env := range req.Environments {
+		dir := filepath.Join(targetRepo, InfraRootModule(env))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+
+		data := rootModuleData{
+			TerraformRequest: req,
+			Environment:      env,
+			StateKey:         TerraformStateKey(req.ServiceName, env),
+		}
+		for _, t := range tmpl.Templates() {
+			var buf bytes.Buffer
+			if err := t.Execute(&buf, data); err != nil {
+				return fmt.Errorf("render %s: %w", t.Name(), err)
+			}
+			name := strings.TrimSuffix(t.Name(), ".tmpl")
+			if err := os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0644); err != nil {
+				return err
+			}
+		}
+	}
+
+	// 3️⃣ Plan/apply pipeline
+	switch cicd {
+	case "github":
+		dest := filepath.Join(targetRepo, ".github", "workflows", InfraWorkflow)
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		if err := copyFile(filepath.Join(versionPath, "pipelines", "github", InfraWorkflow), dest, 0644); err != nil {
+			return fmt.Errorf("copy infra workflow failed: %w", err)
+		}
+	case "jenkins":
+		dest := filepath.Join(targetRepo, filepath.FromSlash(InfraJenkinsfile))
+		if err := copyFile(filepath.Join(versionPath, "pipelines", "jenkins", "Jenkinsfile"), dest, 0644); err != nil {
+			return fmt.Errorf("copy infra Jenkinsfile failed: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported cicd type: %s", cicd)
+	}
+
+	return nil
+}
+
+// verifyTerraform renders every Terraform version for each CI system and
+// checks the root modules and plan/apply pipelines come out whole.
+func verifyTerraform(root string) ([]VerifyResult, error) {
+	versions, err := subDirs(filepath.Join(root, terraformDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var results []VerifyResult
+	for _, version := range versions {
+		for _, ci := range SupportedCICD {
+			results = append(results, verifyTerraformVersion(root, version, ci))
+		}
+	}
+	return results, nil
+}
+
+func verifyTerraformVersion(root, version, ci string) VerifyResult {
+	result := VerifyResult{Request: TemplateRequest{Language: terraformDir, Version: version, CICD: ci, DeployType: "ec2"}}
+	fail := func(format string, args ...interface{}) {
+		result.Errors = append(result.Errors, fmt.Sprintf(format, args...))
+	}
+
+	target, err := os.MkdirTemp("", "terraform-verify-*")
+	if err != nil {
+		fail("create temp dir: %v", err)
+		return result
+	}
+	defer os.RemoveAll(target)
+
+	req := TerraformRequest{
+		ModuleVersion: version,
+		ServiceName:   "verify",
+		Environments:  []string{"dev", "prod"},
+		Cloud:         "aws",
+		Compute:       "ec2",
+		CPU:           "2",
+		Memory:        "4Gi",
+		InstanceType:  "t3.medium",
+		Region:        "us-east-1",
+		StateBucket:   "verify-state",
+	}
+	if err := renderTerraform(root, req, ci, target); err != nil {
+		fail("render failed: %v", err)
+		return result
+	}
+
+	for _, env := range req.Environments {
+		for _, f := range []string{"main.tf", "terraform.tfvars"} {
+			if err := requireNonEmpty(filepath.Join(target, InfraRootModule(env), f)); err != nil {
+				fail("%s/%s: %v", InfraRootModule(env), f, err)
+			}
+		}
+	}
+
+	switch ci {
+	case "github":
+		data, err := os.ReadFile(filepath.Join(target, ".github", "workflows", InfraWorkflow))
+		if err == nil {
+			err = ValidateWorkflow(data, false)
+		}
+		if err != nil {
+			fail("%s: %v", InfraWorkflow, err)
+		}
+	case "jenkins":
+		data, err := os.ReadFile(filepath.Join(target, filepath.FromSlash(InfraJenkinsfile)))
+		if err == nil {
+			err = validateJenkinsfile(string(data), false)
+		}
+		if err != nil {
+			fail("%s: %v", InfraJenkinsfile, err)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,540 @@
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Layout: <root>/<language>/<version>/cicd/<cicd>/<deployType>/...
+var (
+	SupportedCICD        = []string{"github", "jenkins"}
+	SupportedDeployTypes = []string{"ec2", "microservice", "kubernetes"}
+)
+
+// Workflow file the golden templates ship and the name the backend
+// dispatches for github services (renamed on render when they differ)
+const templateWorkflow = "cicd.yaml"
+
+var dispatchWorkflow = templateWorkflow
+
+// SetDispatchWorkflow changes the workflow file name generated repositories
+// use for deploys and rollbacks.
+func SetDispatchWorkflow(name string) {
+	dispatchWorkflow = name
+}
+
+type VerifyResult struct {
+	Request TemplateRequest
+	Errors  []string
+}
+
+func (r VerifyResult) OK() bool {
+	return len(r.Errors) == 0
+}
+
+func (r VerifyResult) Name() string {
+	return fmt.Sprintf("%s/%s/%s/%s",
+		r.Request.Language, r.Request.Version, r.Request.CICD, r.Request.DeployType)
+}
+
+// VerifyAll verifies every template under the runtime template root.
+func VerifyAll() ([]VerifyResult, error) {
+	root, err := templateRoot()
+	if err != nil {
+		return nil, err
+	}
+	return Verify(root)
+}
+
+// Verify enumerates every language/version/cicd/deployType combination under
+// root, renders each one into a temp dir and validates the result, then does
+// the same for the Terraform modules. Layout
+// problems that prevent a combination from being enumerated are reported as
+// failed results too.
+func Verify(root string) ([]VerifyResult, error) {
+	combos, layoutErrs, err := Enumerate(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []VerifyResult
+	results = append(results, layoutErrs...)
+
+	for _, c := range combos {
+		results = append(results, verifyCombination(root, c))
+	}
+
+	terraform, err := verifyTerraform(root)
+	if err != nil {
+		return nil, err
+	}
+	results = append(results, terraform...)
+
+	return results, nil
+}
+
+// Enumerate returns every language/version under root crossed with
+// SupportedCICD and SupportedDeployTypes. Missing combinations and folders
+// outside that matrix come back as failed results.
+func Enumerate(root string) ([]TemplateRequest, []VerifyResult, error) {
+	var (
+		combos []TemplateRequest
+		broken []VerifyResult
+	)
+
+	languages, err := subDirs(root)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, lang := range languages {
+		if lang == terraformDir {
+			continue // platform modules, not a language
+		}
+
+		versions, err := subDirs(filepath.Join(root, lang))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, version := range versions {
+			cicdRoot := filepath.Join(root, lang, version, "cicd")
+			cicds, err := subDirs(cicdRoot)
+			if err != nil {
+				broken = append(broken, VerifyResult{
+					Request: TemplateRequest{Language: lang, Version: version},
+					Errors:  []string{"cicd folder missing in template"},
+				})
+				continue
+			}
+
+			// Folders the platform would never select
+			for _, ci := range cicds {
+				if !contains(SupportedCICD, ci) {
+					broken = append(broken, VerifyResult{
+						Request: TemplateRequest{Language: lang, Version: version, CICD: ci},
+						Errors:  []string{fmt.Sprintf("unsupported cicd type '%s'", ci)},
+					})
+					continue
+				}
+
+				deployTypes, err := subDirs(filepath.Join(cicdRoot, ci))
+				if err != nil {
+					return nil, nil, err
+				}
+
+				for _, dt := range deployTypes {
+					if !contains(SupportedDeployTypes, dt) {
+						broken = append(broken, VerifyResult{
+							Request: TemplateRequest{Language: lang, Version: version, CICD: ci, DeployType: dt},
+							Errors: []string{fmt.Sprintf(
+								"cicd/%s/%s is not a supported deployType (expected cicd/%s/{%s}/...)",
+								ci, dt, ci, strings.Join(SupportedDeployTypes, ","),
+							)},
+						})
+					}
+				}
+			}
+
+			// Every supported combination must exist: the platform offers
+			// each runtime with every cicd and deploy type
+			for _, ci := range SupportedCICD {
+				for _, dt := range SupportedDeployTypes {
+					req := TemplateRequest{Language: lang, Version: version, CICD: ci, DeployType: dt}
+
+					if info, err := os.Stat(filepath.Join(cicdRoot, ci, dt)); err != nil || !info.IsDir() {
+						broken = append(broken, VerifyResult{
+							Request: req,
+							Errors:  []string{fmt.Sprintf("cicd/%s/%s missing", ci, dt)},
+						})
+						continue
+					}
+
+					combos = append(combos, req)
+				}
+			}
+		}
+	}
+
+	return combos, broken, nil
+}
+
+func verifyCombination(root string, req TemplateRequest) VerifyResult {
+	result := VerifyResult{Request: req}
+	fail := func(format string, args ...interface{}) {
+		result.Errors = append(result.Errors, fmt.Sprintf(format, args...))
+	}
+
+	target, err := os.MkdirTemp("", "template-verify-*")
+	if err != nil {
+		fail("create temp dir: %v", err)
+		return result
+	}
+	defer os.RemoveAll(target)
+
+	// 1️⃣ Render
+	if err := renderTemplate(root, req, target); err != nil {
+		fail("render failed: %v", err)
+		return result
+	}
+
+	// 2️⃣ Required files
+	for _, f := range []string{"Dockerfile", "config.json"} {
+		if err := requireNonEmpty(filepath.Join(target, f)); err != nil {
+			fail("%s: %v", f, err)
+		}
+	}
+
+	if err := requireSource(filepath.Join(target, "src")); err != nil {
+		fail("src: %v", err)
+	}
+
+	// 3️⃣ config.json
+	if err := validateConfigJSON(filepath.Join(target, "config.json")); err != nil {
+		fail("config.json: %v", err)
+	}
+
+	// 4️⃣ Pipeline definitions; kubernetes pipelines only build images
+	// and are never dispatched by the platform
+	dispatched := req.DeployType != "kubernetes"
+	switch req.CICD {
+	case "github":
+		for _, e := range validateWorkflows(filepath.Join(target, ".github", "workflows"), dispatched) {
+			fail("%s", e)
+		}
+	case "jenkins":
+		data, err := os.ReadFile(filepath.Join(target, "Jenkinsfile"))
+		if err != nil {
+			fail("Jenkinsfile: %v", err)
+			break
+		}
+		if err := validateJenkinsfile(string(data), dispatched); err != nil {
+			fail("Jenkinsfile: %v", err)
+		}
+	}
+
+	return result
+}
+
+/* ===================== CHECKS ===================== */
+
+func requireNonEmpty(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("required file missing")
+	}
+	if info.Size() == 0 {
+		return fmt.Errorf("required file is empty")
+	}
+	return nil
+}
+
+func requireSource(dir string) error {
+	found := false
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && info.Size() > 0 {
+			found = true
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("src folder missing")
+	}
+	if !found {
+		return fmt.Errorf("src folder has no non-empty source files")
+	}
+	return nil
+}
+
+func validateConfigJSON(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var cfg map[string]interface{}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("invalid json: %v", err)
+	}
+
+	// Pipelines read these keys; UpdateConfigJSON fills them in
+	for _, key := range []string{"serviceName", "repoUrl"} {
+		v, ok := cfg[key]
+		if !ok {
+			return fmt.Errorf("missing key '%s'", key)
+		}
+		if _, ok := v.(string); !ok {
+			return fmt.Errorf("key '%s' must be a string", key)
+		}
+	}
+
+	return nil
+}
+
+func validateWorkflows(dir string, dispatched bool) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return []string{"no .github/workflows rendered"}
+	}
+
+	var errs []string
+	dispatchFound := false
+
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || (!strings.HasSuffix(name, ".yaml") && !strings.HasSuffix(name, ".yml")) {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+
+		if err := ValidateWorkflow(data, dispatched && name == dispatchWorkflow); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+		}
+
+		if name == dispatchWorkflow {
+			dispatchFound = true
+		}
+	}
+
+	if dispatched && !dispatchFound {
+		errs = append(errs, fmt.Sprintf("workflow %s missing (dispatched by the platform)", dispatchWorkflow))
+	}
+
+	return errs
+}
+
+// ValidateWorkflow checks a GitHub Actions workflow parses and has the shape
+// the platform relies on. Dispatched workflows must accept the rollback inputs.
+func ValidateWorkflow(data []byte, dispatched bool) error {
+	var wf map[string]interface{}
+	if err := yaml.Unmarshal(data, &wf); err != nil {
+		return fmt.Errorf("invalid yaml: %v", err)
+	}
+	if wf == nil {
+		return fmt.Errorf("workflow is empty")
+	}
+
+	on, ok := wf["on"]
+	if !ok {
+		return fmt.Errorf("missing 'on' triggers")
+	}
+
+	jobs, ok := wf["jobs"].(map[string]interface{})
+	if !ok || len(jobs) == 0 {
+		return fmt.Errorf("missing 'jobs'")
+	}
+
+	for name, j := range jobs {
+		job, ok := j.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("job '%s' is not a mapping", name)
+		}
+		if _, ok := job["runs-on"]; !ok {
+			if _, reusable := job["uses"]; !reusable {
+				return fmt.Errorf("job '%s' missing 'runs-on'", name)
+			}
+		}
+	}
+
+	if !dispatched {
+		return nil
+	}
+
+	triggers, ok := on.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("'on' must declare workflow_dispatch with inputs")
+	}
+	dispatch, ok := triggers["workflow_dispatch"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("missing workflow_dispatch trigger")
+	}
+	inputs, _ := dispatch["inputs"].(map[string]interface{})
+	for _, in := range []string{"rollback", "rollback_version"} {
+		if _, ok := inputs[in]; !ok {
+			return fmt.Errorf("workflow_dispatch missing input '%s'", in)
+		}
+	}
+
+	return nil
+}
+
+// ValidateJenkinsfile performs a structural syntax check of a declarative
+// pipeline: balanced brackets outside strings/comments, a top-level pipeline
+// block with agent and stages, and the rollback parameters the platform sends.
+func ValidateJenkinsfile(src string) error {
+	return validateJenkinsfile(src, true)
+}
+
+func validateJenkinsfile(src string, dispatched bool) error {
+	code, err := stripGroovy(src)
+	if err != nil {
+		return err
+	}
+
+	var stack []rune
+	pairs := map[rune]rune{')': '(', ']': '[', '}': '{'}
+
+	line := 1
+	for _, c := range code {
+		switch c {
+		case '\n':
+			line++
+		case '(', '[', '{':
+			stack = append(stack, c)
+		case ')', ']', '}':
+			if len(stack) == 0 || stack[len(stack)-1] != pairs[c] {
+				return fmt.Errorf("unbalanced '%c' at line %d", c, line)
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if len(stack) > 0 {
+		return fmt.Errorf("unclosed '%c' at end of file", stack[len(stack)-1])
+	}
+
+	fields := strings.Fields(strings.ReplaceAll(code, "{", " { "))
+	if len(fields) < 2 || fields[0] != "pipeline" || fields[1] != "{" {
+		return fmt.Errorf("file must start with a declarative 'pipeline {' block")
+	}
+
+	for _, kw := range []string{"agent", "stages"} {
+		if !contains(fields, kw) {
+			return fmt.Errorf("pipeline missing '%s'", kw)
+		}
+	}
+
+	if !dispatched {
+		return nil
+	}
+	for _, p := range []string{"'ROLLBACK'", "'ROLLBACK_VERSION'"} {
+		if !strings.Contains(src, p) {
+			return fmt.Errorf("pipeline missing parameter %s", p)
+		}
+	}
+
+	return nil
+}
+
+// stripGroovy blanks out comments and string literals, keeping newlines so
+// line numbers in errors stay accurate.
+func stripGroovy(src string) (string, error) {
+	rs := []rune(src)
+	out := make([]rune, 0, len(rs))
+
+	// blank copies rs[from:to] as spaces, preserving newlines
+	blank := func(from, to int) {
+		for _, r := range rs[from:to] {
+			if r == '\n' {
+				out = append(out, '\n')
+			} else {
+				out = append(out, ' ')
+			}
+		}
+	}
+
+	for i := 0; i < len(rs); {
+		switch {
+		case hasPrefixAt(rs, i, "//"):
+			end := indexAt(rs, i, "\n")
+			if end < 0 {
+				end = len(rs)
+			}
+			blank(i, end)
+			i = end
+
+		case hasPrefixAt(rs, i, "/*"):
+			end := indexAt(rs, i+2, "*/")
+			if end < 0 {
+				return "", fmt.Errorf("unterminated block comment")
+			}
+			blank(i, end+2)
+			i = end + 2
+
+		case hasPrefixAt(rs, i, `"""`), hasPrefixAt(rs, i, "'''"):
+			quote := string(rs[i : i+3])
+			end := indexAt(rs, i+3, quote)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated %s string", quote)
+			}
+			blank(i, end+3)
+			i = end + 3
+
+		case rs[i] == '"' || rs[i] == '\'':
+			j := i + 1
+			for ; j < len(rs) && rs[j] != rs[i]; j++ {
+				if rs[j] == '\\' {
+					j++
+				} else if rs[j] == '\n' {
+					return "", fmt.Errorf("unterminated string literal")
+				}
+			}
+			if j >= len(rs) {
+				return "", fmt.Errorf("unterminated string literal")
+			}
+			blank(i, j+1)
+			i = j + 1
+
+		default:
+			out = append(out, rs[i])
+			i++
+		}
+	}
+
+	return string(out), nil
+}
+
+func hasPrefixAt(rs []rune, i int, prefix string) bool {
+	return strings.HasPrefix(string(rs[i:min(i+len(prefix), len(rs))]), prefix)
+}
+
+// indexAt returns the rune index of sub in rs at or after start, or -1.
+func indexAt(rs []rune, start int, sub string) int {
+	pat := []rune(sub)
+	for i := start; i+len(pat) <= len(rs); i++ {
+		if string(rs[i:i+len(pat)]) == sub {
+			return i
+		}
+	}
+	return -1
+}
+
+/* ===================== HELPERS ===================== */
+
+func subDirs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []string
+	for _, e := range entries {
+		if e.IsDir() {
+			dirs = append(dirs, e.Name())
+		}
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
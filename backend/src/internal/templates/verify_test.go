@@ -0,0 +1,168 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAllTemplatesVerify(t *testing.T) {
+	results, err := Verify(filepath.Join("..", "template_data"))
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if len(results) == 0 {
+		t.Fatal("no template combinations found")
+	}
+
+	for _, r := range results {
+		r := r
+		t.Run(r.Name(), func(t *testing.T) {
+			for _, e := range r.Errors {
+				t.Error(e)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsWorkflowsWithoutDeployType(t *testing.T) {
+	root := t.TempDir()
+	base := filepath.Join(root, "node", "v1")
+
+	writeFile(t, filepath.Join(base, "src", "index.js"), "console.log('hi')")
+	writeFile(t, filepath.Join(base, "Dockerfile"), "FROM node:20")
+	writeFile(t, filepath.Join(base, "config.json"), `{"serviceName":"","repoUrl":""}`)
+	writeFile(t, filepath.Join(base, "cicd", "github", "workflows", "ci.yaml"), "on: push")
+
+	results, err := Verify(root)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if r := findError(results, "node/v1/github/workflows", "not a supported deployType"); r == nil {
+		t.Fatalf("workflows folder not rejected: %+v", results)
+	}
+}
+
+func TestVerifyRejectsEmptySource(t *testing.T) {
+	root := t.TempDir()
+	base := filepath.Join(root, "go", "v1")
+
+	writeFile(t, filepath.Join(base, "src", "main.go"), "")
+	writeFile(t, filepath.Join(base, "Dockerfile"), "FROM golang")
+	writeFile(t, filepath.Join(base, "config.json"), `{"serviceName":"","repoUrl":""}`)
+	writeFile(t, filepath.Join(base, "cicd", "jenkins", "ec2", "Jenkinsfile"), validJenkinsfile)
+
+	results, err := Verify(root)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if r := findError(results, "go/v1/jenkins/ec2", "src"); r == nil {
+		t.Fatalf("empty source not rejected: %+v", results)
+	}
+}
+
+func TestVerifyRequiresEveryCombination(t *testing.T) {
+	root := t.TempDir()
+	base := filepath.Join(root, "go", "v1")
+
+	writeFile(t, filepath.Join(base, "src", "main.go"), "package main")
+	writeFile(t, filepath.Join(base, "Dockerfile"), "FROM golang")
+	writeFile(t, filepath.Join(base, "config.json"), `{"serviceName":"","repoUrl":""}`)
+	writeFile(t, filepath.Join(base, "cicd", "jenkins", "ec2", "Jenkinsfile"), validJenkinsfile)
+
+	results, err := Verify(root)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	want := len(SupportedCICD) * len(SupportedDeployTypes)
+	if len(results) != want {
+		t.Fatalf("got %d results, want one per combination (%d)", len(results), want)
+	}
+	for _, r := range results {
+		if r.Name() == "go/v1/jenkins/ec2" {
+			if !r.OK() {
+				t.Errorf("%s: %v", r.Name(), r.Errors)
+			}
+			continue
+		}
+		if r.OK() || !strings.Contains(r.Errors[0], "missing") {
+			t.Errorf("%s: want a missing-combination error, got %v", r.Name(), r.Errors)
+		}
+	}
+}
+
+// findError returns the failed result for name with an error containing
+// substr.
+func findError(results []VerifyResult, name, substr string) *VerifyResult {
+	for i, r := range results {
+		if r.Name() != name {
+			continue
+		}
+		for _, e := range r.Errors {
+			if strings.Contains(e, substr) {
+				return &results[i]
+			}
+		}
+	}
+	return nil
+}
+
+const validJenkinsfile = `pipeline {
+    agent any
+    parameters {
+        booleanParam(name: 'ROLLBACK', defaultValue: false)
+        string(name: 'ROLLBACK_VERSION', defaultValue: '')
+    }
+    stages {
+        stage('Build') {
+            steps {
+                echo "building ${env.BRANCH_NAME} }"
+            }
+        }
+    }
+}`
+
+func TestValidateJenkinsfile(t *testing.T) {
+	cases := map[string]struct {
+		src     string
+		wantErr bool
+	}{
+		"valid":            {validJenkinsfile, false},
+		"unbalanced":       {strings.TrimSuffix(validJenkinsfile, "}"), true},
+		"placeholder":      {"pipeline{\n    this is the ec2 instance based architecture\n}", true},
+		"unterminated str": {"pipeline { agent any\n stages { echo 'x }\n}", true},
+	}
+
+	for name, tc := range cases {
+		err := ValidateJenkinsfile(tc.src)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: err=%v wantErr=%v", name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestValidateWorkflow(t *testing.T) {
+	if err := ValidateWorkflow([]byte("name: this is the ec2 instance based deplyoment"), false); err == nil {
+		t.Error("expected placeholder workflow to fail")
+	}
+
+	noInputs := "on:\n  workflow_dispatch: {}\njobs:\n  deploy:\n    runs-on: ubuntu-latest\n    steps: []\n"
+	if err := ValidateWorkflow([]byte(noInputs), false); err != nil {
+		t.Errorf("non-dispatched workflow: %v", err)
+	}
+	if err := ValidateWorkflow([]byte(noInputs), true); err == nil {
+		t.Error("expected dispatched workflow without rollback inputs to fail")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,504 @@
+package validate
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"src/src/internal/freeze"
+	"src/src/internal/infra"
+	"src/src/internal/model"
+)
+
+// Column sizes from db/scehma.go; longer values would be truncated or
+// rejected by MySQL after the repository was already created.
+const (
+	MaxServiceName     = 150
+	MaxRepoName        = 255
+	MaxRepoPath        = 255
+	MaxOwnerTeam       = 100
+	MaxRuntime         = 50
+	MaxCICDType        = 50
+	MaxTemplateVersion = 50
+	MaxDeployType      = 50
+	MaxVersion         = 255
+	MaxArtifactType    = 20
+	MaxLockReason      = 255
+	MaxFreezeName      = 150
+	MaxFreezeSchedule  = 255
+	MaxFreezeReason    = 255
+	MaxHealthCheckURL  = 2048
+	MaxRunURL          = 2048
+)
+
+// MaxScheduleAhead bounds how far ahead a deployment may be scheduled.
+const MaxScheduleAhead = 30 * 24 * time.Hour
+
+var (
+	Environments         = []string{"dev", "test", "prod"}
+	ArtifactEnvironments = []string{"dev", "test", "pre-prod", "prod"}
+	ExemptionStatuses    = []string{"pending", "approved", "rejected"}
+	Strategies           = []string{model.StrategyAllAtOnce, model.StrategyCanary, model.StrategyBlueGreen}
+	CICDTypes            = []string{"github", "jenkins"}
+	DeployTypes          = []string{model.DeployTypeEC2, model.DeployTypeMicroservice, model.DeployTypeKubernetes}
+	RepoModes            = []string{"", model.RepoModeStandalone, model.RepoModeMonorepo}
+	Visibilities         = []string{"", "private", "internal", "public"}
+	TeamPermissions      = []string{"", "pull", "triage", "push", "maintain", "admin"}
+	Clouds               = []string{"aws"}
+	Computes             = []string{"ec2"}
+	InfraActions         = []string{model.InfraPlan, model.InfraApply}
+)
+
+var (
+	// Service names end up in Jenkins job names, branch-protection contexts
+	// and workflow paths, so keep them DNS-label shaped.
+	serviceNamePattern = regexp.MustCompile(`^[a-z]([a-z0-9-]*[a-z0-9])?$`)
+	// GitHub's own repository name rule.
+	repoNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+	// GitHub user / organization login.
+	ownerPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?$`)
+	// Build versions, e.g. "1.4.0+build.7" or the templates'
+	// "myservice/<service>-<sha>-<n>": '/'-separated, no empty segments.
+	versionPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]*(/[A-Za-z0-9][A-Za-z0-9._+-]*)*$`)
+	commitPattern  = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+	// Platform module versions in template_data/terraform.
+	moduleVersionPattern = regexp.MustCompile(`^v[0-9]+$`)
+	configKeyPattern     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+)
+
+/* ===================== FIELD RULES ===================== */
+
+func (e *Errors) ServiceName(field, v string) {
+	if e.Required(field, v) && e.MaxLen(field, v, MaxServiceName) {
+		e.Match(field, v, serviceNamePattern,
+			"must be lowercase letters, digits and '-', starting with a letter")
+	}
+}
+
+func (e *Errors) RepoName(field, v string) {
+	if !e.Required(field, v) || !e.MaxLen(field, v, MaxRepoName) {
+		return
+	}
+	if v == "." || v == ".." {
+		e.Add(field, "is not a valid repository name")
+		return
+	}
+	e.Match(field, v, repoNamePattern, "must be letters, digits, '.', '_' and '-'")
+}
+
+// Repo accepts "owner/name" or "name".
+func (e *Errors) Repo(field, v string) {
+	owner, name, ok := strings.Cut(v, "/")
+	if !ok {
+		e.RepoName(field, v)
+		return
+	}
+	if !ownerPattern.MatchString(owner) {
+		e.Add(field, "owner %q is not a valid GitHub login", owner)
+	}
+	e.RepoName(field, name)
+}
+
+func (e *Errors) Environment(field, v string, allowed []string) {
+	if e.Required(field, v) {
+		e.OneOf(field, v, allowed...)
+	}
+}
+
+func (e *Errors) Version(field, v string) {
+	if e.Required(field, v) && e.MaxLen(field, v, MaxVersion) {
+		e.Match(field, v, versionPattern, "must be letters, digits, '.', '_', '+' and '-', in segments separated by '/'")
+	}
+}
+
+func (e *Errors) environments(field string, envs []string) {
+	seen := map[string]bool{}
+	for _, env := range envs {
+		if !e.OneOf(field, env, Environments...) {
+			continue
+		}
+		if seen[env] {
+			e.Add(field, "lists %s twice", env)
+		}
+		seen[env] = true
+	}
+}
+
+/* ===================== REQUESTS ===================== */
+
+// CreateService validates a service definition. repoPath is cleaned in
+// place for monorepo requests.
+func CreateService(req *model.CreateServiceRequest) error {
+	var errs Errors
+
+	errs.ServiceName("serviceName", req.ServiceName)
+	if errs.Required("ownerTeam", req.OwnerTeam) {
+		errs.MaxLen("ownerTeam", req.OwnerTeam, MaxOwnerTeam)
+	}
+	if errs.Required("runtime", req.Runtime) {
+		errs.MaxLen("runtime", req.Runtime, MaxRuntime)
+	}
+	if errs.Required("templateVersion", req.TemplateVersion) {
+		errs.MaxLen("templateVersion", req.TemplateVersion, MaxTemplateVersion)
+	}
+	if errs.Required("cicdType", req.CICDType) {
+		errs.OneOf("cicdType", req.CICDType, CICDTypes...)
+	}
+	if errs.Required("deploytype", req.DeployType) {
+		errs.OneOf("deploytype", req.DeployType, DeployTypes...)
+	}
+	if len(req.Environments) == 0 {
+		errs.Add("environments", "is required")
+	}
+	errs.environments("environments", req.Environments)
+
+	// Monorepo mode needs an existing repo and a safe relative path
+	errs.OneOf("repoMode", req.RepoMode, RepoModes...)
+	if req.RepoMode == model.RepoModeMonorepo {
+		if errs.Required("targetRepo", req.TargetRepo) {
+			errs.Repo("targetRepo", req.TargetRepo)
+		}
+		if errs.Required("repoPath", req.RepoPath) && errs.MaxLen("repoPath", req.RepoPath, MaxRepoPath) {
+			clean := path.Clean(req.RepoPath)
+			if path.IsAbs(clean) || clean == "." || strings.HasPrefix(clean, "..") {
+				errs.Add("repoPath", "must be a relative path inside the repository")
+			} else {
+				req.RepoPath = clean
+			}
+		}
+	} else {
+		errs.RepoName("repoName", req.RepoName)
+	}
+
+	// Repository settings
+	if req.Repository.Org != "" && !ownerPattern.MatchString(req.Repository.Org) {
+		errs.Add("repository.org", "is not a valid GitHub organization")
+	}
+	if errs.OneOf("repository.visibility", req.Repository.Visibility, Visibilities...) &&
+		req.Repository.Visibility == "internal" && req.Repository.Org == "" {
+		errs.Add("repository.visibility", "internal requires repository.org")
+	}
+	errs.OneOf("repository.teamPermission", req.Repository.TeamPermission, TeamPermissions...)
+
+	if req.Infra != nil {
+		errs.infra(req.Infra, req.DeployType)
+	}
+
+	return errs.Err()
+}
+
+// infra validates a service's infrastructure, defaulting to two vCPUs and
+// 4Gi on AWS EC2. Only ec2 services run on infrastructure the platform
+// provisions.
+func (e *Errors) infra(spec *model.InfraSpec, deployType string) {
+	if deployType != model.DeployTypeEC2 {
+		e.Add("infra", "only applies to deploytype %s", model.DeployTypeEC2)
+		return
+	}
+
+	defaultString(&spec.Cloud, "aws")
+	defaultString(&spec.Compute, "ec2")
+	defaultString(&spec.CPU, "2")
+	defaultString(&spec.Memory, "4Gi")
+
+	e.OneOf("infra.cloud", spec.Cloud, Clouds...)
+	e.OneOf("infra.compute", spec.Compute, Computes...)
+	if _, err := infra.InstanceType(spec.CPU, spec.Memory); err != nil {
+		e.Add("infra", "%v", err)
+	}
+	e.Match("infra.moduleVersion", spec.ModuleVersion, moduleVersionPattern, "must look like v1")
+}
+
+func ImportService(req *model.ImportServiceRequest) error {
+	var errs Errors
+
+	errs.ServiceName("serviceName", req.ServiceName)
+	if errs.Required("repo", req.Repo) {
+		errs.Repo("repo", req.Repo)
+	}
+	if errs.Required("ownerTeam", req.OwnerTeam) {
+		errs.MaxLen("ownerTeam", req.OwnerTeam, MaxOwnerTeam)
+	}
+	errs.environments("environments", req.Environments)
+	errs.MaxLen("runtime", req.Runtime, MaxRuntime)
+	errs.OneOf("cicdType", req.CICDType, CICDTypes...)
+	errs.OneOf("deploytype", req.DeployType, DeployTypes...)
+
+	return errs.Err()
+}
+
+func ArtifactEvent(req *model.ArtifactEvent) error {
+	var errs Errors
+
+	errs.ServiceName("serviceName", req.ServiceName)
+	if !model.IsPreviewEnvironment(req.Environment) {
+		errs.Environment("environment", req.Environment, ArtifactEnvironments)
+	}
+	errs.Version("version", req.Version)
+	errs.MaxLen("artifactType", req.ArtifactType, MaxArtifactType)
+	errs.Match("commitSha", req.CommitSHA, commitPattern, "must be a 7 to 40 character hex commit SHA")
+	if errs.Required("pipeline", req.Pipeline) {
+		errs.OneOf("pipeline", req.Pipeline, CICDTypes...)
+	}
+	if errs.Required("action", req.Action) {
+		errs.OneOf("action", req.Action, "deploy", "rollback")
+	}
+	if errs.Required("status", req.Status) && req.Status != "success" {
+		errs.Add("status", "only successful pipelines are accepted")
+	}
+
+	return errs.Err()
+}
+
+// Deploy validates a deploy request; scheduledAt is optional.
+func Deploy(environment, reason string, scheduledAt *time.Time) error {
+	var errs Errors
+	errs.Environment("environment", environment, Environments)
+	errs.MaxLen("reason", reason, MaxLockReason)
+	if scheduledAt != nil {
+		if d := time.Until(*scheduledAt); d <= 0 {
+			errs.Add("scheduledAt", "must be in the future")
+		} else if d > MaxScheduleAhead {
+			errs.Add("scheduledAt", "must be within %d days", int(MaxScheduleAhead.Hours()/24))
+		}
+	}
+	return errs.Err()
+}
+
+// Rollback requires a reason for emergency rollbacks, which may override a
+// deploy freeze and are audited.
+func Rollback(environment, version, reason string, emergency bool) error {
+	var errs Errors
+	errs.Environment("environment", environment, Environments)
+	errs.Version("version", version)
+	if emergency && !errs.Required("reason", reason) {
+		return errs.Err()
+	}
+	errs.MaxLen("reason", reason, MaxLockReason)
+	return errs.Err()
+}
+
+// FreezeWindow validates a new window. An empty timezone becomes UTC.
+func FreezeWindow(w *model.FreezeWindow) error {
+	var errs Errors
+
+	if errs.Required("name", w.Name) {
+		errs.MaxLen("name", w.Name, MaxFreezeName)
+	}
+	errs.OneOf("environment", w.Environment, Environments...)
+	errs.MaxLen("ownerTeam", w.OwnerTeam, MaxOwnerTeam)
+	errs.MaxLen("reason", w.Reason, MaxFreezeReason)
+
+	if w.Timezone == "" {
+		w.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(w.Timezone); err != nil {
+		errs.Add("timezone", "is not an IANA time zone")
+	}
+
+	if w.Schedule == "" {
+		// One-off: the bounds are the freeze itself
+		if w.StartsAt == nil {
+			errs.Add("startsAt", "is required without a schedule")
+		}
+		if w.EndsAt == nil {
+			errs.Add("endsAt", "is required without a schedule")
+		}
+		if w.DurationMinutes != 0 {
+			errs.Add("durationMinutes", "only applies to a schedule")
+		}
+	} else if errs.MaxLen("schedule", w.Schedule, MaxFreezeSchedule) {
+		if _, err := freeze.ParseSchedule(w.Schedule); err != nil {
+			errs.Add("schedule", "%v", err)
+		}
+		if w.DurationMinutes < 1 || time.Duration(w.DurationMinutes)*time.Minute > freeze.MaxDuration {
+			errs.Add("durationMinutes", "must be between 1 and %d", int(freeze.MaxDuration/time.Minute))
+		}
+	}
+	if w.StartsAt != nil && w.EndsAt != nil && !w.EndsAt.After(*w.StartsAt) {
+		errs.Add("endsAt", "must be after startsAt")
+	}
+
+	return errs.Err()
+}
+
+func FreezeExemption(e *model.FreezeExemption) error {
+	var errs Errors
+
+	errs.ServiceName("serviceName", e.ServiceName)
+	errs.Environment("environment", e.Environment, Environments)
+	if errs.Required("reason", e.Reason) {
+		errs.MaxLen("reason", e.Reason, MaxFreezeReason)
+	}
+	if e.ExpiresAt.IsZero() {
+		errs.Add("expiresAt", "is required")
+	} else if !e.ExpiresAt.After(time.Now()) {
+		errs.Add("expiresAt", "must be in the future")
+	}
+
+	return errs.Err()
+}
+
+// HealthCheck validates a check definition. Zero numbers take the defaults:
+// expect 200, probe every 10s with a 5s timeout, pass after 5 healthy
+// minutes and fail after 3 failures in a row.
+func HealthCheck(c *model.HealthCheck) error {
+	var errs Errors
+
+	errs.ServiceName("name", c.ServiceName)
+	errs.Environment("env", c.Environment, Environments)
+
+	if errs.Required("url", c.URL) && errs.MaxLen("url", c.URL, MaxHealthCheckURL) {
+		if u, err := url.Parse(c.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs.Add("url", "must be an absolute http(s) URL")
+		}
+	}
+
+	defaultInt(&c.ExpectedStatus, 200)
+	defaultInt(&c.WindowSeconds, 300)
+	defaultInt(&c.IntervalSeconds, 10)
+	defaultInt(&c.TimeoutSeconds, 5)
+	defaultInt(&c.FailureThreshold, 3)
+
+	errs.between("expectedStatus", c.ExpectedStatus, 100, 599)
+	errs.between("windowSeconds", c.WindowSeconds, 10, 3600)
+	errs.between("intervalSeconds", c.IntervalSeconds, 1, c.WindowSeconds)
+	errs.between("timeoutSeconds", c.TimeoutSeconds, 1, min(60, c.IntervalSeconds))
+	errs.between("failureThreshold", c.FailureThreshold, 1, 20)
+
+	return errs.Err()
+}
+
+// MaxCanarySteps bounds a canary's traffic steps.
+const MaxCanarySteps = 10
+
+// DeploymentStrategy validates a strategy. A canary's steps are increasing
+// traffic percentages ending at 100; the other strategies take none.
+func DeploymentStrategy(s *model.DeploymentStrategy) error {
+	var errs Errors
+
+	errs.ServiceName("name", s.ServiceName)
+	errs.Environment("env", s.Environment, Environments)
+	if errs.Required("strategy", s.Strategy) {
+		errs.OneOf("strategy", s.Strategy, Strategies...)
+	}
+
+	switch s.Strategy {
+	case model.StrategyCanary:
+		if len(s.CanarySteps) == 0 || len(s.CanarySteps) > MaxCanarySteps {
+			errs.Add("canarySteps", "must list 1 to %d traffic percentages", MaxCanarySteps)
+			break
+		}
+		prev := 0
+		for _, pct := range s.CanarySteps {
+			if pct <= prev || pct > 100 {
+				errs.Add("canarySteps", "must increase strictly between 1 and 100")
+				prev = -1
+				break
+			}
+			prev = pct
+		}
+		if prev > 0 && prev != 100 {
+			errs.Add("canarySteps", "must end at 100")
+		}
+	default:
+		if len(s.CanarySteps) > 0 {
+			errs.Add("canarySteps", "only applies to canary")
+		}
+	}
+
+	if s.Strategy == model.StrategyAllAtOnce && s.StepIntervalSeconds != 0 {
+		errs.Add("stepIntervalSeconds", "does not apply to all-at-once")
+	} else {
+		errs.between("stepIntervalSeconds", s.StepIntervalSeconds, 0, 86400)
+	}
+
+	return errs.Err()
+}
+
+// InfraRun validates a plan / apply request.
+func InfraRun(req *model.InfraRunRequest) error {
+	var errs Errors
+	errs.Environment("environment", req.Environment, Environments)
+	if errs.Required("action", req.Action) {
+		errs.OneOf("action", req.Action, InfraActions...)
+	}
+	return errs.Err()
+}
+
+// InfraRunResult validates what a plan/apply pipeline reports.
+func InfraRunResult(res *model.InfraRunResult) error {
+	var errs Errors
+	if errs.Required("status", res.Status) {
+		errs.OneOf("status", res.Status, model.InfraSucceeded, model.InfraFailed)
+	}
+	errs.MaxLen("runUrl", res.RunURL, MaxRunURL)
+	return errs.Err()
+}
+
+// Bounds of a service environment's configuration.
+const (
+	MaxConfigKeys    = 200
+	MaxConfigKey     = 128
+	MaxConfigValue   = 4096
+	MaxConfigMessage = 255
+)
+
+// ConfigUpdate validates new configuration for env. Keys land in a JSON
+// file and usually in environment variables, so they are identifier
+// shaped; serviceName and repoUrl belong to the platform. Prod changes
+// default to a pull request, since its branch is protected.
+func ConfigUpdate(env string, u *model.ConfigUpdate) error {
+	var errs Errors
+	errs.Environment("env", env, Environments)
+
+	if len(u.Values) > MaxConfigKeys {
+		errs.Add("values", "must have at most %d keys", MaxConfigKeys)
+	}
+	for k, v := range u.Values {
+		switch {
+		case !configKeyPattern.MatchString(k) || len(k) > MaxConfigKey:
+			errs.Add("values", "key %q must be at most %d letters, digits, '_', '.' or '-' and start with a letter or '_'", k, MaxConfigKey)
+		case k == "serviceName" || k == "repoUrl":
+			errs.Add("values", "key %q is set by the platform", k)
+		case utf8.RuneCountInString(v) > MaxConfigValue:
+			errs.Add("values", "value of %q must be at most %d characters", k, MaxConfigValue)
+		}
+	}
+
+	errs.OneOf("file", u.File, "", model.ConfigFileShared, model.EnvConfigFile(env))
+	if u.Delivery == "" {
+		u.Delivery = model.ConfigCommit
+		if env == "prod" {
+			u.Delivery = model.ConfigPullRequest
+		}
+	}
+	errs.OneOf("delivery", u.Delivery, model.ConfigCommit, model.ConfigPullRequest)
+	errs.MaxLen("message", u.Message, MaxConfigMessage)
+	if u.BaseVersion != nil && *u.BaseVersion < 0 {
+		errs.Add("baseVersion", "must not be negative")
+	}
+
+	return errs.Err()
+}
+
+func (e *Errors) between(field string, v, lo, hi int) {
+	if v < lo || v > hi {
+		e.Add(field, "must be between %d and %d", lo, hi)
+	}
+}
+
+func defaultInt(v *int, def int) {
+	if *v == 0 {
+		*v = def
+	}
+}
+
+func defaultString(v *string, def string) {
+	if *v == "" {
+		*v = def
+	}
+}
@@ -0,0 +1,93 @@
+// Package validate checks API input before it reaches the service layer.
+// Every rule appends to Errors so a caller can report all rejected fields at
+// once instead of failing on the first.
+package validate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError is one rejected field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors collects rejected fields; the zero value is ready to use.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Field + ": " + fe.Message
+	}
+	return "invalid request: " + strings.Join(msgs, "; ")
+}
+
+// Err returns nil when nothing was rejected, so callers can
+// `return errs.Err()`.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *Errors) Add(field, format string, args ...any) {
+	*e = append(*e, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Required reports whether v is set, recording an error when it is not.
+func (e *Errors) Required(field, v string) bool {
+	if strings.TrimSpace(v) == "" {
+		e.Add(field, "is required")
+		return false
+	}
+	return true
+}
+
+// MaxLen rejects values longer than the backing column.
+func (e *Errors) MaxLen(field, v string, n int) bool {
+	if utf8.RuneCountInString(v) > n {
+		e.Add(field, "must be at most %d characters", n)
+		return false
+	}
+	return true
+}
+
+// OneOf rejects values outside allowed. Empty values pass; pair with
+// Required when the field is mandatory.
+func (e *Errors) OneOf(field, v string, allowed ...string) bool {
+	if v == "" {
+		return true
+	}
+	for _, a := range allowed {
+		if v == a {
+			return true
+		}
+	}
+	e.Add(field, "must be one of %s", strings.Join(nonEmpty(allowed), ", "))
+	return false
+}
+
+// Match rejects values that do not match re; hint describes the format.
+func (e *Errors) Match(field, v string, re *regexp.Regexp, hint string) bool {
+	if v != "" && !re.MatchString(v) {
+		e.Add(field, "%s", hint)
+		return false
+	}
+	return true
+}
+
+func nonEmpty(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
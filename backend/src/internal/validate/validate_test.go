@@ -0,0 +1,237 @@
+package validate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"src/src/internal/model"
+)
+
+func validCreate() model.CreateServiceRequest {
+	return model.CreateServiceRequest{
+		ServiceName:     "orders",
+		RepoName:        "orders",
+		OwnerTeam:       "payments",
+		Runtime:         "go",
+		CICDType:        "github",
+		TemplateVersion: "v1",
+		DeployType:      "microservice",
+		Environments:    []string{"dev", "prod"},
+	}
+}
+
+func fields(err error) []string {
+	var errs Errors
+	if !errors.As(err, &errs) {
+		return nil
+	}
+	out := make([]string, len(errs))
+	for i, fe := range errs {
+		out[i] = fe.Field
+	}
+	return out
+}
+
+func TestCreateService(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(r *model.CreateServiceRequest)
+		want   []string
+	}{
+		{"valid", func(r *model.CreateServiceRequest) {}, nil},
+		{"uppercase name", func(r *model.CreateServiceRequest) { r.ServiceName = "Orders" }, []string{"serviceName"}},
+		{"trailing dash", func(r *model.CreateServiceRequest) { r.ServiceName = "orders-" }, []string{"serviceName"}},
+		{"name too long", func(r *model.CreateServiceRequest) { r.ServiceName = "a" + strings.Repeat("b", MaxServiceName) }, []string{"serviceName"}},
+		{"repo with slash", func(r *model.CreateServiceRequest) { r.RepoName = "org/orders" }, []string{"repoName"}},
+		{"unknown cicd", func(r *model.CreateServiceRequest) { r.CICDType = "gitlab" }, []string{"cicdType"}},
+		{"duplicate env", func(r *model.CreateServiceRequest) { r.Environments = []string{"dev", "dev"} }, []string{"environments"}},
+		{"no envs", func(r *model.CreateServiceRequest) { r.Environments = nil }, []string{"environments"}},
+		{"internal without org", func(r *model.CreateServiceRequest) { r.Repository.Visibility = "internal" }, []string{"repository.visibility"}},
+		{"monorepo escapes", func(r *model.CreateServiceRequest) {
+			r.RepoMode, r.TargetRepo, r.RepoPath = model.RepoModeMonorepo, "acme/platform", "../etc"
+		}, []string{"repoPath"}},
+		{"several at once", func(r *model.CreateServiceRequest) { r.ServiceName, r.Runtime = "", "" }, []string{"serviceName", "runtime"}},
+		{"infra on ec2", func(r *model.CreateServiceRequest) {
+			r.DeployType, r.Infra = model.DeployTypeEC2, &model.InfraSpec{CPU: "4", Memory: "16Gi", ModuleVersion: "v1"}
+		}, nil},
+		{"infra on microservice", func(r *model.CreateServiceRequest) { r.Infra = &model.InfraSpec{} }, []string{"infra"}},
+		{"infra too large", func(r *model.CreateServiceRequest) {
+			r.DeployType, r.Infra = model.DeployTypeEC2, &model.InfraSpec{CPU: "64", Memory: "4Gi"}
+		}, []string{"infra"}},
+		{"infra unknown cloud", func(r *model.CreateServiceRequest) {
+			r.DeployType, r.Infra = model.DeployTypeEC2, &model.InfraSpec{Cloud: "gcp", ModuleVersion: "latest"}
+		}, []string{"infra.cloud", "infra.moduleVersion"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validCreate()
+			tt.mutate(&req)
+
+			got := fields(CreateService(&req))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("rejected %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateServiceCleansRepoPath(t *testing.T) {
+	req := validCreate()
+	req.RepoMode, req.TargetRepo, req.RepoPath = model.RepoModeMonorepo, "platform", "services/orders/"
+
+	if err := CreateService(&req); err != nil {
+		t.Fatal(err)
+	}
+	if req.RepoPath != "services/orders" {
+		t.Errorf("repoPath = %q", req.RepoPath)
+	}
+}
+
+func TestCreateServiceDefaultsInfra(t *testing.T) {
+	req := validCreate()
+	req.DeployType, req.Infra = model.DeployTypeEC2, &model.InfraSpec{}
+
+	if err := CreateService(&req); err != nil {
+		t.Fatal(err)
+	}
+	if want := (model.InfraSpec{Cloud: "aws", Compute: "ec2", CPU: "2", Memory: "4Gi"}); *req.Infra != want {
+		t.Errorf("infra = %+v, want %+v", *req.Infra, want)
+	}
+}
+
+func TestArtifactEvent(t *testing.T) {
+	ev := model.ArtifactEvent{
+		ServiceName: "orders", Environment: "pre-prod", Version: "1.4.0+build.7",
+		CommitSHA: "3f2a9c1", Pipeline: "jenkins", Action: "rollback", Status: "success",
+	}
+	if err := ArtifactEvent(&ev); err != nil {
+		t.Fatalf("valid event rejected: %v", err)
+	}
+
+	ev.Environment = "pr-12"
+	if err := ArtifactEvent(&ev); err != nil {
+		t.Fatalf("preview event rejected: %v", err)
+	}
+
+	// What the shipped GitHub workflow and Jenkinsfile report
+	for _, v := range []string{"myservive/orders-3f2a9c1-4821", "myservice/orders-3f2a9c1-4821"} {
+		ev.Version = v
+		if err := ArtifactEvent(&ev); err != nil {
+			t.Errorf("template version %q rejected: %v", v, err)
+		}
+	}
+
+	ev.Environment, ev.Status, ev.Version = "pr-012", "failed", "1.4.0 beta"
+	if got := fields(ArtifactEvent(&ev)); strings.Join(got, ",") != "environment,version,status" {
+		t.Errorf("rejected %v", got)
+	}
+}
+
+func TestFreezeWindow(t *testing.T) {
+	start := time.Date(2024, 12, 20, 0, 0, 0, 0, time.UTC)
+	end, before := start.Add(24*time.Hour), start.Add(-time.Hour)
+
+	tests := []struct {
+		name string
+		w    model.FreezeWindow
+		want []string
+	}{
+		{"recurring", model.FreezeWindow{Name: "weekends", Schedule: "0 18 * * FRI", DurationMinutes: 3720}, nil},
+		{"one-off", model.FreezeWindow{Name: "holidays", StartsAt: &start, EndsAt: &end}, nil},
+		{"one-off reversed", model.FreezeWindow{Name: "holidays", StartsAt: &start, EndsAt: &before}, []string{"endsAt"}},
+		{"one-off missing bounds", model.FreezeWindow{Name: "holidays"}, []string{"startsAt", "endsAt"}},
+		{"no duration", model.FreezeWindow{Name: "weekends", Schedule: "0 18 * * FRI"}, []string{"durationMinutes"}},
+		{"bad schedule and zone", model.FreezeWindow{Name: "x", Schedule: "FREQ=HOURLY", DurationMinutes: 60, Timezone: "Mars/Olympus"}, []string{"timezone", "schedule"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fields(FreezeWindow(&tt.w))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("rejected %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmergencyRollbackNeedsReason(t *testing.T) {
+	if got := fields(Rollback("prod", "1.2.0", "", true)); strings.Join(got, ",") != "reason" {
+		t.Errorf("rejected %v", got)
+	}
+	if err := Rollback("prod", "1.2.0", "", false); err != nil {
+		t.Errorf("plain rollback rejected: %v", err)
+	}
+	if err := Rollback("prod", "myservice/orders-3f2a9c1-4821", "", false); err != nil {
+		t.Errorf("rollback to a template version rejected: %v", err)
+	}
+	for _, v := range []string{"/orders", "myservice/", "myservice//orders", "../orders"} {
+		if got := fields(Rollback("prod", v, "", false)); strings.Join(got, ",") != "version" {
+			t.Errorf("version %q: rejected %v", v, got)
+		}
+	}
+}
+
+func TestDeploymentStrategy(t *testing.T) {
+	tests := []struct {
+		name string
+		s    model.DeploymentStrategy
+		want []string
+	}{
+		{"all at once", model.DeploymentStrategy{Strategy: model.StrategyAllAtOnce}, nil},
+		{"canary", model.DeploymentStrategy{Strategy: model.StrategyCanary, CanarySteps: []int{10, 50, 100}, StepIntervalSeconds: 600}, nil},
+		{"blue-green", model.DeploymentStrategy{Strategy: model.StrategyBlueGreen}, nil},
+		{"canary without steps", model.DeploymentStrategy{Strategy: model.StrategyCanary}, []string{"canarySteps"}},
+		{"canary not ending at 100", model.DeploymentStrategy{Strategy: model.StrategyCanary, CanarySteps: []int{10, 50}}, []string{"canarySteps"}},
+		{"canary decreasing", model.DeploymentStrategy{Strategy: model.StrategyCanary, CanarySteps: []int{50, 10, 100}}, []string{"canarySteps"}},
+		{"steps on blue-green", model.DeploymentStrategy{Strategy: model.StrategyBlueGreen, CanarySteps: []int{100}}, []string{"canarySteps"}},
+		{"interval on all at once", model.DeploymentStrategy{Strategy: model.StrategyAllAtOnce, StepIntervalSeconds: 60}, []string{"stepIntervalSeconds"}},
+		{"unknown", model.DeploymentStrategy{Strategy: "rolling"}, []string{"strategy"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.s.ServiceName, tt.s.Environment = "orders", "prod"
+			got := fields(DeploymentStrategy(&tt.s))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("rejected %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigUpdate(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		u    model.ConfigUpdate
+		want []string
+	}{
+		{"env file", "dev", model.ConfigUpdate{Values: map[string]string{"LOG_LEVEL": "debug", "db.pool-size": "10"}, File: "config.dev.json"}, nil},
+		{"shared file", "test", model.ConfigUpdate{Values: map[string]string{"FEATURE_X": "on"}, File: "config.json"}, nil},
+		{"empty", "dev", model.ConfigUpdate{}, nil},
+		{"other env's file", "dev", model.ConfigUpdate{File: "config.prod.json"}, []string{"file"}},
+		{"bad key", "dev", model.ConfigUpdate{Values: map[string]string{"1X": "a"}}, []string{"values"}},
+		{"reserved key", "dev", model.ConfigUpdate{Values: map[string]string{"serviceName": "x"}}, []string{"values"}},
+		{"long value", "dev", model.ConfigUpdate{Values: map[string]string{"X": strings.Repeat("a", MaxConfigValue+1)}}, []string{"values"}},
+		{"unknown delivery", "dev", model.ConfigUpdate{Delivery: "email"}, []string{"delivery"}},
+		{"unknown env", "qa", model.ConfigUpdate{}, []string{"env"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fields(ConfigUpdate(tt.env, &tt.u))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("rejected %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigUpdateDefaultsDelivery(t *testing.T) {
+	for env, want := range map[string]string{"dev": model.ConfigCommit, "prod": model.ConfigPullRequest} {
+		u := model.ConfigUpdate{}
+		if err := ConfigUpdate(env, &u); err != nil || u.Delivery != want {
+			t.Errorf("%s: delivery = %q, %v; want %q", env, u.Delivery, err, want)
+		}
+	}
+}
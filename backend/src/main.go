@@ -1,42 +1,82 @@
 package main
 
 import (
+	"context"
 	"log"
-	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
+	"src/src/internal/argocd"
+	"src/src/internal/cicd"
+	"src/src/internal/config"
 	"src/src/internal/db"
+	"src/src/internal/deploy"
+	"src/src/internal/git"
+	"src/src/internal/gitops"
 	"src/src/internal/handler"
-	"strings"
+	"src/src/internal/infra"
+	"src/src/internal/locks"
+	"src/src/internal/notify"
+	"src/src/internal/preview"
+	"src/src/internal/scheduler"
+	"src/src/internal/secrets"
+	"src/src/internal/service"
+	"src/src/internal/svcconfig"
+	"src/src/internal/templates"
 )
 
 func main() {
-	db.InitMySQL()
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+
+	if err := secrets.Init(cfg.Secrets); err != nil {
+		log.Fatal("❌ Secrets initialization failed:", err)
+	}
+
+	tokens, err := git.NewTokenSource(cfg.GitHub)
+	if err != nil {
+		log.Fatal("❌ GitHub auth initialization failed:", err)
+	}
+
+	templates.SetDispatchWorkflow(cfg.GitHub.DispatchWorkflow)
+	cicd.Configure(cfg.Jenkins)
+	service.Configure(cfg, tokens)
+	locks.Configure(cfg.Deployments)
+	scheduler.Configure(cfg.Deployments)
+	notify.Configure(cfg.Notify)
+	gitops.Configure(cfg.Kubernetes, cfg.Workspace, tokens)
+	argocd.Configure(cfg.ArgoCD)
+	infra.Configure(cfg.Terraform, cfg.Platform, tokens)
+	svcconfig.Configure(cfg.Workspace, tokens)
+	preview.Configure(cfg.Previews, tokens)
+	deploy.SetReporter(handler.RecordArtifact)
+	deploy.SetTokenSource(tokens)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := db.InitMySQL(ctx, cfg.Database); err != nil {
+		log.Fatal("❌ Database initialization failed:", err)
+	}
 	if err := db.EnsureSchema(); err != nil {
 		log.Fatal("❌ Database schema initialization failed:", err)
 	}
-	
-	http.HandleFunc("/create-service", handler.CreateService)
-	http.HandleFunc("/services", handler.GetServices)
-	http.HandleFunc("/services/", handler.DeployService)
-	http.HandleFunc("/artifacts", handler.RegisterArtifact)
-	http.HandleFunc("/servicesdashboard/", handler.GetServiceDashboard)
-	http.HandleFunc("/service-by-env/", handler.GetServiceEnvironments)
-	http.HandleFunc("/artifact-by-env/", handler.GetServiceArtifacts)
-	http.HandleFunc("/deploy-services/", handler.DeployServices)
-	http.HandleFunc("/rollback-services/", handler.RollbackService)
-	http.HandleFunc("/approvals", handler.GetApprovals)
-	http.HandleFunc("/approvals/", func(w http.ResponseWriter, r *http.Request) {
-		if strings.HasSuffix(r.URL.Path, "/approve") {
-			handler.ApproveDeployment(w, r)
-			return
-		}
-		if strings.HasSuffix(r.URL.Path, "/reject") {
-			handler.RejectDeployment(w, r)
-			return
-		}
-		http.NotFound(w, r)
-	})
-
-	log.Println("🚀 Server started on :8080")
-	log.Fatal(http.ListenAndServe(":8080", nil))
+
+	go scheduler.Run(ctx)
+
+	handler.SetReadinessChecks(cfg.Server.Readiness.Timeout, readinessChecks(cfg.Server.Readiness, tokens)...)
+	if err := handler.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatal("❌ ", err)
+	}
+
+	if err := serve(ctx, cfg.Server, handler.NewRouter()); err != nil {
+		log.Fatal("❌ Server failed:", err)
+	}
 }
\ No newline at end of file
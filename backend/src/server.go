@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+
+	"src/src/internal/cicd"
+	"src/src/internal/config"
+	"src/src/internal/db"
+	"src/src/internal/git"
+	"src/src/internal/handler"
+	"src/src/internal/secrets"
+)
+
+// readinessChecks lists what /readyz probes: MySQL and the secrets backend
+// always, GitHub and Jenkins when enabled in the config.
+func readinessChecks(cfg config.Readiness, tokens git.TokenSource) []handler.ReadinessCheck {
+	checks := []handler.ReadinessCheck{
+		{Name: "mysql", Probe: db.Ping},
+		{Name: "secrets", Probe: secrets.Ping},
+	}
+	if cfg.GitHub {
+		checks = append(checks, handler.ReadinessCheck{Name: "github", Probe: func(ctx context.Context) error {
+			return git.Ping(ctx, tokens)
+		}})
+	}
+	if cfg.Jenkins {
+		checks = append(checks, handler.ReadinessCheck{Name: "jenkins", Probe: cicd.PingJenkins})
+	}
+	return checks
+}
+
+// serve runs the HTTP server until ctx is cancelled (SIGTERM/SIGINT), then
+// fails readiness and waits up to ShutdownTimeout for in-flight requests —
+// provisioning runs inside the request — to finish.
+func serve(ctx context.Context, cfg config.Server, h http.Handler) error {
+	ln, err := net.Listen("tcp", cfg.Addr)
+	if err != nil {
+		return err
+	}
+	return serveListener(ctx, ln, cfg, h)
+}
+
+// serveListener is serve on an open listener (tests listen on port 0).
+func serveListener(ctx context.Context, ln net.Listener, cfg config.Server, h http.Handler) error {
+	srv := &http.Server{
+		Handler:           h,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Println("🚀 Server started on", ln.Addr())
+		errCh <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("🛑 Shutdown requested, draining in-flight requests (up to %s)", cfg.ShutdownTimeout)
+	handler.StartDraining()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	log.Println("👋 Server stopped")
+	return nil
+}
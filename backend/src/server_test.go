@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"src/src/internal/config"
+	"src/src/internal/handler"
+)
+
+// testServer runs serveListener on a free port. /slow stands in for a
+// provisioning request: it holds until release is closed.
+type testServer struct {
+	addr    string
+	stop    context.CancelFunc // SIGTERM
+	started chan struct{}
+	release chan struct{}
+	done    chan error
+}
+
+func startServer(t *testing.T, shutdownTimeout time.Duration) *testServer {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &testServer{
+		addr:    "http://" + ln.Addr().String(),
+		started: make(chan struct{}, 1),
+		release: make(chan struct{}),
+		done:    make(chan error, 1),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		s.started <- struct{}{}
+		<-s.release
+		io.WriteString(w, "provisioned")
+	})
+
+	cfg := config.Default().Server
+	cfg.ShutdownTimeout = shutdownTimeout
+
+	var ctx context.Context
+	ctx, s.stop = context.WithCancel(context.Background())
+	go func() { s.done <- serveListener(ctx, ln, cfg, mux) }()
+
+	return s
+}
+
+// inFlight starts GET /slow and returns once the handler is running.
+func (s *testServer) inFlight(t *testing.T) chan *http.Response {
+	t.Helper()
+
+	res := make(chan *http.Response, 1)
+	go func() {
+		resp, err := http.Get(s.addr + "/slow")
+		if err != nil {
+			t.Errorf("in-flight request: %v", err)
+		}
+		res <- resp
+	}()
+
+	select {
+	case <-s.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("request never reached the handler")
+	}
+	return res
+}
+
+func TestServeDrainsInFlightRequests(t *testing.T) {
+	s := startServer(t, 5*time.Second)
+	res := s.inFlight(t)
+
+	s.stop()
+
+	// Readiness fails so the load balancer stops routing here, while the
+	// request already in flight keeps running
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		rec := httptest.NewRecorder()
+		handler.Readyz(rec, httptest.NewRequest("GET", "/readyz", nil))
+		if rec.Code == http.StatusServiceUnavailable {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("/readyz still %d after shutdown started", rec.Code)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case err := <-s.done:
+		t.Fatalf("server stopped with a request in flight: %v", err)
+	default:
+	}
+
+	close(s.release)
+
+	resp := <-res
+	if resp == nil {
+		t.FailNow()
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "provisioned" {
+		t.Errorf("in-flight request got %d %q", resp.StatusCode, body)
+	}
+
+	select {
+	case err := <-s.done:
+		if err != nil {
+			t.Errorf("serve: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop after draining")
+	}
+}
+
+func TestServeGivesUpAfterShutdownTimeout(t *testing.T) {
+	s := startServer(t, 100*time.Millisecond)
+	defer close(s.release)
+	s.inFlight(t)
+
+	s.stop()
+
+	select {
+	case err := <-s.done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("serve = %v, want the shutdown deadline", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server ignored the shutdown timeout")
+	}
+}
@@ -45,7 +45,15 @@ export default function  CreateServiceForm ()  {
 
       if (!res.ok) {
         const text = await res.text()
-        throw new Error(text || "API error")
+        let message = text
+        try {
+          // { code, message, details: [{ field, message }], requestId }
+          const body = JSON.parse(text)
+          const fields = (body.details || []).map((d) => `${d.field} ${d.message}`)
+          message = [body.message, ...fields].join("; ")
+          if (body.requestId) message += ` (request ${body.requestId})`
+        } catch {}
+        throw new Error(message || "API error")
       }
 
       const data = await res.json()
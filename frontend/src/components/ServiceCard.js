@@ -171,6 +171,21 @@ export default function ServiceCard({ serviceName, dashboard }) {
               ?.currentVersion || "N/A"}
           </p>
 
+          {/* ARGO CD (microservices only) */}
+          {dashboard.environments[selectedEnv]?.argocd && (
+            <p>
+              <strong>Argo CD:</strong>{" "}
+              {dashboard.environments[selectedEnv].argocd.syncStatus}
+              {" / "}
+              {dashboard.environments[selectedEnv].argocd.healthStatus}
+              {dashboard.environments[selectedEnv].argocd.revision
+                ? ` @ ${dashboard.environments[
+                    selectedEnv
+                  ].argocd.revision.slice(0, 7)}`
+                : ""}
+            </p>
+          )}
+
           <select
             value={selectedVersion}
             disabled={loadingArtifacts}